@@ -0,0 +1,272 @@
+package alert
+
+import (
+	"path/filepath"
+	"stock-go/stockData"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stockWithPrices 按收盘价构造票票数据（工作日连续）
+func stockWithPrices(code string, prices []float32) *stockData.StockInfo {
+	stock := &stockData.StockInfo{Code: code, Name: "名称" + code[len(code)-1:]}
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	for i, p := range prices {
+		for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			date = date.AddDate(0, 0, 1)
+		}
+		stock.Datas.DayDatas = append(stock.Datas.DayDatas, &stockData.StockDataDay{
+			Index:      i + 1,
+			DataStr:    date.Format("2006-01-02"),
+			PriceA:     p,
+			PriceBegin: p,
+			PriceEnd:   p,
+			PriceHigh:  p * 1.01,
+			PriceLow:   p * 0.99,
+		})
+		date = date.AddDate(0, 0, 1)
+	}
+	return stock
+}
+
+// ramp 从 from 线性变化到 to 的 n 个价格
+func ramp(from, to float32, n int) []float32 {
+	prices := make([]float32, n)
+	for i := range prices {
+		prices[i] = from + (to-from)*float32(i)/float32(n-1)
+	}
+	return prices
+}
+
+// appendBar 追加一根K线（下一个工作日）
+func appendBar(stock *stockData.StockInfo, p float32) {
+	bars := stock.Datas.DayDatas
+	date, _ := time.Parse("2006-01-02", bars[len(bars)-1].DataStr)
+	date = date.AddDate(0, 0, 1)
+	for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		date = date.AddDate(0, 0, 1)
+	}
+	stock.Datas.DayDatas = append(bars, &stockData.StockDataDay{
+		Index: len(bars) + 1, DataStr: date.Format("2006-01-02"),
+		PriceA: p, PriceBegin: p, PriceEnd: p, PriceHigh: p * 1.01, PriceLow: p * 0.99,
+	})
+}
+
+// TestRules 测试各规则只在最新K线满足条件时触发
+func TestRules(t *testing.T) {
+	rising := stockWithPrices("sz.000001", ramp(10, 12, 30))
+	flat := stockWithPrices("sz.000002", append(ramp(12, 10, 29), 11))
+
+	if _, ok := NewHighRule(20).Check(rising); !ok {
+		t.Error("持续上涨应创20日新高")
+	}
+	if _, ok := NewHighRule(20).Check(flat); ok {
+		t.Error("下跌后反弹未超过前高，不应创新高")
+	}
+	if _, ok := NewHighRule(40).Check(rising); ok {
+		t.Error("数据不足时不应触发")
+	}
+
+	stop := NewStopLevelRule([]Holding{{Code: "sz.000002", Cost: 12, StopPercent: 0.08}})
+	if detail, ok := stop.Check(flat); !ok || !strings.Contains(detail, "止损价 11.04") {
+		t.Errorf("最低价低于止损价应触发: %q", detail)
+	}
+	if _, ok := stop.Check(rising); ok {
+		t.Error("不在关注列表中的票票不应触发")
+	}
+
+	rule, err := NewExprRule("close > ma(close, 5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rule.Check(rising); !ok {
+		t.Error("表达式条件满足时应触发")
+	}
+	if _, err := NewExprRule("ma(close, 5)"); err == nil {
+		t.Error("数值表达式应返回错误")
+	}
+
+	// 上涨到15后回落到11（区间），再涨回接近15，最后一天突破区间高点
+	prices := append(append(ramp(10, 15, 30), ramp(14.8, 11, 30)...), ramp(11.2, 15, 30)...)
+	section := stockWithPrices("sh.600000", prices)
+	if _, ok := NewSectionHighRule().Check(section); ok {
+		t.Error("尚未突破区间高点")
+	}
+	appendBar(section, 15.5)
+	if detail, ok := NewSectionHighRule().Check(section); !ok {
+		t.Error("突破区间高点应触发")
+	} else if !strings.Contains(detail, "区间高点 15.15") {
+		t.Errorf("说明 %q", detail)
+	}
+	appendBar(section, 16)
+	if _, ok := NewSectionHighRule().Check(section); ok {
+		t.Error("只在突破当天触发")
+	}
+
+	// 长期下跌后最后一天创500日最高，之前30天都不是最高
+	high := stockWithPrices("sh.600001", ramp(20, 10, 559))
+	if _, ok := NewHighPointRule().Check(high); ok {
+		t.Error("下跌中不应触发高点策略")
+	}
+	appendBar(high, 25)
+	if detail, ok := NewHighPointRule().Check(high); !ok || detail != "收盘 25.00 为区间最高" {
+		t.Errorf("创区间最高应触发: %q", detail)
+	}
+	appendBar(high, 26)
+	if _, ok := NewHighPointRule().Check(high); ok {
+		t.Error("之前30天已经是最高时不应触发")
+	}
+	if err := HighPointConfig().Validate(); err != nil {
+		t.Errorf("高点策略默认配置 %v", err)
+	}
+}
+
+// TestParseConfig 测试配置校验
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"rules": [{"name": "新高", "type": "newHigh", "days": 20}, {"name": "每日", "type": "sectionHigh", "cooldownDays": 0}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Cooldown(cfg.Rules[0]) != 5 || cfg.Cooldown(cfg.Rules[1]) != 0 || cfg.HistoryDays != 365 {
+		t.Errorf("冷却期默认值不正确: %+v", cfg)
+	}
+
+	cases := []struct{ src, want string }{
+		{`{"rules": []}`, "没有配置预警规则"},
+		{`{"rules": [{"name": "a", "type": "newHigh", "days": 20}, {"name": "a", "type": "sectionHigh"}]}`, "重复"},
+		{`{"rules": [{"name": "a", "type": "newhigh"}]}`, "未知的规则类型"},
+		{`{"rules": [{"name": "a", "type": "newHigh"}]}`, "days 应至少为2"},
+		{`{"rules": [{"name": "a", "type": "stopLevel", "holdings": [{"code": "sz.000001"}]}]}`, "需要设置 code 和 stop"},
+		{`{"rules": [{"name": "a", "type": "expr", "expression": "close >"}]}`, "规则 a: 表达式"},
+		{`{"historyDays": 30, "scorecardDays": 30, "rules": [{"name": "a", "type": "sectionHigh", "cooldownDays": 100}]}`, "超过历史保留天数"},
+		{`{"scorecardWeekday": 7, "rules": [{"name": "a", "type": "sectionHigh"}]}`, "scorecardWeekday"},
+		{`{"rules": [{"name": "a", "type": "sectionHigh", "cooldown": 1}]}`, `unknown field "cooldown"`},
+	}
+	for _, c := range cases {
+		if _, err := ParseConfig([]byte(c.src)); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: 错误 %v，期望包含 %q", c.src, err, c.want)
+		}
+	}
+}
+
+// TestExampleConfigs 测试 conf/alerts 下的示例配置都能通过校验
+func TestExampleConfigs(t *testing.T) {
+	paths, _ := filepath.Glob("../conf/alerts/*.json")
+	if len(paths) == 0 {
+		t.Skip("没有示例配置")
+	}
+	for _, path := range paths {
+		if _, err := LoadConfig(path); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// TestEngine 测试重复运行去重、冷却期、新增和持续的区分，以及历史的保存和读取
+func TestEngine(t *testing.T) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": stockWithPrices("sz.000001", ramp(10, 12, 30)),
+		"sz.000002": stockWithPrices("sz.000002", ramp(10, 13, 30)),
+		"sh.600000": stockWithPrices("sh.600000", ramp(12, 10, 30)),
+	}
+	// 停牌的票票不检查
+	stale := stockWithPrices("sz.000003", ramp(10, 12, 29))
+	data["sz.000003"] = stale
+
+	src := `{"historyFile": "` + filepath.ToSlash(filepath.Join(t.TempDir(), "history.json")) + `", "rules": [
+		{"name": "20日新高", "type": "newHigh", "days": 20, "cooldownDays": 0},
+		{"name": "10日新高", "type": "newHigh", "days": 10, "cooldownDays": 30}
+	]}`
+	cfg, err := ParseConfig([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewEngine(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day1 := engine.Evaluate(data)
+	if total, added := day1.Count(); total != 4 || added != 4 || len(day1.Groups) != 2 {
+		t.Fatalf("第一天预警 %d 条，新增 %d 条", total, added)
+	}
+	if got := day1.Groups[0].Alerts; got[0].Code != "sz.000001" || got[1].Code != "sz.000002" || got[0].Name != "名称1" {
+		t.Errorf("预警 %+v", got)
+	}
+	if err := engine.Record(day1); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一天重复运行不重复发送
+	again, _ := NewEngine(cfg)
+	if d := again.Evaluate(data); !d.Empty() || d.Duplicates != 4 {
+		t.Errorf("重复运行应全部去重: %+v", d)
+	}
+
+	// 两周后：sz.000001 继续新高，sz.000002 回落，sh.600000 反弹创10日新高（未创20日新高）
+	for i := 0; i < 10; i++ {
+		appendBar(data["sz.000001"], 12.5+float32(i)*0.1)
+		appendBar(data["sz.000002"], 12)
+		appendBar(data["sh.600000"], 9.5)
+	}
+	appendBar(data["sz.000001"], 14)
+	appendBar(data["sz.000002"], 12)
+	appendBar(data["sh.600000"], 10.5)
+	day2 := again.Evaluate(data)
+	if day2.Previous != day1.Date {
+		t.Fatalf("上一个检查日 %s，期望 %s", day2.Previous, day1.Date)
+	}
+	g20, g10 := day2.Groups[0], day2.Groups[1]
+	// 冷却期0：每天都预警，上一个检查日也预警过，标为持续
+	if len(g20.Alerts) != 1 || g20.Alerts[0].Code != "sz.000001" || g20.Alerts[0].New {
+		t.Fatalf("20日新高 %+v", g20)
+	}
+	if g10.Cooling != 1 || len(g10.Alerts) != 1 || g10.Alerts[0].Code != "sh.600000" || !g10.Alerts[0].New {
+		t.Errorf("10日新高 %+v，sz.000001 应在冷却期内", g10)
+	}
+	msg := day2.Message()
+	if msg.Title != "预警 "+day2.Date+"：2 条，新增 1 条" || !strings.Contains(msg.Content, "相对 "+day1.Date) ||
+		!strings.Contains(msg.Content, "【20日新高】1 条，新增 0\n[续] sz.000001 名称1 收盘 14.00 创20日新高") ||
+		!strings.Contains(msg.Content, "【10日新高】1 条，新增 1，冷却中 1\n[新] sh.600000 名称0 收盘 10.50") {
+		t.Errorf("汇总消息:\n%s\n%s", msg.Title, msg.Content)
+	}
+	if err := again.Record(day2); err != nil {
+		t.Fatal(err)
+	}
+
+	// 下一天没有预警，检查日仍然记录：之后 sz.000001 再创新高时算新增
+	appendBar(data["sz.000001"], 13)
+	appendBar(data["sz.000002"], 12)
+	appendBar(data["sh.600000"], 10)
+	third, _ := NewEngine(cfg)
+	day3 := third.Evaluate(data)
+	if !day3.Empty() {
+		t.Fatalf("不应有预警: %+v", day3)
+	}
+	third.Record(day3)
+	appendBar(data["sz.000001"], 15)
+	appendBar(data["sz.000002"], 12)
+	appendBar(data["sh.600000"], 10)
+	if a := third.Evaluate(data).Groups[0].Alerts; len(a) != 1 || !a[0].New {
+		t.Errorf("中断后再次预警应为新增: %+v", a)
+	}
+}
+
+// TestHistoryPrune 测试历史只保留最近的记录
+func TestHistoryPrune(t *testing.T) {
+	h := &History{path: filepath.Join(t.TempDir(), "a", "history.json")}
+	h.Add("2024-01-02", Record{Rule: "r", Code: "c", Date: "2024-01-02"})
+	h.Add("2024-03-01", Record{Rule: "r", Code: "c", Date: "2024-03-01"})
+	h.Prune("2024-03-01", 30)
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadHistory(h.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Records) != 1 || len(loaded.Dates) != 1 || loaded.Last("r", "c").Date != "2024-03-01" {
+		t.Errorf("历史 %+v", loaded)
+	}
+}
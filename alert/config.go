@@ -0,0 +1,168 @@
+// Package alert 预警规则
+//
+// 每日收盘后用配置的规则（N日新高、区间高点突破、高点策略、持仓止损、条件表达式）检查票票的最新K线，
+// 同一规则同一票票在冷却期内不重复预警，已发送的预警保存在历史文件中（重复运行也不会重复发送）。
+// 当天的预警按规则分组汇总成一条消息，并标出相对上一个交易日新增的票票。
+// 历史中的预警之后会按 1/5/10/20 日收益、最大有利/不利波动和胜率统计效果，定期汇总成各规则的评分。
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// 规则类型
+const (
+	TypeNewHigh     = "newHigh"
+	TypeSectionHigh = "sectionHigh"
+	TypeHighPoint   = "highPoint" // 高点策略
+	TypeStopLevel   = "stopLevel"
+	TypeExpr        = "expr"
+)
+
+// TypeStrategy 策略扫描的买入信号，不经过规则检查、直接追加到预警历史
+const TypeStrategy = "strategy"
+
+// HighPointRule 默认规则（高点策略）的名称
+const HighPointRule = "发现高点"
+
+// Config 预警配置
+type Config struct {
+	HistoryFile  string       `json:"historyFile"`  // 预警历史文件，为空时使用数据目录下的 alertHistory.json
+	HistoryDays  int          `json:"historyDays"`  // 历史保留天数，也是评估预警效果的最长回看范围
+	CooldownDays int          `json:"cooldownDays"` // 规则未设置冷却期时的默认值（自然日）
+	Rules        []RuleConfig `json:"rules"`
+
+	ScorecardWeekday int `json:"scorecardWeekday"` // 每周发送预警效果评分的日子，0 为周日，-1 表示不发送
+	ScorecardDays    int `json:"scorecardDays"`    // 评分统计最近多少天的预警
+}
+
+// RuleConfig 规则配置
+type RuleConfig struct {
+	Name         string    `json:"name"`                   // 规则名称，汇总中按名称分组，不能重复
+	Type         string    `json:"type"`                   // newHigh、sectionHigh、highPoint、stopLevel、expr
+	Days         int       `json:"days,omitempty"`         // newHigh：N日
+	Expression   string    `json:"expression,omitempty"`   // expr：条件表达式
+	Holdings     []Holding `json:"holdings,omitempty"`     // stopLevel：关注的持仓
+	CooldownDays *int      `json:"cooldownDays,omitempty"` // 冷却期（自然日），预警后这些天内同一票票不再预警，0表示每天都预警
+}
+
+// DefaultConfig 默认配置（没有规则）
+func DefaultConfig() *Config {
+	return &Config{HistoryDays: 365, CooldownDays: 5, ScorecardWeekday: int(time.Friday), ScorecardDays: 90}
+}
+
+// HighPointConfig 默认配置加上高点策略规则，每日分析没有配置预警规则和策略时使用
+func HighPointConfig() *Config {
+	cfg := DefaultConfig()
+	cfg.Rules = []RuleConfig{{Name: HighPointRule, Type: TypeHighPoint}}
+	return cfg
+}
+
+// LoadConfig 读取预警配置文件
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取预警配置失败: %w", err)
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("预警配置 %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig 解析预警配置，未指定的字段使用默认值
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := DefaultConfig()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate 校验配置，并检查每条规则都能创建
+func (c *Config) Validate() error {
+	if len(c.Rules) == 0 {
+		return fmt.Errorf("没有配置预警规则")
+	}
+	if c.HistoryDays < 1 || c.CooldownDays < 0 {
+		return fmt.Errorf("historyDays 应至少为1，cooldownDays 不能为负")
+	}
+	if c.ScorecardWeekday < -1 || c.ScorecardWeekday > 6 {
+		return fmt.Errorf("scorecardWeekday 应为 0（周日）到 6，或 -1 表示不发送")
+	}
+	if c.ScorecardDays < 1 || c.ScorecardDays > c.HistoryDays {
+		return fmt.Errorf("scorecardDays 应在 1 到 historyDays(%d) 之间", c.HistoryDays)
+	}
+	names := make(map[string]bool, len(c.Rules))
+	for i, rc := range c.Rules {
+		if rc.Name == "" {
+			return fmt.Errorf("rules[%d] 未指定 name", i)
+		}
+		if names[rc.Name] {
+			return fmt.Errorf("规则名称 %q 重复", rc.Name)
+		}
+		names[rc.Name] = true
+		if rc.CooldownDays != nil && *rc.CooldownDays < 0 {
+			return fmt.Errorf("规则 %s: cooldownDays 不能为负", rc.Name)
+		}
+		if rc.CooldownDays != nil && *rc.CooldownDays > c.HistoryDays {
+			return fmt.Errorf("规则 %s: cooldownDays %d 超过历史保留天数 %d", rc.Name, *rc.CooldownDays, c.HistoryDays)
+		}
+		if _, err := rc.Build(); err != nil {
+			return fmt.Errorf("规则 %s: %w", rc.Name, err)
+		}
+	}
+	return nil
+}
+
+// Cooldown 规则的冷却期
+func (c *Config) Cooldown(rc RuleConfig) int {
+	if rc.CooldownDays != nil {
+		return *rc.CooldownDays
+	}
+	return c.CooldownDays
+}
+
+// Build 按类型创建规则
+func (rc RuleConfig) Build() (Rule, error) {
+	switch rc.Type {
+	case TypeNewHigh:
+		if rc.Days < 2 {
+			return nil, fmt.Errorf("newHigh 的 days 应至少为2")
+		}
+		return NewHighRule(rc.Days), nil
+	case TypeSectionHigh:
+		return NewSectionHighRule(), nil
+	case TypeHighPoint:
+		return NewHighPointRule(), nil
+	case TypeStopLevel:
+		if len(rc.Holdings) == 0 {
+			return nil, fmt.Errorf("stopLevel 需要配置 holdings")
+		}
+		for _, h := range rc.Holdings {
+			if h.Code == "" || h.StopLevel() <= 0 {
+				return nil, fmt.Errorf("持仓 %q 需要设置 code 和 stop（或 cost 和 stopPercent）", h.Code)
+			}
+		}
+		return NewStopLevelRule(rc.Holdings), nil
+	case TypeExpr:
+		if rc.Expression == "" {
+			return nil, fmt.Errorf("expr 需要配置 expression")
+		}
+		return NewExprRule(rc.Expression)
+	case "":
+		return nil, fmt.Errorf("未指定 type")
+	default:
+		return nil, fmt.Errorf("未知的规则类型 %q，可选: %s, %s, %s, %s, %s", rc.Type, TypeNewHigh, TypeSectionHigh, TypeHighPoint, TypeStopLevel, TypeExpr)
+	}
+}
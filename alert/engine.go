@@ -0,0 +1,205 @@
+package alert
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	globalDefine "stock-go/globalDefine"
+	"stock-go/notify"
+	"stock-go/stockData"
+	"strings"
+)
+
+// Alert 当天的预警
+type Alert struct {
+	Record
+	New bool // 上一个检查日没有这条预警
+}
+
+// Group 一条规则当天的预警
+type Group struct {
+	Rule    string
+	Alerts  []Alert // 新增的在前，各自按代码排序
+	Cooling int     // 触发但在冷却期内没有发送的票票数
+}
+
+// Digest 当天的预警汇总
+type Digest struct {
+	Date       string  // 最新交易日
+	Previous   string  // 上一个检查日，用于区分新增
+	Groups     []Group // 按配置中规则的顺序
+	Duplicates int     // 当天已经发送过的预警数（重复运行）
+}
+
+// Engine 预警引擎
+type Engine struct {
+	cfg     *Config
+	rules   []Rule
+	history *History
+}
+
+// HistoryPath 预警历史文件
+func (c *Config) HistoryPath() string {
+	if c.HistoryFile != "" {
+		return c.HistoryFile
+	}
+	return filepath.Join(globalDefine.DATA_PATH, "alertHistory.json")
+}
+
+// NewEngine 创建预警引擎并读取预警历史
+func NewEngine(cfg *Config) (*Engine, error) {
+	rules := make([]Rule, len(cfg.Rules))
+	for i, rc := range cfg.Rules {
+		rule, err := rc.Build()
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s: %w", rc.Name, err)
+		}
+		rules[i] = rule
+	}
+	history, err := LoadHistory(cfg.HistoryPath())
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{cfg: cfg, rules: rules, history: history}, nil
+}
+
+// Evaluate 用所有规则检查票票的最新K线（不修改预警历史）
+// 只检查最新K线是最新交易日的票票（停牌的跳过）；当天已发送的预警计入 Duplicates，冷却期内的计入 Cooling
+func (e *Engine) Evaluate(data map[string]*stockData.StockInfo) *Digest {
+	latest := ""
+	for _, info := range data {
+		if n := len(info.Datas.DayDatas); n > 0 && info.Datas.DayDatas[n-1].DataStr > latest {
+			latest = info.Datas.DayDatas[n-1].DataStr
+		}
+	}
+	codes := make([]string, 0, len(data))
+	for code, info := range data {
+		if n := len(info.Datas.DayDatas); n > 0 && info.Datas.DayDatas[n-1].DataStr == latest {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	digest := &Digest{Date: latest, Previous: e.history.PreviousDate(latest)}
+	for i, rule := range e.rules {
+		rc := e.cfg.Rules[i]
+		group := Group{Rule: rc.Name}
+		var continuing []Alert
+		for _, code := range codes {
+			info := data[code]
+			detail, ok := rule.Check(info)
+			if !ok {
+				continue
+			}
+			if e.history.Has(rc.Name, code, latest) {
+				digest.Duplicates++
+				continue
+			}
+			// 冷却期按自然日计算（周末和节假日也计入），与 historyDays 的单位一致
+			last := e.history.Last(rc.Name, code)
+			if last != nil && daysBetween(last.Date, latest) < e.cfg.Cooldown(rc) {
+				group.Cooling++
+				continue
+			}
+			bars := info.Datas.DayDatas
+			alert := Alert{
+				Record: Record{
+					Rule:   rc.Name,
+					Type:   rc.Type,
+					Code:   code,
+					Name:   stockName(code, info),
+					Date:   latest,
+					Price:  float64(bars[len(bars)-1].PriceEnd),
+					Detail: detail,
+				},
+				New: last == nil || last.Date != digest.Previous,
+			}
+			if alert.New {
+				group.Alerts = append(group.Alerts, alert)
+			} else {
+				continuing = append(continuing, alert)
+			}
+		}
+		group.Alerts = append(group.Alerts, continuing...)
+		digest.Groups = append(digest.Groups, group)
+	}
+	return digest
+}
+
+// Record 记录当天的检查和发送的预警并保存历史（预警发送成功后调用，发送失败时下次运行会重新发送）
+func (e *Engine) Record(d *Digest) error {
+	if d.Date == "" {
+		return nil
+	}
+	var records []Record
+	for _, g := range d.Groups {
+		for _, a := range g.Alerts {
+			records = append(records, a.Record)
+		}
+	}
+	e.history.Add(d.Date, records...)
+	e.history.Prune(d.Date, e.cfg.HistoryDays)
+	return e.history.Save()
+}
+
+// stockName 票票名称，数据中没有名称时从票票列表中查找
+func stockName(code string, info *stockData.StockInfo) string {
+	if info.Name != "" {
+		return info.Name
+	}
+	return stockData.StockList[code]
+}
+
+// Count 当天的预警数和其中新增的数量
+func (d *Digest) Count() (total, added int) {
+	for _, g := range d.Groups {
+		for _, a := range g.Alerts {
+			total++
+			if a.New {
+				added++
+			}
+		}
+	}
+	return total, added
+}
+
+// Empty 是否没有需要发送的预警
+func (d *Digest) Empty() bool {
+	total, _ := d.Count()
+	return total == 0
+}
+
+// Message 汇总消息：按规则分组，新增的标 [新]，上一个检查日也预警过的标 [续]
+func (d *Digest) Message() notify.Message {
+	total, added := d.Count()
+	title := fmt.Sprintf("预警 %s：%d 条，新增 %d 条", d.Date, total, added)
+
+	var b strings.Builder
+	if d.Previous != "" {
+		fmt.Fprintf(&b, "相对 %s 的变化\n", d.Previous)
+	}
+	for _, g := range d.Groups {
+		if len(g.Alerts) == 0 {
+			continue
+		}
+		groupAdded := 0
+		for _, a := range g.Alerts {
+			if a.New {
+				groupAdded++
+			}
+		}
+		fmt.Fprintf(&b, "【%s】%d 条，新增 %d", g.Rule, len(g.Alerts), groupAdded)
+		if g.Cooling > 0 {
+			fmt.Fprintf(&b, "，冷却中 %d", g.Cooling)
+		}
+		b.WriteString("\n")
+		for _, a := range g.Alerts {
+			mark := "[续]"
+			if a.New {
+				mark = "[新]"
+			}
+			fmt.Fprintf(&b, "%s %s %s %s\n", mark, a.Code, a.Name, a.Detail)
+		}
+	}
+	return notify.Message{Title: title, Content: strings.TrimSuffix(b.String(), "\n")}
+}
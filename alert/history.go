@@ -0,0 +1,165 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Record 已发送的预警
+type Record struct {
+	Rule   string  `json:"rule"`
+	Type   string  `json:"type,omitempty"` // 规则类型，止损类预警按价格下跌计算命中
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Date   string  `json:"date"` // 触发预警的K线日期
+	Price  float64 `json:"price"`
+	Detail string  `json:"detail"`
+}
+
+// History 预警历史，保存在 JSON 文件中
+type History struct {
+	path    string
+	Dates   []string `json:"dates"` // 已完成预警检查的交易日（升序）
+	Records []Record `json:"records"`
+
+	index map[alertKey][]int // 规则和票票 -> Records 下标（按日期升序），nil 表示需要重建
+}
+
+// alertKey 预警历史的索引键
+type alertKey struct {
+	rule, code string
+}
+
+// lookup 同一规则同一票票的预警在 Records 中的下标（按日期升序），记录变化后第一次查询时重建索引
+func (h *History) lookup(rule, code string) []int {
+	if h.index == nil {
+		h.index = make(map[alertKey][]int)
+		for i, r := range h.Records {
+			key := alertKey{r.Rule, r.Code}
+			h.index[key] = append(h.index[key], i)
+		}
+		for _, indexes := range h.index {
+			sort.SliceStable(indexes, func(a, b int) bool { return h.Records[indexes[a]].Date < h.Records[indexes[b]].Date })
+		}
+	}
+	return h.index[alertKey{rule, code}]
+}
+
+// LoadHistory 读取预警历史，文件不存在时返回空历史
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取预警历史失败: %w", err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("解析预警历史 %s 失败: %w", path, err)
+	}
+	return h, nil
+}
+
+// Save 保存预警历史（先写临时文件再替换，避免写到一半时损坏）
+func (h *History) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// Last 同一规则同一票票最近一次预警，没有时返回nil
+func (h *History) Last(rule, code string) *Record {
+	indexes := h.lookup(rule, code)
+	if len(indexes) == 0 {
+		return nil
+	}
+	return &h.Records[indexes[len(indexes)-1]]
+}
+
+// PreviousDate 早于 date 的最近一次预警检查的交易日，没有时返回空
+func (h *History) PreviousDate(date string) string {
+	previous := ""
+	for _, d := range h.Dates {
+		if d < date && d > previous {
+			previous = d
+		}
+	}
+	return previous
+}
+
+// Has 某日是否已经发送过该预警
+func (h *History) Has(rule, code, date string) bool {
+	for _, i := range h.lookup(rule, code) {
+		if h.Records[i].Date == date {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 记录某日的预警检查和发送的预警，同一天已有的预警不重复记录
+func (h *History) Add(date string, records ...Record) {
+	if !slices.Contains(h.Dates, date) {
+		h.Dates = append(h.Dates, date)
+		slices.Sort(h.Dates)
+	}
+	for _, r := range records {
+		if !h.Has(r.Rule, r.Code, r.Date) {
+			// 同一批中的重复预警也要能查到，先追加到索引，排序后再重建
+			key := alertKey{r.Rule, r.Code}
+			h.index[key] = append(h.index[key], len(h.Records))
+			h.Records = append(h.Records, r)
+		}
+	}
+	sort.SliceStable(h.Records, func(i, j int) bool { return h.Records[i].Date < h.Records[j].Date })
+	h.index = nil
+}
+
+// Prune 删除 date 之前超过 days 天的记录
+func (h *History) Prune(date string, days int) {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return
+	}
+	cutoff := t.AddDate(0, 0, -days).Format("2006-01-02")
+	h.Dates = slices.DeleteFunc(h.Dates, func(d string) bool { return d < cutoff })
+	h.Records = slices.DeleteFunc(h.Records, func(r Record) bool { return r.Date < cutoff })
+	h.index = nil
+}
+
+// AppendHistory 把预警追加到历史文件（不经过规则检查的预警，如高点策略和策略扫描的结果）
+func AppendHistory(path, date string, historyDays int, records ...Record) error {
+	h, err := LoadHistory(path)
+	if err != nil {
+		return err
+	}
+	h.Add(date, records...)
+	h.Prune(date, historyDays)
+	return h.Save()
+}
+
+// daysBetween 两个日期相差的自然日（冷却期按自然日计算）
+func daysBetween(from, to string) int {
+	a, err1 := time.Parse("2006-01-02", from)
+	b, err2 := time.Parse("2006-01-02", to)
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	return int(b.Sub(a).Hours() / 24)
+}
@@ -0,0 +1,265 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"stock-go/notify"
+	"stock-go/stockData"
+	"strings"
+	"time"
+)
+
+// Horizons 评估预警效果的持有天数（交易日）
+var Horizons = []int{1, 5, 10, 20}
+
+// ForwardReturn 预警后持有 Days 个交易日的收益率（按收盘价）
+type ForwardReturn struct {
+	Days   int     `json:"days"`
+	Return float64 `json:"return"`
+}
+
+// Outcome 一条预警之后的表现
+// 止损类预警（stopLevel）看空：收益率、MFE、MAE 均按做空方向计算，价格下跌为正
+type Outcome struct {
+	Record
+	Observed int             `json:"observed"` // 预警后已有的交易日数（最多统计到最长的持有天数）
+	Forward  []ForwardReturn `json:"forward"`  // 已满足天数的持有收益
+	MFE      float64         `json:"mfe"`      // 最大有利波动：观察期内最有利的价格相对预警价
+	MAE      float64         `json:"mae"`      // 最大不利波动：观察期内最不利的价格相对预警价（≤0）
+}
+
+// Return 持有 days 天的收益率，天数不足时返回 false
+func (o Outcome) Return(days int) (float64, bool) {
+	for _, f := range o.Forward {
+		if f.Days == days {
+			return f.Return, true
+		}
+	}
+	return 0, false
+}
+
+// direction 预警方向：止损预警看空
+func direction(r Record) float64 {
+	if r.Type == TypeStopLevel {
+		return -1
+	}
+	return 1
+}
+
+// EvaluateOutcomes 计算每条预警之后的表现，data 中没有该票票或找不到预警日K线的预警跳过
+func EvaluateOutcomes(records []Record, data map[string]*stockData.StockInfo) []Outcome {
+	maxDays := Horizons[len(Horizons)-1]
+	outcomes := make([]Outcome, 0, len(records))
+	for _, r := range records {
+		info, ok := data[r.Code]
+		if !ok || r.Price <= 0 {
+			continue
+		}
+		bars := info.Datas.DayDatas
+		entry := sort.Search(len(bars), func(i int) bool { return bars[i].DataStr >= r.Date })
+		if entry == len(bars) || bars[entry].DataStr != r.Date {
+			continue
+		}
+
+		dir := direction(r)
+		o := Outcome{Record: r, Forward: []ForwardReturn{}}
+		o.Observed = min(len(bars)-1-entry, maxDays)
+		for _, days := range Horizons {
+			if days <= o.Observed {
+				ret := dir * (float64(bars[entry+days].PriceEnd)/r.Price - 1)
+				o.Forward = append(o.Forward, ForwardReturn{Days: days, Return: ret})
+			}
+		}
+		for _, bar := range bars[entry+1 : entry+1+o.Observed] {
+			up := float64(bar.PriceHigh)/r.Price - 1
+			down := float64(bar.PriceLow)/r.Price - 1
+			if dir < 0 {
+				up, down = -down, -up
+			}
+			o.MFE = max(o.MFE, up)
+			o.MAE = min(o.MAE, down)
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes
+}
+
+// LoadPrices 从数据文件读取预警涉及的票票的日K线
+func LoadPrices(records []Record) map[string]*stockData.StockInfo {
+	data := make(map[string]*stockData.StockInfo)
+	for _, r := range records {
+		if _, ok := data[r.Code]; ok {
+			continue
+		}
+		data[r.Code] = &stockData.StockInfo{Code: r.Code, Name: r.Name, Datas: stockData.LoadFromCsv(r.Code)}
+	}
+	return data
+}
+
+// Score 读取预警历史和K线，统计截至 now 最近 days 天的预警效果，detail 为 true 时附带每条预警的表现
+func Score(cfg *Config, now time.Time, days int, detail bool) (*Scorecard, error) {
+	history, err := LoadHistory(cfg.HistoryPath())
+	if err != nil {
+		return nil, err
+	}
+	to := now.Format("2006-01-02")
+	from := now.AddDate(0, 0, -days).Format("2006-01-02")
+	var records []Record
+	for _, r := range history.Records {
+		if r.Date >= from && r.Date <= to {
+			records = append(records, r)
+		}
+	}
+	outcomes := EvaluateOutcomes(records, LoadPrices(records))
+	card := BuildScorecard(outcomes, from, to)
+	if detail {
+		card.Outcomes = outcomes
+	}
+	return card, nil
+}
+
+// HorizonScore 一条规则在某个持有天数上的统计
+type HorizonScore struct {
+	Days      int     `json:"days"`
+	Samples   int     `json:"samples"`   // 已满足天数的预警数
+	AvgReturn float64 `json:"avgReturn"` // 平均收益率
+	Median    float64 `json:"median"`    // 收益率中位数
+	HitRate   float64 `json:"hitRate"`   // 收益率为正的比例
+}
+
+// RuleScore 一条规则的预警效果
+type RuleScore struct {
+	Rule     string         `json:"rule"`
+	Alerts   int            `json:"alerts"`   // 统计范围内的预警数
+	Horizons []HorizonScore `json:"horizons"` // 按 Horizons 的顺序
+	AvgMFE   float64        `json:"avgMfe"`
+	AvgMAE   float64        `json:"avgMae"`
+}
+
+// Horizon 某个持有天数的统计
+func (s RuleScore) Horizon(days int) HorizonScore {
+	for _, h := range s.Horizons {
+		if h.Days == days {
+			return h
+		}
+	}
+	return HorizonScore{Days: days}
+}
+
+// Scorecard 预警效果评分
+type Scorecard struct {
+	From     string      `json:"from"` // 统计的预警日期范围
+	To       string      `json:"to"`
+	Rules    []RuleScore `json:"rules"`              // 按 RankDays 的平均收益从高到低
+	Outcomes []Outcome   `json:"outcomes,omitempty"` // 每条预警的表现（需要时填充）
+}
+
+// RankDays 评分排序使用的持有天数
+const RankDays = 10
+
+// BuildScorecard 按规则汇总 [from, to] 之间预警的表现
+func BuildScorecard(outcomes []Outcome, from, to string) *Scorecard {
+	byRule := make(map[string][]Outcome)
+	for _, o := range outcomes {
+		if o.Date >= from && o.Date <= to {
+			byRule[o.Rule] = append(byRule[o.Rule], o)
+		}
+	}
+
+	card := &Scorecard{From: from, To: to, Rules: make([]RuleScore, 0, len(byRule))}
+	for rule, list := range byRule {
+		score := RuleScore{Rule: rule, Alerts: len(list)}
+		observed := 0
+		for _, o := range list {
+			if o.Observed > 0 {
+				observed++
+				score.AvgMFE += o.MFE
+				score.AvgMAE += o.MAE
+			}
+		}
+		if observed > 0 {
+			score.AvgMFE /= float64(observed)
+			score.AvgMAE /= float64(observed)
+		}
+		for _, days := range Horizons {
+			score.Horizons = append(score.Horizons, horizonScore(list, days))
+		}
+		card.Rules = append(card.Rules, score)
+	}
+
+	sort.Slice(card.Rules, func(i, j int) bool {
+		a, b := card.Rules[i].Horizon(RankDays), card.Rules[j].Horizon(RankDays)
+		if (a.Samples > 0) != (b.Samples > 0) {
+			return a.Samples > 0
+		}
+		if a.AvgReturn != b.AvgReturn {
+			return a.AvgReturn > b.AvgReturn
+		}
+		return card.Rules[i].Rule < card.Rules[j].Rule
+	})
+	return card
+}
+
+// horizonScore 统计持有 days 天的收益
+func horizonScore(outcomes []Outcome, days int) HorizonScore {
+	h := HorizonScore{Days: days}
+	var returns []float64
+	for _, o := range outcomes {
+		if ret, ok := o.Return(days); ok {
+			returns = append(returns, ret)
+		}
+	}
+	h.Samples = len(returns)
+	if h.Samples == 0 {
+		return h
+	}
+	sort.Float64s(returns)
+	hits := 0
+	for _, ret := range returns {
+		h.AvgReturn += ret
+		if ret > 0 {
+			hits++
+		}
+	}
+	h.AvgReturn /= float64(h.Samples)
+	h.HitRate = float64(hits) / float64(h.Samples)
+	if mid := h.Samples / 2; h.Samples%2 == 1 {
+		h.Median = returns[mid]
+	} else {
+		h.Median = (returns[mid-1] + returns[mid]) / 2
+	}
+	return h
+}
+
+// Message 评分消息：每条规则一行汇总各持有天数的平均收益和胜率
+func (s *Scorecard) Message() notify.Message {
+	title := fmt.Sprintf("预警效果 %s ~ %s", s.From, s.To)
+	if len(s.Rules) == 0 {
+		return notify.Message{Title: title, Content: "没有预警记录"}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "按%d日平均收益排序，格式：天数 平均收益/胜率(样本)\n", RankDays)
+	for _, r := range s.Rules {
+		fmt.Fprintf(&b, "【%s】%d 条，MFE %s，MAE %s\n", r.Rule, r.Alerts, percent(r.AvgMFE), percent(r.AvgMAE))
+		parts := make([]string, 0, len(r.Horizons))
+		for _, h := range r.Horizons {
+			if h.Samples == 0 {
+				parts = append(parts, fmt.Sprintf("%d日 -", h.Days))
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%d日 %s/%.0f%%(%d)", h.Days, percent(h.AvgReturn), h.HitRate*100, h.Samples))
+		}
+		b.WriteString(strings.Join(parts, "  "))
+		b.WriteString("\n")
+	}
+	return notify.Message{Title: title, Content: strings.TrimSuffix(b.String(), "\n")}
+}
+
+// percent 带符号的百分比
+func percent(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+	return fmt.Sprintf("%+.2f%%", v*100)
+}
@@ -0,0 +1,93 @@
+package alert
+
+import (
+	"math"
+	"stock-go/stockData"
+	"strings"
+	"testing"
+)
+
+// near 浮点数近似相等
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestEvaluateOutcomes 测试预警之后的收益、MFE/MAE，以及止损预警按下跌方向计算
+func TestEvaluateOutcomes(t *testing.T) {
+	stock := stockWithPrices("sz.000001", ramp(10, 12.9, 30))
+	bars := stock.Datas.DayDatas
+	data := map[string]*stockData.StockInfo{"sz.000001": stock}
+	at := func(rule, typ string, i int) Record {
+		return Record{Rule: rule, Type: typ, Code: "sz.000001", Date: bars[i].DataStr, Price: float64(bars[i].PriceEnd)}
+	}
+	records := []Record{
+		at("上涨", TypeNewHigh, 5),
+		at("上涨", TypeNewHigh, 25),
+		at("止损", TypeStopLevel, 5),
+		{Rule: "上涨", Code: "sz.000001", Date: "2023-12-29", Price: 10},    // 没有这一天的K线
+		{Rule: "上涨", Code: "sh.600000", Date: bars[5].DataStr, Price: 10}, // 没有数据
+	}
+	outcomes := EvaluateOutcomes(records, data)
+	if len(outcomes) != 3 {
+		t.Fatalf("表现 %d 条，期望 3 条", len(outcomes))
+	}
+
+	price := float64(bars[5].PriceEnd)
+	long := outcomes[0]
+	if long.Observed != 20 || len(long.Forward) != len(Horizons) {
+		t.Fatalf("观察 %d 天，收益 %+v", long.Observed, long.Forward)
+	}
+	if ret, _ := long.Return(20); !near(ret, float64(bars[25].PriceEnd)/price-1) {
+		t.Errorf("20日收益 %v", ret)
+	}
+	if !near(long.MFE, float64(bars[25].PriceHigh)/price-1) || !near(long.MAE, float64(bars[6].PriceLow)/price-1) {
+		t.Errorf("MFE %v MAE %v", long.MFE, long.MAE)
+	}
+
+	if late := outcomes[1]; late.Observed != 4 || len(late.Forward) != 1 {
+		t.Errorf("数据不足时只计算已满足的天数: %+v", late)
+	}
+
+	short := outcomes[2]
+	if ret, _ := short.Return(1); !near(ret, 1-float64(bars[6].PriceEnd)/price) || ret >= 0 {
+		t.Errorf("止损预警后上涨应为负收益: %v", ret)
+	}
+	if !near(short.MFE, 1-float64(bars[6].PriceLow)/price) || !near(short.MAE, 1-float64(bars[25].PriceHigh)/price) {
+		t.Errorf("止损预警 MFE %v MAE %v", short.MFE, short.MAE)
+	}
+}
+
+// TestScorecard 测试按规则汇总胜率、排序和日期范围
+func TestScorecard(t *testing.T) {
+	outcomes := []Outcome{
+		{Record: Record{Rule: "a", Date: "2024-03-01"}, Observed: 20, MFE: 0.2, MAE: -0.1,
+			Forward: []ForwardReturn{{1, 0.01}, {5, 0.02}, {10, 0.05}, {20, 0.1}}},
+		{Record: Record{Rule: "a", Date: "2024-03-04"}, Observed: 10, MFE: 0.1, MAE: -0.05,
+			Forward: []ForwardReturn{{1, -0.01}, {5, 0.03}, {10, -0.01}}},
+		{Record: Record{Rule: "b", Date: "2024-03-01"}, Observed: 20, MFE: 0.3, MAE: -0.02,
+			Forward: []ForwardReturn{{1, 0.02}, {5, 0.04}, {10, 0.08}, {20, 0.12}}},
+		{Record: Record{Rule: "c", Date: "2024-03-05"}, Forward: []ForwardReturn{}},
+		{Record: Record{Rule: "d", Date: "2024-01-01"}, Observed: 20, Forward: []ForwardReturn{{10, 0.5}}},
+	}
+	card := BuildScorecard(outcomes, "2024-02-01", "2024-03-31")
+	if len(card.Rules) != 3 || card.Rules[0].Rule != "b" || card.Rules[1].Rule != "a" || card.Rules[2].Rule != "c" {
+		t.Fatalf("规则排序 %+v", card.Rules)
+	}
+	a := card.Rules[1]
+	if h := a.Horizon(10); h.Samples != 2 || !near(h.AvgReturn, 0.02) || !near(h.HitRate, 0.5) || !near(h.Median, 0.02) {
+		t.Errorf("10日统计 %+v", h)
+	}
+	if h := a.Horizon(20); h.Samples != 1 || !near(h.HitRate, 1) {
+		t.Errorf("20日统计 %+v", h)
+	}
+	if a.Alerts != 2 || !near(a.AvgMFE, 0.15) || !near(a.AvgMAE, -0.075) {
+		t.Errorf("规则 a %+v", a)
+	}
+
+	msg := card.Message()
+	if msg.Title != "预警效果 2024-02-01 ~ 2024-03-31" ||
+		!strings.Contains(msg.Content, "【a】2 条，MFE +15.00%，MAE -7.50%\n1日 +0.00%/50%(2)  5日 +2.50%/100%(2)  10日 +2.00%/50%(2)  20日 +10.00%/100%(1)") ||
+		!strings.Contains(msg.Content, "【c】1 条，MFE +0.00%，MAE +0.00%\n1日 -  5日 -  10日 -  20日 -") {
+		t.Errorf("评分消息:\n%s\n%s", msg.Title, msg.Content)
+	}
+}
@@ -0,0 +1,180 @@
+package alert
+
+import (
+	"fmt"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/expr"
+)
+
+// Rule 预警规则，只检查票票的最新K线
+type Rule interface {
+	// Check 最新K线是否触发预警，触发时返回说明
+	Check(stock *stockData.StockInfo) (detail string, ok bool)
+}
+
+// newHighRule 收盘价高于之前 days 天的最高收盘价
+type newHighRule struct {
+	days int
+}
+
+// NewHighRule 创建N日新高规则：最新收盘价高于之前 days 根K线的最高收盘价
+func NewHighRule(days int) Rule {
+	return &newHighRule{days: days}
+}
+
+func (r *newHighRule) Check(stock *stockData.StockInfo) (string, bool) {
+	bars := stock.Datas.DayDatas
+	n := len(bars)
+	if n < r.days+1 {
+		return "", false
+	}
+	high := float32(0)
+	for _, bar := range bars[n-1-r.days : n-1] {
+		high = max(high, bar.PriceEnd)
+	}
+	last := bars[n-1]
+	if last.PriceEnd <= high {
+		return "", false
+	}
+	return fmt.Sprintf("收盘 %.2f 创%d日新高（前高收盘 %.2f）", last.PriceEnd, r.days, high), true
+}
+
+// sectionHighRule 收盘价向上突破最近一个区间的高点
+type sectionHighRule struct{}
+
+// NewSectionHighRule 创建区间高点突破规则
+// 区间由 stockData 的峰谷点计算（涨跌幅超过20%的波段），前一天收盘不高于区间高点、当天收盘高于区间高点时触发
+func NewSectionHighRule() Rule {
+	return &sectionHighRule{}
+}
+
+func (r *sectionHighRule) Check(stock *stockData.StockInfo) (string, bool) {
+	bars := stock.Datas.DayDatas
+	n := len(bars)
+	if n < 2 {
+		return "", false
+	}
+	high := lastSectionHigh(stock)
+	if high == nil {
+		return "", false
+	}
+	level := high.PriceHigh
+	if bars[n-2].PriceEnd > level || bars[n-1].PriceEnd <= level {
+		return "", false
+	}
+	return fmt.Sprintf("收盘 %.2f 突破 %s 的区间高点 %.2f", bars[n-1].PriceEnd, high.DataStr, level), true
+}
+
+// lastSectionHigh 最近一个区间的高点
+// 数据加载时已计算区间（LoadDataOneByOne）的直接使用，否则在副本上计算
+func lastSectionHigh(stock *stockData.StockInfo) *stockData.StockDataDay {
+	sections := stock.Datas.Sections
+	if len(sections) == 0 {
+		tmp := &stockData.StockInfo{Code: stock.Code, Datas: stockData.StockData{DayDatas: stock.Datas.DayDatas}}
+		tmp.DealStockPoints()
+		if len(tmp.Datas.Points) == 0 {
+			return nil
+		}
+		tmp.DealStockSession(0)
+		sections = tmp.Datas.Sections
+	}
+	// 区间按时间顺序成对保存（高点和低点）
+	if len(sections) < 2 {
+		return nil
+	}
+	a, b := sections[len(sections)-2], sections[len(sections)-1]
+	if a.PriceA > b.PriceA {
+		return a
+	}
+	return b
+}
+
+// highPointRule 高点策略：最新K线是最近 STOCK_SESSION_LEN 天的最高点，且之前30天都不是
+type highPointRule struct{}
+
+// NewHighPointRule 创建高点策略规则（见 stockStrategy.HighPointStrategyLast）
+func NewHighPointRule() Rule {
+	return &highPointRule{}
+}
+
+func (r *highPointRule) Check(stock *stockData.StockInfo) (string, bool) {
+	if ok, _ := stockStrategy.HighPointLast(stock); !ok {
+		return "", false
+	}
+	bars := stock.Datas.DayDatas
+	return fmt.Sprintf("收盘 %.2f 为区间最高", bars[len(bars)-1].PriceEnd), true
+}
+
+// Holding 关注的持仓
+type Holding struct {
+	Code        string  `json:"code"`
+	Stop        float64 `json:"stop,omitempty"`        // 止损价
+	Cost        float64 `json:"cost,omitempty"`        // 成本价，未设置止损价时按 cost*(1-stopPercent) 计算止损价
+	StopPercent float64 `json:"stopPercent,omitempty"` // 止损比例，如 0.08
+}
+
+// StopLevel 止损价
+func (h Holding) StopLevel() float64 {
+	if h.Stop > 0 {
+		return h.Stop
+	}
+	return h.Cost * (1 - h.StopPercent)
+}
+
+// stopLevelRule 持仓的最低价触及止损价
+type stopLevelRule struct {
+	holdings map[string]Holding
+}
+
+// NewStopLevelRule 创建持仓止损规则：只检查关注的持仓，最新K线最低价不高于止损价时触发
+func NewStopLevelRule(holdings []Holding) Rule {
+	r := &stopLevelRule{holdings: make(map[string]Holding, len(holdings))}
+	for _, h := range holdings {
+		r.holdings[h.Code] = h
+	}
+	return r
+}
+
+func (r *stopLevelRule) Check(stock *stockData.StockInfo) (string, bool) {
+	h, ok := r.holdings[stock.Code]
+	bars := stock.Datas.DayDatas
+	if !ok || len(bars) == 0 {
+		return "", false
+	}
+	last := bars[len(bars)-1]
+	stop := h.StopLevel()
+	if float64(last.PriceLow) > stop {
+		return "", false
+	}
+	return fmt.Sprintf("最低 %.2f 触及止损价 %.2f，收盘 %.2f", last.PriceLow, stop, last.PriceEnd), true
+}
+
+// exprRule 条件表达式（语法见 stockStrategy/expr）
+type exprRule struct {
+	e *expr.Expr
+}
+
+// NewExprRule 创建表达式规则，最新K线满足条件表达式时触发
+func NewExprRule(expression string) (Rule, error) {
+	e, err := expr.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsCondition() {
+		return nil, fmt.Errorf("表达式 %q 应为条件表达式", expression)
+	}
+	return &exprRule{e: e}, nil
+}
+
+func (r *exprRule) Check(stock *stockData.StockInfo) (string, bool) {
+	bars := stock.Datas.DayDatas
+	if len(bars) == 0 {
+		return "", false
+	}
+	ctx := stockStrategy.NewBarContext(stock.Code, bars, len(bars)-1, r.e.Window)
+	if !r.e.Test(ctx, nil) {
+		return "", false
+	}
+	return fmt.Sprintf("收盘 %.2f 满足 %s", bars[len(bars)-1].PriceEnd, r.e.Source), true
+}
@@ -1,105 +1,212 @@
-package main
-
-import (
-	"fmt"
-	"os"
-	"os/exec"
-	"os/signal"
-	globalDefine "stock-go/globalDefine"
-	"stock-go/logger"
-	"stock-go/stockData"
-	"stock-go/stockStrategy"
-	"stock-go/utils"
-	"strings"
-	"syscall"
-	"time"
-)
-
-func main() {
-
-	executeTime := globalDefine.ExecuteAnalyseDataTime
-
-	// 启动定时任务
-	utils.DoWorkEveryDayOnce(doworkEveryDay, &executeTime)
-
-	// 等待中断信号
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	select {
-	case <-sigChan:
-		logger.Infof("收到中断信号，正在优雅关闭...")
-
-		// 等待定时任务停止，最多等待30分钟
-		select {
-		case <-time.After(30 * time.Minute):
-			logger.Warnf("定时任务停止超时")
-		}
-
-		logger.Infof("程序退出")
-	}
-}
-
-func doworkEveryDay() {
-
-	analyseData()
-}
-
-func analyseData() error {
-	logger.Info("analyseData start")
-
-	stockData.ReLoadAllData()
-
-	var lastErr error
-	successCount := 0
-
-	var stockList []string
-
-	for _, stock := range stockData.Stocks {
-		if stock == nil {
-			logger.Warnf("stock数据为空，跳过")
-			continue
-		}
-
-		if isHighPoint, dataStr := stockStrategy.HighPointStrategyLast(stock.Code); isHighPoint {
-			logger.Infof("发现高点: %s - %s", stock.Code, dataStr)
-			successCount++
-			stockStr := stock.Code + " " + stock.Name
-			stockList = append(stockList, stockStr)
-		} else if dataStr != "" {
-			logger.Infof("stock %s 处理完成，未发现高点", stock.Code)
-			successCount++
-		} else {
-			logger.Warnf("stock %s 处理失败", stock.Code)
-			lastErr = fmt.Errorf("stock %s 处理失败", stock.Code)
-		}
-	}
-
-	logger.Infof("找到高点stock数量: %d", len(stockList))
-	message := ""
-	for _, stock := range stockList {
-		message += fmt.Sprintf("%s\n", stock)
-	}
-	if message != "" {
-		// 获取公网IP
-		publicIP := getPublicIP()
-		finalMessage := fmt.Sprintf("公网IP: %s\n发现高点:\n %s", publicIP, message)
-		logger.Infof("发送微信消息长度: %d 字节", len(finalMessage))
-		logger.Infof("完整消息内容: %s", finalMessage)
-		utils.SendWeChatMessage(finalMessage)
-	}
-
-	logger.Infof("analyseData end - 成功处理 %d 只stock", successCount)
-
-	return lastErr
-}
-
-func getPublicIP() string {
-	cmd := exec.Command("curl", "-4", "ifconfig.me")
-	output, err := cmd.Output()
-	if err != nil {
-		logger.Warnf("获取公网IP失败: %v", err)
-		return "未知"
-	}
-	return strings.TrimSpace(string(output))
-}
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"stock-go/alert"
+	globalDefine "stock-go/globalDefine"
+	"stock-go/logger"
+	"stock-go/notify"
+	"stock-go/scheduler"
+	"stock-go/stockData"
+	"stock-go/tradeTest/runner"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// strategyConfig 每日分析使用的策略配置，nil表示不按策略分析
+var strategyConfig *runner.Config
+
+// alertConfig 每日分析使用的预警规则，nil表示不检查预警；没有配置预警规则和策略时使用高点策略规则
+var alertConfig *alert.Config
+
+func main() {
+	configPath := flag.String("config", "", "策略配置文件（与回测配置相同），为空时使用配置 analysis.strategyConfig，都为空时使用高点策略")
+	configFlags := globalDefine.BindFlags(flag.CommandLine)
+	flag.Parse()
+	global, err := globalDefine.Setup(configFlags)
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if err := logger.SetLogPath(global.LogPath); err != nil {
+		logger.Errorf("切换日志目录失败: %v", err)
+	}
+	if *configPath == "" {
+		*configPath = global.Analysis.StrategyConfig
+	}
+	if *configPath != "" {
+		cfg, err := runner.LoadConfig(*configPath)
+		if err != nil {
+			logger.Errorf("加载策略配置失败: %v", err)
+			os.Exit(1)
+		}
+		strategyConfig = cfg
+		logger.Infof("每日分析使用策略配置 %s: %s", *configPath, cfg.Name)
+	}
+	if path := global.Analysis.AlertConfig; path != "" {
+		cfg, err := alert.LoadConfig(path)
+		if err != nil {
+			logger.Errorf("加载预警配置失败: %v", err)
+			os.Exit(1)
+		}
+		alertConfig = cfg
+		logger.Infof("每日分析使用预警配置 %s: %d 条规则", path, len(cfg.Rules))
+	}
+	if strategyConfig == nil && alertConfig == nil {
+		alertConfig = alert.HighPointConfig()
+		logger.Infof("每日分析使用高点策略")
+	}
+
+	// 每个交易日分析数据，默认等当天更新数据任务（updateDataEveryDay）成功后才分析
+	// 策略扫描、预警检查、预警效果评分是单独的任务，每项在每个计划时间只成功运行一次，失败时只重试失败的一项
+	var after []string
+	if global.Schedule.AnalyseAfterUpdate {
+		after = []string{scheduler.JobUpdateData}
+	}
+	newJob := func(name string, run func(slot time.Time) error) *scheduler.Job {
+		return &scheduler.Job{
+			Name:     name,
+			Schedule: global.Schedule.AnalyseDataTime,
+			After:    after,
+			Run:      func(ctx context.Context) error { return run(scheduler.SlotTime(ctx)) },
+		}
+	}
+	var jobs []*scheduler.Job
+	if strategyConfig != nil {
+		jobs = append(jobs, newJob(scheduler.JobAnalyseStrategy, func(time.Time) error { return analyseDataByConfig(strategyConfig) }))
+	}
+	if alertConfig != nil {
+		jobs = append(jobs, newJob(scheduler.JobAnalyseAlerts, func(time.Time) error { return analyseAlerts(alertConfig) }))
+	}
+	// 最后运行，评分包含当天的预警；按计划时间判断星期，补跑时不会因为当前日期而漏发或多发
+	jobs = append(jobs, newJob(scheduler.JobAlertScorecard, sendScorecard))
+	sched, err := scheduler.FromConfig(global, jobs...)
+	if err != nil {
+		logger.Errorf("创建定时任务失败: %v", err)
+		os.Exit(1)
+	}
+
+	// 收到中断信号时停止
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	sched.Run(ctx)
+
+	logger.Infof("收到中断信号，程序退出")
+}
+
+// alertSettings 预警历史和评分的配置，没有预警配置时使用默认值
+func alertSettings() *alert.Config {
+	if alertConfig != nil {
+		return alertConfig
+	}
+	return alert.DefaultConfig()
+}
+
+// recordAlerts 把策略扫描发出的买入信号追加到预警历史，用于之后统计效果
+func recordAlerts(date string, records []alert.Record) {
+	if len(records) == 0 {
+		return
+	}
+	cfg := alertSettings()
+	if err := alert.AppendHistory(cfg.HistoryPath(), date, cfg.HistoryDays, records...); err != nil {
+		logger.Errorf("保存预警历史失败: %v", err)
+	}
+}
+
+// sendScorecard 每周在配置的日子发送最近一段时间各预警规则的效果评分，now 为任务的计划时间
+func sendScorecard(now time.Time) error {
+	cfg := alertSettings()
+	if cfg.ScorecardWeekday != int(now.Weekday()) {
+		return nil
+	}
+	card, err := alert.Score(cfg, now, cfg.ScorecardDays, false)
+	if err != nil {
+		logger.Errorf("统计预警效果失败: %v", err)
+		return err
+	}
+	logger.Infof("预警效果 %s ~ %s: %d 条规则", card.From, card.To, len(card.Rules))
+	return notify.Send(context.Background(), card.Message())
+}
+
+// analyseAlerts 用预警规则检查所有票票的最新数据，发送按规则分组的汇总
+// 发送成功（或没有预警）后才记录到预警历史，发送失败时下次运行会重新发送
+func analyseAlerts(cfg *alert.Config) error {
+	logger.Infof("analyseAlerts start")
+
+	engine, err := alert.NewEngine(cfg)
+	if err != nil {
+		logger.Errorf("创建预警引擎失败: %v", err)
+		return err
+	}
+	stockData.ReLoadAllData()
+	digest := engine.Evaluate(stockData.Stocks)
+	total, added := digest.Count()
+	logger.Infof("预警 %s: %d 条，新增 %d 条，当天已发送 %d 条", digest.Date, total, added, digest.Duplicates)
+
+	if !digest.Empty() {
+		if err := notify.Send(context.Background(), digest.Message()); err != nil {
+			return err
+		}
+	}
+	if err := engine.Record(digest); err != nil {
+		logger.Errorf("保存预警历史失败: %v", err)
+		return err
+	}
+
+	logger.Infof("analyseAlerts end")
+	return nil
+}
+
+// analyseDataByConfig 用配置的策略分析配置票票池的最新数据，发送出现买入信号的票票
+// 发送成功后才记录到预警历史，发送失败时返回错误
+func analyseDataByConfig(cfg *runner.Config) error {
+	logger.Infof("analyseDataByConfig start: %s", cfg.Name)
+
+	stockData.ClearRawData()
+	candidates, err := cfg.Scan(cfg.Backtest.Universe.LoadUniverse())
+	if err != nil {
+		logger.Errorf("策略 %s 分析失败: %v", cfg.Name, err)
+		return err
+	}
+
+	logger.Infof("策略 %s 发现买入信号: %d 只", cfg.Name, len(candidates))
+	message := ""
+	records := make([]alert.Record, 0, len(candidates))
+	for _, c := range candidates {
+		message += fmt.Sprintf("%s %s %.2f\n", c.Code, c.Name, c.Price)
+		records = append(records, alert.Record{
+			Rule: "策略[" + cfg.Name + "]", Type: alert.TypeStrategy,
+			Code: c.Code, Name: c.Name, Date: c.Date, Price: c.Price, Detail: "买入信号",
+		})
+	}
+	if message != "" {
+		finalMessage := fmt.Sprintf("公网IP: %s\n %s", getPublicIP(), message)
+		logger.Infof("完整消息内容: %s", finalMessage)
+		if err := notify.Send(context.Background(), notify.Message{
+			Title:   fmt.Sprintf("策略[%s]买入信号(%s)", cfg.Name, candidates[0].Date),
+			Content: finalMessage,
+		}); err != nil {
+			return err
+		}
+		recordAlerts(candidates[0].Date, records)
+	}
+
+	logger.Infof("analyseDataByConfig end")
+	return nil
+}
+
+func getPublicIP() string {
+	cmd := exec.Command("curl", "-4", "ifconfig.me")
+	output, err := cmd.Output()
+	if err != nil {
+		logger.Warnf("获取公网IP失败: %v", err)
+		return "未知"
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -1,9 +1,10 @@
 package main
 
 import (
+	"stock-go/alert"
 	"testing"
 )
 
 func TestAnalyseDataEveryDay(t *testing.T) {
-	analyseData()
+	analyseAlerts(alert.HighPointConfig())
 }
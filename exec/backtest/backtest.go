@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	globalDefine "stock-go/globalDefine"
+	"stock-go/logger"
+	"stock-go/stockStrategy/registry"
+	"stock-go/tradeTest/runner"
+	"strings"
+)
+
+// 按配置文件运行回测
+// 示例: go run ./exec/backtest -config conf/breakout.json
+func main() {
+	configPath := flag.String("config", "conf/breakout.json", "策略和回测配置文件")
+	check := flag.Bool("check", false, "只校验配置，不运行回测")
+	list := flag.Bool("list", false, "列出所有可用的策略组件和参数")
+	jsonOut := flag.Bool("json", false, "以JSON输出回测结果摘要")
+	configFlags := globalDefine.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *list {
+		printComponents()
+		return
+	}
+
+	cfg, err := runner.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *check {
+		fmt.Printf("配置 %s 校验通过: %s\n", *configPath, cfg.Name)
+		return
+	}
+	if _, err := globalDefine.Setup(configFlags); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	logger.Infof("按配置 %s 回测", *configPath)
+	result, err := cfg.Run(nil, *jsonOut)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *jsonOut {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(cfg.Summarize(result))
+	}
+}
+
+// printComponents 打印注册表中的组件和参数
+func printComponents() {
+	for _, kind := range registry.Kinds {
+		fmt.Printf("[%s]\n", kind)
+		for _, c := range registry.List(kind) {
+			fmt.Printf("  %s: %s\n", c.Name, c.Desc)
+			for _, p := range c.Params {
+				extra := make([]string, 0, 3)
+				if p.Required {
+					extra = append(extra, "必填")
+				} else {
+					extra = append(extra, fmt.Sprintf("默认 %v", p.Default))
+				}
+				if p.Min != nil && p.Max != nil {
+					extra = append(extra, fmt.Sprintf("范围 [%v, %v]", *p.Min, *p.Max))
+				}
+				fmt.Printf("    %s (%s, %s): %s\n", p.Name, p.Type, strings.Join(extra, ", "), p.Desc)
+			}
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package globaldefine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"stock-go/scheduler/cron"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // 内嵌时区数据，服务器没有安装时也能使用 Asia/Shanghai
+)
+
+// DefaultConfigFile 未通过 -conf 或 STOCK_CONF 指定时读取的配置文件（不存在时只使用默认值）
+const DefaultConfigFile = "conf/stock.json"
+
+// Config 运行配置
+// 按 默认值 -> 配置文件 -> 环境变量 -> 命令行参数 的顺序逐层覆盖
+type Config struct {
+	DataPath string         `json:"dataPath"` // 数据目录（stockList.csv 和日线CSV所在目录）
+	LogPath  string         `json:"logPath"`  // 日志目录
+	Schedule ScheduleConfig `json:"schedule"` // 定时任务
+	Universe UniverseConfig `json:"universe"` // 票票池抽样
+	Notify   NotifyConfig   `json:"notify"`   // 消息通知
+	Server   ServerConfig   `json:"server"`   // HTTP服务
+	Analysis AnalysisConfig `json:"analysis"` // 每日分析
+
+	file string // 实际读取的配置文件，空表示没有读取配置文件
+}
+
+// DefaultTimezone 定时任务默认的时区
+const DefaultTimezone = "Asia/Shanghai"
+
+// ScheduleConfig 定时任务（见 scheduler），执行时间为 HH:MM 或 cron 表达式，只在交易日执行
+type ScheduleConfig struct {
+	UpdateDataTime     string      `json:"updateDataTime"`     // 更新数据
+	AnalyseDataTime    string      `json:"analyseDataTime"`    // 分析数据
+	AnalyseAfterUpdate bool        `json:"analyseAfterUpdate"` // 当天更新数据成功后才分析
+	Timezone           string      `json:"timezone"`           // 执行时间的时区
+	HolidayFile        string      `json:"holidayFile"`        // 补充的休市日期（JSON 日期数组），内置日历没有的年份需要配置
+	CatchUpDays        int         `json:"catchUpDays"`        // 启动时补跑最近多少天内错过的最后一次执行，0表示不补跑
+	Retry              RetryConfig `json:"retry"`              // 失败重试
+}
+
+// UniverseConfig 票票池抽样
+type UniverseConfig struct {
+	LoadPct int `json:"loadPct"` // 随机加载 1/LoadPct 的票票，1表示全部加载
+}
+
+// NotifyConfig 消息通知，配置了的渠道都会发送（见 notify 包），都没有配置时输出到标准输出
+type NotifyConfig struct {
+	WeChat  WeChatConfig  `json:"wechat"`  // 企业微信应用消息
+	Webhook WebhookConfig `json:"webhook"` // 通用 webhook
+	SMTP    SMTPConfig    `json:"smtp"`    // 邮件
+	File    FileConfig    `json:"file"`    // 文件
+	Retry   RetryConfig   `json:"retry"`   // 发送失败重试
+}
+
+// WeChatConfig 企业微信应用的凭据，不要提交到代码仓库，建议用环境变量设置 CorpSecret
+type WeChatConfig struct {
+	CorpID     string `json:"corpId"`
+	CorpSecret string `json:"corpSecret"`
+	AgentID    string `json:"agentId"`
+	ToUser     string `json:"toUser"` // 接收人，多个用 | 分隔，为空时发送给全部（@all）
+}
+
+// Enabled 是否配置了企业微信
+func (c WeChatConfig) Enabled() bool {
+	return c.CorpID != "" && c.CorpSecret != ""
+}
+
+// WebhookConfig 通用 webhook，以 JSON POST 消息
+type WebhookConfig struct {
+	URL string `json:"url"` // 地址中常带有令牌，建议用环境变量设置
+}
+
+// SMTPConfig 邮件
+type SMTPConfig struct {
+	Addr     string   `json:"addr"` // 服务器地址 host:port，为空表示不发送邮件
+	Username string   `json:"username"`
+	Password string   `json:"password"` // 建议用环境变量设置
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// FileConfig 文件
+type FileConfig struct {
+	Path string `json:"path"` // 追加写入的文件，- 表示标准输出，为空表示不写文件
+}
+
+// RetryConfig 发送失败时按指数退避重试
+type RetryConfig struct {
+	Attempts  int `json:"attempts"`  // 最多发送次数（含第一次）
+	BackoffMs int `json:"backoffMs"` // 第一次重试前的等待时间，之后每次翻倍
+}
+
+// ServerConfig HTTP服务
+type ServerConfig struct {
+	Addr string `json:"addr"` // 监听地址，如 :8080
+}
+
+// AnalysisConfig 每日分析
+type AnalysisConfig struct {
+	StrategyConfig string `json:"strategyConfig"` // 策略配置文件（见 tradeTest/runner）
+	AlertConfig    string `json:"alertConfig"`    // 预警规则配置文件（见 alert），与策略配置都为空时使用高点策略
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		DataPath: "../Data/",
+		LogPath:  "../Log/",
+		Schedule: ScheduleConfig{
+			UpdateDataTime:     "19:00",
+			AnalyseDataTime:    "19:30",
+			AnalyseAfterUpdate: true,
+			Timezone:           DefaultTimezone,
+			CatchUpDays:        3,
+			Retry:              RetryConfig{Attempts: 3, BackoffMs: 10 * 60 * 1000},
+		},
+		Universe: UniverseConfig{LoadPct: 4},
+		Notify: NotifyConfig{
+			Retry: RetryConfig{Attempts: 3, BackoffMs: 1000},
+		},
+		Server: ServerConfig{Addr: ":8080"},
+	}
+}
+
+// File 实际读取的配置文件，空表示没有读取配置文件
+func (c *Config) File() string {
+	return c.file
+}
+
+// setting 可以由环境变量和命令行参数设置的配置项
+type setting struct {
+	flag  string // 命令行参数名，空表示不能通过命令行设置（如密钥，避免出现在进程列表中）
+	env   string // 环境变量名
+	usage string
+	set   func(c *Config, v string) error
+}
+
+// settings 所有可以由环境变量和命令行参数设置的配置项
+var settings = []setting{
+	{"data-path", "STOCK_DATA_PATH", "数据目录", func(c *Config, v string) error { c.DataPath = v; return nil }},
+	{"log-path", "STOCK_LOG_PATH", "日志目录", func(c *Config, v string) error { c.LogPath = v; return nil }},
+	{"update-time", "STOCK_UPDATE_TIME", "每日更新数据的时间（HH:MM 或 cron 表达式）", func(c *Config, v string) error { c.Schedule.UpdateDataTime = v; return nil }},
+	{"analyse-time", "STOCK_ANALYSE_TIME", "每日分析数据的时间（HH:MM 或 cron 表达式）", func(c *Config, v string) error { c.Schedule.AnalyseDataTime = v; return nil }},
+	{"load-pct", "STOCK_LOAD_PCT", "随机加载 1/N 的票票", func(c *Config, v string) (err error) {
+		c.Universe.LoadPct, err = strconv.Atoi(v)
+		return err
+	}},
+	{"addr", "STOCK_SERVER_ADDR", "HTTP服务监听地址", func(c *Config, v string) error { c.Server.Addr = v; return nil }},
+	{"strategy-config", "STOCK_STRATEGY_CONFIG", "每日分析使用的策略配置文件", func(c *Config, v string) error { c.Analysis.StrategyConfig = v; return nil }},
+	{"alert-config", "STOCK_ALERT_CONFIG", "每日分析使用的预警规则配置文件", func(c *Config, v string) error { c.Analysis.AlertConfig = v; return nil }},
+	{"", "STOCK_WECHAT_CORP_ID", "", func(c *Config, v string) error { c.Notify.WeChat.CorpID = v; return nil }},
+	{"", "STOCK_WECHAT_CORP_SECRET", "", func(c *Config, v string) error { c.Notify.WeChat.CorpSecret = v; return nil }},
+	{"", "STOCK_WECHAT_AGENT_ID", "", func(c *Config, v string) error { c.Notify.WeChat.AgentID = v; return nil }},
+	{"", "STOCK_WECHAT_TO_USER", "", func(c *Config, v string) error { c.Notify.WeChat.ToUser = v; return nil }},
+	{"", "STOCK_WEBHOOK_URL", "", func(c *Config, v string) error { c.Notify.Webhook.URL = v; return nil }},
+	{"", "STOCK_SMTP_USERNAME", "", func(c *Config, v string) error { c.Notify.SMTP.Username = v; return nil }},
+	{"", "STOCK_SMTP_PASSWORD", "", func(c *Config, v string) error { c.Notify.SMTP.Password = v; return nil }},
+}
+
+// Flags 绑定到命令行的配置参数，只有在命令行中出现的参数才覆盖配置
+type Flags struct {
+	fs   *flag.FlagSet
+	file *string
+}
+
+// BindFlags 在 fs 上注册配置参数（-conf 和各配置项），需要在 fs.Parse 之前调用
+func BindFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs}
+	f.file = fs.String("conf", "", "配置文件（默认读取环境变量 STOCK_CONF 或 "+DefaultConfigFile+"）")
+	for _, s := range settings {
+		if s.flag != "" {
+			fs.String(s.flag, "", s.usage+"（环境变量 "+s.env+"）")
+		}
+	}
+	return f
+}
+
+// configFile 配置文件路径：命令行 -conf、环境变量 STOCK_CONF、默认文件（不存在时返回空）
+func (f *Flags) configFile() (string, bool) {
+	if f != nil && *f.file != "" {
+		return *f.file, true
+	}
+	if env := os.Getenv("STOCK_CONF"); env != "" {
+		return env, true
+	}
+	if _, err := os.Stat(DefaultConfigFile); err == nil {
+		return DefaultConfigFile, false
+	}
+	return "", false
+}
+
+// Load 按 默认值 -> 配置文件 -> 环境变量 -> 命令行参数 加载配置（不校验）
+// flags 为nil时不读取命令行参数；显式指定的配置文件不存在时返回错误
+func Load(flags *Flags) (*Config, error) {
+	cfg := DefaultConfig()
+
+	if file, explicit := flags.configFile(); file != "" {
+		if err := cfg.loadFile(file); err != nil {
+			if explicit || !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(cfg, v); err != nil {
+				return nil, fmt.Errorf("环境变量 %s=%q: %w", s.env, v, err)
+			}
+		}
+	}
+
+	if flags != nil {
+		var err error
+		flags.fs.Visit(func(fl *flag.Flag) {
+			for _, s := range settings {
+				if s.flag == fl.Name && err == nil {
+					if e := s.set(cfg, fl.Value.String()); e != nil {
+						err = fmt.Errorf("参数 -%s=%q: %w", s.flag, fl.Value.String(), e)
+					}
+				}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.DataPath = withSeparator(cfg.DataPath)
+	cfg.LogPath = withSeparator(cfg.LogPath)
+	return cfg, nil
+}
+
+// loadFile 读取 JSON 配置文件，文件中的字段覆盖当前值；出现未知字段时报错
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	c.file = path
+	return nil
+}
+
+// withSeparator 目录以路径分隔符结尾（代码中以 DATA_PATH + 文件名 拼接路径）
+func withSeparator(dir string) string {
+	if dir == "" || strings.HasSuffix(dir, "/") || strings.HasSuffix(dir, string(filepath.Separator)) {
+		return dir
+	}
+	return dir + string(filepath.Separator)
+}
+
+// Validate 校验配置：目录存在（日志目录不存在时创建）、时间格式、地址格式等
+func (c *Config) Validate() error {
+	var errs []error
+	if c.DataPath == "" {
+		errs = append(errs, fmt.Errorf("dataPath 不能为空"))
+	} else if info, err := os.Stat(c.DataPath); err != nil || !info.IsDir() {
+		errs = append(errs, fmt.Errorf("dataPath %s 不是有效的目录", c.DataPath))
+	}
+	if c.LogPath == "" {
+		errs = append(errs, fmt.Errorf("logPath 不能为空"))
+	} else if err := os.MkdirAll(c.LogPath, 0o755); err != nil {
+		errs = append(errs, fmt.Errorf("logPath %s 无法创建: %w", c.LogPath, err))
+	}
+	for _, t := range []struct{ name, value string }{
+		{"schedule.updateDataTime", c.Schedule.UpdateDataTime},
+		{"schedule.analyseDataTime", c.Schedule.AnalyseDataTime},
+	} {
+		if _, err := cron.Parse(t.value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+		}
+	}
+	if _, err := time.LoadLocation(c.Schedule.Timezone); err != nil {
+		errs = append(errs, fmt.Errorf("schedule.timezone %q 无效", c.Schedule.Timezone))
+	}
+	if c.Schedule.HolidayFile != "" {
+		if _, err := os.Stat(c.Schedule.HolidayFile); err != nil {
+			errs = append(errs, fmt.Errorf("schedule.holidayFile %s 不存在", c.Schedule.HolidayFile))
+		}
+	}
+	if c.Schedule.CatchUpDays < 0 {
+		errs = append(errs, fmt.Errorf("schedule.catchUpDays 不能为负"))
+	}
+	if c.Schedule.Retry.Attempts < 1 || c.Schedule.Retry.BackoffMs < 0 {
+		errs = append(errs, fmt.Errorf("schedule.retry.attempts 应至少为1，backoffMs 不能为负"))
+	}
+	if c.Universe.LoadPct < 1 {
+		errs = append(errs, fmt.Errorf("universe.loadPct 应至少为1，实际为 %d", c.Universe.LoadPct))
+	}
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("server.addr %q 格式应为 host:port: %w", c.Server.Addr, err))
+	}
+	for _, f := range []struct{ name, path string }{
+		{"analysis.strategyConfig", c.Analysis.StrategyConfig},
+		{"analysis.alertConfig", c.Analysis.AlertConfig},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s 不存在", f.name, f.path))
+		}
+	}
+	errs = append(errs, c.Notify.validate()...)
+	return errors.Join(errs...)
+}
+
+// validate 校验消息通知配置
+func (c NotifyConfig) validate() []error {
+	var errs []error
+	if (c.WeChat.CorpID == "") != (c.WeChat.CorpSecret == "") {
+		errs = append(errs, fmt.Errorf("notify.wechat 的 corpId 和 corpSecret 需要同时设置"))
+	}
+	if c.WeChat.Enabled() && c.WeChat.AgentID == "" {
+		errs = append(errs, fmt.Errorf("notify.wechat.agentId 不能为空"))
+	}
+	if c.Webhook.URL != "" {
+		if u, err := url.Parse(c.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("notify.webhook.url 应为 http(s) 地址"))
+		}
+	}
+	if c.SMTP.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.SMTP.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("notify.smtp.addr %q 格式应为 host:port: %w", c.SMTP.Addr, err))
+		}
+		if c.SMTP.From == "" || len(c.SMTP.To) == 0 {
+			errs = append(errs, fmt.Errorf("notify.smtp 需要设置 from 和 to"))
+		}
+	}
+	if c.Retry.Attempts < 1 || c.Retry.BackoffMs < 0 {
+		errs = append(errs, fmt.Errorf("notify.retry.attempts 应至少为1，backoffMs 不能为负"))
+	}
+	return errs
+}
+
+var (
+	mu      sync.RWMutex
+	current = DefaultConfig()
+)
+
+// Current 当前生效的配置（只读，不要修改）
+func Current() *Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Apply 使配置生效：更新 DATA_PATH 等全局变量，只在程序启动时调用（见 Setup）
+// 这些全局变量在程序中直接读取，运行中不再修改，重新加载配置见 Reload
+func Apply(cfg *Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = cfg
+	DATA_PATH = cfg.DataPath
+	LOG_PATH = cfg.LogPath
+	ExecuteUpdataDataTime = cfg.Schedule.UpdateDataTime
+	ExecuteAnalyseDataTime = cfg.Schedule.AnalyseDataTime
+	STOCK_DATA_LOAD_PCT = cfg.Universe.LoadPct
+}
+
+// Setup 加载、校验并应用配置，程序启动时在 flag.Parse 之后调用
+func Setup(flags *Flags) (*Config, error) {
+	cfg, err := Load(flags)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("配置校验失败:\n%w", err)
+	}
+	Apply(cfg)
+	return cfg, nil
+}
+
+// Reload 重新加载配置（收到 SIGHUP 时调用），加载或校验失败时保留当前配置
+// 只在启动时生效的配置（见 keepStartupSettings）保留启动时的值，restart 列出这些配置的变化
+// 返回重新加载前后的配置，用 Changes 列出其余的差异
+func Reload(flags *Flags) (old, cfg *Config, restart []string, err error) {
+	old = Current()
+	if cfg, err = Load(flags); err != nil {
+		return old, nil, nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return old, nil, nil, fmt.Errorf("配置校验失败:\n%w", err)
+	}
+	restart = keepStartupSettings(old, cfg)
+
+	mu.Lock()
+	defer mu.Unlock()
+	current = cfg
+	return old, cfg, restart, nil
+}
+
+// keepStartupSettings 重新加载时保留只在启动时生效的配置，返回需要重启才能生效的变化
+// 数据目录、日志目录、加载比例对应的全局变量（DATA_PATH、LOG_PATH、STOCK_DATA_LOAD_PCT）在程序中直接读取，
+// 服务地址在启动时监听，运行中都不修改；
+// 定时任务在 updateDataEveryDay、analyseDataEveryDay 程序中运行，启动时读取 schedule，不处理重新加载
+func keepStartupSettings(old, cfg *Config) []string {
+	var restart []string
+	keep := func(name string, a string, b *string) {
+		if a != *b {
+			restart = append(restart, fmt.Sprintf("%s: %s -> %s", name, a, *b))
+			*b = a
+		}
+	}
+	keep("dataPath", old.DataPath, &cfg.DataPath)
+	keep("logPath", old.LogPath, &cfg.LogPath)
+	keep("server.addr", old.Server.Addr, &cfg.Server.Addr)
+	if old.Universe.LoadPct != cfg.Universe.LoadPct {
+		restart = append(restart, fmt.Sprintf("universe.loadPct: %d -> %d", old.Universe.LoadPct, cfg.Universe.LoadPct))
+		cfg.Universe.LoadPct = old.Universe.LoadPct
+	}
+	if changes := scheduleChanges(old.Schedule, cfg.Schedule); len(changes) > 0 {
+		restart = append(restart, changes...)
+		cfg.Schedule = old.Schedule
+	}
+	return restart
+}
+
+// scheduleChanges 列出定时任务配置的差异
+func scheduleChanges(old, cfg ScheduleConfig) []string {
+	var changes []string
+	diff := func(name, a, b string) {
+		if a != b {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, a, b))
+		}
+	}
+	diff("schedule.updateDataTime", old.UpdateDataTime, cfg.UpdateDataTime)
+	diff("schedule.analyseDataTime", old.AnalyseDataTime, cfg.AnalyseDataTime)
+	diff("schedule.analyseAfterUpdate", strconv.FormatBool(old.AnalyseAfterUpdate), strconv.FormatBool(cfg.AnalyseAfterUpdate))
+	diff("schedule.timezone", old.Timezone, cfg.Timezone)
+	diff("schedule.holidayFile", old.HolidayFile, cfg.HolidayFile)
+	diff("schedule.catchUpDays", strconv.Itoa(old.CatchUpDays), strconv.Itoa(cfg.CatchUpDays))
+	diff("schedule.retry.attempts", strconv.Itoa(old.Retry.Attempts), strconv.Itoa(cfg.Retry.Attempts))
+	diff("schedule.retry.backoffMs", strconv.Itoa(old.Retry.BackoffMs), strconv.Itoa(cfg.Retry.BackoffMs))
+	return changes
+}
+
+// Changes 列出两份配置的差异（用于重新加载时记录日志，密钥只显示是否变化）
+func Changes(old, cfg *Config) []string {
+	var changes []string
+	diff := func(name, a, b string) {
+		if a != b {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, a, b))
+		}
+	}
+	diff("dataPath", old.DataPath, cfg.DataPath)
+	diff("logPath", old.LogPath, cfg.LogPath)
+	changes = append(changes, scheduleChanges(old.Schedule, cfg.Schedule)...)
+	diff("universe.loadPct", strconv.Itoa(old.Universe.LoadPct), strconv.Itoa(cfg.Universe.LoadPct))
+	diff("server.addr", old.Server.Addr, cfg.Server.Addr)
+	diff("analysis.strategyConfig", old.Analysis.StrategyConfig, cfg.Analysis.StrategyConfig)
+	diff("analysis.alertConfig", old.Analysis.AlertConfig, cfg.Analysis.AlertConfig)
+	diff("notify.wechat.corpId", old.Notify.WeChat.CorpID, cfg.Notify.WeChat.CorpID)
+	diff("notify.wechat.agentId", old.Notify.WeChat.AgentID, cfg.Notify.WeChat.AgentID)
+	diff("notify.wechat.toUser", old.Notify.WeChat.ToUser, cfg.Notify.WeChat.ToUser)
+	diff("notify.smtp.addr", old.Notify.SMTP.Addr, cfg.Notify.SMTP.Addr)
+	diff("notify.smtp.from", old.Notify.SMTP.From, cfg.Notify.SMTP.From)
+	diff("notify.smtp.to", strings.Join(old.Notify.SMTP.To, ","), strings.Join(cfg.Notify.SMTP.To, ","))
+	diff("notify.file.path", old.Notify.File.Path, cfg.Notify.File.Path)
+	diff("notify.retry.attempts", strconv.Itoa(old.Notify.Retry.Attempts), strconv.Itoa(cfg.Notify.Retry.Attempts))
+	diff("notify.retry.backoffMs", strconv.Itoa(old.Notify.Retry.BackoffMs), strconv.Itoa(cfg.Notify.Retry.BackoffMs))
+	for _, secret := range []struct{ name, a, b string }{
+		{"notify.wechat.corpSecret", old.Notify.WeChat.CorpSecret, cfg.Notify.WeChat.CorpSecret},
+		{"notify.webhook.url", old.Notify.Webhook.URL, cfg.Notify.Webhook.URL},
+		{"notify.smtp.username", old.Notify.SMTP.Username, cfg.Notify.SMTP.Username},
+		{"notify.smtp.password", old.Notify.SMTP.Password, cfg.Notify.SMTP.Password},
+	} {
+		if secret.a != secret.b {
+			changes = append(changes, secret.name+": 已更新")
+		}
+	}
+	return changes
+}
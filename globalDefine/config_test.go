@@ -0,0 +1,163 @@
+package globaldefine
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig 在临时目录写入配置文件，并把 dataPath 指向临时目录
+func writeConfig(t *testing.T, src string) string {
+	dir := t.TempDir()
+	src = strings.ReplaceAll(src, "$DIR", filepath.ToSlash(dir))
+	path := filepath.Join(dir, "stock.json")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// parseFlags 解析命令行参数
+func parseFlags(t *testing.T, args ...string) *Flags {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags
+}
+
+// TestLoadLayers 测试 默认值 -> 配置文件 -> 环境变量 -> 命令行参数 的覆盖顺序
+func TestLoadLayers(t *testing.T) {
+	path := writeConfig(t, `{
+		"dataPath": "$DIR",
+		"schedule": {"updateDataTime": "18:00", "analyseDataTime": "18:30"},
+		"universe": {"loadPct": 2},
+		"server": {"addr": ":9000"},
+		"notify": {"wechat": {"corpId": "file-corp", "corpSecret": "file-secret"}}
+	}`)
+	t.Setenv("STOCK_ANALYSE_TIME", "20:00")
+	t.Setenv("STOCK_SERVER_ADDR", ":9100")
+	t.Setenv("STOCK_WECHAT_CORP_SECRET", "env-secret")
+
+	cfg, err := Load(parseFlags(t, "-conf", path, "-addr", "127.0.0.1:9200"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.File() != path || !strings.HasSuffix(cfg.DataPath, string(filepath.Separator)) {
+		t.Errorf("配置文件 %q，数据目录 %q 应以分隔符结尾", cfg.File(), cfg.DataPath)
+	}
+	if cfg.LogPath != "../Log/" {
+		t.Errorf("未配置的日志目录应使用默认值，实际为 %q", cfg.LogPath)
+	}
+	if cfg.Schedule.UpdateDataTime != "18:00" || cfg.Universe.LoadPct != 2 {
+		t.Errorf("配置文件应覆盖默认值: %+v", cfg)
+	}
+	if cfg.Schedule.AnalyseDataTime != "20:00" || cfg.Notify.WeChat.CorpSecret != "env-secret" || cfg.Notify.WeChat.CorpID != "file-corp" {
+		t.Errorf("环境变量应覆盖配置文件: %+v", cfg)
+	}
+	if cfg.Server.Addr != "127.0.0.1:9200" {
+		t.Errorf("命令行参数应覆盖环境变量，地址为 %q", cfg.Server.Addr)
+	}
+
+	// 没有出现在命令行中的参数不覆盖环境变量
+	cfg, err = Load(parseFlags(t, "-conf", path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != ":9100" {
+		t.Errorf("地址 %q，期望使用环境变量 :9100", cfg.Server.Addr)
+	}
+}
+
+// TestLoadErrors 测试配置文件和环境变量错误
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(parseFlags(t, "-conf", filepath.Join(t.TempDir(), "missing.json"))); err == nil {
+		t.Error("显式指定的配置文件不存在时应返回错误")
+	}
+	path := writeConfig(t, `{"dataPth": "/tmp"}`)
+	if _, err := Load(parseFlags(t, "-conf", path)); err == nil || !strings.Contains(err.Error(), `unknown field "dataPth"`) {
+		t.Errorf("未知字段错误 %v", err)
+	}
+	t.Setenv("STOCK_LOAD_PCT", "four")
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "STOCK_LOAD_PCT") {
+		t.Errorf("环境变量错误 %v", err)
+	}
+}
+
+// TestValidate 测试配置校验
+func TestValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	cfg.LogPath = filepath.Join(t.TempDir(), "log", "sub")
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cfg.LogPath); err != nil {
+		t.Error("日志目录不存在时应创建")
+	}
+	cfg.Schedule.AnalyseDataTime = "30 19 * * 1-5"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("cron 表达式应通过校验: %v", err)
+	}
+
+	cfg.DataPath = filepath.Join(cfg.DataPath, "missing")
+	cfg.Schedule.UpdateDataTime = "7pm"
+	cfg.Schedule.Timezone = "Mars/Olympus"
+	cfg.Universe.LoadPct = 0
+	cfg.Server.Addr = "8080"
+	cfg.Notify.WeChat.CorpID = "corp"
+	err := cfg.Validate()
+	for _, want := range []string{"dataPath", "schedule.updateDataTime", "schedule.timezone", "universe.loadPct", "server.addr", "corpSecret"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("错误 %v，期望包含 %q", err, want)
+		}
+	}
+}
+
+// TestReload 测试重新加载：成功时更新配置，只在启动时生效的配置和全局变量不变，失败时保留当前配置
+func TestReload(t *testing.T) {
+	saved := Current()
+	t.Cleanup(func() { Apply(saved) })
+
+	path := writeConfig(t, `{"dataPath": "$DIR", "logPath": "$DIR", "universe": {"loadPct": 3}, "schedule": {"catchUpDays": 2}}`)
+	flags := parseFlags(t, "-conf", path)
+	if _, err := Setup(flags); err != nil {
+		t.Fatal(err)
+	}
+	if STOCK_DATA_LOAD_PCT != 3 || DATA_PATH != Current().DataPath {
+		t.Fatalf("配置没有生效: loadPct=%d dataPath=%s", STOCK_DATA_LOAD_PCT, DATA_PATH)
+	}
+
+	content := strings.Replace(readFile(t, path), `"loadPct": 3`, `"loadPct": 5`, 1)
+	os.WriteFile(path, []byte(strings.Replace(content, `"catchUpDays": 2`, `"catchUpDays": 4`, 1)), 0o644)
+	old, cfg, restart, err := Reload(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := Changes(old, cfg); len(changes) != 0 {
+		t.Errorf("变化 %v", changes)
+	}
+	if len(restart) != 2 || restart[0] != "universe.loadPct: 3 -> 5" || restart[1] != "schedule.catchUpDays: 2 -> 4" ||
+		cfg.Universe.LoadPct != 3 || STOCK_DATA_LOAD_PCT != 3 || cfg.Schedule.CatchUpDays != 2 {
+		t.Errorf("需要重启的变化 %v，loadPct=%d/%d", restart, cfg.Universe.LoadPct, STOCK_DATA_LOAD_PCT)
+	}
+
+	os.WriteFile(path, []byte(strings.Replace(readFile(t, path), `"loadPct": 5`, `"loadPct": 0`, 1)), 0o644)
+	if _, _, _, err := Reload(flags); err == nil {
+		t.Fatal("校验失败时应返回错误")
+	}
+	if Current() != cfg {
+		t.Error("重新加载失败时应保留当前配置")
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -1,6 +1,9 @@
 package globaldefine
 
-import "runtime"
+import (
+	"fmt"
+	"os"
+)
 
 const (
 	STOCK_SESSION_LEN            = 500
@@ -8,31 +11,28 @@ const (
 )
 
 const (
-	STOCK_DATA_LOAD_PCT = 4
 	STOCK_DATA_LOAD_MOD = 1
 )
 
+// STOCK_DATA_LOAD_PCT 随机加载 1/STOCK_DATA_LOAD_PCT 的票票，启动时由配置 universe.loadPct 设置
+var STOCK_DATA_LOAD_PCT = 4
+
+// 以下变量在程序启动时由配置设置（见 config.go 的 Setup），程序中读取这些变量即可
+// 运行中重新加载配置不会修改这些变量，修改对应的配置后需要重启
 var DATA_PATH = "../Data/"
 var LOG_PATH = "../Log/"
 
 var ExecuteUpdataDataTime = "19:00"
 var ExecuteAnalyseDataTime = "19:30"
 
+// init 按配置文件和环境变量设置默认路径（不读取命令行参数，也不校验）
+// 使得日志等在 main 调用 Setup 之前就使用配置的目录
 func init() {
-
-	sysType := runtime.GOOS
-	if sysType == "linux" {
-		DATA_PATH = "/home/beven/gits/stockData/"
-		LOG_PATH = "../Log/"
-	}
-
-	if sysType == "darwin" {
-		DATA_PATH = "/Users/beven/Item/stockData/"
-		LOG_PATH = "/Users/beven/Item/stock-go/Log/"
-	}
-
-	if sysType == "windows" {
-		DATA_PATH = "D:\\Data\\"
-		LOG_PATH = "D:\\Log\\"
+	cfg, err := Load(nil)
+	if err != nil {
+		// logger 依赖本包，这里只能输出到标准错误
+		fmt.Fprintln(os.Stderr, "加载配置失败，使用默认配置:", err)
+		return
 	}
+	Apply(cfg)
 }
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"stock-go/alert"
+	globalDefine "stock-go/globalDefine"
+	"strconv"
+	"time"
+)
+
+// alertScorecard 返回各预警规则的效果评分
+// 参数 days 统计最近多少天的预警（默认使用预警配置的 scorecardDays），detail=true 时附带每条预警的表现
+func alertScorecard(w http.ResponseWriter, r *http.Request) {
+	cfg := alert.DefaultConfig()
+	if path := globalDefine.Current().Analysis.AlertConfig; path != "" {
+		loaded, err := alert.LoadConfig(path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		cfg = loaded
+	}
+
+	query := r.URL.Query()
+	days := cfg.ScorecardDays
+	if s := query.Get("days"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > cfg.HistoryDays {
+			http.Error(w, "days 应为 1 到 "+strconv.Itoa(cfg.HistoryDays)+" 之间的整数", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	detail, _ := strconv.ParseBool(query.Get("detail"))
+
+	slog.Info("alert scorecard request", "days", days, "detail", detail)
+	card, err := alert.Score(cfg, time.Now(), days, detail)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, card)
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"stock-go/stockStrategy/registry"
+	"stock-go/tradeTest/runner"
+	"sync"
+)
+
+// backtestMu 同一时间只运行一个回测，回测占用大量内存和CPU
+var backtestMu sync.Mutex
+
+// maxBacktestBody 回测配置请求体的最大字节数
+const maxBacktestBody = 1 << 20
+
+// backtest 按请求体中的配置（与回测配置文件格式相同）运行回测，返回结果摘要
+func backtest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "请使用 POST 提交回测配置", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBacktestBody))
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	cfg, err := runner.ParseConfig(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(cfg.Backtest.Universe.Codes) == 0 && cfg.Backtest.Universe.SampleSeed == 0 {
+		http.Error(w, "backtest.universe 需要指定 codes 或 sampleSeed", http.StatusBadRequest)
+		return
+	}
+	if !backtestMu.TryLock() {
+		http.Error(w, "已有回测在运行，请稍后再试", http.StatusTooManyRequests)
+		return
+	}
+	defer backtestMu.Unlock()
+
+	slog.Info("backtest request", "name", cfg.Name)
+	result, err := cfg.RunIsolated(true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, cfg.Summarize(result))
+}
+
+// components 列出注册表中所有可用的策略组件和参数
+func components(w http.ResponseWriter, r *http.Request) {
+	list := make(map[registry.Kind][]*registry.Component, len(registry.Kinds))
+	for _, kind := range registry.Kinds {
+		list[kind] = registry.List(kind)
+	}
+	writeJSON(w, list)
+}
+
+// writeJSON 以JSON格式返回
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("write response failed", "err", err)
+	}
+}
@@ -3,15 +3,19 @@ package http
 import (
 	"log"
 	"net/http"
+	globalDefine "stock-go/globalDefine"
 )
 
 func init() {
 	http.HandleFunc("/stock", stock)
 	http.HandleFunc("/readDayDate", readDayDate)
+	http.HandleFunc("/backtest", backtest)
+	http.HandleFunc("/components", components)
+	http.HandleFunc("/alerts/scorecard", alertScorecard)
 
 }
 
 func StartServer() {
-	// 启动HTTP服务器，监听本地8080端口
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	// 启动HTTP服务器，监听配置的地址（默认 :8080）
+	log.Fatal(http.ListenAndServe(globalDefine.Current().Server.Addr, nil))
 }
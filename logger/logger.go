@@ -18,6 +18,8 @@ import (
 var (
 	once           sync.Once
 	defaultHandler *CustomTextHandler // 导出处理器实例
+	defaultFile    *os.File           // 当前的日志文件
+	defaultDir     string             // 当前的日志目录
 )
 
 // 自定义文本处理器，直接控制输出格式
@@ -256,39 +258,76 @@ func NewLineHandler(h slog.Handler) *LineHandler {
 // Init 初始化日志系统，确保只初始化一次
 func Init() {
 	once.Do(func() {
-		// 获取进程PID
-		processPID := os.Getpid()
-
-		// 获取启动时间（当前时间）
-		startTime := time.Now().Format("01021504") // MMDDHHMM 格式
-
-		// 构建日志文件名
-		logFileName := fmt.Sprintf("stock_%d_%s.log", processPID, startTime)
-
-		logFile, err := os.OpenFile(globalDefine.LOG_PATH+logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		logFile, logFileName, err := openLogFile(globalDefine.LOG_PATH)
 		if err != nil {
 			fmt.Printf("无法打开日志文件: %v\n", err)
 			return
 		}
+		install(logFile)
+		defaultDir = filepath.Clean(globalDefine.LOG_PATH)
 
-		// 创建一个多输出写入器，同时写入文件和标准输出
-		multiWriter := io.MultiWriter(logFile, os.Stdout)
+		// 输出一条测试日志，验证行号是否正常
+		slog.Info("日志系统初始化完成", "logFile", logFileName)
+	})
+}
 
-		// 创建自定义文本处理器
-		textHandler := NewCustomTextHandler(multiWriter, &slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		})
-		defaultHandler = textHandler // 保存处理器实例
+// openLogFile 在日志目录下创建日志文件，文件名包含进程PID和启动时间
+func openLogFile(dir string) (*os.File, string, error) {
+	// 获取启动时间（当前时间）
+	startTime := time.Now().Format("01021504") // MMDDHHMM 格式
 
-		// 添加行号信息
-		handler := NewLineHandler(textHandler)
+	// 构建日志文件名
+	logFileName := fmt.Sprintf("stock_%d_%s.log", os.Getpid(), startTime)
 
-		// 设置全局 logger
-		slog.SetDefault(slog.New(handler))
+	logFile, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	return logFile, logFileName, err
+}
 
-		// 输出一条测试日志，验证行号是否正常
-		slog.Info("日志系统初始化完成", "logFile", logFileName)
+// install 创建写入日志文件和标准输出的处理器，并设置为全局 logger
+func install(logFile *os.File) {
+	// 创建一个多输出写入器，同时写入文件和标准输出
+	multiWriter := io.MultiWriter(logFile, os.Stdout)
+
+	// 创建自定义文本处理器
+	textHandler := NewCustomTextHandler(multiWriter, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
 	})
+	defaultHandler = textHandler // 保存处理器实例
+	defaultFile = logFile
+
+	// 添加行号信息
+	handler := NewLineHandler(textHandler)
+
+	// 设置全局 logger
+	slog.SetDefault(slog.New(handler))
+}
+
+// SetLogPath 切换日志目录（配置加载或重新加载后调用），之后的日志写入新目录下的日志文件
+// 目录没有变化时不做任何操作
+func SetLogPath(dir string) error {
+	if defaultHandler != nil && filepath.Clean(dir) == defaultDir {
+		return nil
+	}
+	logFile, logFileName, err := openLogFile(dir)
+	if err != nil {
+		return err
+	}
+
+	if defaultHandler == nil {
+		install(logFile)
+	} else {
+		defaultHandler.mu.Lock()
+		defaultHandler.w = io.MultiWriter(logFile, os.Stdout)
+		old := defaultFile
+		defaultFile = logFile
+		defaultHandler.mu.Unlock()
+		if old != nil {
+			old.Close()
+		}
+	}
+	defaultDir = filepath.Clean(dir)
+	slog.Info("日志目录已切换", "dir", dir, "logFile", logFileName)
+	return nil
 }
 
 // Close 关闭日志处理器，确保所有日志都被写入
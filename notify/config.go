@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	globalDefine "stock-go/globalDefine"
+	"stock-go/logger"
+	"sync"
+	"time"
+)
+
+// FromConfig 按配置创建通知渠道：配置了的渠道都发送，每个渠道独立重试
+// 没有配置任何渠道时写入标准输出
+func FromConfig(cfg globalDefine.NotifyConfig) Notifier {
+	var notifiers []Notifier
+	if cfg.WeChat.Enabled() {
+		notifiers = append(notifiers, NewWeChat(cfg.WeChat))
+	}
+	if cfg.Webhook.URL != "" {
+		notifiers = append(notifiers, NewWebhook(cfg.Webhook.URL))
+	}
+	if cfg.SMTP.Addr != "" {
+		notifiers = append(notifiers, NewSMTP(cfg.SMTP))
+	}
+	if cfg.File.Path != "" {
+		notifiers = append(notifiers, NewFile(cfg.File.Path))
+	}
+	if len(notifiers) == 0 {
+		notifiers = append(notifiers, NewFile("-"))
+	}
+
+	backoff := time.Duration(cfg.Retry.BackoffMs) * time.Millisecond
+	for i, n := range notifiers {
+		notifiers[i] = Retry(n, cfg.Retry.Attempts, backoff)
+	}
+	return Multi(notifiers...)
+}
+
+var (
+	defaultMu       sync.Mutex
+	defaultConfig   *globalDefine.Config
+	defaultNotifier Notifier
+)
+
+// Default 按当前配置创建的通知渠道，配置重新加载后重新创建（同一份配置复用，企业微信令牌得以缓存）
+func Default() Notifier {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if cfg := globalDefine.Current(); cfg != defaultConfig {
+		defaultConfig = cfg
+		defaultNotifier = FromConfig(cfg.Notify)
+	}
+	return defaultNotifier
+}
+
+// Send 用 Default 发送消息，失败时记录日志并返回错误
+func Send(ctx context.Context, msg Message) error {
+	n := Default()
+	if err := n.Notify(ctx, msg); err != nil {
+		logger.Errorf("发送通知失败 [%s]: %v", n.Name(), err)
+		return err
+	}
+	logger.Infof("发送通知成功 [%s]: %s", n.Name(), msg.Title)
+	return nil
+}
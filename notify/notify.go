@@ -0,0 +1,100 @@
+// Package notify 消息通知
+//
+// 所有渠道都实现 Notifier 接口：企业微信应用消息、通用 webhook、SMTP 邮件、文件/标准输出。
+// Retry 为渠道加上指数退避重试，Multi 把消息同时发送到多个渠道，
+// FromConfig 按 globalDefine 的 notify 配置组合出这些渠道。
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Message 通知消息
+type Message struct {
+	Title   string // 标题，可以为空
+	Content string // 正文，按行组织
+}
+
+// Text 纯文本形式：标题单独一行，后接正文
+func (m Message) Text() string {
+	if m.Title == "" {
+		return m.Content
+	}
+	if m.Content == "" {
+		return m.Title
+	}
+	return m.Title + "\n" + m.Content
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	// Name 渠道名称，用于日志和错误信息
+	Name() string
+	// Notify 发送消息，ctx 取消时停止发送和重试
+	Notify(ctx context.Context, msg Message) error
+}
+
+// permanentError 重试也不会成功的错误（如凭据错误、请求被拒绝）
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记错误不需要重试
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// IsPermanent 是否为不需要重试的错误
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// splitByLines 按换行符拆分消息，每个片段不超过 maxBytes 字节（单行超过时单独成为一个片段）
+func splitByLines(text string, maxBytes int) []string {
+	var chunks []string
+	current := ""
+	for _, line := range strings.Split(text, "\n") {
+		candidate := line
+		if current != "" {
+			candidate = current + "\n" + line
+		}
+		if len(candidate) <= maxBytes {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			chunks = append(chunks, current)
+		}
+		current = line
+		if len(current) > maxBytes {
+			chunks = append(chunks, current)
+			current = ""
+		}
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// numberChunks 多个片段时加上序号 [i/n]
+func numberChunks(chunks []string) []string {
+	if len(chunks) <= 1 {
+		return chunks
+	}
+	numbered := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		numbered[i] = fmt.Sprintf("[%d/%d]\n%s", i+1, len(chunks), chunk)
+	}
+	return numbered
+}
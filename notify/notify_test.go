@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	globalDefine "stock-go/globalDefine"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flaky 前 failures 次发送失败
+type flaky struct {
+	name     string
+	failures int
+	err      error
+	calls    atomic.Int32
+}
+
+func (f *flaky) Name() string { return f.name }
+
+func (f *flaky) Notify(ctx context.Context, msg Message) error {
+	if int(f.calls.Add(1)) <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+// TestRetry 测试重试次数、退避时间、不重试的错误
+func TestRetry(t *testing.T) {
+	var delays []time.Duration
+	newRetry := func(n Notifier) *retryNotifier {
+		r := Retry(n, 3, 10*time.Millisecond).(*retryNotifier)
+		r.sleep = func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		}
+		return r
+	}
+
+	f := &flaky{name: "a", failures: 2, err: errors.New("timeout")}
+	if err := newRetry(f).Notify(context.Background(), Message{}); err != nil || f.calls.Load() != 3 {
+		t.Fatalf("第3次应成功: %v, 发送 %d 次", err, f.calls.Load())
+	}
+	if len(delays) != 2 || delays[0] != 10*time.Millisecond || delays[1] != 20*time.Millisecond {
+		t.Errorf("退避时间 %v", delays)
+	}
+
+	f = &flaky{name: "b", failures: 5, err: errors.New("timeout")}
+	if err := newRetry(f).Notify(context.Background(), Message{}); err == nil || f.calls.Load() != 3 {
+		t.Errorf("最多发送3次: %v, 发送 %d 次", err, f.calls.Load())
+	}
+
+	f = &flaky{name: "c", failures: 5, err: Permanent(errors.New("forbidden"))}
+	if err := newRetry(f).Notify(context.Background(), Message{}); err == nil || f.calls.Load() != 1 {
+		t.Errorf("不需要重试的错误只发送1次，发送 %d 次", f.calls.Load())
+	}
+
+	// ctx 取消时停止等待
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f = &flaky{name: "d", failures: 5, err: errors.New("timeout")}
+	if err := Retry(f, 3, time.Hour).Notify(ctx, Message{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx 取消时错误 %v", err)
+	}
+}
+
+// TestMulti 测试同时发送到多个渠道：一个渠道失败不影响其他渠道，至少一个渠道成功即视为成功
+func TestMulti(t *testing.T) {
+	ok := &flaky{name: "ok"}
+	bad := &flaky{name: "bad", failures: 1, err: errors.New("down")}
+	var buf bytes.Buffer
+	m := Multi(ok, bad, NewWriter(&buf))
+	if err := m.Notify(context.Background(), Message{Title: "标题", Content: "内容"}); err != nil {
+		t.Errorf("部分渠道成功时不应返回错误: %v", err)
+	}
+	if ok.calls.Load() != 1 || bad.calls.Load() != 1 || !strings.Contains(buf.String(), "标题\n内容\n") {
+		t.Errorf("其他渠道应正常发送: %q", buf.String())
+	}
+	if m.Name() != "ok+bad+输出" {
+		t.Errorf("名称 %s", m.Name())
+	}
+
+	// 全部失败时返回所有渠道的错误
+	m = Multi(&flaky{name: "a", failures: 1, err: errors.New("down")}, &flaky{name: "b", failures: 1, err: errors.New("timeout")})
+	if err := m.Notify(context.Background(), Message{}); err == nil || err.Error() != "a: down\nb: timeout" {
+		t.Errorf("错误 %v", err)
+	}
+}
+
+// TestWebhook 测试 webhook 请求体和错误分类
+func TestWebhook(t *testing.T) {
+	var got webhookPayload
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Error("Content-Type 应为 application/json")
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	w := NewWebhook(server.URL + "/hook?token=secret")
+	if err := w.Notify(context.Background(), Message{Title: "标题", Content: "内容"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "标题" || got.Content != "内容" || got.Text != "标题\n内容" || got.Time == "" {
+		t.Errorf("请求体 %+v", got)
+	}
+
+	status = http.StatusBadRequest
+	if err := w.Notify(context.Background(), Message{}); err == nil || !IsPermanent(err) {
+		t.Errorf("4xx 不应重试: %v", err)
+	}
+	status = http.StatusBadGateway
+	if err := w.Notify(context.Background(), Message{}); err == nil || IsPermanent(err) {
+		t.Errorf("5xx 应可重试: %v", err)
+	}
+
+	server.Close()
+	if err := w.Notify(context.Background(), Message{}); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("错误信息不应包含地址中的令牌: %v", err)
+	}
+}
+
+// TestSMTP 测试邮件内容和认证
+func TestSMTP(t *testing.T) {
+	s := NewSMTP(globalDefine.SMTPConfig{Addr: "smtp.example.com:465", Username: "u", Password: "p", From: "a@example.com", To: []string{"b@example.com", "c@example.com"}})
+	var sent []byte
+	var auth smtp.Auth
+	s.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		if addr != "smtp.example.com:465" || from != "a@example.com" || len(to) != 2 {
+			t.Errorf("发送参数 %s %s %v", addr, from, to)
+		}
+		sent, auth = msg, a
+		return nil
+	}
+	if err := s.Notify(context.Background(), Message{Content: "买入信号\nsz.000001"}); err != nil {
+		t.Fatal(err)
+	}
+	text := string(sent)
+	if auth == nil || !strings.Contains(text, "Subject: =?UTF-8?b?") || !strings.Contains(text, "To: b@example.com, c@example.com\r\n") ||
+		!strings.HasSuffix(text, "\r\n\r\n买入信号\r\nsz.000001\r\n") {
+		t.Errorf("邮件内容 %q", text)
+	}
+}
+
+// TestFromConfig 测试按配置组合渠道，文件渠道追加写入
+func TestFromConfig(t *testing.T) {
+	if n := FromConfig(globalDefine.DefaultConfig().Notify); n.Name() != "标准输出" {
+		t.Errorf("没有配置渠道时应输出到标准输出，实际为 %s", n.Name())
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.log")
+	cfg := globalDefine.DefaultConfig().Notify
+	cfg.File.Path = path
+	cfg.Webhook.URL = "http://127.0.0.1:1/hook"
+	n := FromConfig(cfg)
+	if n.Name() != "webhook+文件 "+path {
+		t.Errorf("渠道 %s", n.Name())
+	}
+
+	file := NewFile(path)
+	file.Notify(context.Background(), Message{Title: "一", Content: "1"})
+	file.Notify(context.Background(), Message{Title: "二", Content: "2"})
+	data, _ := os.ReadFile(path)
+	if strings.Count(string(data), "\n\n") != 2 || !strings.Contains(string(data), "二\n2\n") {
+		t.Errorf("文件内容 %q", data)
+	}
+}
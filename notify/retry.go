@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stock-go/logger"
+	"sync"
+	"time"
+)
+
+// retryNotifier 失败时按指数退避重试
+type retryNotifier struct {
+	Notifier
+	attempts int
+	backoff  time.Duration
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+// Retry 发送失败时重试，最多发送 attempts 次，第一次重试前等待 backoff，之后每次翻倍
+// Permanent 标记的错误和 ctx 取消不重试
+func Retry(n Notifier, attempts int, backoff time.Duration) Notifier {
+	if attempts <= 1 {
+		return n
+	}
+	return &retryNotifier{Notifier: n, attempts: attempts, backoff: backoff, sleep: sleepContext}
+}
+
+// Notify 发送消息，失败时重试（分多条发送的渠道从第一条失败的消息继续）
+func (r *retryNotifier) Notify(ctx context.Context, msg Message) error {
+	ctx = withProgress(ctx)
+	delay := r.backoff
+	var err error
+	for i := 1; ; i++ {
+		if err = r.Notifier.Notify(ctx, msg); err == nil || IsPermanent(err) || i >= r.attempts {
+			break
+		}
+		logger.Warnf("%s 发送失败，%v 后第%d次重试: %v", r.Name(), delay, i, err)
+		if e := r.sleep(ctx, delay); e != nil {
+			return errors.Join(err, e)
+		}
+		delay *= 2
+	}
+	return err
+}
+
+// progress 一次发送（含重试）中已经发送成功的分段数
+type progress struct {
+	sent int
+}
+
+type progressKey struct{}
+
+// withProgress 为一次发送记录分段进度，重试时分段发送的渠道据此跳过已经发送的分段
+func withProgress(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(progressKey{}).(*progress); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, &progress{})
+}
+
+// progressOf 本次发送的分段进度，没有重试时返回新的进度
+func progressOf(ctx context.Context) *progress {
+	if p, ok := ctx.Value(progressKey{}).(*progress); ok {
+		return p
+	}
+	return &progress{}
+}
+
+// sleepContext 等待 d，ctx 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// multiNotifier 把消息同时发送到多个渠道
+type multiNotifier []Notifier
+
+// Multi 同时发送到多个渠道，每个渠道独立发送（一个渠道失败不影响其他渠道）
+// 至少一个渠道发送成功即视为成功，失败的渠道只记录日志，避免调用方重试时向已送达的渠道重复发送；
+// 全部失败时返回所有渠道的错误
+func Multi(notifiers ...Notifier) Notifier {
+	if len(notifiers) == 1 {
+		return notifiers[0]
+	}
+	return multiNotifier(notifiers)
+}
+
+// Name 渠道名称
+func (m multiNotifier) Name() string {
+	names := ""
+	for i, n := range m {
+		if i > 0 {
+			names += "+"
+		}
+		names += n.Name()
+	}
+	return names
+}
+
+// Notify 并发发送到所有渠道，等待全部完成，至少一个渠道成功时返回nil
+func (m multiNotifier) Notify(ctx context.Context, msg Message) error {
+	errs := make([]error, len(m))
+	var wg sync.WaitGroup
+	for i, n := range m {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := n.Notify(ctx, msg); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", n.Name(), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == len(m) {
+		return errors.Join(errs...)
+	}
+	for _, err := range errs {
+		if err != nil {
+			logger.Errorf("发送通知失败（其他渠道已送达）%v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	globalDefine "stock-go/globalDefine"
+	"strings"
+	"time"
+)
+
+// SMTP 邮件通知
+type SMTP struct {
+	cfg      globalDefine.SMTPConfig
+	now      func() time.Time
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTP 创建邮件通知，配置了用户名时使用 PLAIN 认证（net/smtp 只允许在 TLS 或本机连接上使用）
+func NewSMTP(cfg globalDefine.SMTPConfig) *SMTP {
+	return &SMTP{cfg: cfg, now: time.Now, sendMail: smtp.SendMail}
+}
+
+// Name 渠道名称
+func (s *SMTP) Name() string {
+	return "邮件"
+}
+
+// Notify 发送邮件，标题为空时使用正文第一行作为主题
+// net/smtp 不支持 ctx，只在发送前检查是否已取消
+func (s *SMTP) Notify(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		host, _, _ := net.SplitHostPort(s.cfg.Addr)
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)
+	}
+	if err := s.sendMail(s.cfg.Addr, auth, s.cfg.From, s.cfg.To, s.build(msg)); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return nil
+}
+
+// build 构造邮件内容（UTF-8 纯文本，主题按 RFC 2047 编码）
+func (s *SMTP) build(msg Message) []byte {
+	subject := msg.Title
+	if subject == "" {
+		subject, _, _ = strings.Cut(msg.Content, "\n")
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", s.now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Content, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// webhookPayload webhook 请求体
+type webhookPayload struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Text    string `json:"text"` // 标题和正文合并的纯文本，便于只取一个字段的接收方
+	Time    string `json:"time"`
+}
+
+// Webhook 通用 webhook，以 JSON POST 消息
+// 返回 4xx（429 除外）时不重试，其余失败可以重试
+type Webhook struct {
+	url    string
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewWebhook 创建 webhook 通知
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// Name 渠道名称
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Notify 发送消息
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	body, _ := json.Marshal(webhookPayload{
+		Title:   msg.Title,
+		Content: msg.Content,
+		Text:    msg.Text(),
+		Time:    w.now().Format("2006-01-02 15:04:05"),
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// 地址中可能带有令牌，错误信息中去掉地址
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("webhook 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	err = fmt.Errorf("webhook 返回 HTTP %d", resp.StatusCode)
+	if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
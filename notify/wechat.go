@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	globalDefine "stock-go/globalDefine"
+	"sync"
+	"time"
+)
+
+const (
+	// WeChatAPI 企业微信接口地址
+	WeChatAPI = "https://qyapi.weixin.qq.com"
+	// wechatMaxBytes 企业微信文本消息的最大长度
+	wechatMaxBytes = 2048
+	// tokenMargin 令牌在过期前提前刷新的时间
+	tokenMargin = 5 * time.Minute
+)
+
+// 企业微信错误码：令牌无效或过期，需要重新获取令牌
+const (
+	wechatInvalidToken = 40014
+	wechatExpiredToken = 42001
+)
+
+// wechatPermanent 凭据或权限错误的错误码，重试也不会成功；其他错误码（如 -1 系统繁忙、45009 调用频率超限）可以重试
+var wechatPermanent = map[int]bool{
+	40001:  true, // secret 错误
+	40013:  true, // corpid 错误
+	40056:  true, // agentid 错误
+	40091:  true, // secret 不合法
+	48002:  true, // 接口没有权限
+	60011:  true, // 没有通讯录权限
+	60020:  true, // 服务器 IP 不在应用的可信 IP 中
+	81013:  true, // 接收人都不存在
+	301002: true, // 应用不能给该成员发消息
+}
+
+// wechatError 企业微信返回的错误，凭据和权限错误标记为不重试
+func wechatError(action string, res wechatResponse) error {
+	err := fmt.Errorf("企业微信%s失败: %d %s", action, res.ErrCode, res.ErrMsg)
+	if wechatPermanent[res.ErrCode] {
+		return Permanent(err)
+	}
+	return err
+}
+
+// wechatResponse 企业微信接口的公共返回字段
+type wechatResponse struct {
+	ErrCode     int    `json:"errcode"`
+	ErrMsg      string `json:"errmsg"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// WeChat 企业微信应用消息
+// 访问令牌在有效期内缓存复用，令牌失效时重新获取一次
+type WeChat struct {
+	cfg    globalDefine.WeChatConfig
+	api    string
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewWeChat 创建企业微信通知
+func NewWeChat(cfg globalDefine.WeChatConfig) *WeChat {
+	return NewWeChatWithAPI(cfg, WeChatAPI)
+}
+
+// NewWeChatWithAPI 使用指定的接口地址创建企业微信通知（测试或代理）
+func NewWeChatWithAPI(cfg globalDefine.WeChatConfig, api string) *WeChat {
+	return &WeChat{
+		cfg:    cfg,
+		api:    api,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// Name 渠道名称
+func (w *WeChat) Name() string {
+	return "企业微信"
+}
+
+// Notify 发送文本消息，超过2048字节时按行拆分成多条
+// 某一条失败时返回错误，重试（见 Retry）时从失败的那一条继续，已经发送的不重复发送
+func (w *WeChat) Notify(ctx context.Context, msg Message) error {
+	p := progressOf(ctx)
+	chunks := numberChunks(splitByLines(msg.Text(), wechatMaxBytes))
+	for ; p.sent < len(chunks); p.sent++ {
+		if err := w.send(ctx, chunks[p.sent]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send 发送一条消息，令牌失效时刷新令牌后再发送一次
+func (w *WeChat) send(ctx context.Context, text string) error {
+	toUser := w.cfg.ToUser
+	if toUser == "" {
+		toUser = "@all"
+	}
+	body, _ := json.Marshal(map[string]any{
+		"touser":  toUser,
+		"msgtype": "text",
+		"agentid": w.cfg.AgentID,
+		"text":    map[string]string{"content": text},
+		"safe":    0,
+	})
+
+	for attempt := 0; ; attempt++ {
+		token, err := w.Token(ctx)
+		if err != nil {
+			return err
+		}
+		var res wechatResponse
+		err = w.call(ctx, http.MethodPost, "/cgi-bin/message/send?access_token="+url.QueryEscape(token), body, &res)
+		if err != nil {
+			return err
+		}
+		switch {
+		case res.ErrCode == 0:
+			return nil
+		case (res.ErrCode == wechatInvalidToken || res.ErrCode == wechatExpiredToken) && attempt == 0:
+			w.invalidate(token)
+		default:
+			return wechatError("发送消息", res)
+		}
+	}
+}
+
+// Token 获取访问令牌，有效期内直接返回缓存的令牌
+func (w *WeChat) Token(ctx context.Context) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.token != "" && w.now().Before(w.expiresAt) {
+		return w.token, nil
+	}
+
+	query := url.Values{"corpid": {w.cfg.CorpID}, "corpsecret": {w.cfg.CorpSecret}}
+	var res wechatResponse
+	if err := w.call(ctx, http.MethodGet, "/cgi-bin/gettoken?"+query.Encode(), nil, &res); err != nil {
+		return "", err
+	}
+	if res.ErrCode != 0 {
+		return "", wechatError("获取令牌", res)
+	}
+	if res.AccessToken == "" {
+		return "", fmt.Errorf("企业微信获取令牌失败: 没有返回令牌")
+	}
+	w.token = res.AccessToken
+	w.expiresAt = w.now().Add(time.Duration(res.ExpiresIn)*time.Second - tokenMargin)
+	return w.token, nil
+}
+
+// invalidate 丢弃失效的令牌（其他请求已经刷新过时保留新令牌）
+func (w *WeChat) invalidate(token string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.token == token {
+		w.token = ""
+	}
+}
+
+// call 调用企业微信接口并解析返回的 JSON（错误信息中不包含带凭据的地址）
+func (w *WeChat) call(ctx context.Context, method, path string, body []byte, res *wechatResponse) error {
+	req, err := http.NewRequestWithContext(ctx, method, w.api+path, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("企业微信请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("企业微信请求失败: HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return fmt.Errorf("企业微信返回格式错误: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	globalDefine "stock-go/globalDefine"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWeChat 模拟企业微信接口：签发带序号的令牌，记录收到的消息
+type fakeWeChat struct {
+	mu        sync.Mutex
+	tokens    int      // 签发过的令牌数
+	revoked   string   // 被判定为过期的令牌
+	messages  []string // 收到的消息内容
+	agentIDs  []string
+	sendError int // 发送消息时返回的错误码
+	busyAt    int // 收到第 busyAt 条消息时返回一次 -1 系统繁忙
+}
+
+func (f *fakeWeChat) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cgi-bin/gettoken", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.URL.Query().Get("corpid") != "corp" || r.URL.Query().Get("corpsecret") != "secret" {
+			json.NewEncoder(w).Encode(map[string]any{"errcode": 40001, "errmsg": "invalid credential"})
+			return
+		}
+		f.tokens++
+		json.NewEncoder(w).Encode(map[string]any{"errcode": 0, "access_token": "token" + strconv.Itoa(f.tokens), "expires_in": 7200})
+	})
+	mux.HandleFunc("/cgi-bin/message/send", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		token := r.URL.Query().Get("access_token")
+		if token == f.revoked {
+			json.NewEncoder(w).Encode(map[string]any{"errcode": wechatExpiredToken, "errmsg": "access_token expired"})
+			return
+		}
+		if f.busyAt > 0 && len(f.messages)+1 == f.busyAt {
+			f.busyAt = 0
+			json.NewEncoder(w).Encode(map[string]any{"errcode": -1, "errmsg": "system busy"})
+			return
+		}
+		if f.sendError != 0 {
+			json.NewEncoder(w).Encode(map[string]any{"errcode": f.sendError, "errmsg": "error"})
+			return
+		}
+		var body struct {
+			AgentID string `json:"agentid"`
+			Text    struct {
+				Content string `json:"content"`
+			} `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		f.messages = append(f.messages, body.Text.Content)
+		f.agentIDs = append(f.agentIDs, body.AgentID)
+		json.NewEncoder(w).Encode(map[string]any{"errcode": 0, "errmsg": "ok"})
+	})
+	return mux
+}
+
+func newTestWeChat(t *testing.T) (*WeChat, *fakeWeChat) {
+	fake := &fakeWeChat{}
+	server := httptest.NewServer(fake.handler(t))
+	t.Cleanup(server.Close)
+	cfg := globalDefine.WeChatConfig{CorpID: "corp", CorpSecret: "secret", AgentID: "1000002"}
+	return NewWeChatWithAPI(cfg, server.URL), fake
+}
+
+// TestWeChatTokenCache 测试令牌缓存：有效期内复用，过期后重新获取，接口判定过期时刷新后重发
+func TestWeChatTokenCache(t *testing.T) {
+	w, fake := newTestWeChat(t)
+	now := time.Date(2024, 1, 2, 19, 30, 0, 0, time.Local)
+	w.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := w.Notify(ctx, Message{Title: "标题", Content: "内容"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fake.tokens != 1 || len(fake.messages) != 3 || fake.messages[0] != "标题\n内容" || fake.agentIDs[0] != "1000002" {
+		t.Fatalf("令牌 %d 个，消息 %q", fake.tokens, fake.messages)
+	}
+
+	// 提前5分钟过期
+	now = now.Add(7200*time.Second - tokenMargin)
+	w.Notify(ctx, Message{Content: "过期"})
+	if fake.tokens != 2 {
+		t.Errorf("令牌过期后应重新获取，签发 %d 个", fake.tokens)
+	}
+
+	fake.revoked = "token2"
+	if err := w.Notify(ctx, Message{Content: "刷新"}); err != nil {
+		t.Fatal(err)
+	}
+	if fake.tokens != 3 || fake.messages[len(fake.messages)-1] != "刷新" {
+		t.Errorf("令牌失效时应刷新后重发，签发 %d 个", fake.tokens)
+	}
+}
+
+// TestWeChatErrors 测试凭据错误和发送错误不重试，错误信息不包含密钥
+func TestWeChatErrors(t *testing.T) {
+	w, fake := newTestWeChat(t)
+	w.cfg.CorpSecret = "wrong"
+	err := w.Notify(context.Background(), Message{Content: "x"})
+	if err == nil || !IsPermanent(err) || !strings.Contains(err.Error(), "40001") || strings.Contains(err.Error(), "wrong") {
+		t.Errorf("凭据错误 %v", err)
+	}
+
+	w.cfg.CorpSecret = "secret"
+	fake.sendError = 60020
+	if err := w.Notify(context.Background(), Message{Content: "x"}); err == nil || !IsPermanent(err) {
+		t.Errorf("发送错误 %v 应不重试", err)
+	}
+
+	fake.sendError = -1
+	if err := w.Notify(context.Background(), Message{Content: "x"}); err == nil || IsPermanent(err) {
+		t.Errorf("系统繁忙 %v 应可以重试", err)
+	}
+}
+
+// TestWeChatRetryResume 测试分段发送失败重试时从失败的分段继续，不重复发送已经成功的分段
+func TestWeChatRetryResume(t *testing.T) {
+	w, fake := newTestWeChat(t)
+	fake.busyAt = 2
+	r := Retry(w, 3, time.Millisecond)
+	line := strings.Repeat("票", 100)
+	lines := make([]string, 18)
+	for i := range lines {
+		lines[i] = line
+	}
+	if err := r.Notify(context.Background(), Message{Content: strings.Join(lines, "\n")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.messages) != 3 {
+		t.Fatalf("收到 %d 条消息，期望 3", len(fake.messages))
+	}
+	for i, m := range fake.messages {
+		if prefix := fmt.Sprintf("[%d/3]\n", i+1); !strings.HasPrefix(m, prefix) {
+			t.Errorf("第%d条消息应以 %q 开头", i+1, prefix)
+		}
+	}
+
+	// 每次发送单独记录进度
+	if err := r.Notify(context.Background(), Message{Content: "下一条"}); err != nil || fake.messages[3] != "下一条" {
+		t.Errorf("下一次发送 %v %q", err, fake.messages)
+	}
+}
+
+// TestWeChatSplit 测试长消息按行拆分并加序号
+func TestWeChatSplit(t *testing.T) {
+	w, fake := newTestWeChat(t)
+	line := strings.Repeat("票", 100) // 300字节
+	lines := make([]string, 10)
+	for i := range lines {
+		lines[i] = line
+	}
+	if err := w.Notify(context.Background(), Message{Content: strings.Join(lines, "\n")}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.messages) != 2 || !strings.HasPrefix(fake.messages[0], "[1/2]\n") || !strings.HasPrefix(fake.messages[1], "[2/2]\n") {
+		t.Fatalf("拆分为 %d 条", len(fake.messages))
+	}
+	for _, m := range fake.messages {
+		if len(m) > wechatMaxBytes+len("[1/2]\n") {
+			t.Errorf("片段长度 %d 超过限制", len(m))
+		}
+	}
+}
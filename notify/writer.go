@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Writer 把消息写入文件或标准输出
+type Writer struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+	open func() (io.WriteCloser, error) // 每次发送时打开文件，nil表示直接写 w
+	now  func() time.Time
+}
+
+// NewWriter 写入 w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{name: "输出", w: w, now: time.Now}
+}
+
+// NewFile 追加写入文件，path 为 - 时写入标准输出
+// 每次发送时打开文件，文件被移走或删除后会重新创建
+func NewFile(path string) *Writer {
+	if path == "-" {
+		return &Writer{name: "标准输出", w: os.Stdout, now: time.Now}
+	}
+	return &Writer{
+		name: "文件 " + path,
+		open: func() (io.WriteCloser, error) {
+			return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		},
+		now: time.Now,
+	}
+}
+
+// Name 渠道名称
+func (w *Writer) Name() string {
+	return w.name
+}
+
+// Notify 写入一条消息：时间和标题一行，之后是正文，消息之间空一行
+func (w *Writer) Notify(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	out := w.w
+	if w.open != nil {
+		f, err := w.open()
+		if err != nil {
+			return Permanent(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	_, err := fmt.Fprintf(out, "[%s] %s\n%s\n\n", w.now().Format("2006-01-02 15:04:05"), msg.Title, msg.Content)
+	return err
+}
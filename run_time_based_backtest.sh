@@ -23,7 +23,7 @@ echo "========================================="
 echo "提示："
 echo "1. 本测试模拟真实时间流逝"
 echo "2. 使用50%仓位，适合信号较少的策略"
-echo "3. 每次运行使用随机选择的票票数据"
+echo "3. 每次运行使用随机选择的票票数据（按分布评价策略请使用 tradeTest/study 多种子抽样研究）"
 echo "4. 回测时间约2016-2025年，近10年数据"
 echo "5. 如需调整仓位，请修改测试文件参数"
 echo "========================================="
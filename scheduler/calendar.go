@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// holidays 沪深交易所工作日休市的日期（周末总是休市，调休的周末也不开市）
+// 每年年底交易所公布下一年的安排后补充，或者用配置 schedule.holidayFile 补充
+var holidays = map[int][]string{
+	2025: {
+		"2025-01-01",
+		"2025-01-28", "2025-01-29", "2025-01-30", "2025-01-31", "2025-02-03", "2025-02-04",
+		"2025-04-04",
+		"2025-05-01", "2025-05-02", "2025-05-05",
+		"2025-06-02",
+		"2025-10-01", "2025-10-02", "2025-10-03", "2025-10-06", "2025-10-07", "2025-10-08",
+	},
+	2026: {
+		"2026-01-01", "2026-01-02",
+		"2026-02-16", "2026-02-17", "2026-02-18", "2026-02-19", "2026-02-20", "2026-02-23",
+		"2026-04-06",
+		"2026-05-01", "2026-05-04", "2026-05-05",
+		"2026-06-19",
+		"2026-09-25",
+		"2026-10-01", "2026-10-02", "2026-10-05", "2026-10-06", "2026-10-07",
+	},
+}
+
+// Calendar 交易日历：周一到周五中除去休市日
+type Calendar struct {
+	holidays map[string]bool
+	years    map[int]bool // 已知休市安排的年份
+}
+
+// NewCalendar 创建交易日历，holidays 为休市日期（YYYY-MM-DD），其所在年份视为已知
+func NewCalendar(holidays ...string) (*Calendar, error) {
+	c := &Calendar{holidays: make(map[string]bool), years: make(map[int]bool)}
+	if err := c.add(holidays); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// DefaultCalendar 内置的沪深交易所交易日历
+func DefaultCalendar() *Calendar {
+	c := &Calendar{holidays: make(map[string]bool), years: make(map[int]bool)}
+	for _, days := range holidays {
+		c.add(days)
+	}
+	return c
+}
+
+// LoadCalendar 内置交易日历加上文件中的休市日期（JSON 字符串数组，如 ["2027-01-01"]）
+func LoadCalendar(path string) (*Calendar, error) {
+	c := DefaultCalendar()
+	if path == "" {
+		return c, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取休市日期失败: %w", err)
+	}
+	var days []string
+	if err := json.Unmarshal(data, &days); err != nil {
+		return nil, fmt.Errorf("休市日期 %s 应为日期字符串数组: %w", path, err)
+	}
+	if err := c.add(days); err != nil {
+		return nil, fmt.Errorf("休市日期 %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// add 添加休市日期
+func (c *Calendar) add(days []string) error {
+	for _, d := range days {
+		t, err := time.Parse("2006-01-02", d)
+		if err != nil {
+			return fmt.Errorf("日期 %q 格式应为 YYYY-MM-DD", d)
+		}
+		c.holidays[d] = true
+		c.years[t.Year()] = true
+	}
+	return nil
+}
+
+// IsTradingDay t 所在的日期（按 t 的时区）是否为交易日
+func (c *Calendar) IsTradingDay(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return !c.holidays[t.Format("2006-01-02")]
+}
+
+// Known 是否知道该年的休市安排，不知道时只按周末判断
+func (c *Calendar) Known(year int) bool {
+	return c.years[year]
+}
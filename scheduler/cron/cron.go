@@ -0,0 +1,159 @@
+// Package cron 解析定时任务的执行时间
+//
+// 支持标准的5段 cron 表达式（分 时 日 月 周），每段可以是 *、数字、范围 a-b、步长 */n 或 a-b/n，
+// 以及用逗号分隔的列表；周的取值为 0-7（0 和 7 都表示周日）。日和周都有限制时满足其一即可（与 cron 相同）。
+// 也支持 HH:MM 的简写，表示每天的这个时间。
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 解析后的执行时间
+type Schedule struct {
+	source string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	domAny bool // 日为 *
+	dowAny bool // 周为 *
+}
+
+// field 每段的取值范围
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"分", 0, 59},
+	{"时", 0, 23},
+	{"日", 1, 31},
+	{"月", 1, 12},
+	{"周", 0, 7},
+}
+
+// Parse 解析 cron 表达式或 HH:MM
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if t, err := time.Parse("15:04", spec); err == nil {
+		s, err := Parse(fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour()))
+		if err == nil {
+			s.source = spec
+		}
+		return s, err
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("%q 应为 HH:MM 或5段 cron 表达式（分 时 日 月 周）", spec)
+	}
+	bits := make([]uint64, len(fields))
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", spec, err)
+		}
+		bits[i] = b
+	}
+	// 7 也表示周日
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &Schedule{
+		source: spec,
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domAny: parts[2] == "*", dowAny: parts[4] == "*",
+	}, nil
+}
+
+// parseField 解析一段，返回取值的位集合
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("%s %q 的步长应为正整数", f.name, item)
+			}
+			rangePart, step = item[:i], n
+		}
+		lo, hi := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil || lo > hi {
+				return 0, fmt.Errorf("%s %q 不是有效的范围", f.name, item)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("%s %q 不是有效的数字", f.name, item)
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = f.max
+			}
+		}
+		if lo < f.min || hi > f.max {
+			return 0, fmt.Errorf("%s %q 超出范围 %d-%d", f.name, item, f.min, f.max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// String 原始表达式
+func (s *Schedule) String() string {
+	return s.source
+}
+
+// Match t（按 t 的时区）所在的分钟是否满足表达式
+func (s *Schedule) Match(t time.Time) bool {
+	return s.month&(1<<int(t.Month())) != 0 && s.dayMatch(t) &&
+		s.hour&(1<<t.Hour()) != 0 && s.minute&(1<<t.Minute()) != 0
+}
+
+// dayMatch 日和周：都有限制时满足其一即可
+func (s *Schedule) dayMatch(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next t 之后（不含 t 所在的分钟）第一个满足表达式的时间，按 t 的时区计算；5年内没有时返回零值
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatch(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,62 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNext 测试各种表达式的下一次执行时间
+func TestNext(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	// 2024-01-05 是周五
+	from := time.Date(2024, 1, 5, 19, 0, 30, 0, loc)
+	cases := []struct{ spec, want string }{
+		{"19:00", "2024-01-06 19:00"},
+		{"19:30", "2024-01-05 19:30"},
+		{"*/15 * * * *", "2024-01-05 19:15"},
+		{"0 9-11,19 * * 1-5", "2024-01-08 09:00"},
+		{"30 19 * * 0", "2024-01-07 19:30"},
+		{"30 19 * * 7", "2024-01-07 19:30"},
+		{"0 0 1 */3 *", "2024-04-01 00:00"},
+		{"0 8 31 * *", "2024-01-31 08:00"},
+		{"0 8 29 2 *", "2024-02-29 08:00"},
+		// 日和周都有限制时满足其一
+		{"0 8 10 * 1", "2024-01-08 08:00"},
+	}
+	for _, c := range cases {
+		s, err := Parse(c.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", c.spec, err)
+		}
+		next := s.Next(from)
+		if got := next.Format("2006-01-02 15:04"); got != c.want {
+			t.Errorf("%s: 下一次 %s，期望 %s", c.spec, got, c.want)
+		}
+		if !s.Match(next) || next.Location() != loc {
+			t.Errorf("%s: %v 应满足表达式且保持时区", c.spec, next)
+		}
+	}
+	if s, _ := Parse("0 0 30 2 *"); !s.Next(from).IsZero() {
+		t.Error("不存在的日期应返回零值")
+	}
+}
+
+// TestParseErrors 测试表达式错误
+func TestParseErrors(t *testing.T) {
+	cases := []struct{ spec, want string }{
+		{"7pm", "5段"},
+		{"0 19 * *", "5段"},
+		{"60 19 * * *", "超出范围"},
+		{"0 19 0 * *", "超出范围"},
+		{"0 19 * * 8", "超出范围"},
+		{"0 5-3 * * *", "范围"},
+		{"*/0 * * * *", "步长"},
+		{"a * * * *", "数字"},
+	}
+	for _, c := range cases {
+		if _, err := Parse(c.spec); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: 错误 %v，期望包含 %q", c.spec, err, c.want)
+		}
+	}
+}
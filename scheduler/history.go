@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// 运行结果
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"  // 重试后仍然失败
+	StatusSkipped = "skipped" // 依赖的任务失败，没有运行
+)
+
+// maxRuns 每个任务保留的运行记录数
+const maxRuns = 200
+
+// Run 一次运行记录
+type Run struct {
+	Job      string `json:"job"`
+	Date     string `json:"date"` // 计划时间所在的交易日
+	Slot     string `json:"slot"` // 计划时间（RFC3339）
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Attempts int    `json:"attempts"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	CatchUp  bool   `json:"catchUp,omitempty"` // 启动时补跑错过的计划
+}
+
+// history 一个任务的运行记录，每个任务单独保存一个文件（<dir>/<job>.json）
+// 不同进程中的任务只写自己的文件，通过读取其他任务的文件判断依赖是否完成
+type history struct {
+	path string
+	Runs []Run `json:"runs"` // 按时间顺序
+}
+
+// historyPath 任务运行记录的文件
+func historyPath(dir, job string) string {
+	return filepath.Join(dir, job+".json")
+}
+
+// loadHistory 读取任务的运行记录，文件不存在时返回空记录
+func loadHistory(dir, job string) (*history, error) {
+	h := &history{path: historyPath(dir, job)}
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取任务 %s 的运行记录失败: %w", job, err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("解析运行记录 %s 失败: %w", h.path, err)
+	}
+	return h, nil
+}
+
+// add 追加运行记录并保存（先写临时文件再替换）
+func (h *history) add(run Run) error {
+	h.Runs = append(h.Runs, run)
+	if len(h.Runs) > maxRuns {
+		h.Runs = h.Runs[len(h.Runs)-maxRuns:]
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// slot 某个计划时间的运行记录，没有时返回nil
+func (h *history) slot(slot string) *Run {
+	for i := len(h.Runs) - 1; i >= 0; i-- {
+		if h.Runs[i].Slot == slot {
+			return &h.Runs[i]
+		}
+	}
+	return nil
+}
+
+// date 某个交易日最近一次运行记录，没有时返回nil
+func (h *history) date(date string) *Run {
+	for i := len(h.Runs) - 1; i >= 0; i-- {
+		if h.Runs[i].Date == date {
+			return &h.Runs[i]
+		}
+	}
+	return nil
+}
+
+// History 读取任务最近的运行记录（最新的在前），最多 n 条
+func History(dir, job string, n int) ([]Run, error) {
+	h, err := loadHistory(dir, job)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]Run, 0, min(n, len(h.Runs)))
+	for i := len(h.Runs) - 1; i >= 0 && len(runs) < n; i-- {
+		runs = append(runs, h.Runs[i])
+	}
+	return runs, nil
+}
@@ -0,0 +1,364 @@
+// Package scheduler 定时任务
+//
+// 任务按 cron 表达式（见 scheduler/cron）在配置的时区（默认 Asia/Shanghai）只在交易日运行，
+// 可以依赖其他任务（同一交易日依赖的任务成功后才运行，依赖失败时跳过），失败时按指数退避重试，
+// 启动时补跑最近错过的计划。每次运行的结果保存在运行记录中，进程重启后不会重复运行，
+// 分别运行在不同进程中的任务也通过运行记录判断依赖是否完成。
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	globalDefine "stock-go/globalDefine"
+	"stock-go/logger"
+	"stock-go/notify"
+	"stock-go/scheduler/cron"
+	"time"
+)
+
+// 每日任务的名称，分析任务依赖更新数据任务
+// 每日分析的各项分别是单独的任务，分别重试和记录，一项失败重试时不会重复发送其他已经成功的消息
+const (
+	JobUpdateData      = "updateData"
+	JobAnalyseStrategy = "analyseStrategy" // 按策略配置扫描买入信号
+	JobAnalyseAlerts   = "analyseAlerts"   // 按预警规则检查
+	JobAlertScorecard  = "alertScorecard"  // 发送预警效果评分
+)
+
+// Job 定时任务
+type Job struct {
+	Name     string
+	Schedule string   // HH:MM 或 cron 表达式，只在交易日运行
+	After    []string // 依赖的任务：同一交易日这些任务都成功后才运行，可以是其他进程中的任务
+	Run      func(ctx context.Context) error
+
+	schedule *cron.Schedule
+}
+
+// Options 调度配置
+type Options struct {
+	Location    *time.Location // 计划时间的时区，为nil时使用 Asia/Shanghai
+	Calendar    *Calendar      // 交易日历，为nil时使用内置日历
+	Attempts    int            // 每次计划最多运行次数（含第一次）
+	Backoff     time.Duration  // 第一次重试前的等待时间，之后每次翻倍
+	CatchUpDays int            // 启动时补跑最近多少天内错过的最后一次计划，0表示不补跑
+	HistoryDir  string         // 运行记录目录
+	Interval    time.Duration  // 检查间隔，默认1分钟
+	OnFailure   func(run Run)  // 运行失败或因依赖失败跳过时调用
+}
+
+// Scheduler 定时任务调度
+type Scheduler struct {
+	opts    Options
+	jobs    []*Job // 按依赖排序，依赖的任务在前
+	started time.Time
+	waiting map[string]string // 正在等待依赖的任务及其计划时间，避免重复记录日志
+	warned  map[int]bool      // 已经提示过没有休市安排的年份
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewScheduler 创建调度器，校验任务的执行时间和依赖
+func NewScheduler(opts Options, jobs ...*Job) (*Scheduler, error) {
+	if opts.HistoryDir == "" {
+		return nil, fmt.Errorf("未指定运行记录目录")
+	}
+	if opts.Location == nil {
+		loc, err := LoadLocation(globalDefine.DefaultTimezone)
+		if err != nil {
+			return nil, err
+		}
+		opts.Location = loc
+	}
+	if opts.Calendar == nil {
+		opts.Calendar = DefaultCalendar()
+	}
+	opts.Attempts = max(opts.Attempts, 1)
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+
+	names := make(map[string]*Job, len(jobs))
+	for _, job := range jobs {
+		if job.Name == "" || job.Run == nil {
+			return nil, fmt.Errorf("任务需要设置名称和运行函数")
+		}
+		if names[job.Name] != nil {
+			return nil, fmt.Errorf("任务 %s 重复", job.Name)
+		}
+		schedule, err := cron.Parse(job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("任务 %s: %w", job.Name, err)
+		}
+		job.schedule = schedule
+		names[job.Name] = job
+	}
+	sorted, err := sortJobs(jobs, names)
+	if err != nil {
+		return nil, err
+	}
+	return &Scheduler{
+		opts:    opts,
+		jobs:    sorted,
+		started: time.Now(),
+		waiting: make(map[string]string),
+		warned:  make(map[int]bool),
+		sleep:   sleep,
+	}, nil
+}
+
+// sortJobs 按依赖排序（同一进程中的依赖先运行），有循环依赖时返回错误
+func sortJobs(jobs []*Job, names map[string]*Job) ([]*Job, error) {
+	sorted := make([]*Job, 0, len(jobs))
+	state := make(map[string]int) // 1: 正在访问，2: 已排序
+	var visit func(job *Job, path []string) error
+	visit = func(job *Job, path []string) error {
+		switch state[job.Name] {
+		case 1:
+			return fmt.Errorf("任务循环依赖: %v", append(path, job.Name))
+		case 2:
+			return nil
+		}
+		state[job.Name] = 1
+		for _, dep := range job.After {
+			if dep == job.Name {
+				return fmt.Errorf("任务 %s 不能依赖自己", job.Name)
+			}
+			if d := names[dep]; d != nil {
+				if err := visit(d, append(path, job.Name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[job.Name] = 2
+		sorted = append(sorted, job)
+		return nil
+	}
+	for _, job := range jobs {
+		if err := visit(job, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// LoadLocation 加载时区（已内嵌时区数据，服务器没有安装时也能使用）
+func LoadLocation(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("时区 %q 无效: %w", name, err)
+	}
+	return loc, nil
+}
+
+// FromConfig 按配置 schedule 创建调度器，运行记录保存在数据目录的 schedule 下，失败时发送通知
+func FromConfig(cfg *globalDefine.Config, jobs ...*Job) (*Scheduler, error) {
+	loc, err := LoadLocation(cfg.Schedule.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := LoadCalendar(cfg.Schedule.HolidayFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewScheduler(Options{
+		Location:    loc,
+		Calendar:    calendar,
+		Attempts:    cfg.Schedule.Retry.Attempts,
+		Backoff:     time.Duration(cfg.Schedule.Retry.BackoffMs) * time.Millisecond,
+		CatchUpDays: cfg.Schedule.CatchUpDays,
+		HistoryDir:  HistoryDir(cfg.DataPath),
+		OnFailure:   notifyFailure,
+	}, jobs...)
+}
+
+// HistoryDir 数据目录下的运行记录目录
+func HistoryDir(dataPath string) string {
+	return filepath.Join(dataPath, "schedule")
+}
+
+// notifyFailure 发送任务失败的通知
+func notifyFailure(run Run) {
+	title := fmt.Sprintf("定时任务 %s 失败", run.Job)
+	if run.Status == StatusSkipped {
+		title = fmt.Sprintf("定时任务 %s 跳过", run.Job)
+	}
+	notify.Send(context.Background(), notify.Message{
+		Title:   title,
+		Content: fmt.Sprintf("计划时间 %s，运行 %d 次\n%s", run.Slot, run.Attempts, run.Error),
+	})
+}
+
+// Run 每隔 Interval 检查一次并运行到期的任务，直到 ctx 取消
+func (s *Scheduler) Run(ctx context.Context) error {
+	for _, job := range s.jobs {
+		logger.Infof("定时任务 %s: %s（交易日，%s），依赖 %v", job.Name, job.schedule, s.opts.Location, job.After)
+	}
+	for {
+		s.Tick(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.opts.Interval):
+		}
+	}
+}
+
+// Tick 按依赖顺序检查并运行 now 时到期的任务
+func (s *Scheduler) Tick(ctx context.Context, now time.Time) {
+	for _, job := range s.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		s.check(ctx, job, now.In(s.opts.Location))
+	}
+}
+
+// check 任务最近一次计划还没有运行过时运行：依赖未完成时等待，依赖失败时跳过
+func (s *Scheduler) check(ctx context.Context, job *Job, now time.Time) {
+	slot, ok := s.lastSlot(job, now)
+	if !ok {
+		return
+	}
+	catchUp := slot.Before(s.started)
+	if catchUp && s.opts.CatchUpDays == 0 {
+		return
+	}
+	h, err := loadHistory(s.opts.HistoryDir, job.Name)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return
+	}
+	key := slot.Format(time.RFC3339)
+	if h.slot(key) != nil {
+		return
+	}
+
+	run := Run{Job: job.Name, Date: slot.Format("2006-01-02"), Slot: key, CatchUp: catchUp}
+	for _, dep := range job.After {
+		dh, err := loadHistory(s.opts.HistoryDir, dep)
+		if err != nil {
+			logger.Errorf("%v", err)
+			return
+		}
+		r := dh.date(run.Date)
+		if r == nil {
+			if s.waiting[job.Name] != key {
+				logger.Infof("任务 %s（%s）等待 %s 完成", job.Name, key, dep)
+				s.waiting[job.Name] = key
+			}
+			return
+		}
+		if r.Status != StatusSuccess {
+			run.Status = StatusSkipped
+			run.Error = fmt.Sprintf("依赖的任务 %s 没有成功（%s）", dep, r.Status)
+			logger.Warnf("任务 %s（%s）跳过: %s", job.Name, key, run.Error)
+			s.record(h, run)
+			return
+		}
+	}
+	delete(s.waiting, job.Name)
+
+	if catchUp {
+		logger.Infof("补跑任务 %s 错过的计划 %s", job.Name, key)
+	}
+	run.Start = time.Now().In(s.opts.Location).Format(time.RFC3339)
+	for run.Attempts = 1; ; run.Attempts++ {
+		logger.Infof("运行任务 %s（%s）第 %d 次", job.Name, key, run.Attempts)
+		err = execute(context.WithValue(ctx, slotKey{}, slot), job)
+		if err == nil {
+			run.Status = StatusSuccess
+			break
+		}
+		logger.Warnf("任务 %s 第 %d 次运行失败: %v", job.Name, run.Attempts, err)
+		if ctx.Err() != nil {
+			// 程序退出时不记录，下次启动时补跑
+			return
+		}
+		if run.Attempts >= s.opts.Attempts {
+			run.Status = StatusFailed
+			run.Error = err.Error()
+			break
+		}
+		if s.sleep(ctx, s.opts.Backoff<<(run.Attempts-1)) != nil {
+			return
+		}
+	}
+	run.End = time.Now().In(s.opts.Location).Format(time.RFC3339)
+	logger.Infof("任务 %s（%s）%s，运行 %d 次", job.Name, key, run.Status, run.Attempts)
+	s.record(h, run)
+}
+
+// lastSlot 不晚于 now 的最近一次交易日的计划时间（最多往前查找 CatchUpDays 天，至少1天）
+func (s *Scheduler) lastSlot(job *Job, now time.Time) (time.Time, bool) {
+	from := now.AddDate(0, 0, -max(s.opts.CatchUpDays, 1))
+	var last time.Time
+	for t := job.schedule.Next(from); !t.IsZero() && !t.After(now); t = job.schedule.Next(t) {
+		if s.tradingDay(t) {
+			last = t
+		}
+	}
+	return last, !last.IsZero()
+}
+
+// tradingDay 是否为交易日，没有该年的休市安排时提示一次
+func (s *Scheduler) tradingDay(t time.Time) bool {
+	if year := t.Year(); !s.opts.Calendar.Known(year) && !s.warned[year] {
+		logger.Warnf("交易日历没有 %d 年的休市安排，只按周末判断，请更新内置日历或配置 schedule.holidayFile", year)
+		s.warned[year] = true
+	}
+	return s.opts.Calendar.IsTradingDay(t)
+}
+
+// record 保存运行记录，失败或跳过时调用 OnFailure
+func (s *Scheduler) record(h *history, run Run) {
+	if err := h.add(run); err != nil {
+		logger.Errorf("保存任务 %s 的运行记录失败: %v", run.Job, err)
+	}
+	if run.Status != StatusSuccess && s.opts.OnFailure != nil {
+		s.opts.OnFailure(run)
+	}
+}
+
+// slotKey 任务计划时间在 context 中的键
+type slotKey struct{}
+
+// SlotTime 正在运行的任务的计划时间（补跑时为错过的那次计划），按日期判断的任务应使用它而不是当前时间
+// 不是由调度器运行时返回当前时间
+func SlotTime(ctx context.Context) time.Time {
+	if slot, ok := ctx.Value(slotKey{}).(time.Time); ok {
+		return slot
+	}
+	return time.Now()
+}
+
+// execute 运行任务，panic 时作为错误返回
+func execute(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// sleep 等待 d，ctx 取消时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Jobs 按运行顺序列出任务名称
+func (s *Scheduler) Jobs() []string {
+	names := make([]string, len(s.jobs))
+	for i, job := range s.jobs {
+		names[i] = job.Name
+	}
+	return names
+}
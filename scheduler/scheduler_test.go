@@ -0,0 +1,244 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var cst = time.FixedZone("CST", 8*3600)
+
+// at 2024年1月的某个时间（北京时间）
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, 1, day, hour, minute, 0, 0, cst)
+}
+
+// newTestScheduler 创建测试用的调度器：启动时间为 started，重试等待只记录不等待
+func newTestScheduler(t *testing.T, dir string, started time.Time, catchUpDays int, jobs ...*Job) (*Scheduler, *[]time.Duration, *[]Run) {
+	calendar, _ := NewCalendar("2024-01-01")
+	var waits []time.Duration
+	var failures []Run
+	s, err := NewScheduler(Options{
+		Location:    cst,
+		Calendar:    calendar,
+		Attempts:    3,
+		Backoff:     time.Minute,
+		CatchUpDays: catchUpDays,
+		HistoryDir:  dir,
+		OnFailure:   func(run Run) { failures = append(failures, run) },
+	}, jobs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.started = started
+	s.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return s, &waits, &failures
+}
+
+// counter 记录运行次数，按 results 依次返回结果（用完后返回nil）
+type counter struct {
+	runs    int
+	results []error
+}
+
+func (c *counter) run(ctx context.Context) error {
+	c.runs++
+	if len(c.results) > 0 {
+		err := c.results[0]
+		c.results = c.results[1:]
+		return err
+	}
+	return nil
+}
+
+// TestCalendar 测试内置交易日历和补充的休市日期
+func TestCalendar(t *testing.T) {
+	c := DefaultCalendar()
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02", s, cst)
+		return d
+	}
+	for date, want := range map[string]bool{
+		"2025-10-08": false, // 国庆
+		"2025-10-09": true,
+		"2025-10-11": false, // 调休的周六也不开市
+		"2026-02-20": false, // 春节
+		"2026-02-24": true,
+	} {
+		if c.IsTradingDay(day(date)) != want {
+			t.Errorf("%s 是否交易日应为 %v", date, want)
+		}
+	}
+	if !c.Known(2026) || c.Known(2030) {
+		t.Error("内置日历的年份不正确")
+	}
+
+	path := filepath.Join(t.TempDir(), "holidays.json")
+	os.WriteFile(path, []byte(`["2030-01-01"]`), 0o644)
+	c, err := LoadCalendar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.IsTradingDay(day("2030-01-01")) || !c.Known(2030) || !c.Known(2025) {
+		t.Error("补充的休市日期没有生效")
+	}
+	os.WriteFile(path, []byte(`["2030/01/01"]`), 0o644)
+	if _, err := LoadCalendar(path); err == nil || !strings.Contains(err.Error(), "YYYY-MM-DD") {
+		t.Errorf("日期格式错误 %v", err)
+	}
+}
+
+// TestDependencies 测试依赖顺序、重试、依赖失败时跳过，以及非交易日不运行
+func TestDependencies(t *testing.T) {
+	dir := t.TempDir()
+	update := &counter{results: []error{errors.New("网络错误")}}
+	analyse := &counter{}
+	s, waits, failures := newTestScheduler(t, dir, at(5, 9, 0), 3,
+		&Job{Name: JobAnalyseAlerts, Schedule: "19:30", After: []string{JobUpdateData}, Run: analyse.run},
+		&Job{Name: JobUpdateData, Schedule: "19:00", Run: update.run},
+	)
+	if jobs := s.Jobs(); jobs[0] != JobUpdateData {
+		t.Fatalf("依赖的任务应先运行: %v", jobs)
+	}
+
+	// 周五 19:10 只有更新任务到期：第一次失败，重试后成功
+	ctx := context.Background()
+	s.Tick(ctx, at(5, 19, 10))
+	if update.runs != 2 || analyse.runs != 0 || len(*waits) != 1 || (*waits)[0] != time.Minute {
+		t.Fatalf("更新 %d 次，分析 %d 次，等待 %v", update.runs, analyse.runs, *waits)
+	}
+	s.Tick(ctx, at(5, 19, 40))
+	s.Tick(ctx, at(5, 19, 45))
+	if update.runs != 2 || analyse.runs != 1 {
+		t.Fatalf("每个计划只运行一次: 更新 %d 次，分析 %d 次", update.runs, analyse.runs)
+	}
+
+	// 周末不运行
+	s.Tick(ctx, at(6, 20, 0))
+	if update.runs != 2 {
+		t.Error("周末不应运行")
+	}
+
+	// 周一更新一直失败，分析跳过
+	update.results = []error{errors.New("a"), errors.New("b"), errors.New("c")}
+	s.Tick(ctx, at(8, 20, 0))
+	if update.runs != 5 || analyse.runs != 1 || len(*failures) != 2 {
+		t.Fatalf("更新 %d 次，分析 %d 次，失败 %+v", update.runs, analyse.runs, *failures)
+	}
+	if f := (*failures)[0]; f.Status != StatusFailed || f.Attempts != 3 || f.Error != "c" {
+		t.Errorf("更新失败记录 %+v", f)
+	}
+	if f := (*failures)[1]; f.Status != StatusSkipped || f.Job != JobAnalyseAlerts || f.Date != "2024-01-08" {
+		t.Errorf("分析跳过记录 %+v", f)
+	}
+
+	runs, err := History(dir, JobUpdateData, 10)
+	if err != nil || len(runs) != 2 || runs[0].Status != StatusFailed || runs[1].Attempts != 2 || runs[1].Slot != "2024-01-05T19:00:00+08:00" {
+		t.Errorf("运行记录 %+v %v", runs, err)
+	}
+}
+
+// TestIndependentJobs 测试同一时间的任务分别重试：一个失败重试时不重复运行已经成功的另一个
+func TestIndependentJobs(t *testing.T) {
+	alerts := &counter{}
+	scorecard := &counter{results: []error{errors.New("发送失败")}}
+	s, _, _ := newTestScheduler(t, t.TempDir(), at(5, 9, 0), 3,
+		&Job{Name: JobAnalyseAlerts, Schedule: "19:30", Run: alerts.run},
+		&Job{Name: JobAlertScorecard, Schedule: "19:30", Run: scorecard.run},
+	)
+	s.Tick(context.Background(), at(5, 19, 30))
+	s.Tick(context.Background(), at(5, 19, 31))
+	if alerts.runs != 1 || scorecard.runs != 2 {
+		t.Errorf("预警 %d 次，评分 %d 次", alerts.runs, scorecard.runs)
+	}
+}
+
+// TestCatchUp 测试启动时补跑错过的计划，以及重启后不重复运行
+func TestCatchUp(t *testing.T) {
+	dir := t.TempDir()
+	update := &counter{}
+	job := func() *Job { return &Job{Name: JobUpdateData, Schedule: "19:00", Run: update.run} }
+
+	// 不补跑
+	s, _, _ := newTestScheduler(t, dir, at(9, 9, 0), 0, job())
+	s.Tick(context.Background(), at(9, 9, 0))
+	if update.runs != 0 {
+		t.Fatal("catchUpDays 为0时不应补跑")
+	}
+
+	// 周二早上启动：补跑周一（01-01 休市）之前最近的一次，即 01-08 19:00，任务得到的计划时间是错过的那次
+	var slot time.Time
+	s, _, _ = newTestScheduler(t, dir, at(9, 9, 0), 3, &Job{Name: JobUpdateData, Schedule: "19:00", Run: func(ctx context.Context) error {
+		slot = SlotTime(ctx)
+		return update.run(ctx)
+	}})
+	s.Tick(context.Background(), at(9, 9, 0))
+	runs, _ := History(dir, JobUpdateData, 10)
+	if update.runs != 1 || len(runs) != 1 || !runs[0].CatchUp || runs[0].Date != "2024-01-08" {
+		t.Fatalf("补跑 %d 次，记录 %+v", update.runs, runs)
+	}
+	if !slot.Equal(at(8, 19, 0)) {
+		t.Errorf("计划时间 %v，期望 %v", slot, at(8, 19, 0))
+	}
+
+	// 重启后不重复运行
+	s, _, _ = newTestScheduler(t, dir, at(9, 9, 5), 3, job())
+	s.Tick(context.Background(), at(9, 9, 5))
+	if update.runs != 1 {
+		t.Error("重启后不应重复运行")
+	}
+
+	// 补跑范围之外的不补跑
+	other := t.TempDir()
+	s, _, _ = newTestScheduler(t, other, at(2, 9, 0), 1, job())
+	s.Tick(context.Background(), at(2, 9, 0))
+	if update.runs != 1 {
+		t.Error("休市日之前超出补跑范围的计划不应补跑")
+	}
+}
+
+// TestExternalDependency 测试依赖其他进程中的任务：等待其运行记录出现
+func TestExternalDependency(t *testing.T) {
+	dir := t.TempDir()
+	analyse := &counter{}
+	s, _, _ := newTestScheduler(t, dir, at(5, 9, 0), 3,
+		&Job{Name: JobAnalyseAlerts, Schedule: "19:30", After: []string{JobUpdateData}, Run: analyse.run})
+	s.Tick(context.Background(), at(5, 19, 30))
+	if analyse.runs != 0 {
+		t.Fatal("依赖的任务没有完成时应等待")
+	}
+
+	update := &counter{}
+	other, _, _ := newTestScheduler(t, dir, at(5, 9, 0), 3, &Job{Name: JobUpdateData, Schedule: "19:00", Run: update.run})
+	other.Tick(context.Background(), at(5, 19, 40))
+	s.Tick(context.Background(), at(5, 19, 41))
+	if update.runs != 1 || analyse.runs != 1 {
+		t.Errorf("更新 %d 次，分析 %d 次", update.runs, analyse.runs)
+	}
+}
+
+// TestNewSchedulerErrors 测试任务配置错误
+func TestNewSchedulerErrors(t *testing.T) {
+	run := func(ctx context.Context) error { return nil }
+	cases := []struct {
+		jobs []*Job
+		want string
+	}{
+		{[]*Job{{Name: "a", Schedule: "7pm", Run: run}}, "cron"},
+		{[]*Job{{Name: "a", Schedule: "19:00", Run: run}, {Name: "a", Schedule: "19:00", Run: run}}, "重复"},
+		{[]*Job{{Name: "a", Schedule: "19:00", After: []string{"b"}, Run: run}, {Name: "b", Schedule: "19:00", After: []string{"a"}, Run: run}}, "循环依赖"},
+		{[]*Job{{Name: "a", Schedule: "19:00", After: []string{"a"}, Run: run}}, "依赖自己"},
+	}
+	for _, c := range cases {
+		if _, err := NewScheduler(Options{HistoryDir: t.TempDir()}, c.jobs...); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("错误 %v，期望包含 %q", err, c.want)
+		}
+	}
+}
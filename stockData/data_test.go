@@ -3,6 +3,9 @@ package stockData
 import (
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	globalDefine "stock-go/globalDefine"
 	"testing"
 )
 
@@ -18,3 +21,24 @@ func TestLoadStockList(t *testing.T) {
 
 	slog.Info("stock list size", "size", len(StockList))
 }
+
+// TestStockListReaders 测试抽样保留票票名称、行业映射跳过没有行业的票票
+func TestStockListReaders(t *testing.T) {
+	dir := t.TempDir()
+	content := "SZ,000001,平安银行,x,银行\nSH,600000,浦发银行,x,\nSH,600519,贵州茅台,x,白酒\n"
+	if err := os.WriteFile(filepath.Join(dir, "stockList.csv"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	savedPath, savedPct := globalDefine.DATA_PATH, globalDefine.STOCK_DATA_LOAD_PCT
+	defer func() { globalDefine.DATA_PATH, globalDefine.STOCK_DATA_LOAD_PCT = savedPath, savedPct }()
+	globalDefine.DATA_PATH, globalDefine.STOCK_DATA_LOAD_PCT = dir+string(filepath.Separator), 1
+
+	sample := SampleStockList(1)
+	if len(sample) != 3 || sample["sz.000001"] != "平安银行" || sample["sh.600519"] != "贵州茅台" {
+		t.Errorf("抽样结果 %v", sample)
+	}
+	industries := LoadIndustryMap()
+	if len(industries) != 2 || industries["sz.000001"] != "银行" || industries["sh.600519"] != "白酒" {
+		t.Errorf("行业映射 %v", industries)
+	}
+}
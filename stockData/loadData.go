@@ -26,29 +26,42 @@ import (
 // 	slog.Info("Data Path", "path", path)
 // }
 
-// 加载stock列表
-func LoadAllStockList() [][]string {
+// readStockList 读取 stockList.csv 的所有行（第1列含 SZ 为深市，第2列代码，第3列名称，第5列行业）
+// 读取失败时记录日志，返回已读取的部分
+func readStockList() [][]string {
 	fileName := globalDefine.DATA_PATH + "stockList.csv"
-	fs1, _ := os.Open(fileName)
-	r1 := csv.NewReader(fs1)
-	content, err := r1.ReadAll()
+	fs1, err := os.Open(fileName)
+	if err != nil {
+		logger.Error("can not open stock list", "err", err)
+		return nil
+	}
+	defer fs1.Close()
+
+	content, err := csv.NewReader(fs1).ReadAll()
 	if err != nil {
 		logger.Error("can not readall", "err", err)
 	}
+	return content
+}
 
-	for _, row := range content {
+// stockListCode stockList.csv 一行对应的票票代码（带市场前缀），列数不足时返回空字符串
+func stockListCode(row []string) string {
+	if len(row) < 3 {
+		return ""
+	}
+	if strings.Contains(row[0], "SZ") {
+		return "sz." + row[1]
+	}
+	return "sh." + row[1]
+}
 
-		row0 := string(row[0])
-		row1 := string(row[1])
-		row2 := string(row[2])
-		var code string
-		if strings.Contains(row0, "SZ") {
-			code = "sz." + row1
-		} else {
-			code = "sh." + row1
+// 加载stock列表
+func LoadAllStockList() [][]string {
+	content := readStockList()
+	for _, row := range content {
+		if code := stockListCode(row); code != "" {
+			StockList[code] = row[2]
 		}
-		StockList[code] = row2
-
 	}
 
 	slog.Info("stock list size", "size", len(content))
@@ -61,13 +74,7 @@ func LoadPreStockList() map[string]string {
 	StockList = make(map[string]string)
 	StocksRaw = make(map[string]*StockInfo)
 
-	fileName := globalDefine.DATA_PATH + "stockList.csv"
-	fs1, _ := os.Open(fileName)
-	r1 := csv.NewReader(fs1)
-	content, err := r1.ReadAll()
-	if err != nil {
-		logger.Error("can not readall", "err", err)
-	}
+	content := readStockList()
 
 	// 使用 math/rand/v2 的全局随机数生成器，自动使用随机种子
 	// 生成一个随机标识来验证每次调用确实是新的
@@ -76,16 +83,9 @@ func LoadPreStockList() map[string]string {
 	for _, row := range content {
 		// 随机选择 1/STOCK_DATA_LOAD_PCT 的数据
 		if rand.IntN(globalDefine.STOCK_DATA_LOAD_PCT) == 0 {
-			row0 := string(row[0])
-			row1 := string(row[1])
-			row2 := string(row[2])
-			var code string
-			if strings.Contains(row0, "SZ") {
-				code = "sz." + row1
-			} else {
-				code = "sh." + row1
+			if code := stockListCode(row); code != "" {
+				StockList[code] = row[2]
 			}
-			StockList[code] = row2
 		}
 	}
 
@@ -93,6 +93,39 @@ func LoadPreStockList() map[string]string {
 	return StockList
 }
 
+// SampleStockList 按种子随机选择 1/STOCK_DATA_LOAD_PCT 的票票，返回 票票代码 -> 名称
+// 不修改全局的 StockList 和 StocksRaw，相同种子选出的票票相同
+func SampleStockList(seed uint64) map[string]string {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	sample := make(map[string]string)
+	for _, row := range readStockList() {
+		if rng.IntN(globalDefine.STOCK_DATA_LOAD_PCT) != 0 {
+			continue
+		}
+		if code := stockListCode(row); code != "" {
+			sample[code] = row[2]
+		}
+	}
+
+	slog.Info("stock list sampled", "size", len(sample), "seed", seed)
+	return sample
+}
+
+// LoadIndustryMap 加载票票所属行业（stockList.csv 第5列），返回 票票代码 -> 行业
+// 不修改全局数据，没有行业的票票不包含在结果中
+func LoadIndustryMap() map[string]string {
+	content := readStockList()
+	industries := make(map[string]string, len(content))
+	for _, row := range content {
+		if code := stockListCode(row); code != "" && len(row) >= 5 && row[4] != "" {
+			industries[code] = row[4]
+		}
+	}
+
+	slog.Info("industry map loaded", "size", len(industries))
+	return industries
+}
+
 func LoadFromCsv(code string) (stockData StockData) {
 	fileName := globalDefine.DATA_PATH + code + "_ALL.csv"
 	fs1, _ := os.Open(fileName)
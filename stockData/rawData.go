@@ -29,6 +29,15 @@ func LoadRawDataAll() {
 	logger.Infof("StocksRaw loaded size=%d", len(StocksRaw))
 }
 
+// ReadStockRaw 从数据文件读取原始数据，不读写 StocksRaw 缓存，可以与其他代码并发调用
+func ReadStockRaw(code string) *StockInfo {
+	return &StockInfo{
+		Code:  code,
+		Name:  StockList[code],
+		Datas: LoadFromCsv(code),
+	}
+}
+
 func GetStockRawBycode(code string) *StockInfo {
 	if code == "" {
 		logger.Warnf("票票代码为空")
@@ -0,0 +1,56 @@
+// Package synthetic 构造测试用的票票数据，不依赖本地数据文件
+//
+// 日期从2016-01-04开始，跳过周末；开盘价等于收盘价（与 LoadFromCsv 一致），最高价、最低价在收盘价上下浮动1%
+package synthetic
+
+import (
+	"math/rand/v2"
+	"stock-go/stockData"
+	"time"
+)
+
+// Stock 构造 days 个交易日的票票数据，price(i) 为第 i 个交易日的收盘价
+func Stock(code string, days int, price func(i int) float64) *stockData.StockInfo {
+	stock := &stockData.StockInfo{Code: code, Name: code}
+	date := time.Date(2016, 1, 4, 0, 0, 0, 0, time.Local)
+	for i := 0; i < days; i++ {
+		for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			date = date.AddDate(0, 0, 1)
+		}
+		p := float32(price(i))
+		stock.Datas.DayDatas = append(stock.Datas.DayDatas, &stockData.StockDataDay{
+			Index:      i + 1,
+			DataStr:    date.Format("2006-01-02"),
+			PriceA:     p,
+			PriceBegin: p,
+			PriceEnd:   p,
+			PriceHigh:  p * 1.01,
+			PriceLow:   p * 0.99,
+			PriceShow:  p,
+		})
+		date = date.AddDate(0, 0, 1)
+	}
+	return stock
+}
+
+// FromPrices 根据收盘价序列构造票票数据
+func FromPrices(code string, prices []float64) *stockData.StockInfo {
+	return Stock(code, len(prices), func(i int) float64 { return prices[i] })
+}
+
+// Bars 根据收盘价序列构造K线
+func Bars(prices []float64) []*stockData.StockDataDay {
+	return FromPrices("", prices).Datas.DayDatas
+}
+
+// RandomWalk 固定种子的随机游走价格序列：从10开始，每天涨跌幅服从标准差2%的正态分布
+func RandomWalk(seed uint64, days int) []float64 {
+	rng := rand.New(rand.NewPCG(seed, 0))
+	prices := make([]float64, days)
+	price := 10.0
+	for i := range prices {
+		prices[i] = price
+		price *= 1 + 0.02*rng.NormFloat64()
+	}
+	return prices
+}
@@ -0,0 +1,28 @@
+package synthetic
+
+import (
+	"testing"
+	"time"
+)
+
+// TestStockSkipsWeekends 测试日期跳过周末，价格按价格函数生成
+func TestStockSkipsWeekends(t *testing.T) {
+	stock := Stock("sz.000001", 10, func(i int) float64 { return float64(i + 1) })
+	bars := stock.Datas.DayDatas
+	if len(bars) != 10 || bars[0].DataStr != "2016-01-04" || bars[5].DataStr != "2016-01-11" {
+		t.Fatalf("日期不正确: %s, %s", bars[0].DataStr, bars[5].DataStr)
+	}
+	for i, bar := range bars {
+		date, _ := time.Parse("2006-01-02", bar.DataStr)
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			t.Errorf("%s 是周末", bar.DataStr)
+		}
+		if bar.Index != i+1 || bar.PriceEnd != float32(i+1) || bar.PriceBegin != bar.PriceEnd {
+			t.Errorf("第%d根K线 %+v 不正确", i+1, bar)
+		}
+	}
+
+	if walk := RandomWalk(1, 50); walk[0] != 10 || RandomWalk(1, 50)[49] != walk[49] {
+		t.Error("同一种子的随机游走应一致")
+	}
+}
@@ -4,9 +4,11 @@ package main
 import (
 	_ "stock-go/logger" // 确保logger最先初始化
 
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	globalDefine "stock-go/globalDefine"
 	"stock-go/http"
 	"stock-go/logger"
 	"stock-go/painter"
@@ -17,13 +19,26 @@ import (
 
 var (
 	survivalTimeout = int(3e9)
+	configFlags     *globalDefine.Flags // 命令行配置参数，重新加载时同样生效
 )
 
-// need to setup environment variable "CONF_PROVIDER_FILE_PATH" to "conf/server.yml" before run
+// 配置见 conf/stock.json.example，可用 -conf 指定配置文件，收到 SIGHUP 时重新加载
 func main() {
 	// 确保在程序退出时关闭日志处理器
 	defer logger.Close()
 
+	configFlags = globalDefine.BindFlags(flag.CommandLine)
+	flag.Parse()
+	cfg, err := globalDefine.Setup(configFlags)
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if err := logger.SetLogPath(cfg.LogPath); err != nil {
+		logger.Errorf("切换日志目录失败: %v", err)
+	}
+	logger.Infof("配置加载完成 file=%q dataPath=%s addr=%s", cfg.File(), cfg.DataPath, cfg.Server.Addr)
+
 	go http.StartServer()
 
 	go stockData.Start()
@@ -41,7 +56,7 @@ func initSignal() {
 		logger.Infof("get signal %s", sig.String())
 		switch sig {
 		case syscall.SIGHUP:
-			// reload()
+			reload()
 		default:
 			time.AfterFunc(time.Duration(survivalTimeout), func() {
 				logger.Warn("app exit now by force...")
@@ -56,3 +71,23 @@ func initSignal() {
 		}
 	}
 }
+
+// reload 重新加载配置（SIGHUP），失败时保留当前配置
+func reload() {
+	old, cfg, restart, err := globalDefine.Reload(configFlags)
+	if err != nil {
+		logger.Errorf("重新加载配置失败，继续使用当前配置: %v", err)
+		return
+	}
+	for _, change := range restart {
+		logger.Warnf("配置变化 %s 需要重启后生效", change)
+	}
+	changes := globalDefine.Changes(old, cfg)
+	if len(changes) == 0 && len(restart) == 0 {
+		logger.Infof("重新加载配置完成，没有变化")
+		return
+	}
+	for _, change := range changes {
+		logger.Infof("配置变化 %s", change)
+	}
+}
@@ -0,0 +1,231 @@
+package stockStrategy
+
+import (
+	"math"
+	"stock-go/stockData"
+)
+
+// DefaultHistoryWindow 默认的历史窗口大小（K线根数，含当天）
+const DefaultHistoryWindow = 500
+
+// BarContext 信号生成器在某一天可见的K线数据（只读）
+// 只包含截至当天（含）的最近若干根K线，无法访问未来数据；信号生成器不需要自己保存历史价格
+// 参数 k 表示往前第几根K线：0为当天，1为前一天；k 超出窗口时返回0
+// 价格统一使用 PriceBegin（回测成交价）；最高价、最低价、收盘价分别使用 PriceHigh、PriceLow、PriceEnd
+type BarContext struct {
+	code  string
+	index int                       // 当天K线在票票全部数据中的下标
+	bars  []*stockData.StockDataDay // 窗口内的K线（按时间先后排序，最后一根为当天）
+}
+
+// NewBarContext 创建截至 dayDatas[index] 的K线上下文，最多包含 window 根K线
+func NewBarContext(code string, dayDatas []*stockData.StockDataDay, index int, window int) *BarContext {
+	start := max(index+1-max(window, 1), 0)
+	return &BarContext{
+		code:  code,
+		index: index,
+		bars:  dayDatas[start : index+1 : index+1],
+	}
+}
+
+// HistoryWindow 信号生成器需要的历史窗口大小
+// 实现 HistoryWindowProvider 时使用其返回值，否则为 DefaultHistoryWindow
+func HistoryWindow(gen SignalGenerator) int {
+	if provider, ok := gen.(HistoryWindowProvider); ok {
+		return provider.GetHistoryWindow()
+	}
+	return DefaultHistoryWindow
+}
+
+// Code 票票代码
+func (c *BarContext) Code() string {
+	return c.code
+}
+
+// Index 当天K线在票票全部数据中的下标
+func (c *BarContext) Index() int {
+	return c.index
+}
+
+// Date 当天日期
+func (c *BarContext) Date() string {
+	return c.bars[len(c.bars)-1].DataStr
+}
+
+// BarKey K线的标识，同一份数据中的同一根K线标识相同，可用于按K线缓存计算结果
+type BarKey struct {
+	bar *stockData.StockDataDay
+}
+
+// Key 当天K线的标识
+func (c *BarContext) Key() BarKey {
+	return BarKey{bar: c.bars[len(c.bars)-1]}
+}
+
+// Len 窗口内的K线数量（含当天）
+func (c *BarContext) Len() int {
+	return len(c.bars)
+}
+
+// Bar 往前第 k 根K线（返回副本，修改不影响原始数据）
+func (c *BarContext) Bar(k int) (stockData.StockDataDay, bool) {
+	bar := c.at(k)
+	if bar == nil {
+		return stockData.StockDataDay{}, false
+	}
+	return *bar, true
+}
+
+// Price 往前第 k 根K线的价格（回测成交价）
+func (c *BarContext) Price(k int) float64 {
+	return c.field(k, priceOf)
+}
+
+// Open 往前第 k 根K线的开盘价（与 Price 相同，数据加载时开盘价已按收盘价处理）
+func (c *BarContext) Open(k int) float64 {
+	return c.field(k, priceOf)
+}
+
+// Close 往前第 k 根K线的收盘价
+func (c *BarContext) Close(k int) float64 {
+	return c.field(k, closeOf)
+}
+
+// High 往前第 k 根K线的最高价
+func (c *BarContext) High(k int) float64 {
+	return c.field(k, highOf)
+}
+
+// Low 往前第 k 根K线的最低价
+func (c *BarContext) Low(k int) float64 {
+	return c.field(k, lowOf)
+}
+
+// Change 往前第 k 根K线相对前一根的涨跌幅（如0.05表示涨5%），没有前一根时返回0
+func (c *BarContext) Change(k int) float64 {
+	prev := c.Price(k + 1)
+	if prev <= 0 {
+		return 0
+	}
+	return c.Price(k)/prev - 1
+}
+
+// TrueRange 往前第 k 根K线的真实波幅，没有前一根时为最高价-最低价
+func (c *BarContext) TrueRange(k int) float64 {
+	high, low := c.High(k), c.Low(k)
+	if c.at(k+1) == nil {
+		return high - low
+	}
+	prevClose := c.Close(k + 1)
+	return math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
+}
+
+// Prices 最近 n 根K线的价格（按时间先后排序，含当天），不足 n 根时返回全部
+func (c *BarContext) Prices(n int) []float64 {
+	return c.series(n, priceOf)
+}
+
+// Closes 最近 n 根K线的收盘价（按时间先后排序，含当天）
+func (c *BarContext) Closes(n int) []float64 {
+	return c.series(n, closeOf)
+}
+
+// Highs 最近 n 根K线的最高价（按时间先后排序，含当天）
+func (c *BarContext) Highs(n int) []float64 {
+	return c.series(n, highOf)
+}
+
+// Lows 最近 n 根K线的最低价（按时间先后排序，含当天）
+func (c *BarContext) Lows(n int) []float64 {
+	return c.series(n, lowOf)
+}
+
+// Highest 最近 n 根K线（含当天）的最高价格
+// 不含当天的N天最高价使用 Shift(1).Highest(n)
+func (c *BarContext) Highest(n int) float64 {
+	return c.extreme(n, priceOf, math.Max)
+}
+
+// Lowest 最近 n 根K线（含当天）的最低价格
+func (c *BarContext) Lowest(n int) float64 {
+	return c.extreme(n, priceOf, math.Min)
+}
+
+// HighestHigh 最近 n 根K线（含当天）最高价的最大值
+func (c *BarContext) HighestHigh(n int) float64 {
+	return c.extreme(n, highOf, math.Max)
+}
+
+// LowestLow 最近 n 根K线（含当天）最低价的最小值
+func (c *BarContext) LowestLow(n int) float64 {
+	return c.extreme(n, lowOf, math.Min)
+}
+
+// Average 最近 n 根K线（含当天）的平均价格
+func (c *BarContext) Average(n int) float64 {
+	n = min(n, len(c.bars))
+	if n <= 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, bar := range c.bars[len(c.bars)-n:] {
+		sum += float64(bar.PriceBegin)
+	}
+	return sum / float64(n)
+}
+
+// Shift 截至往前第 k 根K线的上下文（窗口随之缩短），k 超出窗口时返回nil
+func (c *BarContext) Shift(k int) *BarContext {
+	if k < 0 || k >= len(c.bars) {
+		return nil
+	}
+	end := len(c.bars) - k
+	return &BarContext{
+		code:  c.code,
+		index: c.index - k,
+		bars:  c.bars[:end:end],
+	}
+}
+
+// at 往前第 k 根K线，超出窗口返回nil
+func (c *BarContext) at(k int) *stockData.StockDataDay {
+	if k < 0 || k >= len(c.bars) {
+		return nil
+	}
+	return c.bars[len(c.bars)-1-k]
+}
+
+func (c *BarContext) field(k int, price func(*stockData.StockDataDay) float32) float64 {
+	bar := c.at(k)
+	if bar == nil {
+		return 0
+	}
+	return float64(price(bar))
+}
+
+func (c *BarContext) series(n int, price func(*stockData.StockDataDay) float32) []float64 {
+	n = max(min(n, len(c.bars)), 0)
+	values := make([]float64, n)
+	for i, bar := range c.bars[len(c.bars)-n:] {
+		values[i] = float64(price(bar))
+	}
+	return values
+}
+
+func (c *BarContext) extreme(n int, price func(*stockData.StockDataDay) float32, pick func(a, b float64) float64) float64 {
+	n = min(n, len(c.bars))
+	if n <= 0 {
+		return 0
+	}
+	bars := c.bars[len(c.bars)-n:]
+	result := float64(price(bars[0]))
+	for _, bar := range bars[1:] {
+		result = pick(result, float64(price(bar)))
+	}
+	return result
+}
+
+func priceOf(d *stockData.StockDataDay) float32 { return d.PriceBegin }
+func closeOf(d *stockData.StockDataDay) float32 { return d.PriceEnd }
+func highOf(d *stockData.StockDataDay) float32  { return d.PriceHigh }
+func lowOf(d *stockData.StockDataDay) float32   { return d.PriceLow }
@@ -0,0 +1,98 @@
+package stockStrategy
+
+import (
+	"math"
+	"stock-go/stockData"
+	"testing"
+)
+
+// testBars 价格依次为 1, 2, ..., n 的K线，最高价+0.5，最低价-0.5
+func testBars(n int) []*stockData.StockDataDay {
+	bars := make([]*stockData.StockDataDay, n)
+	for i := range bars {
+		p := float32(i + 1)
+		bars[i] = &stockData.StockDataDay{
+			Index:      i + 1,
+			DataStr:    string(rune('a' + i%26)),
+			PriceBegin: p,
+			PriceEnd:   p,
+			PriceHigh:  p + 0.5,
+			PriceLow:   p - 0.5,
+		}
+	}
+	return bars
+}
+
+// TestBarContextWindow 测试窗口有界且不包含未来数据
+func TestBarContextWindow(t *testing.T) {
+	bars := testBars(100)
+	ctx := NewBarContext("sz.000001", bars, 49, 10)
+
+	if ctx.Len() != 10 || ctx.Index() != 49 || ctx.Price(0) != 50 || ctx.Close(9) != 41 {
+		t.Fatalf("窗口 Len=%d Index=%d 当天=%v 最早=%v", ctx.Len(), ctx.Index(), ctx.Price(0), ctx.Close(9))
+	}
+	if ctx.Price(10) != 0 || ctx.Price(-1) != 0 {
+		t.Error("超出窗口的K线应返回0")
+	}
+	if got := ctx.Prices(100); len(got) != 10 || got[9] != 50 {
+		t.Errorf("价格序列 %v 不应包含窗口外或未来的数据", got)
+	}
+	if ctx.Highest(100) != 50 || ctx.HighestHigh(3) != 50.5 || ctx.Lowest(3) != 48 || ctx.LowestLow(100) != 40.5 {
+		t.Error("最高、最低价不正确")
+	}
+	if ctx.Average(3) != 49 {
+		t.Errorf("平均价 %v，期望 49", ctx.Average(3))
+	}
+	if math.Abs(ctx.Change(0)-1.0/49) > 1e-12 || ctx.TrueRange(0) != 1.5 {
+		t.Errorf("涨跌幅 %v 真实波幅 %v 不正确", ctx.Change(0), ctx.TrueRange(0))
+	}
+
+	// 窗口开头之前没有数据
+	first := NewBarContext("sz.000001", bars, 0, 10)
+	if first.Len() != 1 || first.Change(0) != 0 || first.TrueRange(0) != 1 || first.Shift(1) != nil {
+		t.Error("第一根K线的上下文不正确")
+	}
+}
+
+// TestBarContextShiftAndCopy 测试 Shift 截至之前的K线，Bar 返回副本
+func TestBarContextShiftAndCopy(t *testing.T) {
+	bars := testBars(30)
+	ctx := NewBarContext("sz.000001", bars, 29, 20)
+
+	prev := ctx.Shift(1)
+	if prev.Len() != 19 || prev.Price(0) != 29 || prev.Index() != 28 || prev.Highest(5) != 29 {
+		t.Errorf("Shift(1) Len=%d 价格=%v", prev.Len(), prev.Price(0))
+	}
+
+	bar, ok := ctx.Bar(0)
+	if !ok || bar.PriceBegin != 30 {
+		t.Fatalf("当天K线 %+v", bar)
+	}
+	bar.PriceBegin = 999
+	if bars[29].PriceBegin != 30 || ctx.Price(0) != 30 {
+		t.Error("修改 Bar 返回的副本不应影响原始数据")
+	}
+	if _, ok := ctx.Bar(20); ok {
+		t.Error("窗口外的K线应返回false")
+	}
+}
+
+// historySignal 指定历史窗口的测试信号生成器
+type historySignal struct{ window int }
+
+func (s *historySignal) Reset()                                      {}
+func (s *historySignal) ProcessDay(ctx *BarContext, p *Position) int { return 0 }
+func (s *historySignal) GetName() string                             { return "测试" }
+func (s *historySignal) GetHistoryWindow() int                       { return s.window }
+
+// TestHistoryWindow 测试信号生成器的历史窗口
+func TestHistoryWindow(t *testing.T) {
+	if got := HistoryWindow(&historySignal{window: 61}); got != 61 {
+		t.Errorf("历史窗口 %d，期望 61", got)
+	}
+
+	var gen SignalGenerator = struct{ SignalGenerator }{&historySignal{}}
+	if got := HistoryWindow(gen); got != DefaultHistoryWindow {
+		t.Errorf("未指定时历史窗口 %d，期望 %d", got, DefaultHistoryWindow)
+	}
+}
@@ -0,0 +1,190 @@
+// Package combine 信号生成器组合
+// 把简单的条件和已有的信号生成器组合成完整的信号生成器，不需要为每种组合手写结构体：
+//
+//	entry := combine.And(
+//		combine.When(combine.Breakout(20)),
+//		combine.When(combine.MAAbove(20, 60)),
+//		combine.Not(combine.When(combine.RecentSurge(5, 0.07))),
+//	)
+//	gen := combine.EntryExit(entry, combine.Exit(signals.NewDefaultExitRule()))
+//
+// 信号"触发"的含义取决于持仓：空仓时为买入（1），持仓时为卖出（-1）。
+// 组合器每天都会调用所有子信号生成器（不短路），保证有内部状态的子信号生成器不漏处理K线；
+// Reset 会传递给所有子信号生成器。组合器（如 Confirm）有内部状态，每只票票应使用独立的组合，
+// 参见 strategies.NewComposedStrategy
+package combine
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+	"strings"
+)
+
+// VoteSignal 按触发数量组合：至少 Need 个子信号生成器触发时触发
+// And、Or、Majority、AtLeast 都是 VoteSignal
+type VoteSignal struct {
+	Children []stockStrategy.SignalGenerator // 子信号生成器
+	Need     int                             // 需要触发的数量
+
+	op         string // 名称中的连接词
+	exitReason string // 最近一次卖出信号的原因
+}
+
+// And 所有子信号生成器都触发时触发
+func And(children ...stockStrategy.SignalGenerator) *VoteSignal {
+	return newVote(len(children), "且", children)
+}
+
+// Or 任意一个子信号生成器触发时触发
+func Or(children ...stockStrategy.SignalGenerator) *VoteSignal {
+	return newVote(1, "或", children)
+}
+
+// Majority 超过半数的子信号生成器触发时触发
+func Majority(children ...stockStrategy.SignalGenerator) *VoteSignal {
+	return newVote(len(children)/2+1, "多数", children)
+}
+
+// AtLeast 至少 n 个子信号生成器触发时触发
+func AtLeast(n int, children ...stockStrategy.SignalGenerator) *VoteSignal {
+	return newVote(n, fmt.Sprintf("至少%d个", n), children)
+}
+
+func newVote(need int, op string, children []stockStrategy.SignalGenerator) *VoteSignal {
+	return &VoteSignal{
+		Children: children,
+		Need:     max(need, 1),
+		op:       op,
+	}
+}
+
+// Reset 重置所有子信号生成器
+func (sg *VoteSignal) Reset() {
+	resetAll(sg.Children)
+	sg.exitReason = ""
+}
+
+// GetHistoryWindow 子信号生成器中最长的历史窗口
+func (sg *VoteSignal) GetHistoryWindow() int {
+	return historyWindow(sg.Children)
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *VoteSignal) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	sg.exitReason = ""
+	var reasons []string
+	for _, child := range sg.Children {
+		if fired(child.ProcessDay(ctx, position), position) {
+			reasons = append(reasons, reasonOf(child))
+		}
+	}
+	if len(reasons) < sg.Need {
+		return 0
+	}
+	if position != nil {
+		sg.exitReason = strings.Join(reasons, "、")
+	}
+	return signalOf(position)
+}
+
+// GetExitReason 获取最近一次卖出信号的原因（触发的子信号生成器的原因）
+func (sg *VoteSignal) GetExitReason() string {
+	return sg.exitReason
+}
+
+// GetName 获取信号生成器名称
+func (sg *VoteSignal) GetName() string {
+	if sg.op == "且" || sg.op == "或" {
+		return "(" + strings.Join(names(sg.Children), " "+sg.op+" ") + ")"
+	}
+	return sg.op + "(" + strings.Join(names(sg.Children), ", ") + ")"
+}
+
+// NotSignal 取反：子信号生成器不触发时触发
+type NotSignal struct {
+	Inner stockStrategy.SignalGenerator // 被取反的信号生成器
+}
+
+// Not 子信号生成器不触发时触发
+func Not(inner stockStrategy.SignalGenerator) *NotSignal {
+	return &NotSignal{Inner: inner}
+}
+
+// Reset 重置被取反的信号生成器
+func (sg *NotSignal) Reset() {
+	sg.Inner.Reset()
+}
+
+// GetHistoryWindow 被取反信号生成器的历史窗口
+func (sg *NotSignal) GetHistoryWindow() int {
+	return stockStrategy.HistoryWindow(sg.Inner)
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *NotSignal) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	if fired(sg.Inner.ProcessDay(ctx, position), position) {
+		return 0
+	}
+	return signalOf(position)
+}
+
+// GetExitReason 获取卖出原因
+func (sg *NotSignal) GetExitReason() string {
+	return sg.GetName()
+}
+
+// GetName 获取信号生成器名称
+func (sg *NotSignal) GetName() string {
+	return "非" + sg.Inner.GetName()
+}
+
+// fired 信号是否在当前方向上触发：空仓时为买入，持仓时为卖出
+func fired(signal int, position *stockStrategy.Position) bool {
+	if position == nil {
+		return signal == 1
+	}
+	return signal == -1
+}
+
+// signalOf 当前方向上触发时的信号
+func signalOf(position *stockStrategy.Position) int {
+	if position == nil {
+		return 1
+	}
+	return -1
+}
+
+// reasonOf 子信号生成器的卖出原因，没有时使用其名称
+func reasonOf(gen stockStrategy.SignalGenerator) string {
+	if provider, ok := gen.(stockStrategy.ExitReasonProvider); ok {
+		if reason := provider.GetExitReason(); reason != "" {
+			return reason
+		}
+	}
+	return gen.GetName()
+}
+
+// resetAll 重置所有信号生成器
+func resetAll(gens []stockStrategy.SignalGenerator) {
+	for _, gen := range gens {
+		gen.Reset()
+	}
+}
+
+// historyWindow 信号生成器中最长的历史窗口
+func historyWindow(gens []stockStrategy.SignalGenerator) int {
+	window := 1
+	for _, gen := range gens {
+		window = max(window, stockStrategy.HistoryWindow(gen))
+	}
+	return window
+}
+
+// names 信号生成器名称列表
+func names(gens []stockStrategy.SignalGenerator) []string {
+	result := make([]string, len(gens))
+	for i, gen := range gens {
+		result[i] = gen.GetName()
+	}
+	return result
+}
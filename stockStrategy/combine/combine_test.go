@@ -0,0 +1,251 @@
+package combine
+
+import (
+	"stock-go/stockData/synthetic"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/signals"
+	"testing"
+)
+
+// scripted 在指定下标触发的信号生成器（测试用），记录调用次数
+type scripted struct {
+	name   string
+	buys   map[int]bool // 空仓时在这些下标返回1
+	sells  map[int]bool // 持仓时在这些下标返回-1
+	calls  int
+	resets int
+}
+
+func newScripted(name string, buys, sells []int) *scripted {
+	s := &scripted{name: name, buys: map[int]bool{}, sells: map[int]bool{}}
+	for _, i := range buys {
+		s.buys[i] = true
+	}
+	for _, i := range sells {
+		s.sells[i] = true
+	}
+	return s
+}
+
+func (s *scripted) Reset() { s.resets++ }
+
+func (s *scripted) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	s.calls++
+	if position == nil && s.buys[ctx.Index()] {
+		return 1
+	}
+	if position != nil && s.sells[ctx.Index()] {
+		return -1
+	}
+	return 0
+}
+
+func (s *scripted) GetName() string { return s.name }
+
+// fireDays 逐日运行信号生成器（不改变持仓），返回触发的下标
+func fireDays(gen stockStrategy.SignalGenerator, days int, position *stockStrategy.Position) []int {
+	bars := synthetic.Bars(make([]float64, days))
+	gen.Reset()
+	var result []int
+	for i := range bars {
+		if fired(gen.ProcessDay(stockStrategy.NewBarContext("sz.000001", bars, i, stockStrategy.HistoryWindow(gen)), position), position) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestVote 测试 And/Or/Majority/AtLeast 在空仓和持仓时的触发，以及子信号生成器每天都被调用
+func TestVote(t *testing.T) {
+	build := func() (a, b, c *scripted) {
+		return newScripted("a", []int{1, 2, 3}, []int{5, 6, 7}),
+			newScripted("b", []int{2, 3}, []int{6, 7}),
+			newScripted("c", []int{3}, []int{7})
+	}
+	holding := &stockStrategy.Position{}
+
+	cases := []struct {
+		name       string
+		combine    func(a, b, c stockStrategy.SignalGenerator) stockStrategy.SignalGenerator
+		buys, sell []int
+	}{
+		{"And", func(a, b, c stockStrategy.SignalGenerator) stockStrategy.SignalGenerator { return And(a, b, c) }, []int{3}, []int{7}},
+		{"Or", func(a, b, c stockStrategy.SignalGenerator) stockStrategy.SignalGenerator { return Or(a, b, c) }, []int{1, 2, 3}, []int{5, 6, 7}},
+		{"Majority", func(a, b, c stockStrategy.SignalGenerator) stockStrategy.SignalGenerator { return Majority(a, b, c) }, []int{2, 3}, []int{6, 7}},
+		{"AtLeast", func(a, b, c stockStrategy.SignalGenerator) stockStrategy.SignalGenerator { return AtLeast(3, a, b, c) }, []int{3}, []int{7}},
+	}
+	for _, tc := range cases {
+		a, b, c := build()
+		gen := tc.combine(a, b, c)
+		if got := fireDays(gen, 10, nil); !equalInts(got, tc.buys) {
+			t.Errorf("%s 买入 %v, 期望 %v", tc.name, got, tc.buys)
+		}
+		if got := fireDays(gen, 10, holding); !equalInts(got, tc.sell) {
+			t.Errorf("%s 卖出 %v, 期望 %v", tc.name, got, tc.sell)
+		}
+		if a.calls != 20 || b.calls != 20 || c.calls != 20 {
+			t.Errorf("%s 子信号生成器应每天都被调用: %d %d %d", tc.name, a.calls, b.calls, c.calls)
+		}
+	}
+
+	a, b, _ := build()
+	or := Or(a, b)
+	bars := synthetic.Bars(make([]float64, 10))
+	if or.ProcessDay(stockStrategy.NewBarContext("sz.000001", bars, 6, 1), holding) != -1 || or.GetExitReason() != "a、b" {
+		t.Errorf("卖出原因 %q, 期望触发的子信号生成器", or.GetExitReason())
+	}
+	if or.GetName() != "(a 或 b)" || Majority(a, b).GetName() != "多数(a, b)" {
+		t.Errorf("名称 %s %s", or.GetName(), Majority(a, b).GetName())
+	}
+}
+
+// TestNot 测试取反在当前方向上生效
+func TestNot(t *testing.T) {
+	gen := Not(newScripted("a", []int{1, 2}, []int{0}))
+	if got := fireDays(gen, 4, nil); !equalInts(got, []int{0, 3}) {
+		t.Errorf("买入 %v", got)
+	}
+	if got := fireDays(gen, 4, &stockStrategy.Position{}); !equalInts(got, []int{1, 2, 3}) {
+		t.Errorf("卖出 %v", got)
+	}
+	if gen.GetName() != "非a" {
+		t.Errorf("名称 %s", gen.GetName())
+	}
+}
+
+// TestConfirm 测试确认期限、同一天确认和触发后失效
+func TestConfirm(t *testing.T) {
+	cases := []struct {
+		trigger, confirm []int
+		days             int
+		want             []int
+	}{
+		{[]int{2}, []int{4, 5}, 3, []int{4}},          // 确认一次后失效
+		{[]int{2}, []int{6}, 3, nil},                  // 超过期限
+		{[]int{2}, []int{5}, 3, []int{5}},             // 期限的最后一天
+		{[]int{2}, []int{2}, 0, []int{2}},             // 同一天确认
+		{[]int{2, 8}, []int{1, 4, 9}, 3, []int{4, 9}}, // 触发之前的确认无效，重新触发后可以再次确认
+	}
+	for _, tc := range cases {
+		gen := Confirm(newScripted("t", tc.trigger, nil), newScripted("c", tc.confirm, nil), tc.days)
+		if got := fireDays(gen, 12, nil); !equalInts(got, tc.want) {
+			t.Errorf("触发%v 确认%v 期限%d: %v, 期望 %v", tc.trigger, tc.confirm, tc.days, got, tc.want)
+		}
+	}
+
+	// 空仓时的触发不能确认卖出
+	gen := Confirm(newScripted("t", []int{2}, []int{5}), newScripted("c", nil, []int{3}), 3)
+	bars := synthetic.Bars(make([]float64, 10))
+	holding := &stockStrategy.Position{}
+	gen.ProcessDay(stockStrategy.NewBarContext("sz.000001", bars, 2, 1), nil)
+	if gen.ProcessDay(stockStrategy.NewBarContext("sz.000001", bars, 3, 1), holding) != 0 {
+		t.Error("买入方向的触发不应确认卖出")
+	}
+}
+
+// TestResetPropagation 测试 Reset 传递到组合中的每个信号生成器，并清空组合器的状态
+func TestResetPropagation(t *testing.T) {
+	leaves := []*scripted{
+		newScripted("a", nil, nil),
+		newScripted("b", []int{1}, nil),
+		newScripted("c", []int{3}, nil),
+		newScripted("d", nil, nil),
+		newScripted("e", nil, nil),
+	}
+	confirm := Confirm(leaves[1], leaves[2], 5)
+	gen := EntryExit(And(Not(leaves[0]), confirm, Or(leaves[3])), leaves[4])
+
+	before := make([]int, len(leaves))
+	for i, leaf := range leaves {
+		before[i] = leaf.resets
+	}
+	gen.Reset()
+	for i, leaf := range leaves {
+		if leaf.resets != before[i]+1 {
+			t.Errorf("%s 的 Reset 调用 %d 次, 期望 1 次", leaf.name, leaf.resets-before[i])
+		}
+	}
+
+	// 下标1触发后 Reset，下标3的确认不应再生效
+	bars := synthetic.Bars(make([]float64, 10))
+	confirm.ProcessDay(stockStrategy.NewBarContext("sz.000001", bars, 1, 1), nil)
+	gen.Reset()
+	if confirm.ProcessDay(stockStrategy.NewBarContext("sz.000001", bars, 3, 1), nil) != 0 {
+		t.Error("Reset 后之前的触发应失效")
+	}
+}
+
+// TestConditions 测试条件转换的信号生成器和卖出规则
+func TestConditions(t *testing.T) {
+	// 前30天在10附近震荡，第30天起每天上涨1%，第40天起每天下跌2%
+	prices := make([]float64, 0, 60)
+	for i := 0; i < 30; i++ {
+		prices = append(prices, 10+0.1*float64(i%2))
+	}
+	for i := 0; i < 10; i++ {
+		prices = append(prices, prices[len(prices)-1]*1.01)
+	}
+	for i := 0; i < 20; i++ {
+		prices = append(prices, prices[len(prices)-1]*0.98)
+	}
+	bars := synthetic.Bars(prices)
+
+	gen := EntryExit(
+		And(When(Breakout(20)), When(MAAbove(5, 20))),
+		Exit(signals.ExitRule{StopLoss: 0.05}),
+	)
+	if w := stockStrategy.HistoryWindow(gen); w != 21 {
+		t.Errorf("历史窗口 %d, 期望 21", w)
+	}
+	if gen.GetStopLossPercent() != 0.05 {
+		t.Errorf("止损比例 %v", gen.GetStopLossPercent())
+	}
+
+	gen.Reset()
+	var position *stockStrategy.Position
+	buy, sell := -1, -1
+	for i := range bars {
+		ctx := stockStrategy.NewBarContext("sz.000001", bars, i, stockStrategy.HistoryWindow(gen))
+		switch gen.ProcessDay(ctx, position) {
+		case 1:
+			buy = i
+			position = &stockStrategy.Position{BuyPrice: bars[i].PriceBegin, HighestPrice: bars[i].PriceBegin}
+		case -1:
+			sell = i
+			position = nil
+		}
+		if sell >= 0 {
+			break
+		}
+	}
+	if buy != 30 {
+		t.Errorf("买入下标 %d, 期望突破当天30", buy)
+	}
+	if sell < 40 || gen.GetExitReason() == "" {
+		t.Errorf("卖出下标 %d 原因 %q, 期望下跌后止损", sell, gen.GetExitReason())
+	}
+
+	// 前5天内有单日涨幅超过7%
+	surge := synthetic.Bars([]float64{10, 10, 11, 11, 11, 11, 11, 11, 11})
+	cond := RecentSurge(5, 0.07)
+	for i, want := range map[int]bool{2: false, 3: true, 7: true, 8: false} {
+		if got := cond.Test(stockStrategy.NewBarContext("sz.000001", surge, i, cond.Window)); got != want {
+			t.Errorf("下标%d 近期涨幅过大 %v, 期望 %v", i, got, want)
+		}
+	}
+	if fireDays(Not(When(cond)), 3, nil) == nil {
+		t.Error("历史不足时条件不满足，取反应触发")
+	}
+}
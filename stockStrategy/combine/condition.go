@@ -0,0 +1,168 @@
+package combine
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/signals"
+)
+
+// Condition 只根据K线判断的条件（不能保存状态，不能访问 BarContext 以外的数据）
+type Condition struct {
+	Name   string                                   // 条件名称
+	Window int                                      // 需要的历史窗口（K线根数，含当天）
+	Test   func(ctx *stockStrategy.BarContext) bool // 判断条件是否满足
+}
+
+// NewCondition 创建条件
+func NewCondition(name string, window int, test func(ctx *stockStrategy.BarContext) bool) Condition {
+	return Condition{Name: name, Window: window, Test: test}
+}
+
+// ConditionSignal 把条件转换为信号生成器：条件满足时在当前方向上触发（空仓时买入，持仓时卖出）
+// 历史不足 Window 根K线时不触发
+type ConditionSignal struct {
+	Cond Condition
+}
+
+// When 把条件转换为信号生成器
+func When(cond Condition) *ConditionSignal {
+	return &ConditionSignal{Cond: cond}
+}
+
+// Reset 条件没有状态
+func (sg *ConditionSignal) Reset() {}
+
+// GetHistoryWindow 条件需要的历史窗口
+func (sg *ConditionSignal) GetHistoryWindow() int {
+	return max(sg.Cond.Window, 1)
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *ConditionSignal) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	if ctx.Len() < sg.Cond.Window || !sg.Cond.Test(ctx) {
+		return 0
+	}
+	return signalOf(position)
+}
+
+// GetExitReason 获取卖出原因（条件名称）
+func (sg *ConditionSignal) GetExitReason() string {
+	return sg.Cond.Name
+}
+
+// GetName 获取信号生成器名称
+func (sg *ConditionSignal) GetName() string {
+	return sg.Cond.Name
+}
+
+// EntryExitSignal 分别指定买入和卖出：空仓时使用 Entry 的买入信号，持仓时使用 Exit 的卖出信号
+type EntryExitSignal struct {
+	Entry stockStrategy.SignalGenerator // 买入信号
+	Exit  stockStrategy.SignalGenerator // 卖出信号
+}
+
+// EntryExit 用 entry 判断买入、exit 判断卖出
+func EntryExit(entry, exit stockStrategy.SignalGenerator) *EntryExitSignal {
+	return &EntryExitSignal{Entry: entry, Exit: exit}
+}
+
+// Reset 重置买入和卖出信号生成器
+func (sg *EntryExitSignal) Reset() {
+	sg.Entry.Reset()
+	sg.Exit.Reset()
+}
+
+// GetHistoryWindow 买入和卖出信号生成器中最长的历史窗口
+func (sg *EntryExitSignal) GetHistoryWindow() int {
+	return historyWindow([]stockStrategy.SignalGenerator{sg.Entry, sg.Exit})
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *EntryExitSignal) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	entry := sg.Entry.ProcessDay(ctx, position)
+	exit := sg.Exit.ProcessDay(ctx, position)
+	if position == nil {
+		if entry == 1 {
+			return 1
+		}
+		return 0
+	}
+	if exit == -1 {
+		return -1
+	}
+	return 0
+}
+
+// GetExitReason 获取卖出信号生成器的卖出原因
+func (sg *EntryExitSignal) GetExitReason() string {
+	return reasonOf(sg.Exit)
+}
+
+// GetStopLossPercent 获取止损比例（卖出信号生成器优先）
+func (sg *EntryExitSignal) GetStopLossPercent() float64 {
+	for _, gen := range []stockStrategy.SignalGenerator{sg.Exit, sg.Entry} {
+		if provider, ok := gen.(stockStrategy.StopLossProvider); ok && provider.GetStopLossPercent() > 0 {
+			return provider.GetStopLossPercent()
+		}
+	}
+	return 0
+}
+
+// GetName 获取信号生成器名称
+func (sg *EntryExitSignal) GetName() string {
+	return fmt.Sprintf("买入%s,卖出%s", sg.Entry.GetName(), sg.Exit.GetName())
+}
+
+// ExitRuleSignal 把卖出规则转换为信号生成器：持仓满足卖出规则时卖出，空仓时不触发
+type ExitRuleSignal struct {
+	Rule signals.ExitRule
+
+	exitReason string // 最近一次卖出信号的原因
+}
+
+// Exit 把卖出规则（止盈、止损、移动止损、超时）转换为信号生成器
+func Exit(rule signals.ExitRule) *ExitRuleSignal {
+	return &ExitRuleSignal{Rule: rule}
+}
+
+// Reset 重置状态
+func (sg *ExitRuleSignal) Reset() {
+	sg.exitReason = ""
+}
+
+// GetHistoryWindow 只需要当天的K线
+func (sg *ExitRuleSignal) GetHistoryWindow() int {
+	return 1
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *ExitRuleSignal) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	sg.exitReason = ""
+	if position == nil {
+		return 0
+	}
+	currentPrice := float32(ctx.Price(0))
+	if currentPrice > position.HighestPrice {
+		position.HighestPrice = currentPrice
+	}
+	var sell bool
+	if sell, sg.exitReason = sg.Rule.Check(currentPrice, position); sell {
+		return -1
+	}
+	return 0
+}
+
+// GetExitReason 获取最近一次卖出信号的原因
+func (sg *ExitRuleSignal) GetExitReason() string {
+	return sg.exitReason
+}
+
+// GetStopLossPercent 获取止损比例（供仓位管理器按风险计算仓位）
+func (sg *ExitRuleSignal) GetStopLossPercent() float64 {
+	return sg.Rule.StopLoss
+}
+
+// GetName 获取信号生成器名称
+func (sg *ExitRuleSignal) GetName() string {
+	return sg.Rule.String()
+}
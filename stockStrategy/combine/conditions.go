@@ -0,0 +1,48 @@
+package combine
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// Breakout 当天价格高于之前 days 天的最高价
+func Breakout(days int) Condition {
+	return NewCondition(fmt.Sprintf("%d日突破", days), days+1, func(ctx *stockStrategy.BarContext) bool {
+		return ctx.Price(0) > ctx.Shift(1).Highest(days)
+	})
+}
+
+// MAAbove 短期均线高于长期均线（如 MAAbove(20, 60) 表示 MA20 > MA60）
+func MAAbove(short, long int) Condition {
+	return NewCondition(fmt.Sprintf("MA%d>MA%d", short, long), max(short, long), func(ctx *stockStrategy.BarContext) bool {
+		return ctx.Average(short) > ctx.Average(long)
+	})
+}
+
+// PriceAboveMA 当天价格高于 days 日均线
+func PriceAboveMA(days int) Condition {
+	return NewCondition(fmt.Sprintf("价格>MA%d", days), days, func(ctx *stockStrategy.BarContext) bool {
+		return ctx.Price(0) > ctx.Average(days)
+	})
+}
+
+// PriceBelowMA 当天价格低于 days 日均线
+func PriceBelowMA(days int) Condition {
+	return NewCondition(fmt.Sprintf("价格<MA%d", days), days, func(ctx *stockStrategy.BarContext) bool {
+		return ctx.Price(0) < ctx.Average(days)
+	})
+}
+
+// RecentSurge 当天之前的 days 天内有单日涨幅超过 percent（如0.07表示7%）
+// RecentSurge(5, 0.07) 与回测引擎买入前的高风险判断一致
+func RecentSurge(days int, percent float64) Condition {
+	name := fmt.Sprintf("前%d日单日涨幅超过%.0f%%", days, percent*100)
+	return NewCondition(name, days+2, func(ctx *stockStrategy.BarContext) bool {
+		for k := 1; k <= days; k++ {
+			if ctx.Change(k) > percent {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,77 @@
+package combine
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// ConfirmSignal 确认：Trigger 触发后 Days 个交易日内（含当天）Confirmation 也触发时触发
+// 触发一次后需要 Trigger 重新触发；Trigger 空仓时的触发只能确认买入，持仓时的触发只能确认卖出
+type ConfirmSignal struct {
+	Trigger      stockStrategy.SignalGenerator // 先触发的信号生成器
+	Confirmation stockStrategy.SignalGenerator // 确认的信号生成器
+	Days         int                           // 确认的期限（交易日）
+
+	armedIndex  int    // Trigger 最近一次触发的K线下标（-1表示没有）
+	armedSignal int    // Trigger 最近一次触发的方向
+	exitReason  string // 最近一次卖出信号的原因
+}
+
+// Confirm Trigger 触发后 days 个交易日内 Confirmation 也触发时触发
+func Confirm(trigger, confirmation stockStrategy.SignalGenerator, days int) *ConfirmSignal {
+	sg := &ConfirmSignal{
+		Trigger:      trigger,
+		Confirmation: confirmation,
+		Days:         days,
+	}
+	sg.Reset()
+	return sg
+}
+
+// Reset 重置状态和子信号生成器
+func (sg *ConfirmSignal) Reset() {
+	sg.Trigger.Reset()
+	sg.Confirmation.Reset()
+	sg.armedIndex = -1
+	sg.armedSignal = 0
+	sg.exitReason = ""
+}
+
+// GetHistoryWindow 子信号生成器中最长的历史窗口
+func (sg *ConfirmSignal) GetHistoryWindow() int {
+	return historyWindow([]stockStrategy.SignalGenerator{sg.Trigger, sg.Confirmation})
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *ConfirmSignal) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	sg.exitReason = ""
+	want := signalOf(position)
+	if fired(sg.Trigger.ProcessDay(ctx, position), position) {
+		sg.armedIndex = ctx.Index()
+		sg.armedSignal = want
+	}
+	confirmed := fired(sg.Confirmation.ProcessDay(ctx, position), position)
+
+	if sg.armedIndex < 0 || sg.armedSignal != want || ctx.Index()-sg.armedIndex > sg.Days {
+		return 0
+	}
+	if !confirmed {
+		return 0
+	}
+
+	sg.armedIndex = -1
+	if position != nil {
+		sg.exitReason = fmt.Sprintf("%s后%s确认", reasonOf(sg.Trigger), reasonOf(sg.Confirmation))
+	}
+	return want
+}
+
+// GetExitReason 获取最近一次卖出信号的原因
+func (sg *ConfirmSignal) GetExitReason() string {
+	return sg.exitReason
+}
+
+// GetName 获取信号生成器名称
+func (sg *ConfirmSignal) GetName() string {
+	return fmt.Sprintf("%s后%d天内%s确认", sg.Trigger.GetName(), sg.Days, sg.Confirmation.GetName())
+}
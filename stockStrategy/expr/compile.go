@@ -0,0 +1,226 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"stock-go/stockStrategy"
+	"strings"
+)
+
+// env 求值环境：截至当天的K线和持仓（空仓为nil）
+type env struct {
+	bars     *stockStrategy.BarContext
+	position *stockStrategy.Position
+}
+
+// shift 截至往前第 k 根K线的求值环境，超出窗口时 bars 为nil
+func (e *env) shift(k int) *env {
+	return &env{bars: e.bars.Shift(k), position: e.position}
+}
+
+type numFn func(e *env) float64
+type boolFn func(e *env) bool
+
+// compiled 编译后的子表达式：数值或条件，以及需要的历史窗口（K线根数，含当天）
+type compiled struct {
+	isBool bool
+	num    numFn
+	cond   boolFn
+	window int
+}
+
+// fields K线字段
+var fields = map[string]func(bars *stockStrategy.BarContext) float64{
+	"price": func(b *stockStrategy.BarContext) float64 { return b.Price(0) },
+	"open":  func(b *stockStrategy.BarContext) float64 { return b.Open(0) },
+	"close": func(b *stockStrategy.BarContext) float64 { return b.Close(0) },
+	"high":  func(b *stockStrategy.BarContext) float64 { return b.High(0) },
+	"low":   func(b *stockStrategy.BarContext) float64 { return b.Low(0) },
+}
+
+// positionVars 持仓变量（只能用于卖出表达式，空仓时为 NaN）
+var positionVars = map[string]func(price float64, p *stockStrategy.Position) float64{
+	"buy_price":     func(_ float64, p *stockStrategy.Position) float64 { return float64(p.BuyPrice) },
+	"hold_days":     func(_ float64, p *stockStrategy.Position) float64 { return float64(p.HoldDays) },
+	"highest_price": func(price float64, p *stockStrategy.Position) float64 { return max(float64(p.HighestPrice), price) },
+	"profit": func(price float64, p *stockStrategy.Position) float64 {
+		return price/float64(p.BuyPrice) - 1
+	},
+	"drawdown": func(price float64, p *stockStrategy.Position) float64 {
+		return 1 - price/max(float64(p.HighestPrice), price)
+	},
+}
+
+// compiler 把语法树编译为求值函数，同时检查类型
+type compiler struct {
+	src           string
+	allowPosition bool // 是否允许持仓变量
+	inWindow      int  // 当前所在窗口函数的嵌套深度（窗口函数内不能使用持仓变量）
+}
+
+func (c *compiler) errorf(n node, format string, args ...any) *Error {
+	return &Error{Source: c.src, Pos: n.position(), Msg: fmt.Sprintf(format, args...)}
+}
+
+// compileNumber 编译数值表达式
+func (c *compiler) compileNumber(n node, what string) (*compiled, error) {
+	x, err := c.compile(n)
+	if err != nil {
+		return nil, err
+	}
+	if x.isBool {
+		return nil, c.errorf(n, "%s应为数值，而不是条件", what)
+	}
+	return x, nil
+}
+
+// compileCondition 编译条件表达式
+func (c *compiler) compileCondition(n node, what string) (*compiled, error) {
+	x, err := c.compile(n)
+	if err != nil {
+		return nil, err
+	}
+	if !x.isBool {
+		return nil, c.errorf(n, "%s应为条件（比较或 and/or/not），而不是数值", what)
+	}
+	return x, nil
+}
+
+func (c *compiler) compile(n node) (*compiled, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		value := n.value
+		return &compiled{num: func(*env) float64 { return value }, window: 1}, nil
+
+	case *boolNode:
+		value := n.value
+		return &compiled{isBool: true, cond: func(*env) bool { return value }, window: 1}, nil
+
+	case *identNode:
+		return c.compileIdent(n)
+
+	case *callNode:
+		return c.compileCall(n)
+
+	case *unaryNode:
+		if n.op == "not" {
+			x, err := c.compileCondition(n.x, "not 的操作数")
+			if err != nil {
+				return nil, err
+			}
+			return &compiled{isBool: true, cond: func(e *env) bool { return !x.cond(e) }, window: x.window}, nil
+		}
+		x, err := c.compileNumber(n.x, "负号的操作数")
+		if err != nil {
+			return nil, err
+		}
+		return &compiled{num: func(e *env) float64 { return -x.num(e) }, window: x.window}, nil
+
+	case *binaryNode:
+		return c.compileBinary(n)
+	}
+	return nil, c.errorf(n, "无法编译的表达式")
+}
+
+// compileIdent 编译字段和持仓变量
+func (c *compiler) compileIdent(n *identNode) (*compiled, error) {
+	if field, ok := fields[n.name]; ok {
+		return &compiled{num: func(e *env) float64 { return field(e.bars) }, window: 1}, nil
+	}
+	if variable, ok := positionVars[n.name]; ok {
+		if !c.allowPosition {
+			return nil, c.errorf(n, "持仓变量 %s 只能用于卖出表达式", n.name)
+		}
+		if c.inWindow > 0 {
+			return nil, c.errorf(n, "持仓变量 %s 不能用于窗口函数", n.name)
+		}
+		return &compiled{num: func(e *env) float64 {
+			if e.position == nil || e.position.BuyPrice <= 0 {
+				return math.NaN()
+			}
+			return variable(e.bars.Price(0), e.position)
+		}, window: 1}, nil
+	}
+	if _, ok := functions[n.name]; ok {
+		return nil, c.errorf(n, "%s 是函数，请使用 %s", n.name, functions[n.name].usage)
+	}
+	return nil, c.errorf(n, "未知的字段 %s（可用字段: %s）", n.name, strings.Join(sortedKeys(fields), ", "))
+}
+
+// compileBinary 编译二元运算
+func (c *compiler) compileBinary(n *binaryNode) (*compiled, error) {
+	if n.op == "and" || n.op == "or" {
+		left, err := c.compileCondition(n.left, n.op+" 的左边")
+		if err != nil {
+			return nil, err
+		}
+		right, err := c.compileCondition(n.right, n.op+" 的右边")
+		if err != nil {
+			return nil, err
+		}
+		window := max(left.window, right.window)
+		if n.op == "and" {
+			return &compiled{isBool: true, cond: func(e *env) bool { return left.cond(e) && right.cond(e) }, window: window}, nil
+		}
+		return &compiled{isBool: true, cond: func(e *env) bool { return left.cond(e) || right.cond(e) }, window: window}, nil
+	}
+
+	left, err := c.compileNumber(n.left, n.op+" 的左边")
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.compileNumber(n.right, n.op+" 的右边")
+	if err != nil {
+		return nil, err
+	}
+	window := max(left.window, right.window)
+	a, b := left.num, right.num
+
+	// 比较：任一边为 NaN（数据不足）时结果为false
+	var cmp func(x, y float64) bool
+	switch n.op {
+	case "<":
+		cmp = func(x, y float64) bool { return x < y }
+	case "<=":
+		cmp = func(x, y float64) bool { return x <= y }
+	case ">":
+		cmp = func(x, y float64) bool { return x > y }
+	case ">=":
+		cmp = func(x, y float64) bool { return x >= y }
+	case "==":
+		cmp = func(x, y float64) bool { return x == y }
+	case "!=":
+		cmp = func(x, y float64) bool { return x != y && !math.IsNaN(x) && !math.IsNaN(y) }
+	}
+	if cmp != nil {
+		return &compiled{isBool: true, cond: func(e *env) bool { return cmp(a(e), b(e)) }, window: window}, nil
+	}
+
+	var arith func(x, y float64) float64
+	switch n.op {
+	case "+":
+		arith = func(x, y float64) float64 { return x + y }
+	case "-":
+		arith = func(x, y float64) float64 { return x - y }
+	case "*":
+		arith = func(x, y float64) float64 { return x * y }
+	case "/":
+		arith = func(x, y float64) float64 { return x / y }
+	case "%":
+		arith = math.Mod
+	default:
+		return nil, c.errorf(n, "未知的运算符 %s", n.op)
+	}
+	return &compiled{num: func(e *env) float64 { return arith(a(e), b(e)) }, window: window}, nil
+}
+
+// sortedKeys map 的键（排序后）
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
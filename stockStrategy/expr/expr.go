@@ -0,0 +1,99 @@
+// Package expr 交易规则表达式
+// 用类似 `close >= highest(close, 300) * 0.995 and rsi(14) < 80` 的表达式描述买卖条件和选股条件，
+// 编译后可直接作为信号生成器（Signal）、选股器（Selector）或组合条件（combine.Condition）使用。
+//
+// 语法：
+//   - 字段：price（成交价）、open、close、high、low，均为当天的值
+//   - 运算：+ - * / %，比较 < <= > >= == !=，逻辑 and/&&、or/||、not/!，括号
+//   - 窗口函数：ref、change、highest、lowest、sum、sma/ma、ema、std，指标 rsi、macd_*、boll_*、atr、kdj_*
+//   - 其他函数：abs、max、min、cross_up、cross_down
+//   - 卖出表达式可使用持仓变量：buy_price、hold_days、highest_price、profit、drawdown
+//
+// 表达式只通过 BarContext 读取K线，函数的天数和偏移必须是非负的数字常量，因此任何表达式都不能读取未来的K线。
+// 数据不足时数值为 NaN，涉及 NaN 的比较结果都为false。
+package expr
+
+import (
+	"math"
+	"stock-go/stockStrategy"
+)
+
+// Expr 编译后的表达式
+type Expr struct {
+	Source string // 表达式原文
+	Window int    // 需要的历史窗口（K线根数，含当天）
+
+	isBool bool
+	num    numFn
+	cond   boolFn
+}
+
+// Compile 编译表达式（不能使用持仓变量），语法或类型错误时返回 *Error
+func Compile(src string) (*Expr, error) {
+	return compileExpr(src, false)
+}
+
+// CompileExit 编译卖出表达式（可以使用持仓变量）
+func CompileExit(src string) (*Expr, error) {
+	return compileExpr(src, true)
+}
+
+// MustCompile 编译表达式，出错时 panic（用于常量表达式）
+func MustCompile(src string) *Expr {
+	e, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func compileExpr(src string, allowPosition bool) (*Expr, error) {
+	root, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiler{src: src, allowPosition: allowPosition}
+	x, err := c.compile(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{Source: src, Window: x.window, isBool: x.isBool, num: x.num, cond: x.cond}, nil
+}
+
+// IsCondition 是否为条件表达式（比较或 and/or/not）
+func (e *Expr) IsCondition() bool {
+	return e.isBool
+}
+
+// Value 计算数值表达式的值（条件表达式返回1或0），position 为nil表示空仓
+func (e *Expr) Value(ctx *stockStrategy.BarContext, position *stockStrategy.Position) float64 {
+	if ctx == nil || ctx.Len() == 0 {
+		return math.NaN()
+	}
+	env := &env{bars: ctx, position: position}
+	if !e.isBool {
+		return e.num(env)
+	}
+	if e.cond(env) {
+		return 1
+	}
+	return 0
+}
+
+// Test 计算条件表达式（数值表达式非0且不是NaN时为true），position 为nil表示空仓
+func (e *Expr) Test(ctx *stockStrategy.BarContext, position *stockStrategy.Position) bool {
+	if ctx == nil || ctx.Len() == 0 {
+		return false
+	}
+	env := &env{bars: ctx, position: position}
+	if e.isBool {
+		return e.cond(env)
+	}
+	v := e.num(env)
+	return v != 0 && !math.IsNaN(v)
+}
+
+// String 表达式原文
+func (e *Expr) String() string {
+	return e.Source
+}
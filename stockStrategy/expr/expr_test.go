@@ -0,0 +1,305 @@
+package expr
+
+import (
+	"math"
+	"stock-go/stockData"
+	"stock-go/stockData/synthetic"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/combine"
+	"stock-go/stockStrategy/indicators"
+	"stock-go/stockStrategy/lookahead"
+	"strings"
+	"testing"
+)
+
+// randomStock 固定种子的随机游走票票
+func randomStock(code string, seed uint64, days int) *stockData.StockInfo {
+	return synthetic.FromPrices(code, synthetic.RandomWalk(seed, days))
+}
+
+// linearBars 价格依次为 1, 2, ..., n 的K线
+func linearBars(n int) []*stockData.StockDataDay {
+	bars := make([]*stockData.StockDataDay, n)
+	for i := range bars {
+		p := float32(i + 1)
+		bars[i] = &stockData.StockDataDay{Index: i + 1, PriceBegin: p, PriceEnd: p, PriceHigh: p + 0.5, PriceLow: p - 0.5}
+	}
+	return bars
+}
+
+// TestParseErrors 测试错误信息包含出错的列和原因
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		src    string
+		exit   bool
+		column int
+		msg    string
+	}{
+		{"", false, 1, "表达式为空"},
+		{"close >", false, 8, "缺少操作数"},
+		{"close = 1", false, 7, "=="},
+		{"close & 1", false, 7, "&&"},
+		{"(close > 1", false, 11, "缺少 )"},
+		{"close > 1 > 2", false, 11, "不能连写"},
+		{"close 1", false, 7, "缺少运算符"},
+		{"clsoe > 1", false, 1, "未知的字段 clsoe"},
+		{"foo(close) > 1", false, 1, "未知的函数 foo"},
+		{"highest(close) > 1", false, 1, "缺少参数 天数"},
+		{"highest(close, 5, 6) > 1", false, 19, "参数太多"},
+		{"ref(close, -1) > 1", false, 12, "不小于0的整数"},
+		{"sma(close, 2.5) > 1", false, 12, "整数"},
+		{"highest(close, low) > 1", false, 16, "必须是数字常量"},
+		{"close and 1", false, 1, "and 的左边应为条件"},
+		{"close + (low > 1)", false, 10, "应为数值"},
+		{"rsi(14", false, 7, "应为 , 或 )"},
+		{"close > 1 $", false, 11, "无法识别的字符"},
+		{"highest > 1", false, 1, "是函数"},
+		{"profit > 0.1", false, 1, "只能用于卖出表达式"},
+		{"highest(profit, 5) > 0.1", true, 9, "不能用于窗口函数"},
+	}
+	for _, tc := range cases {
+		compile := Compile
+		if tc.exit {
+			compile = CompileExit
+		}
+		_, err := compile(tc.src)
+		exprErr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%q 应返回 *Error, 实际 %v", tc.src, err)
+			continue
+		}
+		if exprErr.Pos+1 != tc.column || !strings.Contains(exprErr.Msg, tc.msg) {
+			t.Errorf("%q: 第%d列 %q, 期望第%d列包含 %q", tc.src, exprErr.Pos+1, exprErr.Msg, tc.column, tc.msg)
+		}
+	}
+
+	_, err := Compile("close > 1 $")
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != 3 || !strings.HasSuffix(lines[2], strings.Repeat(" ", 10)+"^") {
+		t.Errorf("错误信息应包含表达式和出错位置标记:\n%s", err)
+	}
+	if _, err := NewSignal("close + 1", ""); err == nil || !strings.Contains(err.Error(), "买入表达式应为条件") {
+		t.Errorf("数值表达式不能作为买入条件: %v", err)
+	}
+}
+
+// TestEvaluate 测试运算、字段、窗口函数和数据不足时的结果
+func TestEvaluate(t *testing.T) {
+	ctx := stockStrategy.NewBarContext("sz.000001", linearBars(20), 9, 100) // 价格 1..10，当天为10
+	values := map[string]float64{
+		"close":                               10,
+		"1 + 2 * 3 - 4 / 2":                   5,
+		"-close + 10 % 4":                     -8,
+		"(high - low) * 2":                    2,
+		"ref(close, 2)":                       8,
+		"ref(close, 0)":                       10,
+		"change(close, 5)":                    10.0/5 - 1,
+		"highest(close, 3)":                   10,
+		"lowest(low, 3)":                      7.5,
+		"sum(close, 4)":                       34,
+		"sma(close, 4)":                       8.5,
+		"ma(ref(close, 1), 2)":                8.5,
+		"highest(ref(high, 2), 3)":            8.5,
+		"abs(-3) + max(1, 2) + min(close, 0)": 5,
+		"close > 3 and not (close > 100)":     1,
+		"close < 3 || close >= 10":            1,
+		"!(close == 10)":                      0,
+	}
+	for src, want := range values {
+		e, err := Compile(src)
+		if err != nil {
+			t.Errorf("%q 编译失败: %v", src, err)
+			continue
+		}
+		if got := e.Value(ctx, nil); math.Abs(got-want) > 1e-9 {
+			t.Errorf("%q = %v, 期望 %v", src, got, want)
+		}
+	}
+
+	// 数据不足：数值为 NaN，比较为false
+	if v := MustCompile("highest(close, 11)").Value(ctx, nil); !math.IsNaN(v) {
+		t.Errorf("数据不足时应为 NaN: %v", v)
+	}
+	if MustCompile("highest(close, 11) > 0").Test(ctx, nil) || MustCompile("ref(close, 10) != 1").Test(ctx, nil) {
+		t.Error("数据不足时比较应为false")
+	}
+
+	// 上穿：价格上穿5（前一天等于5）
+	cross := MustCompile("cross_up(close, 5)")
+	bars := linearBars(20)
+	if !cross.Test(stockStrategy.NewBarContext("sz.000001", bars, 5, 10), nil) || cross.Test(stockStrategy.NewBarContext("sz.000001", bars, 6, 10), nil) {
+		t.Error("cross_up 应只在穿越当天成立")
+	}
+
+	// 需要的历史窗口
+	windows := map[string]int{
+		"close":                                   1,
+		"ref(close, 5)":                           6,
+		"highest(close, 300) * 0.995":             300,
+		"sma(ref(close, 5), 10)":                  15,
+		"rsi(14)":                                 71,
+		"close > highest(ref(high, 1), 20)":       21,
+		"cross_up(sma(close, 5), sma(close, 20))": 21,
+	}
+	for src, want := range windows {
+		if got := MustCompile(src).Window; got != want {
+			t.Errorf("%q 历史窗口 %d, 期望 %d", src, got, want)
+		}
+	}
+}
+
+// lastOf 序列的最后一个值
+func lastOf[T any](values []T) T {
+	return values[len(values)-1]
+}
+
+// TestIndicatorFunctions 测试指标函数与指标库在相同K线上的计算结果一致
+func TestIndicatorFunctions(t *testing.T) {
+	stock := randomStock("sz.000001", 3, 300)
+	bars := stock.Datas.DayDatas
+	i := 250
+	closes := indicators.Closes(bars[:i+1])
+	highs := indicators.Highs(bars[:i+1])
+	lows := indicators.Lows(bars[:i+1])
+	tail := func(values []float64, n int) []float64 { return values[len(values)-n:] }
+
+	want := map[string]float64{
+		"rsi(14)":                    lastOf(indicators.CalculateRSI(tail(closes, 71), 14)),
+		"rsi(close, 6)":              lastOf(indicators.CalculateRSI(tail(closes, 31), 6)),
+		"ema(close, 10)":             lastOf(indicators.CalculateEMA(tail(closes, 50), 10)),
+		"std(close, 20)":             lastOf(indicators.CalculateStdDev(closes, 20)),
+		"macd_dif()":                 lastOf(indicators.CalculateMACD(tail(closes, 175), 12, 26, 9)).DIF,
+		"macd_hist(5, 10, 3)":        lastOf(indicators.CalculateMACD(tail(closes, 65), 5, 10, 3)).Hist,
+		"boll_upper(20, 2)":          lastOf(indicators.CalculateBollinger(closes, 20, 2)).Upper,
+		"boll_lower(close, 10, 1.5)": lastOf(indicators.CalculateBollinger(closes, 10, 1.5)).Lower,
+		"atr(14)":                    lastOf(indicators.CalculateATR(tail(highs, 15), tail(lows, 15), tail(closes, 15), 14)),
+		"kdj_k()":                    lastOf(indicators.CalculateKDJ(tail(highs, 39), tail(lows, 39), tail(closes, 39), 9, 3, 3)).K,
+		"kdj_j(5, 2, 2)":             lastOf(indicators.CalculateKDJ(tail(highs, 25), tail(lows, 25), tail(closes, 25), 5, 2, 2)).J,
+	}
+	for src, expected := range want {
+		e := MustCompile(src)
+		got := e.Value(stockStrategy.NewBarContext("sz.000001", bars, i, e.Window), nil)
+		if math.IsNaN(got) || math.Abs(got-expected) > 1e-9 {
+			t.Errorf("%s = %v, 期望 %v", src, got, expected)
+		}
+	}
+}
+
+// TestWindowCache 测试逐日求值时按K线缓存的结果与重新编译后单独求值一致，换一份数据时不会使用旧数据的缓存
+func TestWindowCache(t *testing.T) {
+	src := "sma(ema(close, 5) - sma(close, 10), 5) / std(close, 10)"
+	e := MustCompile(src)
+	for seed := uint64(1); seed <= 2; seed++ {
+		bars := randomStock("sz.000001", seed, 120).Datas.DayDatas
+		for i := range bars {
+			got := e.Value(stockStrategy.NewBarContext("sz.000001", bars, i, e.Window), nil)
+			want := MustCompile(src).Value(stockStrategy.NewBarContext("sz.000001", bars, i, e.Window), nil)
+			if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+				t.Fatalf("种子%d 第%d天: 缓存结果 %v, 期望 %v", seed, i, got, want)
+			}
+		}
+	}
+
+	// 窗口不足时为 NaN，且不影响之后窗口足够时的结果
+	bars := randomStock("sz.000001", 3, 120).Datas.DayDatas
+	if v := e.Value(stockStrategy.NewBarContext("sz.000001", bars, 100, 5), nil); !math.IsNaN(v) {
+		t.Errorf("窗口不足时应为 NaN，实际 %v", v)
+	}
+	want := MustCompile(src).Value(stockStrategy.NewBarContext("sz.000001", bars, 100, e.Window), nil)
+	if got := e.Value(stockStrategy.NewBarContext("sz.000001", bars, 100, e.Window), nil); got != want {
+		t.Errorf("窗口足够时 %v, 期望 %v", got, want)
+	}
+}
+
+// TestExpressionsNoLookahead 测试表达式信号生成器和选股器不能读取未来的K线
+func TestExpressionsNoLookahead(t *testing.T) {
+	stock := randomStock("sz.000001", 7, 200)
+	rules := []struct{ entry, exit string }{
+		{"close >= highest(close, 60) * 0.995 and rsi(14) < 80", "drawdown > 0.05 or hold_days >= 20"},
+		{"cross_up(ema(close, 5), sma(close, 20))", "cross_down(ema(close, 5), sma(close, 20)) or profit < -0.04"},
+		{"macd_hist() > 0 and kdj_j() < 100 and close > boll_mid()", "close < ref(low, 1) or atr(14) / close > 0.05"},
+		{"change(close, 10) > 0.05 and std(close, 20) / sma(close, 20) < 0.1", "price < highest_price * 0.95"},
+	}
+	for _, rule := range rules {
+		if _, err := NewSignal(rule.entry, rule.exit); err != nil {
+			t.Fatal(err)
+		}
+		lookahead.AssertSignalGenerator(t, func() stockStrategy.SignalGenerator {
+			sg, _ := NewSignal(rule.entry, rule.exit)
+			return sg
+		}, stock, lookahead.NewDefaultOptions())
+
+		selector, err := NewSelector(rule.entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := map[string]*stockData.StockInfo{"sz.000001": stock, "sz.000002": randomStock("sz.000002", 9, 200)}
+		lookahead.AssertSelector(t, selector, data, lookahead.Options{StartIndex: 0, Step: 5, MaxViolations: 10})
+	}
+}
+
+// TestSignalAndSelector 测试表达式信号生成器的买卖、选股器和组合条件
+func TestSignalAndSelector(t *testing.T) {
+	bars := linearBars(40) // 每天上涨
+	sg, err := NewSignal("close > ref(close, 1) and close >= 10", "profit >= 0.5 or hold_days > 100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sg.GetHistoryWindow() != 2 {
+		t.Errorf("历史窗口 %d, 期望 2", sg.GetHistoryWindow())
+	}
+
+	var position *stockStrategy.Position
+	buy, sell := -1, -1
+	for i := range bars {
+		switch sg.ProcessDay(stockStrategy.NewBarContext("sz.000001", bars, i, sg.GetHistoryWindow()), position) {
+		case 1:
+			buy = i
+			position = &stockStrategy.Position{BuyPrice: bars[i].PriceBegin, HighestPrice: bars[i].PriceBegin}
+		case -1:
+			sell = i
+			position = nil
+		}
+		if sell >= 0 {
+			break
+		}
+	}
+	// 第10根K线（价格10）买入，价格涨到15（盈利50%）时卖出
+	if buy != 9 || sell != 14 || sg.GetExitReason() != "表达式卖出(profit >= 0.5 or hold_days > 100)" {
+		t.Errorf("买入 %d 卖出 %d 原因 %q", buy, sell, sg.GetExitReason())
+	}
+
+	// 只有卖出表达式：空仓时不买入
+	exit, err := NewExitSignal("profit >= 0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := stockStrategy.NewBarContext("sz.000001", bars, 14, exit.GetHistoryWindow())
+	if exit.ProcessDay(ctx, nil) != 0 || exit.ProcessDay(ctx, &stockStrategy.Position{BuyPrice: 10, HighestPrice: 15}) != -1 {
+		t.Error("卖出表达式信号不正确")
+	}
+
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": {Code: "sz.000001", Datas: stockData.StockData{DayDatas: linearBars(40)}},
+		"sz.000002": randomStock("sz.000002", 1, 40),
+	}
+	selector, err := NewSelector("close >= 30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector.SetStockData(data)
+	if got := selector.SelectStocksAtDate([]string{"sz.000001", "sz.000002"}, 35); len(got) != 1 || got[0] != "sz.000001" {
+		t.Errorf("选股结果 %v", got)
+	}
+	if got := selector.SelectStocksAtDate([]string{"sz.000001"}, 20); len(got) != 0 {
+		t.Errorf("第21天价格21不应入选: %v", got)
+	}
+
+	cond, err := Condition("close > sma(close, 5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := combine.And(combine.When(cond), combine.When(combine.Breakout(3)))
+	if gen.ProcessDay(stockStrategy.NewBarContext("sz.000001", bars, 10, stockStrategy.HistoryWindow(gen)), nil) != 1 {
+		t.Error("表达式条件应可以与其他条件组合")
+	}
+}
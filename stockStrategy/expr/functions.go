@@ -0,0 +1,300 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/indicators"
+	"strings"
+	"sync"
+)
+
+// constParam 函数的常量参数（周期等必须是数字常量，保证编译时就能确定需要的历史窗口）
+type constParam struct {
+	name    string
+	def     float64 // 默认值，NaN 表示必填
+	min     float64 // 最小值
+	integer bool    // 是否必须是整数
+}
+
+// function 窗口函数
+// 序列函数对序列参数（省略时为 price）在最近 lookback 根K线上的取值计算；
+// K线函数（series 为false）直接使用最近 lookback 根K线的最高价、最低价和收盘价
+type function struct {
+	usage    string
+	series   bool
+	params   []constParam
+	lookback func(p []float64) int
+	eval     func(values []float64, p []float64) float64
+	evalBars func(bars *stockStrategy.BarContext, p []float64, lookback int) float64
+}
+
+// 常用参数
+var (
+	periodParam = func(name string, def float64) constParam {
+		return constParam{name: name, def: def, min: 1, integer: true}
+	}
+	required = math.NaN()
+)
+
+// smoothing 指数平滑类指标使用的K线倍数：只使用最近 smoothing*周期 根K线，结果与更早的数据无关
+const smoothing = 5
+
+var functions map[string]*function
+
+func init() {
+	n := func(p []float64) int { return int(p[0]) }
+	macdLookback := func(p []float64) int { return smoothing * (int(p[1]) + int(p[2])) }
+	macd := func(pick func(v indicators.MACDValue) float64) func([]float64, []float64) float64 {
+		return func(values, p []float64) float64 {
+			return pick(indicators.CalculateMACD(values, int(p[0]), int(p[1]), int(p[2]))[len(values)-1])
+		}
+	}
+	boll := func(pick func(v indicators.BandValue) float64) func([]float64, []float64) float64 {
+		return func(values, p []float64) float64 {
+			return pick(indicators.CalculateBollinger(values, int(p[0]), p[1])[len(values)-1])
+		}
+	}
+	kdj := func(pick func(v indicators.KDJValue) float64) func(*stockStrategy.BarContext, []float64, int) float64 {
+		return func(bars *stockStrategy.BarContext, p []float64, lookback int) float64 {
+			values := indicators.CalculateKDJ(bars.Highs(lookback), bars.Lows(lookback), bars.Closes(lookback), int(p[0]), int(p[1]), int(p[2]))
+			return pick(values[len(values)-1])
+		}
+	}
+	macdParams := []constParam{periodParam("快线周期", 12), periodParam("慢线周期", 26), periodParam("信号周期", 9)}
+	bollParams := []constParam{periodParam("周期", 20), {name: "倍数", def: 2, min: 0}}
+	kdjParams := []constParam{periodParam("周期", 9), periodParam("K平滑", 3), periodParam("D平滑", 3)}
+	kdjLookback := func(p []float64) int { return int(p[0]) + smoothing*(int(p[1])+int(p[2])) }
+
+	functions = map[string]*function{
+		"ref": {usage: "ref(序列, 天数)", series: true,
+			params:   []constParam{{name: "天数", def: required, min: 0, integer: true}},
+			lookback: func(p []float64) int { return int(p[0]) + 1 },
+			eval:     func(values, _ []float64) float64 { return values[0] }},
+		"change": {usage: "change(序列, 天数)", series: true,
+			params:   []constParam{periodParam("天数", required)},
+			lookback: func(p []float64) int { return int(p[0]) + 1 },
+			eval:     func(values, _ []float64) float64 { return values[len(values)-1]/values[0] - 1 }},
+		"highest": {usage: "highest(序列, 天数)", series: true, params: []constParam{periodParam("天数", required)}, lookback: n,
+			eval: func(values, _ []float64) float64 { return reduce(values, math.Max) }},
+		"lowest": {usage: "lowest(序列, 天数)", series: true, params: []constParam{periodParam("天数", required)}, lookback: n,
+			eval: func(values, _ []float64) float64 { return reduce(values, math.Min) }},
+		"sum": {usage: "sum(序列, 天数)", series: true, params: []constParam{periodParam("天数", required)}, lookback: n,
+			eval: func(values, _ []float64) float64 { return reduce(values, func(a, b float64) float64 { return a + b }) }},
+		"sma": {usage: "sma(序列, 天数)", series: true, params: []constParam{periodParam("天数", required)}, lookback: n,
+			eval: func(values, _ []float64) float64 { return mean(values) }},
+		"std": {usage: "std(序列, 天数)", series: true, params: []constParam{periodParam("天数", required)}, lookback: n,
+			eval: func(values, p []float64) float64 { return indicators.CalculateStdDev(values, int(p[0]))[len(values)-1] }},
+		"ema": {usage: "ema(序列, 天数)", series: true, params: []constParam{periodParam("天数", required)},
+			lookback: func(p []float64) int { return smoothing * int(p[0]) },
+			eval:     func(values, p []float64) float64 { return indicators.CalculateEMA(values, int(p[0]))[len(values)-1] }},
+		"rsi": {usage: "rsi(序列, 周期=14)", series: true, params: []constParam{periodParam("周期", 14)},
+			lookback: func(p []float64) int { return smoothing*int(p[0]) + 1 },
+			eval:     func(values, p []float64) float64 { return indicators.CalculateRSI(values, int(p[0]))[len(values)-1] }},
+		"macd_dif":   {usage: "macd_dif(序列, 12, 26, 9)", series: true, params: macdParams, lookback: macdLookback, eval: macd(func(v indicators.MACDValue) float64 { return v.DIF })},
+		"macd_dea":   {usage: "macd_dea(序列, 12, 26, 9)", series: true, params: macdParams, lookback: macdLookback, eval: macd(func(v indicators.MACDValue) float64 { return v.DEA })},
+		"macd_hist":  {usage: "macd_hist(序列, 12, 26, 9)", series: true, params: macdParams, lookback: macdLookback, eval: macd(func(v indicators.MACDValue) float64 { return v.Hist })},
+		"boll_upper": {usage: "boll_upper(序列, 20, 2)", series: true, params: bollParams, lookback: n, eval: boll(func(v indicators.BandValue) float64 { return v.Upper })},
+		"boll_mid":   {usage: "boll_mid(序列, 20, 2)", series: true, params: bollParams, lookback: n, eval: boll(func(v indicators.BandValue) float64 { return v.Middle })},
+		"boll_lower": {usage: "boll_lower(序列, 20, 2)", series: true, params: bollParams, lookback: n, eval: boll(func(v indicators.BandValue) float64 { return v.Lower })},
+		"atr": {usage: "atr(周期=14)", params: []constParam{periodParam("周期", 14)},
+			lookback: func(p []float64) int { return int(p[0]) + 1 },
+			evalBars: func(bars *stockStrategy.BarContext, p []float64, lookback int) float64 {
+				values := indicators.CalculateATR(bars.Highs(lookback), bars.Lows(lookback), bars.Closes(lookback), int(p[0]))
+				return values[len(values)-1]
+			}},
+		"kdj_k": {usage: "kdj_k(9, 3, 3)", params: kdjParams, lookback: kdjLookback, evalBars: kdj(func(v indicators.KDJValue) float64 { return v.K })},
+		"kdj_d": {usage: "kdj_d(9, 3, 3)", params: kdjParams, lookback: kdjLookback, evalBars: kdj(func(v indicators.KDJValue) float64 { return v.D })},
+		"kdj_j": {usage: "kdj_j(9, 3, 3)", params: kdjParams, lookback: kdjLookback, evalBars: kdj(func(v indicators.KDJValue) float64 { return v.J })},
+	}
+	functions["ma"] = functions["sma"]
+}
+
+// scalarFunctions 参数都是表达式的函数
+var scalarFunctions = map[string]string{
+	"abs":        "abs(数值)",
+	"max":        "max(数值, 数值)",
+	"min":        "min(数值, 数值)",
+	"cross_up":   "cross_up(数值, 数值)",
+	"cross_down": "cross_down(数值, 数值)",
+}
+
+// compileCall 编译函数调用
+func (c *compiler) compileCall(call *callNode) (*compiled, error) {
+	if usage, ok := scalarFunctions[call.name]; ok {
+		return c.compileScalar(call, usage)
+	}
+	fn, ok := functions[call.name]
+	if !ok {
+		names := append(sortedKeys(functions), sortedKeys(scalarFunctions)...)
+		return nil, c.errorf(call, "未知的函数 %s（可用函数: %s）", call.name, strings.Join(names, ", "))
+	}
+
+	// 第一个参数不是数字常量时为序列
+	args := call.args
+	var series *compiled
+	if fn.series && len(args) > 0 {
+		if _, isConst := args[0].(*numberNode); !isConst {
+			c.inWindow++
+			x, err := c.compileNumber(args[0], call.name+" 的序列参数")
+			c.inWindow--
+			if err != nil {
+				return nil, err
+			}
+			series = x
+			args = args[1:]
+		}
+	}
+	if series == nil && fn.series {
+		series = &compiled{num: func(e *env) float64 { return e.bars.Price(0) }, window: 1}
+	}
+
+	params, err := c.constParams(call, fn, args)
+	if err != nil {
+		return nil, err
+	}
+	lookback := fn.lookback(params)
+
+	if fn.evalBars != nil {
+		evalBars := fn.evalBars
+		return &compiled{num: cached(lookback, func(e *env) float64 {
+			return evalBars(e.bars, params, lookback)
+		}), window: lookback}, nil
+	}
+
+	// 序列在最近 lookback 根K线上的取值，每个取值都只使用截至那根K线的数据
+	window := series.window + lookback - 1
+	eval, x := fn.eval, series.num
+	return &compiled{num: cached(window, func(e *env) float64 {
+		values := make([]float64, lookback)
+		for k := 0; k < lookback; k++ {
+			values[lookback-1-k] = x(e.shift(k))
+		}
+		return eval(values, params)
+	}), window: window}, nil
+}
+
+// cached 按K线缓存窗口函数的取值，窗口内的K线不足 window 根时为 NaN
+// 窗口足够时取值只由截至那根K线的数据决定，与从哪一天往前推算无关，
+// 因此每根K线只计算一次：逐日求值时序列参数的历史取值直接命中缓存，嵌套的窗口函数也不会重复计算
+func cached(window int, compute numFn) numFn {
+	var mu sync.Mutex
+	values := make(map[stockStrategy.BarKey]float64)
+	return func(e *env) float64 {
+		if e.bars.Len() < window {
+			return math.NaN()
+		}
+		key := e.bars.Key()
+		mu.Lock()
+		v, ok := values[key]
+		mu.Unlock()
+		if ok {
+			return v
+		}
+		v = compute(e)
+		mu.Lock()
+		values[key] = v
+		mu.Unlock()
+		return v
+	}
+}
+
+// constParams 检查并补全常量参数
+func (c *compiler) constParams(call *callNode, fn *function, args []node) ([]float64, error) {
+	if len(args) > len(fn.params) {
+		return nil, c.errorf(args[len(fn.params)], "%s 的参数太多，用法: %s", call.name, fn.usage)
+	}
+	params := make([]float64, len(fn.params))
+	for i, param := range fn.params {
+		if i >= len(args) {
+			if math.IsNaN(param.def) {
+				return nil, c.errorf(call, "%s 缺少参数 %s，用法: %s", call.name, param.name, fn.usage)
+			}
+			params[i] = param.def
+			continue
+		}
+		num, ok := args[i].(*numberNode)
+		if !ok {
+			return nil, c.errorf(args[i], "%s 的参数 %s 必须是数字常量，用法: %s", call.name, param.name, fn.usage)
+		}
+		if num.value < param.min || (param.integer && num.value != math.Trunc(num.value)) {
+			kind := "数字"
+			if param.integer {
+				kind = "整数"
+			}
+			return nil, c.errorf(args[i], "%s 的参数 %s 必须是不小于%g的%s", call.name, param.name, param.min, kind)
+		}
+		params[i] = num.value
+	}
+	return params, nil
+}
+
+// compileScalar 编译参数都是表达式的函数
+func (c *compiler) compileScalar(call *callNode, usage string) (*compiled, error) {
+	want := 2
+	if call.name == "abs" {
+		want = 1
+	}
+	if len(call.args) != want {
+		return nil, c.errorf(call, "%s 需要%d个参数，用法: %s", call.name, want, usage)
+	}
+	args := make([]*compiled, want)
+	window := 1
+	crossing := strings.HasPrefix(call.name, "cross_")
+	for i, arg := range call.args {
+		if crossing {
+			c.inWindow++ // 上穿、下穿需要前一天的值
+		}
+		x, err := c.compileNumber(arg, fmt.Sprintf("%s 的第%d个参数", call.name, i+1))
+		if crossing {
+			c.inWindow--
+		}
+		if err != nil {
+			return nil, err
+		}
+		args[i] = x
+		window = max(window, x.window)
+	}
+
+	switch call.name {
+	case "abs":
+		x := args[0].num
+		return &compiled{num: func(e *env) float64 { return math.Abs(x(e)) }, window: window}, nil
+	case "max", "min":
+		pick := math.Max
+		if call.name == "min" {
+			pick = math.Min
+		}
+		a, b := args[0].num, args[1].num
+		return &compiled{num: func(e *env) float64 { return pick(a(e), b(e)) }, window: window}, nil
+	}
+
+	// 上穿：今天 a > b 且前一天 a <= b；下穿相反
+	a, b := args[0].num, args[1].num
+	up := call.name == "cross_up"
+	window++
+	return &compiled{isBool: true, cond: func(e *env) bool {
+		if e.bars.Len() < window {
+			return false
+		}
+		prev := e.shift(1)
+		if up {
+			return a(e) > b(e) && a(prev) <= b(prev)
+		}
+		return a(e) < b(e) && a(prev) >= b(prev)
+	}, window: window}, nil
+}
+
+// reduce 依次合并序列的值（有 NaN 时结果为 NaN）
+func reduce(values []float64, merge func(a, b float64) float64) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		result = merge(result, v)
+	}
+	return result
+}
+
+// mean 平均值
+func mean(values []float64) float64 {
+	return reduce(values, func(a, b float64) float64 { return a + b }) / float64(len(values))
+}
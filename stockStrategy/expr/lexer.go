@@ -0,0 +1,126 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota // 结束
+	tokNumber                  // 数字
+	tokIdent                   // 标识符（字段、函数、and/or/not/true/false）
+	tokOp                      // 运算符
+	tokLParen                  // (
+	tokRParen                  // )
+	tokComma                   // ,
+)
+
+// token 词法单元
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int // 在表达式中的位置（从0开始，按字符计算）
+}
+
+// Error 表达式的解析或编译错误
+type Error struct {
+	Source string // 表达式
+	Pos    int    // 出错位置（从0开始，按字符计算）
+	Msg    string // 错误说明
+}
+
+// Error 错误信息，包含出错的列号和指向出错位置的标记
+func (e *Error) Error() string {
+	runes := []rune(e.Source)
+	pos := min(max(e.Pos, 0), len(runes))
+	return fmt.Sprintf("表达式第%d列: %s\n  %s\n  %s^", pos+1, e.Msg, e.Source, strings.Repeat(" ", displayWidth(runes[:pos])))
+}
+
+// displayWidth 字符的显示宽度（中文等宽字符占两列），用于对齐出错标记
+func displayWidth(runes []rune) int {
+	width := 0
+	for _, r := range runes {
+		if r > unicode.MaxASCII && !unicode.IsSpace(r) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// twoCharOps 两个字符的运算符
+var twoCharOps = []string{"<=", ">=", "==", "!=", "&&", "||"}
+
+// lex 把表达式拆分为词法单元
+func lex(src string) ([]token, error) {
+	runes := []rune(src)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &Error{Source: src, Pos: start, Msg: fmt.Sprintf("无效的数字 %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, num: num, pos: start})
+
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: strings.ToLower(string(runes[start:i])), pos: start})
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", pos: i})
+			i++
+
+		default:
+			op := ""
+			if i+1 < len(runes) {
+				for _, candidate := range twoCharOps {
+					if string(runes[i:i+2]) == candidate {
+						op = candidate
+					}
+				}
+			}
+			if op == "" && strings.ContainsRune("+-*/%<>!", r) {
+				op = string(r)
+			}
+			switch {
+			case op != "":
+			case r == '=':
+				return nil, &Error{Source: src, Pos: i, Msg: "比较相等请使用 =="}
+			case r == '&' || r == '|':
+				return nil, &Error{Source: src, Pos: i, Msg: fmt.Sprintf("请使用 %c%c 或 %s", r, r, map[rune]string{'&': "and", '|': "or"}[r])}
+			default:
+				return nil, &Error{Source: src, Pos: i, Msg: fmt.Sprintf("无法识别的字符 %q", r)}
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len([]rune(op))
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
@@ -0,0 +1,237 @@
+package expr
+
+import "fmt"
+
+// node 语法树节点
+type node interface {
+	position() int
+}
+
+// numberNode 数字常量
+type numberNode struct {
+	pos   int
+	value float64
+}
+
+// boolNode 布尔常量 true/false
+type boolNode struct {
+	pos   int
+	value bool
+}
+
+// identNode 字段或持仓变量
+type identNode struct {
+	pos  int
+	name string
+}
+
+// callNode 函数调用
+type callNode struct {
+	pos  int
+	name string
+	args []node
+}
+
+// unaryNode 一元运算：- 和 not
+type unaryNode struct {
+	pos int
+	op  string
+	x   node
+}
+
+// binaryNode 二元运算（pos 为运算符的位置，出错位置按左边操作数的开始计算）
+type binaryNode struct {
+	pos         int
+	op          string
+	left, right node
+}
+
+func (n *numberNode) position() int { return n.pos }
+func (n *boolNode) position() int   { return n.pos }
+func (n *identNode) position() int  { return n.pos }
+func (n *callNode) position() int   { return n.pos }
+func (n *unaryNode) position() int  { return n.pos }
+func (n *binaryNode) position() int { return n.left.position() }
+
+// 运算符优先级（数字越大结合越紧）
+const (
+	precOr = iota + 1
+	precAnd
+	precNot
+	precCompare
+	precAdd
+	precMul
+)
+
+// binaryPrecedence 二元运算符的优先级和统一后的写法（&& 写作 and，|| 写作 or），不是二元运算符时优先级为0
+func binaryPrecedence(tok token) (string, int) {
+	switch {
+	case tok.kind == tokIdent && tok.text == "or", tok.kind == tokOp && tok.text == "||":
+		return "or", precOr
+	case tok.kind == tokIdent && tok.text == "and", tok.kind == tokOp && tok.text == "&&":
+		return "and", precAnd
+	case tok.kind != tokOp:
+		return "", 0
+	}
+	switch tok.text {
+	case "<", "<=", ">", ">=", "==", "!=":
+		return tok.text, precCompare
+	case "+", "-":
+		return tok.text, precAdd
+	case "*", "/", "%":
+		return tok.text, precMul
+	}
+	return "", 0
+}
+
+// parser 递归下降解析器
+type parser struct {
+	src    string
+	tokens []token
+	i      int
+}
+
+// parse 解析表达式，返回语法树
+func parse(src string) (node, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "表达式为空")
+	}
+	root, err := p.parseBinary(precOr)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "多余的 %q，缺少运算符？", tok.text)
+	}
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) *Error {
+	return &Error{Source: p.src, Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseBinary 解析优先级不低于 minPrec 的二元运算
+func (p *parser) parseBinary(minPrec int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		op, prec := binaryPrecedence(tok)
+		if prec == 0 || prec < minPrec {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		if prec == precCompare {
+			if _, nextPrec := binaryPrecedence(p.peek()); nextPrec == precCompare {
+				return nil, p.errorf(p.peek(), "比较运算不能连写，请用 and 连接")
+			}
+		}
+		left = &binaryNode{pos: tok.pos, op: op, left: left, right: right}
+	}
+}
+
+// parseUnary 解析一元运算：not 的优先级低于比较（not a > b 即 not (a > b)），负号只作用于紧跟的操作数
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokIdent && tok.text == "not", tok.kind == tokOp && tok.text == "!":
+		p.next()
+		x, err := p.parseBinary(precCompare)
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: tok.pos, op: "not", x: x}, nil
+	case tok.kind == tokOp && tok.text == "-":
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if num, ok := x.(*numberNode); ok {
+			return &numberNode{pos: tok.pos, value: -num.value}, nil
+		}
+		return &unaryNode{pos: tok.pos, op: "-", x: x}, nil
+	case tok.kind == tokOp && tok.text == "+":
+		p.next()
+		return p.parseUnary()
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary 解析数字、常量、字段、函数调用和括号
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return &numberNode{pos: tok.pos, value: tok.num}, nil
+
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &boolNode{pos: tok.pos, value: tok.text == "true"}, nil
+		case "and", "or":
+			return nil, p.errorf(tok, "%s 前面缺少操作数", tok.text)
+		}
+		if p.peek().kind != tokLParen {
+			return &identNode{pos: tok.pos, name: tok.text}, nil
+		}
+		p.next()
+		call := &callNode{pos: tok.pos, name: tok.text}
+		if p.peek().kind == tokRParen {
+			p.next()
+			return call, nil
+		}
+		for {
+			arg, err := p.parseBinary(precOr)
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			switch sep := p.next(); sep.kind {
+			case tokComma:
+				continue
+			case tokRParen:
+				return call, nil
+			default:
+				return nil, p.errorf(sep, "函数 %s 的参数后面应为 , 或 )", call.name)
+			}
+		}
+
+	case tokLParen:
+		inner, err := p.parseBinary(precOr)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorf(closing, "缺少 )，与第%d列的 ( 对应", tok.pos+1)
+		}
+		return inner, nil
+
+	case tokEOF:
+		return nil, p.errorf(tok, "表达式不完整，缺少操作数")
+	}
+	return nil, p.errorf(tok, "此处不能是 %q", tok.text)
+}
@@ -0,0 +1,176 @@
+package expr
+
+import (
+	"fmt"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/combine"
+)
+
+// compileCondition 编译条件表达式，数值表达式返回错误
+func compileCondition(src string, allowPosition bool, what string) (*Expr, error) {
+	e, err := compileExpr(src, allowPosition)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsCondition() {
+		return nil, &Error{Source: src, Pos: 0, Msg: what + "应为条件（比较或 and/or/not），而不是数值"}
+	}
+	return e, nil
+}
+
+// Signal 表达式信号生成器：空仓时买入表达式成立则买入，持仓时卖出表达式成立则卖出
+// 没有内部状态（除最近一次卖出原因），可以被多只票票共用
+type Signal struct {
+	Entry *Expr // 买入表达式（nil表示不买入，只作为卖出规则使用）
+	Exit  *Expr // 卖出表达式（nil表示不按表达式卖出，需要与其他卖出规则组合）
+
+	exitReason string // 最近一次卖出信号的原因
+}
+
+// NewSignal 编译买入和卖出表达式，创建信号生成器；exit 为空表示不按表达式卖出
+// 卖出表达式可以使用持仓变量（profit、hold_days 等）
+func NewSignal(entry, exit string) (*Signal, error) {
+	entryExpr, err := compileCondition(entry, false, "买入表达式")
+	if err != nil {
+		return nil, err
+	}
+	sg := &Signal{Entry: entryExpr}
+	if exit != "" {
+		if sg.Exit, err = compileCondition(exit, true, "卖出表达式"); err != nil {
+			return nil, err
+		}
+	}
+	return sg, nil
+}
+
+// NewExitSignal 只按卖出表达式卖出的信号生成器，用于替换其他信号生成器的卖出规则
+func NewExitSignal(exit string) (*Signal, error) {
+	exitExpr, err := compileCondition(exit, true, "卖出表达式")
+	if err != nil {
+		return nil, err
+	}
+	return &Signal{Exit: exitExpr}, nil
+}
+
+// Reset 重置状态
+func (sg *Signal) Reset() {
+	sg.exitReason = ""
+}
+
+// GetHistoryWindow 买入和卖出表达式中最长的历史窗口
+func (sg *Signal) GetHistoryWindow() int {
+	window := 1
+	if sg.Entry != nil {
+		window = sg.Entry.Window
+	}
+	if sg.Exit != nil {
+		window = max(window, sg.Exit.Window)
+	}
+	return window
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *Signal) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	sg.exitReason = ""
+	if position == nil {
+		if sg.Entry != nil && sg.Entry.Test(ctx, nil) {
+			return 1
+		}
+		return 0
+	}
+	if sg.Exit != nil && sg.Exit.Test(ctx, position) {
+		sg.exitReason = "表达式卖出(" + sg.Exit.Source + ")"
+		return -1
+	}
+	return 0
+}
+
+// GetExitReason 获取最近一次卖出信号的原因
+func (sg *Signal) GetExitReason() string {
+	return sg.exitReason
+}
+
+// GetName 获取信号生成器名称
+func (sg *Signal) GetName() string {
+	if sg.Entry == nil {
+		return fmt.Sprintf("表达式(卖出:%s)", sg.Exit.Source)
+	}
+	if sg.Exit == nil {
+		return fmt.Sprintf("表达式(买入:%s)", sg.Entry.Source)
+	}
+	return fmt.Sprintf("表达式(买入:%s,卖出:%s)", sg.Entry.Source, sg.Exit.Source)
+}
+
+// Selector 表达式选股器：选择截至指定时间点表达式成立的票票
+// 票票数据由回测引擎通过 SetStockData 注入，未注入时不选择任何票票
+type Selector struct {
+	Expr *Expr
+
+	data map[string]*stockData.StockInfo
+}
+
+// NewSelector 编译选股表达式，创建选股器
+func NewSelector(src string) (*Selector, error) {
+	e, err := compileCondition(src, false, "选股表达式")
+	if err != nil {
+		return nil, err
+	}
+	return &Selector{Expr: e}, nil
+}
+
+// SetStockData 注入选股使用的票票数据
+func (s *Selector) SetStockData(data map[string]*stockData.StockInfo) {
+	s.data = data
+}
+
+// SelectStocks 按最新数据选股
+// 已废弃，请使用 SelectStocksAtDate
+func (s *Selector) SelectStocks(allCodes []string) []string {
+	selected := make([]string, 0, len(allCodes))
+	for _, code := range allCodes {
+		if stock := s.data[code]; stock != nil && s.matches(code, stock, len(stock.Datas.DayDatas)-1) {
+			selected = append(selected, code)
+		}
+	}
+	return selected
+}
+
+// SelectStocksAtDate 选择截至 endIndex 表达式成立的票票（只使用 [0, endIndex] 的数据）
+func (s *Selector) SelectStocksAtDate(allCodes []string, endIndex int) []string {
+	selected := make([]string, 0, len(allCodes))
+	for _, code := range allCodes {
+		stock := s.data[code]
+		if stock == nil || endIndex >= len(stock.Datas.DayDatas) {
+			continue
+		}
+		if s.matches(code, stock, endIndex) {
+			selected = append(selected, code)
+		}
+	}
+	return selected
+}
+
+// matches 票票在 index 处是否满足表达式
+func (s *Selector) matches(code string, stock *stockData.StockInfo, index int) bool {
+	if index < 0 {
+		return false
+	}
+	return s.Expr.Test(stockStrategy.NewBarContext(code, stock.Datas.DayDatas, index, s.Expr.Window), nil)
+}
+
+// GetName 获取选股器名称
+func (s *Selector) GetName() string {
+	return fmt.Sprintf("表达式选股(%s)", s.Expr.Source)
+}
+
+// Condition 编译条件表达式，转换为 combine 包的条件
+func Condition(src string) (combine.Condition, error) {
+	e, err := compileCondition(src, false, "条件表达式")
+	if err != nil {
+		return combine.Condition{}, err
+	}
+	return combine.NewCondition(src, e.Window, func(ctx *stockStrategy.BarContext) bool {
+		return e.Test(ctx, nil)
+	}), nil
+}
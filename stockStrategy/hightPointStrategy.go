@@ -135,7 +135,12 @@ func HighPointStrategyLast(stockCode string) (isHighPoint bool, dataStr string)
 		logger.Warnf("无法获取stock %s 数据", stockCode)
 		return false, ""
 	}
+	return HighPointLast(stock)
+}
 
+// HighPointLast 同 HighPointStrategyLast，使用传入的stock数据
+func HighPointLast(stock *stockData.StockInfo) (isHighPoint bool, dataStr string) {
+	stockCode := stock.Code
 	stockSessionLen := len(stock.Datas.DayDatas)
 
 	if stockSessionLen < globalDefine.STOCK_SESSION_LEN {
@@ -0,0 +1,159 @@
+package indicators
+
+import "math"
+
+// SMA 简单移动平均
+type SMA struct {
+	Period int
+
+	values *window
+	sum    float64
+}
+
+// NewSMA 创建 period 日简单移动平均
+func NewSMA(period int) *SMA {
+	return &SMA{Period: period, values: newWindow(period)}
+}
+
+// Update 加入一个新值，返回最近 Period 个值的平均值
+func (s *SMA) Update(value float64) float64 {
+	evicted, full := s.values.push(value)
+	s.sum += value
+	if full {
+		s.sum -= evicted
+	}
+	return s.Value()
+}
+
+// Value 最近 Period 个值的平均值
+func (s *SMA) Value() float64 {
+	if s.values.count == 0 {
+		return math.NaN()
+	}
+	return s.sum / float64(s.values.count)
+}
+
+// Ready 是否已有 Period 个值
+func (s *SMA) Ready() bool {
+	return s.values.full()
+}
+
+// Reset 清空状态
+func (s *SMA) Reset() {
+	s.values.reset()
+	s.sum = 0
+}
+
+// EMA 指数移动平均，平滑系数 2/(Period+1)，用前 Period 个值的简单平均作为初始值
+type EMA struct {
+	Period int
+
+	alpha float64
+	count int
+	value float64
+}
+
+// NewEMA 创建 period 日指数移动平均
+func NewEMA(period int) *EMA {
+	return &EMA{Period: period, alpha: 2 / float64(period+1)}
+}
+
+// Update 加入一个新值，返回最新的指数移动平均
+func (e *EMA) Update(value float64) float64 {
+	e.count++
+	if e.count <= e.Period {
+		// 初始阶段累计简单平均
+		e.value += (value - e.value) / float64(e.count)
+	} else {
+		e.value += e.alpha * (value - e.value)
+	}
+	return e.Value()
+}
+
+// Value 最新的指数移动平均
+func (e *EMA) Value() float64 {
+	if e.count == 0 {
+		return math.NaN()
+	}
+	return e.value
+}
+
+// Ready 是否已有 Period 个值
+func (e *EMA) Ready() bool {
+	return e.count >= e.Period
+}
+
+// Reset 清空状态
+func (e *EMA) Reset() {
+	e.count = 0
+	e.value = 0
+}
+
+// MACDValue MACD指标值
+type MACDValue struct {
+	DIF  float64 // 快线EMA - 慢线EMA
+	DEA  float64 // DIF 的 Signal 日EMA
+	Hist float64 // MACD柱，按国内习惯为 2*(DIF-DEA)
+}
+
+// MACD 指数平滑异同移动平均线
+type MACD struct {
+	Fast   int // 快线周期，默认12
+	Slow   int // 慢线周期，默认26
+	Signal int // DEA周期，默认9
+
+	fast  *EMA
+	slow  *EMA
+	dea   *EMA
+	value MACDValue
+}
+
+// NewMACD 创建MACD指标
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{
+		Fast:   fast,
+		Slow:   slow,
+		Signal: signal,
+		fast:   NewEMA(fast),
+		slow:   NewEMA(slow),
+		dea:    NewEMA(signal),
+	}
+}
+
+// NewDefaultMACD 创建MACD(12,26,9)
+func NewDefaultMACD() *MACD {
+	return NewMACD(12, 26, 9)
+}
+
+// Update 加入一个收盘价，返回最新的MACD值
+// 慢线就绪后才开始计算DEA
+func (m *MACD) Update(close float64) MACDValue {
+	m.fast.Update(close)
+	m.slow.Update(close)
+	if !m.slow.Ready() {
+		return m.value
+	}
+
+	m.value.DIF = m.fast.Value() - m.slow.Value()
+	m.value.DEA = m.dea.Update(m.value.DIF)
+	m.value.Hist = 2 * (m.value.DIF - m.value.DEA)
+	return m.value
+}
+
+// Value 最新的MACD值
+func (m *MACD) Value() MACDValue {
+	return m.value
+}
+
+// Ready DIF和DEA是否都已就绪（需要 Slow+Signal-1 个值）
+func (m *MACD) Ready() bool {
+	return m.dea.Ready()
+}
+
+// Reset 清空状态
+func (m *MACD) Reset() {
+	m.fast.Reset()
+	m.slow.Reset()
+	m.dea.Reset()
+	m.value = MACDValue{}
+}
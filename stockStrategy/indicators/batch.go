@@ -0,0 +1,223 @@
+package indicators
+
+import (
+	"math"
+	"stock-go/stockData"
+)
+
+// Closes 收盘价序列
+func Closes(days []*stockData.StockDataDay) []float64 {
+	return series(days, func(d *stockData.StockDataDay) float32 { return d.PriceEnd })
+}
+
+// Highs 最高价序列
+func Highs(days []*stockData.StockDataDay) []float64 {
+	return series(days, func(d *stockData.StockDataDay) float32 { return d.PriceHigh })
+}
+
+// Lows 最低价序列
+func Lows(days []*stockData.StockDataDay) []float64 {
+	return series(days, func(d *stockData.StockDataDay) float32 { return d.PriceLow })
+}
+
+func series(days []*stockData.StockDataDay, price func(*stockData.StockDataDay) float32) []float64 {
+	values := make([]float64, len(days))
+	for i, day := range days {
+		values[i] = float64(price(day))
+	}
+	return values
+}
+
+// nanSeries 长度为 n、全部为 NaN 的序列
+func nanSeries(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+	return values
+}
+
+// CalculateSMA 简单移动平均，前 period-1 个位置为 NaN
+// 以下滑动窗口函数的 period 不足1时按1计算，与流式版本一致
+func CalculateSMA(values []float64, period int) []float64 {
+	period = max(period, 1)
+	result := nanSeries(len(values))
+	for i := period - 1; i < len(values); i++ {
+		sum := 0.0
+		for _, v := range values[i-period+1 : i+1] {
+			sum += v
+		}
+		result[i] = sum / float64(period)
+	}
+	return result
+}
+
+// CalculateEMA 指数移动平均（前 period 个值的简单平均作为初始值），前 period-1 个位置为 NaN
+func CalculateEMA(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values) < period {
+		return result
+	}
+	alpha := 2 / float64(period+1)
+	ema := CalculateSMA(values[:period], period)[period-1]
+	result[period-1] = ema
+	for i := period; i < len(values); i++ {
+		ema = alpha*values[i] + (1-alpha)*ema
+		result[i] = ema
+	}
+	return result
+}
+
+// CalculateMACD MACD指标，DEA就绪之前的位置各字段为 NaN
+func CalculateMACD(values []float64, fast, slow, signal int) []MACDValue {
+	result := make([]MACDValue, len(values))
+	nan := math.NaN()
+	for i := range result {
+		result[i] = MACDValue{DIF: nan, DEA: nan, Hist: nan}
+	}
+
+	fastEMA := CalculateEMA(values, fast)
+	slowEMA := CalculateEMA(values, slow)
+	if fast <= 0 || slow <= 0 || signal <= 0 || len(values) < slow {
+		return result
+	}
+	dif := make([]float64, 0, len(values)-slow+1)
+	for i := slow - 1; i < len(values); i++ {
+		dif = append(dif, fastEMA[i]-slowEMA[i])
+	}
+	dea := CalculateEMA(dif, signal)
+	for j := signal - 1; j < len(dif); j++ {
+		i := j + slow - 1
+		result[i] = MACDValue{DIF: dif[j], DEA: dea[j], Hist: 2 * (dif[j] - dea[j])}
+	}
+	return result
+}
+
+// CalculateRSI RSI（Wilder 平滑），前 period 个位置为 NaN
+func CalculateRSI(values []float64, period int) []float64 {
+	result := nanSeries(len(values))
+	if period <= 0 || len(values) <= period {
+		return result
+	}
+
+	avgGain, avgLoss := 0.0, 0.0
+	for i := 1; i <= period; i++ {
+		change := values[i] - values[i-1]
+		avgGain += max(change, 0)
+		avgLoss += max(-change, 0)
+	}
+	avgGain /= float64(period)
+	avgLoss /= float64(period)
+	result[period] = rsiValue(avgGain, avgLoss)
+
+	n := float64(period)
+	for i := period + 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		avgGain = (avgGain*(n-1) + max(change, 0)) / n
+		avgLoss = (avgLoss*(n-1) + max(-change, 0)) / n
+		result[i] = rsiValue(avgGain, avgLoss)
+	}
+	return result
+}
+
+// CalculateKDJ KDJ指标，不足 n 根K线时按已有K线计算RSV（与流式版本一致）
+func CalculateKDJ(highs, lows, closes []float64, n, m1, m2 int) []KDJValue {
+	n = max(n, 1)
+	result := make([]KDJValue, len(closes))
+	prev := KDJValue{K: 50, D: 50, J: 50}
+	for i := range closes {
+		start := max(i-n+1, 0)
+		result[i] = nextKDJ(prev, rsv(closes[i], highest(highs[start:i+1]), lowest(lows[start:i+1])), m1, m2)
+		prev = result[i]
+	}
+	return result
+}
+
+// CalculateBollinger 布林带，前 period-1 个位置各字段为 NaN
+func CalculateBollinger(values []float64, period int, k float64) []BandValue {
+	result := make([]BandValue, len(values))
+	middle := CalculateSMA(values, period)
+	std := CalculateStdDev(values, period)
+	for i := range values {
+		result[i] = BandValue{Upper: middle[i] + k*std[i], Middle: middle[i], Lower: middle[i] - k*std[i]}
+	}
+	return result
+}
+
+// CalculateDonchian 唐奇安通道，前 period-1 个位置各字段为 NaN
+func CalculateDonchian(highs, lows []float64, period int) []BandValue {
+	upper := CalculateHighest(highs, period)
+	lower := CalculateLowest(lows, period)
+	result := make([]BandValue, len(highs))
+	for i := range result {
+		result[i] = BandValue{Upper: upper[i], Middle: (upper[i] + lower[i]) / 2, Lower: lower[i]}
+	}
+	return result
+}
+
+// CalculateATR ATR（真实波幅的简单平均），前 period 个位置为 NaN
+func CalculateATR(highs, lows, closes []float64, period int) []float64 {
+	period = max(period, 1)
+	result := nanSeries(len(closes))
+	for i := period; i < len(closes); i++ {
+		sum := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			sum += trueRange(highs[j], lows[j], closes[j-1])
+		}
+		result[i] = sum / float64(period)
+	}
+	return result
+}
+
+// CalculateStdDev 滑动总体标准差，前 period-1 个位置为 NaN
+func CalculateStdDev(values []float64, period int) []float64 {
+	period = max(period, 1)
+	result := nanSeries(len(values))
+	mean := CalculateSMA(values, period)
+	for i := period - 1; i < len(values); i++ {
+		sumSquares := 0.0
+		for _, v := range values[i-period+1 : i+1] {
+			sumSquares += (v - mean[i]) * (v - mean[i])
+		}
+		result[i] = math.Sqrt(sumSquares / float64(period))
+	}
+	return result
+}
+
+// CalculateHighest 滑动最大值，前 period-1 个位置为 NaN
+func CalculateHighest(values []float64, period int) []float64 {
+	period = max(period, 1)
+	result := nanSeries(len(values))
+	for i := period - 1; i < len(values); i++ {
+		result[i] = highest(values[i-period+1 : i+1])
+	}
+	return result
+}
+
+// CalculateLowest 滑动最小值，前 period-1 个位置为 NaN
+func CalculateLowest(values []float64, period int) []float64 {
+	period = max(period, 1)
+	result := nanSeries(len(values))
+	for i := period - 1; i < len(values); i++ {
+		result[i] = lowest(values[i-period+1 : i+1])
+	}
+	return result
+}
+
+// highest 最大值
+func highest(values []float64) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		result = max(result, v)
+	}
+	return result
+}
+
+// lowest 最小值
+func lowest(values []float64) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		result = min(result, v)
+	}
+	return result
+}
@@ -0,0 +1,131 @@
+package indicators
+
+import "math"
+
+// BandValue 通道指标值
+type BandValue struct {
+	Upper  float64
+	Middle float64
+	Lower  float64
+}
+
+// Bollinger 布林带：中轨为 Period 日均线，上下轨为中轨加减 K 倍总体标准差
+type Bollinger struct {
+	Period int     // 周期，默认20
+	K      float64 // 标准差倍数，默认2
+
+	std *RollingStd
+}
+
+// NewBollinger 创建布林带
+func NewBollinger(period int, k float64) *Bollinger {
+	return &Bollinger{Period: period, K: k, std: NewRollingStd(period)}
+}
+
+// Update 加入一个收盘价，返回最新的布林带
+func (b *Bollinger) Update(close float64) BandValue {
+	b.std.Update(close)
+	return b.Value()
+}
+
+// Value 最新的布林带
+func (b *Bollinger) Value() BandValue {
+	middle := b.std.Mean()
+	width := b.K * b.std.Value()
+	return BandValue{Upper: middle + width, Middle: middle, Lower: middle - width}
+}
+
+// Ready 是否已有 Period 个值
+func (b *Bollinger) Ready() bool {
+	return b.std.Ready()
+}
+
+// Reset 清空状态
+func (b *Bollinger) Reset() {
+	b.std.Reset()
+}
+
+// Donchian 唐奇安通道：上轨为 Period 日最高价，下轨为 Period 日最低价（包含当天）
+// 判断突破时应在 Update 当天K线之前比较
+type Donchian struct {
+	Period int
+
+	highest *RollingMax
+	lowest  *RollingMin
+}
+
+// NewDonchian 创建唐奇安通道
+func NewDonchian(period int) *Donchian {
+	return &Donchian{Period: period, highest: NewRollingMax(period), lowest: NewRollingMin(period)}
+}
+
+// Update 加入一根K线，返回最新的通道
+func (d *Donchian) Update(high, low float64) BandValue {
+	d.highest.Update(high)
+	d.lowest.Update(low)
+	return d.Value()
+}
+
+// Value 最新的通道
+func (d *Donchian) Value() BandValue {
+	upper, lower := d.highest.Value(), d.lowest.Value()
+	return BandValue{Upper: upper, Middle: (upper + lower) / 2, Lower: lower}
+}
+
+// Ready 是否已有 Period 根K线
+func (d *Donchian) Ready() bool {
+	return d.highest.Ready()
+}
+
+// Reset 清空状态
+func (d *Donchian) Reset() {
+	d.highest.Reset()
+	d.lowest.Reset()
+}
+
+// ATR 平均真实波幅：最近 Period 个真实波幅的简单平均（与 sizers.CalculateATR 一致）
+// 真实波幅 = max(最高-最低, |最高-昨收|, |最低-昨收|)，第一根K线没有昨收，不计入
+type ATR struct {
+	Period int
+
+	ranges    *SMA
+	prevClose float64
+	started   bool
+}
+
+// NewATR 创建 period 日ATR
+func NewATR(period int) *ATR {
+	return &ATR{Period: period, ranges: NewSMA(period)}
+}
+
+// Update 加入一根K线，返回最新的ATR
+func (a *ATR) Update(high, low, close float64) float64 {
+	if a.started {
+		a.ranges.Update(trueRange(high, low, a.prevClose))
+	}
+	a.started = true
+	a.prevClose = close
+	return a.Value()
+}
+
+// Value 最新的ATR
+func (a *ATR) Value() float64 {
+	return a.ranges.Value()
+}
+
+// Ready 是否已有 Period 个真实波幅（Period+1 根K线）
+func (a *ATR) Ready() bool {
+	return a.ranges.Ready()
+}
+
+// Reset 清空状态
+func (a *ATR) Reset() {
+	a.ranges.Reset()
+	a.prevClose = 0
+	a.started = false
+}
+
+// trueRange 真实波幅
+func trueRange(high, low, prevClose float64) float64 {
+	return math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
+}
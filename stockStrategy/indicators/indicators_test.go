@@ -0,0 +1,246 @@
+package indicators
+
+import (
+	"math"
+	"math/rand/v2"
+	"stock-go/stockData"
+	"stock-go/stockStrategy/sizers"
+	"testing"
+)
+
+// randomBars 固定种子的随机游走K线
+func randomBars(n int, seed uint64) (highs, lows, closes []float64) {
+	rng := rand.New(rand.NewPCG(seed, 0))
+	price := 10.0
+	for i := 0; i < n; i++ {
+		price *= 1 + 0.02*rng.NormFloat64()
+		spread := price * 0.02 * rng.Float64()
+		closes = append(closes, price)
+		highs = append(highs, price+spread*rng.Float64())
+		lows = append(lows, price-spread*rng.Float64())
+	}
+	// 加入一段横盘，覆盖最高价等于最低价、没有涨跌的情况
+	for i := 0; i < 30; i++ {
+		closes = append(closes, price)
+		highs = append(highs, price)
+		lows = append(lows, price)
+	}
+	return
+}
+
+// 增量标准差在横盘时残留约1e-7的舍入误差，与标准差相关的指标放宽误差
+const (
+	tolerance    = 1e-9
+	stdTolerance = 1e-6
+)
+
+// closeEnough 相对误差不超过 tol（NaN只与NaN相等）
+func closeEnough(a, b, tol float64) bool {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.IsNaN(a) && math.IsNaN(b)
+	}
+	return math.Abs(a-b) <= tol*math.Max(1, math.Abs(b))
+}
+
+// checkSeries 逐个位置比较流式和批量结果：流式未就绪的位置批量结果应为NaN
+func checkSeries(t *testing.T, name string, i int, ready bool, streaming, batch, tol float64) {
+	t.Helper()
+	if !ready {
+		if !math.IsNaN(batch) {
+			t.Fatalf("%s 第%d个值：流式未就绪，批量结果 %v", name, i, batch)
+		}
+		return
+	}
+	if !closeEnough(streaming, batch, tol) {
+		t.Fatalf("%s 第%d个值：流式 %v，批量 %v", name, i, streaming, batch)
+	}
+}
+
+// TestSingleSeriesIndicators 交叉验证单序列指标的流式和批量实现
+func TestSingleSeriesIndicators(t *testing.T) {
+	_, _, closes := randomBars(500, 1)
+	cases := []struct {
+		name      string
+		streaming Indicator
+		batch     []float64
+		tol       float64
+	}{
+		{"SMA(20)", NewSMA(20), CalculateSMA(closes, 20), tolerance},
+		{"SMA(1)", NewSMA(1), CalculateSMA(closes, 1), tolerance},
+		{"EMA(12)", NewEMA(12), CalculateEMA(closes, 12), tolerance},
+		{"RSI(14)", NewRSI(14), CalculateRSI(closes, 14), tolerance},
+		{"StdDev(20)", NewRollingStd(20), CalculateStdDev(closes, 20), stdTolerance},
+		{"Highest(30)", NewRollingMax(30), CalculateHighest(closes, 30), tolerance},
+		{"Lowest(30)", NewRollingMin(30), CalculateLowest(closes, 30), tolerance},
+	}
+	for _, c := range cases {
+		// 运行两遍，验证 Reset 后结果相同
+		for round := 0; round < 2; round++ {
+			c.streaming.Reset()
+			for i, price := range closes {
+				value := c.streaming.Update(price)
+				if value != c.streaming.Value() && !math.IsNaN(value) {
+					t.Fatalf("%s Update 返回值与 Value 不一致", c.name)
+				}
+				checkSeries(t, c.name, i, c.streaming.Ready(), value, c.batch[i], c.tol)
+			}
+		}
+	}
+}
+
+// TestBarIndicators 交叉验证MACD、KDJ、布林带、唐奇安通道和ATR
+func TestBarIndicators(t *testing.T) {
+	highs, lows, closes := randomBars(500, 2)
+
+	macd := NewDefaultMACD()
+	macdBatch := CalculateMACD(closes, 12, 26, 9)
+	kdj := NewDefaultKDJ()
+	kdjBatch := CalculateKDJ(highs, lows, closes, 9, 3, 3)
+	boll := NewBollinger(20, 2)
+	bollBatch := CalculateBollinger(closes, 20, 2)
+	donchian := NewDonchian(20)
+	donchianBatch := CalculateDonchian(highs, lows, 20)
+	atr := NewATR(14)
+	atrBatch := CalculateATR(highs, lows, closes, 14)
+
+	for i := range closes {
+		m := macd.Update(closes[i])
+		checkSeries(t, "MACD.DIF", i, macd.Ready(), m.DIF, macdBatch[i].DIF, tolerance)
+		checkSeries(t, "MACD.DEA", i, macd.Ready(), m.DEA, macdBatch[i].DEA, tolerance)
+		checkSeries(t, "MACD.Hist", i, macd.Ready(), m.Hist, macdBatch[i].Hist, tolerance)
+
+		// KDJ 在就绪前也有定义（按已有K线计算），全部位置都应一致
+		k := kdj.Update(highs[i], lows[i], closes[i])
+		checkSeries(t, "KDJ.K", i, true, k.K, kdjBatch[i].K, tolerance)
+		checkSeries(t, "KDJ.D", i, true, k.D, kdjBatch[i].D, tolerance)
+		checkSeries(t, "KDJ.J", i, true, k.J, kdjBatch[i].J, tolerance)
+
+		b := boll.Update(closes[i])
+		checkSeries(t, "BOLL.Upper", i, boll.Ready(), b.Upper, bollBatch[i].Upper, stdTolerance)
+		checkSeries(t, "BOLL.Middle", i, boll.Ready(), b.Middle, bollBatch[i].Middle, stdTolerance)
+		checkSeries(t, "BOLL.Lower", i, boll.Ready(), b.Lower, bollBatch[i].Lower, stdTolerance)
+
+		d := donchian.Update(highs[i], lows[i])
+		checkSeries(t, "Donchian.Upper", i, donchian.Ready(), d.Upper, donchianBatch[i].Upper, tolerance)
+		checkSeries(t, "Donchian.Lower", i, donchian.Ready(), d.Lower, donchianBatch[i].Lower, tolerance)
+
+		a := atr.Update(highs[i], lows[i], closes[i])
+		checkSeries(t, "ATR", i, atr.Ready(), a, atrBatch[i], tolerance)
+	}
+
+	last := len(closes) - 1
+	if k := kdjBatch[last]; k.K <= 40 || k.K >= 60 {
+		t.Errorf("横盘30天后K值 %.2f 应接近50", k.K)
+	}
+	if b := bollBatch[last]; b.Upper-b.Lower > 1e-9 {
+		t.Errorf("横盘时布林带宽度应为0: %+v", b)
+	}
+}
+
+// TestKnownValues 测试与手工计算和已有实现一致
+func TestKnownValues(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 4, 3}
+	sma := NewSMA(3)
+	for _, v := range values {
+		sma.Update(v)
+	}
+	if sma.Value() != 4 {
+		t.Errorf("SMA(3) = %v，期望 4", sma.Value())
+	}
+
+	max3 := NewRollingMax(3)
+	min3 := NewRollingMin(3)
+	for _, v := range values {
+		max3.Update(v)
+		min3.Update(v)
+	}
+	if max3.Value() != 5 || min3.Value() != 3 {
+		t.Errorf("最近3个值最大 %v 最小 %v，期望 5 和 3", max3.Value(), min3.Value())
+	}
+
+	// RSI 第一个值为简单平均：涨 1+1+1+1，跌 1+1
+	rsi := NewRSI(6)
+	for _, v := range values {
+		rsi.Update(v)
+	}
+	if want := 100 - 100/(1+4.0/2.0); !closeEnough(rsi.Value(), want, tolerance) {
+		t.Errorf("RSI(6) = %v，期望 %v", rsi.Value(), want)
+	}
+
+	// ATR 与仓位管理器的 CalculateATR 一致
+	highs, lows, closes := randomBars(60, 3)
+	history := make([]*stockData.StockDataDay, len(closes))
+	atr := NewATR(20)
+	for i := range closes {
+		history[i] = &stockData.StockDataDay{
+			PriceHigh: float32(highs[i]),
+			PriceLow:  float32(lows[i]),
+			PriceEnd:  float32(closes[i]),
+		}
+	}
+	for _, day := range history {
+		atr.Update(float64(day.PriceHigh), float64(day.PriceLow), float64(day.PriceEnd))
+	}
+	if want := sizers.CalculateATR(history, 20); !closeEnough(atr.Value(), want, tolerance) {
+		t.Errorf("ATR = %v，sizers.CalculateATR = %v", atr.Value(), want)
+	}
+}
+
+// TestRollingStdPrecision 测试长序列、大数值时增量标准差没有累积误差
+func TestRollingStdPrecision(t *testing.T) {
+	rng := rand.New(rand.NewPCG(4, 0))
+	values := make([]float64, 100000)
+	for i := range values {
+		values[i] = 1e6 + rng.Float64()
+	}
+	std := NewRollingStd(20)
+	for _, v := range values {
+		std.Update(v)
+	}
+	want := CalculateStdDev(values[len(values)-20:], 20)[19]
+	if math.Abs(std.Value()-want) > 1e-6 {
+		t.Errorf("标准差 %v，期望 %v", std.Value(), want)
+	}
+}
+
+// TestNonPositivePeriod 测试周期不足1时按1计算，不会越界
+func TestNonPositivePeriod(t *testing.T) {
+	highs, lows, closes := randomBars(50, 3)
+	for _, period := range []int{0, -3} {
+		maxs, mins := NewRollingMax(period), NewRollingMin(period)
+		kdj, want := NewKDJ(period, 3, 3), NewKDJ(1, 3, 3)
+		for i, c := range closes {
+			if v := maxs.Update(c); v != c {
+				t.Fatalf("周期%d 第%d个滑动最大值 %v，期望 %v", period, i, v, c)
+			}
+			if v := mins.Update(c); v != c {
+				t.Fatalf("周期%d 第%d个滑动最小值 %v，期望 %v", period, i, v, c)
+			}
+			if got, exp := kdj.Update(highs[i], lows[i], c), want.Update(highs[i], lows[i], c); got != exp {
+				t.Fatalf("周期%d 第%d个KDJ %+v，期望 %+v", period, i, got, exp)
+			}
+		}
+
+		batches := map[string][]float64{
+			"SMA":     CalculateSMA(closes, period),
+			"StdDev":  CalculateStdDev(closes, period),
+			"Highest": CalculateHighest(closes, period),
+			"Lowest":  CalculateLowest(closes, period),
+			"ATR":     CalculateATR(highs, lows, closes, period),
+		}
+		for name, values := range batches {
+			if len(values) != len(closes) {
+				t.Errorf("周期%d %s 长度 %d", period, name, len(values))
+			}
+		}
+		if CalculateHighest(closes, period)[10] != closes[10] || CalculateSMA(closes, period)[10] != closes[10] {
+			t.Errorf("周期%d 应按周期1计算", period)
+		}
+		if len(CalculateKDJ(highs, lows, closes, period, 3, 3)) != len(closes) || len(CalculateEMA(closes, period)) != len(closes) {
+			t.Errorf("周期%d KDJ/EMA 长度不正确", period)
+		}
+		if macd := CalculateMACD(closes, 12, 26, period); !math.IsNaN(macd[len(macd)-1].DIF) {
+			t.Errorf("周期%d 的MACD应全部为NaN", period)
+		}
+	}
+}
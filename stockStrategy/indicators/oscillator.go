@@ -0,0 +1,146 @@
+package indicators
+
+import "math"
+
+// RSI 相对强弱指标（Wilder 平滑）
+// 前 Period 个涨跌幅取简单平均（与旧策略的 calculateRSI 一致），之后按 (前值*(Period-1)+当天)/Period 平滑
+type RSI struct {
+	Period int
+
+	count     int // 已加入的价格数量
+	prevClose float64
+	avgGain   float64
+	avgLoss   float64
+}
+
+// NewRSI 创建 period 日RSI
+func NewRSI(period int) *RSI {
+	return &RSI{Period: period}
+}
+
+// Update 加入一个收盘价，返回最新的RSI
+func (r *RSI) Update(close float64) float64 {
+	r.count++
+	if r.count > 1 {
+		change := close - r.prevClose
+		gain, loss := max(change, 0), max(-change, 0)
+		if changes := r.count - 1; changes <= r.Period {
+			r.avgGain += (gain - r.avgGain) / float64(changes)
+			r.avgLoss += (loss - r.avgLoss) / float64(changes)
+		} else {
+			n := float64(r.Period)
+			r.avgGain = (r.avgGain*(n-1) + gain) / n
+			r.avgLoss = (r.avgLoss*(n-1) + loss) / n
+		}
+	}
+	r.prevClose = close
+	return r.Value()
+}
+
+// Value 最新的RSI（0~100，没有下跌时为100）
+func (r *RSI) Value() float64 {
+	if r.count < 2 {
+		return math.NaN()
+	}
+	return rsiValue(r.avgGain, r.avgLoss)
+}
+
+// Ready 是否已有 Period 个涨跌幅（Period+1 个价格）
+func (r *RSI) Ready() bool {
+	return r.count > r.Period
+}
+
+// Reset 清空状态
+func (r *RSI) Reset() {
+	r.count = 0
+	r.prevClose = 0
+	r.avgGain = 0
+	r.avgLoss = 0
+}
+
+// rsiValue 根据平均涨幅和平均跌幅计算RSI
+func rsiValue(avgGain, avgLoss float64) float64 {
+	if avgLoss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+avgGain/avgLoss)
+}
+
+// KDJValue KDJ指标值
+type KDJValue struct {
+	K float64
+	D float64
+	J float64 // 3K-2D
+}
+
+// KDJ 随机指标
+// RSV = (收盘价-N日最低价)/(N日最高价-N日最低价)*100，K = RSV的M1日平滑，D = K的M2日平滑
+// K、D初始值为50，N日最高价等于最低价时RSV取50
+type KDJ struct {
+	N  int // RSV周期，默认9
+	M1 int // K平滑周期，默认3
+	M2 int // D平滑周期，默认3
+
+	highest *RollingMax
+	lowest  *RollingMin
+	value   KDJValue
+}
+
+// NewKDJ 创建KDJ指标
+func NewKDJ(n, m1, m2 int) *KDJ {
+	k := &KDJ{
+		N:       n,
+		M1:      m1,
+		M2:      m2,
+		highest: NewRollingMax(n),
+		lowest:  NewRollingMin(n),
+	}
+	k.Reset()
+	return k
+}
+
+// NewDefaultKDJ 创建KDJ(9,3,3)
+func NewDefaultKDJ() *KDJ {
+	return NewKDJ(9, 3, 3)
+}
+
+// Update 加入一根K线，返回最新的KDJ值
+// 不足N根K线时按已有K线计算RSV
+func (k *KDJ) Update(high, low, close float64) KDJValue {
+	hh := k.highest.Update(high)
+	ll := k.lowest.Update(low)
+	k.value = nextKDJ(k.value, rsv(close, hh, ll), k.M1, k.M2)
+	return k.value
+}
+
+// Value 最新的KDJ值
+func (k *KDJ) Value() KDJValue {
+	return k.value
+}
+
+// Ready 是否已有N根K线
+func (k *KDJ) Ready() bool {
+	return k.highest.Ready()
+}
+
+// Reset 清空状态
+func (k *KDJ) Reset() {
+	k.highest.Reset()
+	k.lowest.Reset()
+	k.value = KDJValue{K: 50, D: 50, J: 50}
+}
+
+// rsv 未成熟随机值
+func rsv(close, highest, lowest float64) float64 {
+	if highest == lowest {
+		return 50
+	}
+	return (close - lowest) / (highest - lowest) * 100
+}
+
+// nextKDJ 根据前一天的KDJ和当天RSV计算当天的KDJ
+func nextKDJ(prev KDJValue, rsv float64, m1, m2 int) KDJValue {
+	k := (prev.K*float64(m1-1) + rsv) / float64(m1)
+	d := (prev.D*float64(m2-1) + k) / float64(m2)
+	return KDJValue{K: k, D: d, J: 3*k - 2*d}
+}
@@ -0,0 +1,211 @@
+// Package indicators 技术指标
+// 每个指标提供两种实现：
+//   - 流式：逐根K线调用 Update，每次 O(1)（滑动最值为均摊 O(1)），只保存计算窗口需要的数据，适合信号生成器
+//   - 批量：Calculate* 函数按定义对整段序列计算，指标尚未就绪的位置为 NaN，适合分析和校验
+//
+// 流式指标在 Ready 返回 true 之前的值没有意义
+package indicators
+
+import "math"
+
+// Indicator 单序列流式指标（输入一个价格，输出一个值）
+type Indicator interface {
+	// Update 加入一个新值，返回最新的指标值
+	Update(value float64) float64
+	// Value 最新的指标值
+	Value() float64
+	// Ready 数据是否足够计算指标
+	Ready() bool
+	// Reset 清空状态
+	Reset()
+}
+
+// window 固定容量的循环队列，保存最近 size 个值
+type window struct {
+	values []float64
+	start  int
+	count  int
+}
+
+// newWindow 创建容量为 size 的队列
+func newWindow(size int) *window {
+	return &window{values: make([]float64, max(size, 1))}
+}
+
+// push 加入一个值，队列已满时返回被挤出的最早的值
+func (w *window) push(value float64) (evicted float64, full bool) {
+	size := len(w.values)
+	if w.count < size {
+		w.values[(w.start+w.count)%size] = value
+		w.count++
+		return 0, false
+	}
+	evicted = w.values[w.start]
+	w.values[w.start] = value
+	w.start = (w.start + 1) % size
+	return evicted, true
+}
+
+// full 队列是否已满
+func (w *window) full() bool {
+	return w.count == len(w.values)
+}
+
+// reset 清空队列
+func (w *window) reset() {
+	w.start = 0
+	w.count = 0
+}
+
+// dequeEntry 单调队列的元素
+type dequeEntry struct {
+	index int
+	value float64
+}
+
+// RollingMax 滑动窗口最大值（单调递减队列，均摊 O(1)）
+type RollingMax struct {
+	Period int
+
+	queue []dequeEntry // 下标递增、值递减
+	count int
+}
+
+// NewRollingMax 创建 period 个值的滑动最大值
+func NewRollingMax(period int) *RollingMax {
+	return &RollingMax{Period: period}
+}
+
+// Update 加入一个新值，返回最近 Period 个值（含当前值）的最大值
+func (r *RollingMax) Update(value float64) float64 {
+	r.queue = pushMonotonic(r.queue, r.count, r.Period, value, func(back float64) bool { return back <= value })
+	r.count++
+	return r.Value()
+}
+
+// Value 最近 Period 个值的最大值
+func (r *RollingMax) Value() float64 {
+	if len(r.queue) == 0 {
+		return math.NaN()
+	}
+	return r.queue[0].value
+}
+
+// Ready 是否已有 Period 个值
+func (r *RollingMax) Ready() bool {
+	return r.count >= r.Period
+}
+
+// Reset 清空状态
+func (r *RollingMax) Reset() {
+	r.queue = r.queue[:0]
+	r.count = 0
+}
+
+// RollingMin 滑动窗口最小值（单调递增队列，均摊 O(1)）
+type RollingMin struct {
+	Period int
+
+	queue []dequeEntry // 下标递增、值递增
+	count int
+}
+
+// NewRollingMin 创建 period 个值的滑动最小值
+func NewRollingMin(period int) *RollingMin {
+	return &RollingMin{Period: period}
+}
+
+// Update 加入一个新值，返回最近 Period 个值（含当前值）的最小值
+func (r *RollingMin) Update(value float64) float64 {
+	r.queue = pushMonotonic(r.queue, r.count, r.Period, value, func(back float64) bool { return back >= value })
+	r.count++
+	return r.Value()
+}
+
+// Value 最近 Period 个值的最小值
+func (r *RollingMin) Value() float64 {
+	if len(r.queue) == 0 {
+		return math.NaN()
+	}
+	return r.queue[0].value
+}
+
+// Ready 是否已有 Period 个值
+func (r *RollingMin) Ready() bool {
+	return r.count >= r.Period
+}
+
+// Reset 清空状态
+func (r *RollingMin) Reset() {
+	r.queue = r.queue[:0]
+	r.count = 0
+}
+
+// pushMonotonic 把第 index 个值加入单调队列
+// 先移除队尾所有被新值支配的元素，再移除超出窗口范围的队首元素（period 不足1时按1计算）
+func pushMonotonic(queue []dequeEntry, index, period int, value float64, dominated func(back float64) bool) []dequeEntry {
+	period = max(period, 1)
+	for len(queue) > 0 && dominated(queue[len(queue)-1].value) {
+		queue = queue[:len(queue)-1]
+	}
+	queue = append(queue, dequeEntry{index: index, value: value})
+	for queue[0].index <= index-period {
+		queue = queue[1:]
+	}
+	return queue
+}
+
+// RollingStd 滑动窗口总体标准差（Welford 算法增量更新均值和平方和，避免大数相减的精度损失）
+type RollingStd struct {
+	Period int
+
+	values *window
+	mean   float64
+	m2     float64 // 与均值之差的平方和
+}
+
+// NewRollingStd 创建 period 个值的滑动标准差
+func NewRollingStd(period int) *RollingStd {
+	return &RollingStd{Period: period, values: newWindow(period)}
+}
+
+// Update 加入一个新值，返回最近 Period 个值的总体标准差
+func (r *RollingStd) Update(value float64) float64 {
+	evicted, full := r.values.push(value)
+	if !full {
+		n := float64(r.values.count)
+		delta := value - r.mean
+		r.mean += delta / n
+		r.m2 += delta * (value - r.mean)
+	} else {
+		oldMean := r.mean
+		r.mean += (value - evicted) / float64(r.Period)
+		r.m2 += (value - evicted) * (value - r.mean + evicted - oldMean)
+	}
+	return r.Value()
+}
+
+// Mean 最近 Period 个值的平均值
+func (r *RollingStd) Mean() float64 {
+	return r.mean
+}
+
+// Value 最近 Period 个值的总体标准差
+func (r *RollingStd) Value() float64 {
+	if r.values.count == 0 {
+		return math.NaN()
+	}
+	return math.Sqrt(max(r.m2, 0) / float64(r.values.count))
+}
+
+// Ready 是否已有 Period 个值
+func (r *RollingStd) Ready() bool {
+	return r.values.full()
+}
+
+// Reset 清空状态
+func (r *RollingStd) Reset() {
+	r.values.reset()
+	r.mean = 0
+	r.m2 = 0
+}
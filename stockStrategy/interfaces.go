@@ -33,12 +33,11 @@ type SignalGenerator interface {
 
 	// ProcessDay 处理单日数据,返回交易信号
 	// 参数:
-	//   - dayData: 当天的K线数据
-	//   - dateIndex: 数据索引(从0开始)
+	//   - ctx: 截至当天的K线窗口(只读,不含未来数据),历史价格从这里获取,不需要自己保存
 	//   - position: 当前持仓状态(nil表示空仓)
 	// 返回:
 	//   - signal: 1=买入, -1=卖出, 0=无操作
-	ProcessDay(dayData *stockData.StockDataDay, dateIndex int, position *Position) int
+	ProcessDay(ctx *BarContext, position *Position) int
 
 	// GetName 获取信号生成器名称
 	GetName() string
@@ -57,6 +56,136 @@ type Strategy interface {
 	GetName() string
 }
 
+// ===== 仓位管理接口 =====
+// PositionSizer 负责计算每次买入的股数
+type PositionSizer interface {
+	// Size 计算计划买入的股数
+	// 参数: ctx - 买入时的资金、价格、历史数据等上下文
+	// 返回: 计划买入股数（已按交易单位取整），0表示不买入
+	Size(ctx *SizingContext) int
+
+	// GetName 获取仓位管理器名称
+	GetName() string
+}
+
+// SizingContext 仓位计算上下文
+type SizingContext struct {
+	Code          string                    // 票票代码
+	Date          string                    // 买入日期
+	Price         float64                   // 预计成交价格
+	Cash          float64                   // 可用现金
+	TotalAssets   float64                   // 总资产（现金+持仓市值）
+	MaxPositions  int                       // 最大持仓数量
+	OpenPositions int                       // 当前持仓数量
+	StopPercent   float64                   // 止损比例（0表示未知）
+	History       []*stockData.StockDataDay // 截至前一交易日的历史数据（不含当天）
+	Stats         TradeStats                // 已完成交易的统计（用于凯利公式）
+}
+
+// TradeStats 已完成交易的统计
+type TradeStats struct {
+	ClosedTrades int     // 已完成交易次数
+	WinCount     int     // 盈利次数
+	AvgWinPct    float64 // 平均盈利比例（如0.08表示8%）
+	AvgLossPct   float64 // 平均亏损比例（正数，如0.05表示5%）
+}
+
+// WinRate 胜率（0-1）
+func (s TradeStats) WinRate() float64 {
+	if s.ClosedTrades == 0 {
+		return 0
+	}
+	return float64(s.WinCount) / float64(s.ClosedTrades)
+}
+
+// SizedStrategy 可选接口：策略自带仓位管理器
+// 回测引擎优先使用策略提供的仓位管理器，返回nil时使用引擎默认值
+type SizedStrategy interface {
+	GetSizer() PositionSizer
+}
+
+// StopLossProvider 可选接口：信号生成器提供止损比例
+// 供按风险计算仓位的仓位管理器使用
+type StopLossProvider interface {
+	GetStopLossPercent() float64
+}
+
+// SignalGeneratorFactory 可选接口：策略为每只票票创建独立的信号生成器
+// 回测引擎优先使用该接口，使不同票票的信号状态互不影响
+// 参数 code 为票票代码，需要知道票票所属行业等信息的信号生成器可以使用
+type SignalGeneratorFactory interface {
+	NewSignalGenerator(code string) SignalGenerator
+}
+
+// SizeAdjuster 可选接口：策略按日期调整仓位管理器计算的买入股数（如按市场状态降低仓位）
+// 返回调整后的股数（已按交易单位取整），0表示不买入
+type SizeAdjuster interface {
+	AdjustSize(ctx *SizingContext, stockNum int) int
+}
+
+// RegimeProvider 可选接口：策略提供每个交易日的市场状态，回测报告按市场状态分组统计
+type RegimeProvider interface {
+	RegimeAt(date string) string
+}
+
+// HistoryWindowProvider 可选接口：信号生成器需要的历史窗口大小（K线根数，含当天）
+// 未实现时回测引擎使用 DefaultHistoryWindow
+type HistoryWindowProvider interface {
+	GetHistoryWindow() int
+}
+
+// ExitReasonProvider 可选接口：信号生成器提供最近一次卖出信号的原因
+// 回测引擎用于记录交易的卖出原因
+type ExitReasonProvider interface {
+	GetExitReason() string
+}
+
+// DataAware 可选接口：选股器、策略或信号生成器需要全市场票票数据（选股、行业指数、市场宽度等）
+// 回测引擎和每日分析在加载数据后通过 InjectStockData 注入，实现者只读取注入的数据，不读取全局数据
+type DataAware interface {
+	SetStockData(data map[string]*stockData.StockInfo)
+}
+
+// InjectStockData 把票票数据注入策略及其选股器、信号生成器中实现了 DataAware 的部分
+func InjectStockData(strategy Strategy, data map[string]*stockData.StockInfo) {
+	for _, target := range []any{strategy, strategy.GetSelector(), strategy.GetSignalGenerator()} {
+		if aware, ok := target.(DataAware); ok {
+			aware.SetStockData(data)
+		}
+	}
+}
+
+// DataChecker 可选接口：注入数据后检查需要的数据是否齐全（如市场状态指数）
+// 回测引擎在注入数据后通过 CheckStockData 检查，缺少数据时不运行回测
+type DataChecker interface {
+	CheckStockData() error
+}
+
+// CheckStockData 检查策略及其选股器、信号生成器中实现了 DataChecker 的部分，返回第一个错误
+func CheckStockData(strategy Strategy) error {
+	for _, target := range []any{strategy, strategy.GetSelector(), strategy.GetSignalGenerator()} {
+		if checker, ok := target.(DataChecker); ok {
+			if err := checker.CheckStockData(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PortfolioStrategy 可选接口：横截面策略，每个交易日同时查看全部候选票票，给出目标持仓
+// 适用于动量排名、相对强弱、"买入最强的前N只"等无法逐只票票判断的策略
+// 回测引擎用 Rebalance 的结果代替逐只票票的买入信号；GetSignalGenerator 返回非nil时仍用于判断持仓的卖出
+type PortfolioStrategy interface {
+	Strategy
+
+	// Rebalance 计算当天的目标持仓
+	// 参数: ctx - 当天全部候选票票的K线（只读）和当前持仓
+	// 返回: 按优先级排序的目标权重，资金不足时排在前面的先买入；
+	//       nil 表示当天不调仓，空切片表示清仓
+	Rebalance(ctx *PortfolioContext) []TargetWeight
+}
+
 // ===== 持仓状态 =====
 // Position 表示当前的持仓状态
 type Position struct {
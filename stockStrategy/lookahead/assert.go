@@ -0,0 +1,48 @@
+package lookahead
+
+import (
+	"sort"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"testing"
+)
+
+// AssertSignalGenerator 测试辅助：信号生成器使用了未来数据时测试失败
+func AssertSignalGenerator(t testing.TB, newGen func() stockStrategy.SignalGenerator, stock *stockData.StockInfo, opts Options) {
+	t.Helper()
+	if report := CheckSignalGenerator(newGen, stock, opts); !report.OK() {
+		t.Error(report.String())
+	}
+}
+
+// AssertSelector 测试辅助：选股器使用了未来数据时测试失败
+func AssertSelector(t testing.TB, selector stockStrategy.StockSelector, data map[string]*stockData.StockInfo, opts Options) {
+	t.Helper()
+	if report := CheckSelector(selector, data, opts); !report.OK() {
+		t.Error(report.String())
+	}
+}
+
+// AssertStrategy 测试辅助：检测策略的选股器和信号生成器
+// newStrategy 每次调用返回新的策略实例（信号生成器带有内部状态）
+// 策略实现 SignalGeneratorFactory 时按票票代码创建信号生成器
+func AssertStrategy(t testing.TB, newStrategy func() stockStrategy.Strategy, data map[string]*stockData.StockInfo, opts Options) {
+	t.Helper()
+	AssertSelector(t, newStrategy().GetSelector(), data, opts)
+
+	codes := make([]string, 0, len(data))
+	for code := range data {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		newGen := func() stockStrategy.SignalGenerator {
+			strategy := newStrategy()
+			if factory, ok := strategy.(stockStrategy.SignalGeneratorFactory); ok {
+				return factory.NewSignalGenerator(code)
+			}
+			return strategy.GetSignalGenerator()
+		}
+		AssertSignalGenerator(t, newGen, data[code], opts)
+	}
+}
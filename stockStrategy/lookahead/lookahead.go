@@ -0,0 +1,261 @@
+// Package lookahead 未来函数检测
+//
+// 检测方法：先用全部历史数据运行一次，记录每个时间点 t 的决策；
+// 再把数据截断到 t（只保留 [0, t]），重新运行到 t，比较 t 时刻的决策。
+// 只使用过去数据的策略两次决策必然相同，不同则说明在 t 时刻用到了 t 之后的数据。
+//
+// 截断运行时把截断后的数据注入实现了 stockStrategy.DataAware 的信号生成器/选股器，
+// 同时临时替换全局的 stockData.StocksRaw，因此直接读取全局数据的也能被检测到。检测不能与其他使用全局数据的代码并发运行。
+package lookahead
+
+import (
+	"fmt"
+	"sort"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"strings"
+)
+
+// Options 检测参数
+type Options struct {
+	StartIndex    int // 从该数据索引开始检测（之前的数据只用于积累历史）
+	Step          int // 每隔多少天检测一次，默认1（逐日检测，耗时为数据长度的平方）
+	MaxViolations int // 记录的最多不一致数量，达到后停止检测，默认10
+}
+
+// NewDefaultOptions 默认参数：逐日检测，最多记录10处不一致
+func NewDefaultOptions() Options {
+	return Options{
+		StartIndex:    0,
+		Step:          1,
+		MaxViolations: 10,
+	}
+}
+
+// Violation 一处不一致的决策
+type Violation struct {
+	Index     int    // 数据索引
+	Date      string // 日期
+	Full      string // 使用全部数据时的决策
+	Truncated string // 数据截断到当天时的决策
+}
+
+// Report 检测结果
+type Report struct {
+	Name       string      // 被检测的信号生成器/选股器名称
+	Checked    int         // 检测的时间点数量
+	Violations []Violation // 不一致的决策
+}
+
+// OK 是否未发现未来函数
+func (r *Report) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// String 检测结果描述
+func (r *Report) String() string {
+	if r.OK() {
+		return fmt.Sprintf("%s: 检测 %d 个时间点，未发现未来函数", r.Name, r.Checked)
+	}
+
+	lines := []string{fmt.Sprintf("%s: 检测 %d 个时间点，发现 %d 处决策依赖未来数据:", r.Name, r.Checked, len(r.Violations))}
+	for _, v := range r.Violations {
+		lines = append(lines, fmt.Sprintf("  [%d] %s 全部数据: %s, 截断数据: %s", v.Index, v.Date, v.Full, v.Truncated))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckSignalGenerator 检测信号生成器是否使用了未来数据
+// newGen 每次调用返回新的信号生成器实例；持仓按信号模拟（1开仓、-1平仓）
+func CheckSignalGenerator(newGen func() stockStrategy.SignalGenerator, stock *stockData.StockInfo, opts Options) *Report {
+	opts = normalize(opts)
+	report := &Report{Name: newGen().GetName()}
+	dayDatas := stock.Datas.DayDatas
+
+	restore := installData(map[string]*stockData.StockInfo{stock.Code: stock})
+	full := runSignals(withData(newGen(), map[string]*stockData.StockInfo{stock.Code: stock}), stock.Code, dayDatas)
+	restore()
+
+	for t := opts.StartIndex; t < len(dayDatas); t += opts.Step {
+		truncated := truncate(stock, t)
+		restore := installData(map[string]*stockData.StockInfo{stock.Code: truncated})
+		decisions := runSignals(withData(newGen(), map[string]*stockData.StockInfo{stock.Code: truncated}), stock.Code, truncated.Datas.DayDatas)
+		restore()
+
+		report.Checked++
+		if decisions[t] != full[t] {
+			report.Violations = append(report.Violations, Violation{
+				Index:     t,
+				Date:      dayDatas[t].DataStr,
+				Full:      signalName(full[t]),
+				Truncated: signalName(decisions[t]),
+			})
+			if len(report.Violations) >= opts.MaxViolations {
+				break
+			}
+		}
+	}
+
+	return report
+}
+
+// CheckSelector 检测选股器是否使用了未来数据
+// 在每个时间点 t 比较 SelectStocksAtDate(codes, t) 在全部数据和截断数据下的选股结果
+func CheckSelector(selector stockStrategy.StockSelector, data map[string]*stockData.StockInfo, opts Options) *Report {
+	opts = normalize(opts)
+	report := &Report{Name: selector.GetName()}
+
+	codes := make([]string, 0, len(data))
+	maxLen := 0
+	for code, info := range data {
+		codes = append(codes, code)
+		if len(info.Datas.DayDatas) > maxLen {
+			maxLen = len(info.Datas.DayDatas)
+		}
+	}
+	sort.Strings(codes)
+
+	restore := installData(data)
+	injectData(selector, data)
+	full := make(map[int]string)
+	for t := opts.StartIndex; t < maxLen; t += opts.Step {
+		full[t] = selectionKey(selector.SelectStocksAtDate(append([]string(nil), codes...), t))
+	}
+	restore()
+
+	for t := opts.StartIndex; t < maxLen; t += opts.Step {
+		truncated := make(map[string]*stockData.StockInfo, len(data))
+		for code, info := range data {
+			truncated[code] = truncate(info, t)
+		}
+
+		restore := installData(truncated)
+		injectData(selector, truncated)
+		selected := selectionKey(selector.SelectStocksAtDate(append([]string(nil), codes...), t))
+		restore()
+
+		report.Checked++
+		if selected != full[t] {
+			report.Violations = append(report.Violations, Violation{
+				Index:     t,
+				Date:      dateAt(data, codes, t),
+				Full:      full[t],
+				Truncated: selected,
+			})
+			if len(report.Violations) >= opts.MaxViolations {
+				break
+			}
+		}
+	}
+	injectData(selector, data)
+
+	return report
+}
+
+// runSignals 从头运行信号生成器，返回每天的信号
+func runSignals(gen stockStrategy.SignalGenerator, code string, dayDatas []*stockData.StockDataDay) []int {
+	gen.Reset()
+	signals := make([]int, len(dayDatas))
+	window := stockStrategy.HistoryWindow(gen)
+
+	var position *stockStrategy.Position
+	for i, dayData := range dayDatas {
+		signal := gen.ProcessDay(stockStrategy.NewBarContext(code, dayDatas, i, window), position)
+		signals[i] = signal
+
+		switch {
+		case signal == 1 && position == nil:
+			position = &stockStrategy.Position{
+				StockCode:    code,
+				BuyPrice:     dayData.PriceBegin,
+				BuyDate:      dayData.DataStr,
+				BuyIndex:     i,
+				HighestPrice: dayData.PriceBegin,
+			}
+		case signal == -1 && position != nil:
+			position = nil
+		case position != nil:
+			position.HoldDays++
+		}
+	}
+	return signals
+}
+
+// truncate 复制票票信息，只保留 [0, t] 的数据
+func truncate(stock *stockData.StockInfo, t int) *stockData.StockInfo {
+	copied := *stock
+	end := t + 1
+	if end > len(stock.Datas.DayDatas) {
+		end = len(stock.Datas.DayDatas)
+	}
+	copied.Datas.DayDatas = stock.Datas.DayDatas[:end:end]
+	return &copied
+}
+
+// injectData 向实现了 stockStrategy.DataAware 的信号生成器/选股器注入数据
+func injectData(target any, data map[string]*stockData.StockInfo) {
+	if aware, ok := target.(stockStrategy.DataAware); ok {
+		aware.SetStockData(data)
+	}
+}
+
+// withData 注入数据后返回信号生成器
+func withData(gen stockStrategy.SignalGenerator, data map[string]*stockData.StockInfo) stockStrategy.SignalGenerator {
+	injectData(gen, data)
+	return gen
+}
+
+// installData 临时替换全局原始数据，返回恢复函数
+func installData(data map[string]*stockData.StockInfo) func() {
+	saved := stockData.StocksRaw
+	installed := make(map[string]*stockData.StockInfo, len(data))
+	for code, info := range data {
+		installed[code] = info
+	}
+	stockData.StocksRaw = installed
+	return func() {
+		stockData.StocksRaw = saved
+	}
+}
+
+// selectionKey 选股结果的规范表示（排序后拼接）
+func selectionKey(codes []string) string {
+	sorted := append([]string(nil), codes...)
+	sort.Strings(sorted)
+	return "[" + strings.Join(sorted, ",") + "]"
+}
+
+// dateAt 取任意一只有第 t 天数据的票票的日期
+func dateAt(data map[string]*stockData.StockInfo, codes []string, t int) string {
+	for _, code := range codes {
+		if dayDatas := data[code].Datas.DayDatas; t < len(dayDatas) {
+			return dayDatas[t].DataStr
+		}
+	}
+	return ""
+}
+
+// signalName 信号的可读表示
+func signalName(signal int) string {
+	switch signal {
+	case 1:
+		return "买入"
+	case -1:
+		return "卖出"
+	}
+	return "无操作"
+}
+
+// normalize 补全未设置的参数
+func normalize(opts Options) Options {
+	if opts.Step <= 0 {
+		opts.Step = 1
+	}
+	if opts.StartIndex < 0 {
+		opts.StartIndex = 0
+	}
+	if opts.MaxViolations <= 0 {
+		opts.MaxViolations = 10
+	}
+	return opts
+}
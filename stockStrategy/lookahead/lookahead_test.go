@@ -0,0 +1,146 @@
+package lookahead
+
+import (
+	"fmt"
+	"math"
+	"stock-go/stockData"
+	"stock-go/stockData/synthetic"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/selectors"
+	"stock-go/stockStrategy/signals"
+	"testing"
+)
+
+// testStock 构造测试数据：价格按正弦波动并缓慢上涨
+func testStock(code string, days int, phase float64) *stockData.StockInfo {
+	return synthetic.Stock(code, days, func(i int) float64 {
+		return 10 + 0.01*float64(i) + 2*math.Sin(float64(i)/7+phase)
+	})
+}
+
+// peekSignal 使用未来数据的信号生成器：明天上涨就今天买入，明天下跌就今天卖出
+type peekSignal struct {
+	code string
+}
+
+func (s *peekSignal) Reset() {}
+
+func (s *peekSignal) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	dayDatas := stockData.StocksRaw[s.code].Datas.DayDatas
+	if ctx.Index()+1 >= len(dayDatas) {
+		return 0
+	}
+	tomorrow := float64(dayDatas[ctx.Index()+1].PriceEnd)
+	if position == nil && tomorrow > ctx.Close(0) {
+		return 1
+	}
+	if position != nil && tomorrow < ctx.Close(0) {
+		return -1
+	}
+	return 0
+}
+
+func (s *peekSignal) GetName() string { return "偷看明天" }
+
+// peekSelector 使用全部历史数据的选股器：选出整段数据最高点之后的票票
+type peekSelector struct{}
+
+func (s *peekSelector) SelectStocks(allCodes []string) []string { return allCodes }
+
+func (s *peekSelector) SelectStocksAtDate(allCodes []string, endIndex int) []string {
+	selected := make([]string, 0)
+	for _, code := range allCodes {
+		dayDatas := stockData.StocksRaw[code].Datas.DayDatas
+		maxIdx := 0
+		for i, d := range dayDatas {
+			if d.PriceEnd > dayDatas[maxIdx].PriceEnd {
+				maxIdx = i
+			}
+		}
+		if endIndex < maxIdx {
+			selected = append(selected, code)
+		}
+	}
+	return selected
+}
+
+func (s *peekSelector) GetName() string { return "全局最高点" }
+
+// TestCheckSignalGeneratorDetectsLeak 测试能检测出读取未来数据的信号生成器
+func TestCheckSignalGeneratorDetectsLeak(t *testing.T) {
+	stock := testStock("sz.000001", 120, 0)
+	opts := NewDefaultOptions()
+	opts.MaxViolations = 3
+	report := CheckSignalGenerator(func() stockStrategy.SignalGenerator {
+		return &peekSignal{code: stock.Code}
+	}, stock, opts)
+
+	if report.OK() {
+		t.Fatal("未检测出未来函数")
+	}
+	if len(report.Violations) != 3 {
+		t.Errorf("不一致数量 %d，期望达到上限 3", len(report.Violations))
+	}
+	if report.Violations[0].Date == "" || report.Violations[0].Full == report.Violations[0].Truncated {
+		t.Errorf("不一致记录 %+v 不正确", report.Violations[0])
+	}
+}
+
+// TestCheckSelectorDetectsLeak 测试能检测出使用全部历史数据的选股器
+func TestCheckSelectorDetectsLeak(t *testing.T) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": testStock("sz.000001", 100, 0),
+		"sz.000002": testStock("sz.000002", 100, 2),
+	}
+
+	if report := CheckSelector(&peekSelector{}, data, NewDefaultOptions()); report.OK() {
+		t.Fatal("未检测出未来函数")
+	}
+}
+
+// TestCheckRestoresGlobalData 测试检测结束后恢复全局数据
+func TestCheckRestoresGlobalData(t *testing.T) {
+	saved := stockData.StocksRaw
+	defer func() { stockData.StocksRaw = saved }()
+
+	marker := testStock("sz.999999", 10, 0)
+	stockData.StocksRaw = map[string]*stockData.StockInfo{marker.Code: marker}
+
+	stock := testStock("sz.000001", 50, 0)
+	CheckSignalGenerator(func() stockStrategy.SignalGenerator {
+		return &peekSignal{code: stock.Code}
+	}, stock, NewDefaultOptions())
+
+	if len(stockData.StocksRaw) != 1 || stockData.StocksRaw[marker.Code] != marker {
+		t.Errorf("全局数据未恢复")
+	}
+}
+
+// TestBuyHighSellLowSignalNoLookahead 测试追涨杀跌信号生成器没有未来函数
+func TestBuyHighSellLowSignalNoLookahead(t *testing.T) {
+	stock := testStock("sz.000001", 250, 0)
+	newGen := func() stockStrategy.SignalGenerator {
+		return signals.NewBuyHighSellLowSignal(60, 0.06, 20)
+	}
+
+	report := CheckSignalGenerator(newGen, stock, NewDefaultOptions())
+	if !report.OK() {
+		t.Fatal(report.String())
+	}
+	if report.Checked != 250 {
+		t.Errorf("检测时间点 %d，期望 250", report.Checked)
+	}
+}
+
+// TestSelectorsNoLookahead 测试所有选股器没有未来函数
+func TestSelectorsNoLookahead(t *testing.T) {
+	data := make(map[string]*stockData.StockInfo)
+	for i := 0; i < 3; i++ {
+		code := fmt.Sprintf("sz.00000%d", i+1)
+		data[code] = testStock(code, 120, float64(i))
+	}
+
+	opts := NewDefaultOptions()
+	AssertSelector(t, selectors.NewHighPointSelector(60, 10), data, opts)
+	AssertSelector(t, selectors.NewAllMarketSelector(), data, opts)
+}
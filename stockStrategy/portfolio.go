@@ -0,0 +1,70 @@
+package stockStrategy
+
+import "sort"
+
+// TargetWeight 目标持仓
+type TargetWeight struct {
+	Code   string  // 票票代码
+	Weight float64 // 占总资产的比例（0-1）
+}
+
+// Holding 当前持仓（按当天价格计算市值）
+type Holding struct {
+	StockNum int     // 持有股数
+	BuyPrice float64 // 买入均价
+	HoldDays int     // 持有天数
+	Value    float64 // 市值
+	Weight   float64 // 占总资产的比例
+}
+
+// PortfolioContext 横截面策略在某一天可见的数据
+type PortfolioContext struct {
+	Date        string                 // 当天日期
+	DayIndex    int                    // 交易日序号（从回测的第一个交易日开始）
+	Bars        map[string]*BarContext // 当天有K线的候选票票和持仓票票
+	Holdings    map[string]Holding     // 当前持仓
+	Cash        float64                // 可用现金
+	TotalAssets float64                // 总资产（按当天价格计算）
+}
+
+// Codes 当天有K线的票票代码（排序后）
+func (c *PortfolioContext) Codes() []string {
+	codes := make([]string, 0, len(c.Bars))
+	for code := range c.Bars {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// RankTop 按得分从高到低取前 n 只票票（得分相同时按代码排序），score 返回false的票票不参与排名
+func (c *PortfolioContext) RankTop(n int, score func(ctx *BarContext) (float64, bool)) []string {
+	type scored struct {
+		code  string
+		score float64
+	}
+	candidates := make([]scored, 0, len(c.Bars))
+	for _, code := range c.Codes() {
+		if s, ok := score(c.Bars[code]); ok {
+			candidates = append(candidates, scored{code, s})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	result := make([]string, 0, n)
+	for i := 0; i < len(candidates) && i < n; i++ {
+		result = append(result, candidates[i].code)
+	}
+	return result
+}
+
+// EqualWeights 把 total 的资产平均分配给 codes（保持顺序）
+func EqualWeights(codes []string, total float64) []TargetWeight {
+	targets := make([]TargetWeight, 0, len(codes))
+	for _, code := range codes {
+		targets = append(targets, TargetWeight{Code: code, Weight: total / float64(len(codes))})
+	}
+	return targets
+}
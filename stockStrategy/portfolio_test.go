@@ -0,0 +1,44 @@
+package stockStrategy
+
+import "testing"
+
+// TestPortfolioRankTop 测试按得分排名（得分相同按代码排序，不满足条件的不参与）和等权分配
+func TestPortfolioRankTop(t *testing.T) {
+	bars := testBars(30)
+	ctx := &PortfolioContext{Bars: map[string]*BarContext{
+		"sz.000003": NewBarContext("sz.000003", bars, 29, 10),
+		"sz.000001": NewBarContext("sz.000001", bars, 9, 10),
+		"sz.000002": NewBarContext("sz.000002", bars, 19, 10),
+		"sh.600001": NewBarContext("sh.600001", bars, 19, 10),
+		"sh.600002": NewBarContext("sh.600002", bars, 4, 10),
+	}}
+
+	if codes := ctx.Codes(); codes[0] != "sh.600001" || codes[4] != "sz.000003" {
+		t.Errorf("代码应排序: %v", codes)
+	}
+
+	// 得分为当天价格，历史不足10根的不参与排名
+	score := func(c *BarContext) (float64, bool) { return c.Price(0), c.Len() >= 10 }
+	top := ctx.RankTop(3, score)
+	want := []string{"sz.000003", "sh.600001", "sz.000002"}
+	if len(top) != len(want) {
+		t.Fatalf("排名 %v, 期望 %v", top, want)
+	}
+	for i := range want {
+		if top[i] != want[i] {
+			t.Errorf("排名 %v, 期望 %v", top, want)
+			break
+		}
+	}
+	if all := ctx.RankTop(10, score); len(all) != 4 {
+		t.Errorf("历史不足的票票不应参与排名: %v", all)
+	}
+
+	weights := EqualWeights(top, 0.9)
+	if len(weights) != 3 || weights[0].Code != "sz.000003" || weights[2].Weight != 0.3 {
+		t.Errorf("等权分配 %+v", weights)
+	}
+	if weights := EqualWeights(nil, 1); weights == nil || len(weights) != 0 {
+		t.Error("没有票票时应返回空切片（清仓）而不是nil（不调仓）")
+	}
+}
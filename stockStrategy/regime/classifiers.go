@@ -0,0 +1,191 @@
+package regime
+
+import (
+	"fmt"
+	"math"
+	"stock-go/stockData"
+)
+
+// TrendClassifier 趋势分类器
+// 指数收盘价高于N日均线一定幅度且均线向上为牛市，低于均线一定幅度且均线向下为熊市，其余为震荡
+type TrendClassifier struct {
+	MAPeriod  int     // 均线周期，默认60
+	SlopeDays int     // 比较均线方向的间隔天数，默认20
+	Band      float64 // 价格偏离均线的幅度，默认0.02（2%）
+}
+
+// NewTrendClassifier 创建趋势分类器
+func NewTrendClassifier(maPeriod, slopeDays int, band float64) *TrendClassifier {
+	return &TrendClassifier{
+		MAPeriod:  maPeriod,
+		SlopeDays: slopeDays,
+		Band:      band,
+	}
+}
+
+// Classify 按指数趋势逐日判断市场状态
+func (c *TrendClassifier) Classify(index []*stockData.StockDataDay, universe map[string]*stockData.StockInfo) map[string]Regime {
+	regimes := make(map[string]Regime, len(index))
+	ma := make([]float64, len(index)) // 每天的均线值，数据不足时为0
+	sum := 0.0
+	for i, day := range index {
+		price := float64(day.PriceEnd)
+		sum += price
+		if i >= c.MAPeriod {
+			sum -= float64(index[i-c.MAPeriod].PriceEnd)
+		}
+		if i+1 >= c.MAPeriod {
+			ma[i] = sum / float64(c.MAPeriod)
+		}
+
+		regime := Range
+		if prev := i - c.SlopeDays; prev >= 0 && ma[prev] > 0 {
+			switch {
+			case price > ma[i]*(1+c.Band) && ma[i] > ma[prev]:
+				regime = Bull
+			case price < ma[i]*(1-c.Band) && ma[i] < ma[prev]:
+				regime = Bear
+			}
+		}
+		regimes[day.DataStr] = regime
+	}
+	return regimes
+}
+
+// GetName 获取分类器名称
+func (c *TrendClassifier) GetName() string {
+	return fmt.Sprintf("趋势(%d日均线,%d日方向,±%.0f%%)", c.MAPeriod, c.SlopeDays, c.Band*100)
+}
+
+// BreadthClassifier 市场宽度分类器
+// 统计每天收盘价高于自身N日均线的票票占比，高于 BullRatio 为牛市，低于 BearRatio 为熊市
+type BreadthClassifier struct {
+	MAPeriod  int     // 均线周期，默认20
+	BullRatio float64 // 牛市的最低占比，默认0.6
+	BearRatio float64 // 熊市的最高占比，默认0.4
+	MinStocks int     // 参与统计的最少票票数量，不足时为震荡，默认10
+}
+
+// NewBreadthClassifier 创建市场宽度分类器
+func NewBreadthClassifier(maPeriod int, bullRatio, bearRatio float64) *BreadthClassifier {
+	return &BreadthClassifier{
+		MAPeriod:  maPeriod,
+		BullRatio: bullRatio,
+		BearRatio: bearRatio,
+		MinStocks: 10,
+	}
+}
+
+// Classify 按市场宽度逐日判断市场状态（只使用 universe，不需要指数）
+func (c *BreadthClassifier) Classify(index []*stockData.StockDataDay, universe map[string]*stockData.StockInfo) map[string]Regime {
+	above := make(map[string]int)
+	total := make(map[string]int)
+	for _, stock := range universe {
+		dayDatas := stock.Datas.DayDatas
+		sum := 0.0
+		for i, day := range dayDatas {
+			sum += float64(day.PriceEnd)
+			if i >= c.MAPeriod {
+				sum -= float64(dayDatas[i-c.MAPeriod].PriceEnd)
+			}
+			if i+1 < c.MAPeriod {
+				continue
+			}
+			total[day.DataStr]++
+			if float64(day.PriceEnd) > sum/float64(c.MAPeriod) {
+				above[day.DataStr]++
+			}
+		}
+	}
+
+	regimes := make(map[string]Regime, len(total))
+	for date, n := range total {
+		regime := Range
+		if n >= c.MinStocks {
+			ratio := float64(above[date]) / float64(n)
+			switch {
+			case ratio >= c.BullRatio:
+				regime = Bull
+			case ratio <= c.BearRatio:
+				regime = Bear
+			}
+		}
+		regimes[date] = regime
+	}
+	return regimes
+}
+
+// GetName 获取分类器名称
+func (c *BreadthClassifier) GetName() string {
+	return fmt.Sprintf("市场宽度(%d日均线,牛市%.0f%%,熊市%.0f%%)", c.MAPeriod, c.BullRatio*100, c.BearRatio*100)
+}
+
+// VolatilityClassifier 波动率分类器（包装其他分类器）
+// 指数年化波动率超过阈值时把市场状态降一级：牛市降为震荡，震荡降为熊市
+type VolatilityClassifier struct {
+	Base          Classifier // 被包装的分类器
+	Period        int        // 计算波动率的天数，默认20
+	MaxVolatility float64    // 年化波动率阈值，默认0.35（35%）
+}
+
+// NewVolatilityClassifier 创建波动率分类器
+func NewVolatilityClassifier(base Classifier, period int, maxVolatility float64) *VolatilityClassifier {
+	return &VolatilityClassifier{
+		Base:          base,
+		Period:        period,
+		MaxVolatility: maxVolatility,
+	}
+}
+
+// Classify 先用被包装的分类器判断，再按指数波动率降级
+func (c *VolatilityClassifier) Classify(index []*stockData.StockDataDay, universe map[string]*stockData.StockInfo) map[string]Regime {
+	regimes := c.Base.Classify(index, universe)
+
+	returns := make([]float64, 0, len(index))
+	for i := 1; i < len(index); i++ {
+		prev := float64(index[i-1].PriceEnd)
+		if prev <= 0 {
+			returns = append(returns, 0)
+		} else {
+			returns = append(returns, float64(index[i].PriceEnd)/prev-1)
+		}
+		if len(returns) < c.Period {
+			continue
+		}
+
+		if volatility(returns[len(returns)-c.Period:]) <= c.MaxVolatility {
+			continue
+		}
+		date := index[i].DataStr
+		switch regimes[date] {
+		case Bull:
+			regimes[date] = Range
+		case Range:
+			regimes[date] = Bear
+		}
+	}
+	return regimes
+}
+
+// GetName 获取分类器名称
+func (c *VolatilityClassifier) GetName() string {
+	return fmt.Sprintf("%s+波动率(%d日,>%.0f%%降级)", c.Base.GetName(), c.Period, c.MaxVolatility*100)
+}
+
+// volatility 日收益率的年化波动率（样本标准差，每年252个交易日）
+func volatility(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(variance/float64(len(returns)-1)) * math.Sqrt(252)
+}
@@ -0,0 +1,123 @@
+package regime
+
+import (
+	"fmt"
+	"sort"
+	"stock-go/logger"
+	"stock-go/stockData"
+	"sync"
+)
+
+// Filter 市场状态过滤器
+// 第一次查询时计算所有交易日的市场状态，之后只读，可被多只票票的信号生成器共用
+type Filter struct {
+	Classifier Classifier      // 市场状态分类器
+	IndexCode  string          // 指数代码（未注入指数数据时从注入的全市场数据中查找）
+	Rules      map[Regime]Rule // 各市场状态下的交易规则
+
+	index    *stockData.StockInfo            // 外部注入的指数数据（nil表示从全市场数据中查找）
+	universe map[string]*stockData.StockInfo // 外部注入的全市场数据
+
+	once    sync.Once
+	regimes map[string]Regime // 日期 -> 市场状态
+	dates   []string          // 有市场状态的日期（排序后）
+}
+
+// NewFilter 创建市场状态过滤器（使用默认规则）
+func NewFilter(classifier Classifier, indexCode string) *Filter {
+	return &Filter{
+		Classifier: classifier,
+		IndexCode:  indexCode,
+		Rules:      NewDefaultRules(),
+	}
+}
+
+// NewDefaultFilter 默认过滤器：沪深300的60日均线趋势，波动率过高时降级
+func NewDefaultFilter() *Filter {
+	return NewFilter(NewVolatilityClassifier(NewTrendClassifier(60, 20, 0.02), 20, 0.35), DefaultIndexCode)
+}
+
+// WithRules 设置各市场状态下的交易规则
+func (f *Filter) WithRules(rules map[Regime]Rule) *Filter {
+	f.Rules = rules
+	return f
+}
+
+// SetIndexData 注入指数数据（全市场数据中没有指数时必须注入）
+func (f *Filter) SetIndexData(info *stockData.StockInfo) *Filter {
+	f.index = info
+	return f
+}
+
+// SetUniverse 直接注入全市场数据（市场宽度分类器使用，未注入时市场宽度没有数据）
+func (f *Filter) SetUniverse(data map[string]*stockData.StockInfo) *Filter {
+	f.universe = data
+	return f
+}
+
+// indexData 指数数据：优先使用注入的指数数据，其次从注入的全市场数据中按代码查找
+func (f *Filter) indexData() *stockData.StockInfo {
+	if f.index != nil {
+		return f.index
+	}
+	return f.universe[f.IndexCode]
+}
+
+// CheckStockData 检查指数数据：设置了指数代码却没有数据时返回错误（否则每天都会被判断为震荡）
+func (f *Filter) CheckStockData() error {
+	if f.index == nil && f.IndexCode == "" {
+		return nil
+	}
+	if info := f.indexData(); info == nil || len(info.Datas.DayDatas) == 0 {
+		return fmt.Errorf("缺少市场状态指数 %s 的数据，请通过 SetIndexData 注入", f.IndexCode)
+	}
+	return nil
+}
+
+// prepare 计算所有交易日的市场状态，只使用注入的数据
+func (f *Filter) prepare() {
+	var index []*stockData.StockDataDay
+	if info := f.indexData(); info != nil {
+		index = info.Datas.DayDatas
+	} else if f.IndexCode != "" {
+		logger.Warnf("缺少指数 %s 的数据，市场状态按全市场数据判断", f.IndexCode)
+	}
+
+	f.regimes = f.Classifier.Classify(index, f.universe)
+	f.dates = make([]string, 0, len(f.regimes))
+	for date := range f.regimes {
+		f.dates = append(f.dates, date)
+	}
+	sort.Strings(f.dates)
+}
+
+// RegimeAt 获取指定日期的市场状态
+// 当天没有数据时沿用之前最近一个交易日的状态，之前也没有数据时为震荡
+func (f *Filter) RegimeAt(date string) Regime {
+	f.once.Do(f.prepare)
+
+	if regime, ok := f.regimes[date]; ok {
+		return regime
+	}
+	idx := sort.SearchStrings(f.dates, date)
+	if idx == 0 {
+		return Range
+	}
+	return f.regimes[f.dates[idx-1]]
+}
+
+// RuleAt 获取指定日期的交易规则，未配置规则的市场状态不做限制
+func (f *Filter) RuleAt(date string) Rule {
+	if rule, ok := f.Rules[f.RegimeAt(date)]; ok {
+		return rule
+	}
+	return Rule{AllowBuys: true, SizeScale: 1, StopScale: 1}
+}
+
+// GetName 获取过滤器名称
+func (f *Filter) GetName() string {
+	if f.IndexCode == "" {
+		return f.Classifier.GetName()
+	}
+	return fmt.Sprintf("%s %s", f.IndexCode, f.Classifier.GetName())
+}
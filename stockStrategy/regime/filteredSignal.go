@@ -0,0 +1,105 @@
+package regime
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// FilteredSignal 按市场状态过滤的信号生成器
+// 不允许开仓的市场状态下忽略买入信号；需要收紧止损时，按缩放后的止损比例提前卖出
+type FilteredSignal struct {
+	Inner  stockStrategy.SignalGenerator // 被包装的信号生成器
+	Filter *Filter                       // 市场状态过滤器
+
+	exitReason string // 收紧止损卖出的原因
+}
+
+// NewFilteredSignal 创建按市场状态过滤的信号生成器
+func NewFilteredSignal(inner stockStrategy.SignalGenerator, filter *Filter) *FilteredSignal {
+	return &FilteredSignal{
+		Inner:  inner,
+		Filter: filter,
+	}
+}
+
+// Reset 重置策略状态（每只票票回测前调用）
+func (sg *FilteredSignal) Reset() {
+	sg.Inner.Reset()
+	sg.exitReason = ""
+}
+
+// GetHistoryWindow 被包装信号生成器需要的历史窗口
+func (sg *FilteredSignal) GetHistoryWindow() int {
+	return stockStrategy.HistoryWindow(sg.Inner)
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+// 被包装的信号生成器每天都会处理数据，以保持其内部状态完整
+func (sg *FilteredSignal) ProcessDay(
+	ctx *stockStrategy.BarContext,
+	position *stockStrategy.Position,
+) int {
+	sg.exitReason = ""
+	signal := sg.Inner.ProcessDay(ctx, position)
+	rule := sg.Filter.RuleAt(ctx.Date())
+
+	if position == nil {
+		if signal == 1 && !rule.AllowBuys {
+			return 0
+		}
+		return signal
+	}
+
+	if signal != -1 && rule.StopScale > 0 && rule.StopScale < 1 {
+		if reason := sg.checkTightStop(ctx, position, rule.StopScale); reason != "" {
+			sg.exitReason = reason
+			return -1
+		}
+	}
+	return signal
+}
+
+// checkTightStop 按缩放后的止损比例判断是否卖出，返回卖出原因（空表示不卖出）
+func (sg *FilteredSignal) checkTightStop(ctx *stockStrategy.BarContext, position *stockStrategy.Position, scale float64) string {
+	provider, ok := sg.Inner.(stockStrategy.StopLossProvider)
+	if !ok || provider.GetStopLossPercent() <= 0 {
+		return ""
+	}
+	stop := provider.GetStopLossPercent() * scale
+	regime := sg.Filter.RegimeAt(ctx.Date())
+
+	price := ctx.Price(0)
+	buyPrice := float64(position.BuyPrice)
+	if buyPrice > 0 && (buyPrice-price)/buyPrice >= stop {
+		return fmt.Sprintf("%s收紧止损(跌幅%.2f%%)", regime, (buyPrice-price)/buyPrice*100)
+	}
+	highestPrice := max(float64(position.HighestPrice), price)
+	if highestPrice > buyPrice && (highestPrice-price)/highestPrice >= stop {
+		return fmt.Sprintf("%s收紧止损(回撤%.2f%%)", regime, (highestPrice-price)/highestPrice*100)
+	}
+	return ""
+}
+
+// GetExitReason 获取最近一次卖出信号的原因
+func (sg *FilteredSignal) GetExitReason() string {
+	if sg.exitReason != "" {
+		return sg.exitReason
+	}
+	if provider, ok := sg.Inner.(stockStrategy.ExitReasonProvider); ok {
+		return provider.GetExitReason()
+	}
+	return ""
+}
+
+// GetStopLossPercent 获取被包装信号生成器的止损比例
+func (sg *FilteredSignal) GetStopLossPercent() float64 {
+	if provider, ok := sg.Inner.(stockStrategy.StopLossProvider); ok {
+		return provider.GetStopLossPercent()
+	}
+	return 0
+}
+
+// GetName 获取信号生成器名称
+func (sg *FilteredSignal) GetName() string {
+	return fmt.Sprintf("%s[%s过滤]", sg.Inner.GetName(), sg.Filter.GetName())
+}
@@ -0,0 +1,58 @@
+// Package regime 市场状态（大盘策略）
+// 根据指数或全市场数据把每个交易日划分为牛市、熊市或震荡，
+// 任何策略都可以用 Filter 包装，在不利的市场状态下禁止开仓、收紧止损或降低仓位
+package regime
+
+import "stock-go/stockData"
+
+// DefaultIndexCode 默认用于判断市场状态的指数（沪深300）
+const DefaultIndexCode = "sh.000300"
+
+// Regime 市场状态
+type Regime int
+
+const (
+	Range Regime = iota // 震荡（数据不足时也视为震荡）
+	Bull                // 牛市
+	Bear                // 熊市
+)
+
+// String 市场状态的名称
+func (r Regime) String() string {
+	switch r {
+	case Bull:
+		return "牛市"
+	case Bear:
+		return "熊市"
+	}
+	return "震荡"
+}
+
+// Classifier 市场状态分类器
+type Classifier interface {
+	// Classify 按时间顺序逐日判断市场状态，返回 日期 -> 市场状态
+	// 第 t 天的结果只能使用 [0, t] 的数据
+	// 参数:
+	//   - index: 指数K线（按日期排序）
+	//   - universe: 全市场票票数据（用于市场宽度等指标）
+	Classify(index []*stockData.StockDataDay, universe map[string]*stockData.StockInfo) map[string]Regime
+
+	// GetName 获取分类器名称
+	GetName() string
+}
+
+// Rule 某一市场状态下的交易规则
+type Rule struct {
+	AllowBuys bool    // 是否允许开新仓
+	SizeScale float64 // 买入股数的缩放比例（1表示不调整）
+	StopScale float64 // 止损比例的缩放（小于1表示收紧止损，0或1表示不调整）
+}
+
+// NewDefaultRules 默认规则：牛市正常交易，震荡半仓，熊市禁止开仓并把止损收紧一半
+func NewDefaultRules() map[Regime]Rule {
+	return map[Regime]Rule{
+		Bull:  {AllowBuys: true, SizeScale: 1, StopScale: 1},
+		Range: {AllowBuys: true, SizeScale: 0.5, StopScale: 1},
+		Bear:  {AllowBuys: false, SizeScale: 0, StopScale: 0.5},
+	}
+}
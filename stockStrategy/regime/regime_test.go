@@ -0,0 +1,182 @@
+package regime
+
+import (
+	"fmt"
+	"stock-go/stockData"
+	"stock-go/stockData/synthetic"
+	"stock-go/stockStrategy"
+	"testing"
+)
+
+// newIndex 根据收盘价序列构造指数数据
+func newIndex(code string, prices []float64) *stockData.StockInfo {
+	return synthetic.FromPrices(code, prices)
+}
+
+// upDownPrices 先每天上涨 rate 共 days 天，再每天下跌 rate 共 days 天
+func upDownPrices(days int, rate float64) []float64 {
+	prices := make([]float64, 0, 2*days)
+	price := 100.0
+	for i := 0; i < days; i++ {
+		prices = append(prices, price)
+		price *= 1 + rate
+	}
+	for i := 0; i < days; i++ {
+		prices = append(prices, price)
+		price *= 1 - rate
+	}
+	return prices
+}
+
+// TestTrendClassifier 测试上涨为牛市、下跌为熊市、数据不足为震荡
+func TestTrendClassifier(t *testing.T) {
+	index := newIndex("sh.000300", upDownPrices(150, 0.005)).Datas.DayDatas
+	regimes := NewTrendClassifier(60, 20, 0.02).Classify(index, nil)
+
+	checks := map[int]Regime{10: Range, 100: Bull, 155: Bull, 175: Range, 280: Bear}
+	for i, want := range checks {
+		if got := regimes[index[i].DataStr]; got != want {
+			t.Errorf("第%d天市场状态 %s，期望 %s", i, got, want)
+		}
+	}
+}
+
+// TestClassifiersNoLookahead 测试分类器在截断数据上的结果与完整数据一致
+func TestClassifiersNoLookahead(t *testing.T) {
+	index := newIndex("sh.000300", upDownPrices(150, 0.01)).Datas.DayDatas
+	universe := make(map[string]*stockData.StockInfo)
+	for i := 0; i < 12; i++ {
+		code := fmt.Sprintf("sz.%06d", i+1)
+		universe[code] = newIndex(code, upDownPrices(150, 0.002*float64(i+1)))
+	}
+
+	classifiers := []Classifier{
+		NewTrendClassifier(60, 20, 0.02),
+		NewBreadthClassifier(20, 0.6, 0.4),
+		NewVolatilityClassifier(NewTrendClassifier(60, 20, 0.02), 20, 0.1),
+	}
+	for _, c := range classifiers {
+		full := c.Classify(index, universe)
+		for _, end := range []int{50, 120, 200} {
+			truncated := make(map[string]*stockData.StockInfo, len(universe))
+			for code, info := range universe {
+				copied := *info
+				copied.Datas.DayDatas = info.Datas.DayDatas[:end+1]
+				truncated[code] = &copied
+			}
+			partial := c.Classify(index[:end+1], truncated)
+			for i := 0; i <= end; i++ {
+				date := index[i].DataStr
+				if partial[date] != full[date] {
+					t.Fatalf("%s 截断到第%d天时，第%d天的市场状态 %s 与完整数据 %s 不一致",
+						c.GetName(), end, i, partial[date], full[date])
+				}
+			}
+		}
+	}
+}
+
+// TestBreadthAndVolatility 测试市场宽度分类和高波动降级
+func TestBreadthAndVolatility(t *testing.T) {
+	universe := make(map[string]*stockData.StockInfo)
+	for i := 0; i < 10; i++ {
+		code := fmt.Sprintf("sz.%06d", i+1)
+		universe[code] = newIndex(code, upDownPrices(50, 0.01))
+	}
+	dates := universe["sz.000001"].Datas.DayDatas
+	regimes := NewBreadthClassifier(20, 0.6, 0.4).Classify(nil, universe)
+	if regimes[dates[40].DataStr] != Bull || regimes[dates[90].DataStr] != Bear {
+		t.Errorf("市场宽度: 第40天 %s 第90天 %s，期望牛市和熊市", regimes[dates[40].DataStr], regimes[dates[90].DataStr])
+	}
+
+	// 每天涨跌5%交替，波动率远超阈值：牛市降为震荡
+	prices := upDownPrices(100, 0.005)
+	for i := 80; i < 100; i++ {
+		if i%2 == 0 {
+			prices[i] *= 1.05
+		}
+	}
+	index := newIndex("sh.000300", prices).Datas.DayDatas
+	base := NewTrendClassifier(60, 20, 0.02)
+	if base.Classify(index, nil)[index[98].DataStr] != Bull {
+		t.Fatal("趋势分类应为牛市")
+	}
+	if got := NewVolatilityClassifier(base, 20, 0.35).Classify(index, nil)[index[98].DataStr]; got != Range {
+		t.Errorf("高波动时市场状态 %s，期望震荡", got)
+	}
+}
+
+// TestFilteredSignal 测试熊市禁止买入并收紧止损
+func TestFilteredSignal(t *testing.T) {
+	index := newIndex("sh.000300", upDownPrices(150, 0.005))
+	filter := NewFilter(NewTrendClassifier(60, 20, 0.02), "").SetIndexData(index)
+	days := index.Datas.DayDatas
+
+	// 没有交易日数据的日期沿用之前的状态
+	if filter.RegimeAt("2099-01-01") != filter.RegimeAt(days[len(days)-1].DataStr) || filter.RegimeAt("2000-01-01") != Range {
+		t.Error("缺失日期的市场状态不正确")
+	}
+
+	ctxAt := func(days []*stockData.StockDataDay, i int) *stockStrategy.BarContext {
+		return stockStrategy.NewBarContext("sz.000001", days, i, 1)
+	}
+	sg := NewFilteredSignal(&fixedSignal{signal: 1, stop: 0.06}, filter)
+	if sg.ProcessDay(ctxAt(days, 100), nil) != 1 {
+		t.Error("牛市应允许买入")
+	}
+	if sg.ProcessDay(ctxAt(days, 280), nil) != 0 {
+		t.Error("熊市应禁止买入")
+	}
+
+	// 熊市止损收紧为3%：下跌4%卖出
+	sg.Inner = &fixedSignal{signal: 0, stop: 0.06}
+	price := days[280].PriceBegin
+	position := &stockStrategy.Position{BuyPrice: price / 0.96, HighestPrice: price / 0.96}
+	if sg.ProcessDay(ctxAt(days, 280), position) != -1 {
+		t.Fatal("熊市下跌4%应触发收紧的止损")
+	}
+	if sg.GetExitReason() == "" {
+		t.Error("缺少卖出原因")
+	}
+	position.BuyPrice = days[100].PriceBegin / 0.96
+	if sg.ProcessDay(ctxAt(days, 100), position) != 0 {
+		t.Error("牛市不应收紧止损")
+	}
+}
+
+// TestFilterIndexData 测试指数数据只来自注入的数据：缺少时报错，可以从全市场数据中按代码查找
+func TestFilterIndexData(t *testing.T) {
+	index := newIndex("sh.000300", upDownPrices(150, 0.005))
+	filter := NewFilter(NewTrendClassifier(60, 20, 0.02), "sh.000300")
+	if err := filter.CheckStockData(); err == nil {
+		t.Fatal("缺少指数数据时应返回错误")
+	}
+
+	filter.SetUniverse(map[string]*stockData.StockInfo{"sh.000300": index})
+	if err := filter.CheckStockData(); err != nil {
+		t.Fatalf("全市场数据包含指数时不应报错: %v", err)
+	}
+	if got := filter.RegimeAt(index.Datas.DayDatas[100].DataStr); got != Bull {
+		t.Errorf("市场状态 %s，期望牛市", got)
+	}
+
+	if err := NewFilter(NewBreadthClassifier(20, 0.6, 0.4), "").CheckStockData(); err != nil {
+		t.Errorf("不使用指数时不应报错: %v", err)
+	}
+}
+
+// fixedSignal 固定返回同一信号的测试信号生成器
+type fixedSignal struct {
+	signal int
+	stop   float64
+}
+
+func (s *fixedSignal) Reset() {}
+
+func (s *fixedSignal) ProcessDay(ctx *stockStrategy.BarContext, position *stockStrategy.Position) int {
+	return s.signal
+}
+
+func (s *fixedSignal) GetStopLossPercent() float64 { return s.stop }
+
+func (s *fixedSignal) GetName() string { return "固定信号" }
@@ -0,0 +1,213 @@
+package registry
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/combine"
+	"stock-go/stockStrategy/expr"
+	"stock-go/stockStrategy/sector"
+	"stock-go/stockStrategy/selectors"
+	"stock-go/stockStrategy/signals"
+	"stock-go/stockStrategy/sizers"
+	"stock-go/stockStrategy/strategies"
+)
+
+// 内置组件，参数默认值与各组件的 NewXxx() 默认构造一致
+func init() {
+	registerSelectors()
+	registerSignals()
+	registerExits()
+	registerSizers()
+	registerStrategies()
+}
+
+func registerSelectors() {
+	RegisterSelector("allMarket", "全市场，不做筛选", nil,
+		func(p Params) (stockStrategy.StockSelector, error) {
+			return selectors.NewAllMarketSelector(), nil
+		})
+	RegisterSelector("listedDays", "K线数量足够的票票（排除新股）",
+		[]Param{Int("minDays", 80, 1, 5000, "最少K线数量")},
+		func(p Params) (stockStrategy.StockSelector, error) {
+			return selectors.NewListedDaysSelector(p.Int("minDays")), nil
+		})
+	RegisterSelector("highPoint", "回看期内最近N天出现过最高点的票票",
+		[]Param{
+			Int("lookbackDays", 500, 1, 5000, "回看天数"),
+			Int("recentDays", 15, 1, 500, "最近N天内出现高点"),
+		},
+		func(p Params) (stockStrategy.StockSelector, error) {
+			return selectors.NewHighPointSelector(p.Int("lookbackDays"), p.Int("recentDays")), nil
+		})
+	RegisterSelector("expr", "选股表达式成立的票票",
+		[]Param{Require(String("expression", "", "选股表达式，如 close > ma(close, 250)"))},
+		func(p Params) (stockStrategy.StockSelector, error) {
+			return expr.NewSelector(p.String("expression"))
+		})
+}
+
+func registerSignals() {
+	RegisterSignal("buyHighSellLow", "追高杀跌：创新高买入，从最高点回落卖出",
+		[]Param{
+			Int("lookbackDays", 300, 2, 5000, "新高的回看天数"),
+			Float("sellDropPercent", 0.06, 0, 1, "从最高点回落卖出的比例"),
+			Int("maxHoldDays", 30, 1, 1000, "最大持有天数"),
+		},
+		func(p Params) (stockStrategy.SignalGenerator, error) {
+			return signals.NewBuyHighSellLowSignal(p.Int("lookbackDays"), p.Float("sellDropPercent"), p.Int("maxHoldDays")), nil
+		})
+	RegisterSignal("breakout", "突破前N日最高价且均线多头、RSI适中时买入，按默认卖出规则卖出",
+		[]Param{
+			Int("lookbackDays", 20, 2, 1000, "突破的回看天数"),
+			Float("breakoutPercent", 0.02, 0, 1, "超过前高的比例"),
+		},
+		func(p Params) (stockStrategy.SignalGenerator, error) {
+			return signals.NewBreakoutSignal(p.Int("lookbackDays"), p.Float("breakoutPercent"), signals.NewDefaultExitRule()), nil
+		})
+	RegisterSignal("swing", "波段：超卖且低于均价一定比例时买入，按默认卖出规则卖出",
+		[]Param{
+			Int("windowDays", 60, 2, 1000, "计算均价和标准差的天数"),
+			Float("discountPercent", 0.08, 0, 1, "低于均价的比例"),
+		},
+		func(p Params) (stockStrategy.SignalGenerator, error) {
+			return signals.NewSwingSignal(p.Int("windowDays"), p.Float("discountPercent"), signals.NewDefaultExitRule()), nil
+		})
+	RegisterSignal("expr", "表达式买入；卖出表达式为空时按默认卖出规则卖出",
+		[]Param{
+			Require(String("entry", "", "买入表达式，如 close >= highest(close, 20)")),
+			String("exit", "", "卖出表达式，可使用 profit、hold_days 等持仓变量"),
+		},
+		func(p Params) (stockStrategy.SignalGenerator, error) {
+			sg, err := expr.NewSignal(p.String("entry"), p.String("exit"))
+			if err != nil {
+				return nil, err
+			}
+			if p.String("exit") == "" {
+				return combine.EntryExit(sg, combine.Exit(signals.NewDefaultExitRule())), nil
+			}
+			return sg, nil
+		})
+	RegisterSignal("maCross", "短期均线在长期均线之上时买入，按默认卖出规则卖出",
+		[]Param{
+			Int("shortDays", 20, 1, 500, "短期均线天数"),
+			Int("longDays", 60, 2, 1000, "长期均线天数"),
+		},
+		func(p Params) (stockStrategy.SignalGenerator, error) {
+			if p.Int("shortDays") >= p.Int("longDays") {
+				return nil, fmt.Errorf("shortDays(%d) 应小于 longDays(%d)", p.Int("shortDays"), p.Int("longDays"))
+			}
+			cond := combine.MAAbove(p.Int("shortDays"), p.Int("longDays"))
+			return combine.EntryExit(combine.When(cond), combine.Exit(signals.NewDefaultExitRule())), nil
+		})
+}
+
+func registerExits() {
+	RegisterExit("rule", "止盈、止损、移动止损和最大持有天数",
+		[]Param{
+			Float("takeProfit", 0.15, 0, 10, "止盈比例，0表示不止盈"),
+			Float("stopLoss", 0.04, 0, 1, "止损比例，0表示不止损"),
+			Float("trailingActivate", 0.05, 0, 10, "最高价涨幅超过该比例后启用移动止损"),
+			Float("trailingStop", 0.05, 0, 1, "移动止损的回撤比例，0表示不使用移动止损"),
+			Int("maxHoldDays", 40, 0, 1000, "最大持有天数，0表示不限制"),
+		},
+		func(p Params) (stockStrategy.SignalGenerator, error) {
+			return combine.Exit(signals.ExitRule{
+				TakeProfit:       p.Float("takeProfit"),
+				StopLoss:         p.Float("stopLoss"),
+				TrailingActivate: p.Float("trailingActivate"),
+				TrailingStop:     p.Float("trailingStop"),
+				MaxHoldDays:      p.Int("maxHoldDays"),
+			}), nil
+		})
+	RegisterExit("expr", "卖出表达式成立时卖出",
+		[]Param{Require(String("expression", "", "卖出表达式，如 drawdown > 0.05 or hold_days >= 30"))},
+		func(p Params) (stockStrategy.SignalGenerator, error) {
+			return expr.NewExitSignal(p.String("expression"))
+		})
+	RegisterExit("belowMA", "跌破均线卖出",
+		[]Param{Int("days", 20, 1, 1000, "均线天数")},
+		func(p Params) (stockStrategy.SignalGenerator, error) {
+			return combine.When(combine.PriceBelowMA(p.Int("days"))), nil
+		})
+}
+
+func registerSizers() {
+	RegisterSizer("fixedFraction", "每次买入使用总资产的固定比例",
+		[]Param{Float("fraction", 0.25, 0.001, 1, "每个持仓占总资产的比例")},
+		func(p Params) (stockStrategy.PositionSizer, error) {
+			return sizers.NewFixedFractionSizer(p.Float("fraction")), nil
+		})
+	RegisterSizer("equalWeight", "总资产平均分配给最大持仓数个持仓", nil,
+		func(p Params) (stockStrategy.PositionSizer, error) {
+			return sizers.NewEqualWeightSizer(), nil
+		})
+	RegisterSizer("fixedRisk", "触发止损时的亏损不超过总资产的固定比例",
+		[]Param{
+			Float("riskPercent", 0.01, 0.0001, 1, "单笔交易风险占总资产的比例"),
+			Float("defaultStopPercent", 0.04, 0.001, 1, "信号生成器未提供止损比例时使用的止损比例"),
+			Float("maxFraction", 0.25, 0.001, 1, "单个持仓占总资产的上限比例"),
+		},
+		func(p Params) (stockStrategy.PositionSizer, error) {
+			return sizers.NewFixedRiskSizer(p.Float("riskPercent"), p.Float("defaultStopPercent"), p.Float("maxFraction")), nil
+		})
+	RegisterSizer("kelly", "分数凯利仓位",
+		[]Param{
+			Float("kellyFraction", 0.5, 0.01, 1, "凯利比例的使用系数，0.5表示半凯利"),
+			Int("minTrades", 20, 0, 10000, "使用凯利公式所需的最少已完成交易数"),
+			Float("fallbackFraction", 0.1, 0.001, 1, "交易样本不足时使用的资金比例"),
+			Float("maxFraction", 0.25, 0.001, 1, "单个持仓占总资产的上限比例"),
+		},
+		func(p Params) (stockStrategy.PositionSizer, error) {
+			return sizers.NewKellySizer(p.Float("kellyFraction"), p.Int("minTrades"), p.Float("fallbackFraction"), p.Float("maxFraction")), nil
+		})
+	RegisterSizer("volatility", "按ATR使每个持仓的日波动等于总资产的固定比例",
+		[]Param{
+			Int("atrPeriod", 20, 1, 500, "ATR计算周期"),
+			Float("targetPercent", 0.01, 0.0001, 1, "单个持仓日波动占总资产的目标比例"),
+			Float("maxFraction", 0.25, 0.001, 1, "单个持仓占总资产的上限比例"),
+		},
+		func(p Params) (stockStrategy.PositionSizer, error) {
+			return sizers.NewVolatilitySizer(p.Int("atrPeriod"), p.Float("targetPercent"), p.Float("maxFraction")), nil
+		})
+}
+
+func registerStrategies() {
+	RegisterStrategy("mode", "按策略模式编号创建（与 NewModeStrategy 一致）",
+		[]Param{Int("mode", stockStrategy.Strategy_Mode_1, stockStrategy.Strategy_Mode_1, stockStrategy.Strategy_Mode_7, "策略模式编号")},
+		func(p Params) (stockStrategy.Strategy, error) {
+			strategy := strategies.NewModeStrategy(p.Int("mode"))
+			if strategy == nil {
+				return nil, fmt.Errorf("策略模式 %d 不存在", p.Int("mode"))
+			}
+			return strategy, nil
+		})
+	RegisterStrategy("sectorBreakout", "策略5：行业指数创新高时买入行业龙头",
+		[]Param{
+			Int("breakoutDays", 20, 2, 1000, "突破的回看天数"),
+			Int("momentumDays", 20, 1, 1000, "行业动量天数"),
+			Int("leaders", 2, 1, 100, "每个行业的龙头数量"),
+		},
+		func(p Params) (stockStrategy.Strategy, error) {
+			return strategies.NewSectorBreakoutStrategyWithParams(sector.NewModel(nil),
+				p.Int("breakoutDays"), p.Int("momentumDays"), p.Int("leaders"), signals.NewDefaultExitRule()), nil
+		})
+	RegisterStrategy("sectorRotation", "策略6：板块轮动，定期持有强势行业的龙头",
+		[]Param{
+			Int("topIndustries", 3, 1, 100, "持有的强势行业数量"),
+			Int("leaders", 2, 1, 100, "每个行业的龙头数量"),
+			Int("rebalanceEvery", 20, 1, 1000, "调仓间隔（交易日）"),
+		},
+		func(p Params) (stockStrategy.Strategy, error) {
+			return strategies.NewSectorRotationStrategyWithParams(sector.NewModel(nil),
+				p.Int("topIndustries"), p.Int("leaders"), p.Int("rebalanceEvery")), nil
+		})
+	RegisterStrategy("momentumRank", "策略7：动量排名，定期等权持有涨幅最大的票票",
+		[]Param{
+			Int("lookbackDays", 60, 1, 1000, "动量回看天数"),
+			Int("topN", 5, 1, 100, "持有数量"),
+			Int("rebalanceEvery", 20, 1, 1000, "调仓间隔（交易日）"),
+		},
+		func(p Params) (stockStrategy.Strategy, error) {
+			return strategies.NewMomentumRankStrategyWithParams(p.Int("lookbackDays"), p.Int("topN"), p.Int("rebalanceEvery")), nil
+		})
+}
@@ -0,0 +1,232 @@
+package registry
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ParamType 参数类型
+type ParamType string
+
+const (
+	TypeInt    ParamType = "int"
+	TypeFloat  ParamType = "float"
+	TypeString ParamType = "string"
+	TypeBool   ParamType = "bool"
+)
+
+// Param 组件参数的声明
+type Param struct {
+	Name     string    `json:"name"`
+	Type     ParamType `json:"type"`
+	Default  any       `json:"default,omitempty"`  // 默认值（Required 时忽略）
+	Min      *float64  `json:"min,omitempty"`      // 数值参数的下限（包含）
+	Max      *float64  `json:"max,omitempty"`      // 数值参数的上限（包含）
+	Required bool      `json:"required,omitempty"` // 必须在配置中指定
+	Desc     string    `json:"desc"`
+}
+
+// Int 声明整数参数，取值范围 [min, max]
+func Int(name string, def, min, max int, desc string) Param {
+	lo, hi := float64(min), float64(max)
+	return Param{Name: name, Type: TypeInt, Default: def, Min: &lo, Max: &hi, Desc: desc}
+}
+
+// Float 声明浮点参数，取值范围 [min, max]
+func Float(name string, def, min, max float64, desc string) Param {
+	return Param{Name: name, Type: TypeFloat, Default: def, Min: &min, Max: &max, Desc: desc}
+}
+
+// String 声明字符串参数
+func String(name, def, desc string) Param {
+	return Param{Name: name, Type: TypeString, Default: def, Desc: desc}
+}
+
+// Bool 声明布尔参数
+func Bool(name string, def bool, desc string) Param {
+	return Param{Name: name, Type: TypeBool, Default: def, Desc: desc}
+}
+
+// Require 把参数标记为必填
+func Require(p Param) Param {
+	p.Required = true
+	p.Default = nil
+	return p
+}
+
+// check 检查参数声明本身是否合法
+func (p Param) check() error {
+	if p.Name == "" {
+		return fmt.Errorf("参数名为空")
+	}
+	if p.Required {
+		return nil
+	}
+	if _, err := p.convert(p.Default); err != nil {
+		return fmt.Errorf("参数 %s 的默认值: %w", p.Name, err)
+	}
+	return nil
+}
+
+// convert 把配置中的值转换为参数类型并检查范围
+// JSON 数字解码为 float64，整数参数要求没有小数部分
+func (p Param) convert(v any) (any, error) {
+	switch p.Type {
+	case TypeInt, TypeFloat:
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("应为%s，实际为 %v", p.typeName(), v)
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("不是有效的数值: %v", v)
+		}
+		if p.Min != nil && f < *p.Min {
+			return nil, fmt.Errorf("%v 小于最小值 %v", v, *p.Min)
+		}
+		if p.Max != nil && f > *p.Max {
+			return nil, fmt.Errorf("%v 大于最大值 %v", v, *p.Max)
+		}
+		if p.Type == TypeFloat {
+			return f, nil
+		}
+		if f != math.Trunc(f) {
+			return nil, fmt.Errorf("应为整数，实际为 %v", v)
+		}
+		return int(f), nil
+	case TypeString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("应为字符串，实际为 %v", v)
+		}
+		return s, nil
+	case TypeBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("应为布尔值，实际为 %v", v)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("未知的参数类型 %q", p.Type)
+}
+
+// typeName 参数类型的中文名称
+func (p Param) typeName() string {
+	switch p.Type {
+	case TypeInt:
+		return "整数"
+	case TypeFloat:
+		return "数值"
+	case TypeString:
+		return "字符串"
+	case TypeBool:
+		return "布尔值"
+	}
+	return string(p.Type)
+}
+
+// toFloat 数值类型转换为 float64
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// resolve 按参数表校验配置中的参数并补全默认值
+// 未声明的参数视为错误，避免拼写错误被静默忽略
+func (c *Component) resolve(values map[string]any) (Params, error) {
+	declared := make(map[string]bool, len(c.Params))
+	for _, p := range c.Params {
+		declared[p.Name] = true
+	}
+	unknown := make([]string, 0)
+	for name := range values {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return Params{}, fmt.Errorf("未知的参数 %s，可用参数: %s", strings.Join(unknown, ", "), c.paramNames())
+	}
+
+	resolved := make(map[string]any, len(c.Params))
+	for _, p := range c.Params {
+		v, ok := values[p.Name]
+		if !ok {
+			if p.Required {
+				return Params{}, fmt.Errorf("缺少必填参数 %s（%s）", p.Name, p.Desc)
+			}
+			v = p.Default
+		}
+		converted, err := p.convert(v)
+		if err != nil {
+			return Params{}, fmt.Errorf("参数 %s %w", p.Name, err)
+		}
+		resolved[p.Name] = converted
+	}
+	return Params{values: resolved}, nil
+}
+
+// paramNames 参数名列表
+func (c *Component) paramNames() string {
+	if len(c.Params) == 0 {
+		return "无"
+	}
+	names := make([]string, len(c.Params))
+	for i, p := range c.Params {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// Params 校验后的参数（已补全默认值，类型与声明一致）
+type Params struct {
+	values map[string]any
+}
+
+// Int 获取整数参数
+func (p Params) Int(name string) int {
+	v, _ := p.values[name].(int)
+	return v
+}
+
+// Float 获取浮点参数
+func (p Params) Float(name string) float64 {
+	v, _ := p.values[name].(float64)
+	return v
+}
+
+// String 获取字符串参数
+func (p Params) String(name string) string {
+	v, _ := p.values[name].(string)
+	return v
+}
+
+// Bool 获取布尔参数
+func (p Params) Bool(name string) bool {
+	v, _ := p.values[name].(bool)
+	return v
+}
+
+// Map 参数的副本（用于输出实际生效的配置）
+func (p Params) Map() map[string]any {
+	m := make(map[string]any, len(p.values))
+	for k, v := range p.values {
+		m[k] = v
+	}
+	return m
+}
@@ -0,0 +1,202 @@
+// Package registry 策略组件注册表
+//
+// 选股器、信号生成器、卖出规则、仓位管理器和完整策略按名称注册，并声明带类型的参数表。
+// 配置文件只需要写组件名称和参数，由注册表校验参数并创建组件，不再依赖 Strategy_Mode_* 和写死在测试里的参数。
+//
+// 示例:
+//
+//	sg, err := registry.BuildSignal(registry.Spec{Type: "breakout", Params: map[string]any{"lookbackDays": 30}})
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"stock-go/stockStrategy"
+	"strings"
+	"sync"
+)
+
+// Kind 组件类型
+type Kind string
+
+const (
+	KindSelector Kind = "selector" // 选股器
+	KindSignal   Kind = "signal"   // 信号生成器（买入，并可自带卖出）
+	KindExit     Kind = "exit"     // 卖出规则（替换信号生成器自带的卖出）
+	KindSizer    Kind = "sizer"    // 仓位管理器
+	KindStrategy Kind = "strategy" // 完整策略（选股和信号不可拆分的策略，如横截面策略）
+)
+
+// Kinds 所有组件类型
+var Kinds = []Kind{KindSelector, KindSignal, KindExit, KindSizer, KindStrategy}
+
+// Spec 配置中对组件的引用：组件名称和参数
+type Spec struct {
+	Type   string         `json:"type"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+// Component 已注册的组件
+type Component struct {
+	Kind   Kind    `json:"kind"`
+	Name   string  `json:"name"`
+	Desc   string  `json:"desc"`
+	Params []Param `json:"params"`
+
+	build func(p Params) (any, error)
+}
+
+var (
+	mu         sync.RWMutex
+	components = make(map[Kind]map[string]*Component)
+)
+
+// register 注册组件，名称重复或参数表不合法时 panic（注册在 init 中进行，属于编程错误）
+func register(kind Kind, name, desc string, params []Param, build func(p Params) (any, error)) {
+	for _, p := range params {
+		if err := p.check(); err != nil {
+			panic(fmt.Sprintf("注册%s %q: %v", kind, name, err))
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if components[kind] == nil {
+		components[kind] = make(map[string]*Component)
+	}
+	if _, ok := components[kind][name]; ok {
+		panic(fmt.Sprintf("%s %q 重复注册", kind, name))
+	}
+	components[kind][name] = &Component{Kind: kind, Name: name, Desc: desc, Params: params, build: build}
+}
+
+// RegisterSelector 注册选股器
+func RegisterSelector(name, desc string, params []Param, build func(p Params) (stockStrategy.StockSelector, error)) {
+	register(KindSelector, name, desc, params, func(p Params) (any, error) { return build(p) })
+}
+
+// RegisterSignal 注册信号生成器
+// build 每次调用都要返回新的实例，回测时每只票票使用独立的信号生成器
+func RegisterSignal(name, desc string, params []Param, build func(p Params) (stockStrategy.SignalGenerator, error)) {
+	register(KindSignal, name, desc, params, func(p Params) (any, error) { return build(p) })
+}
+
+// RegisterExit 注册卖出规则（持仓时返回-1的信号生成器）
+func RegisterExit(name, desc string, params []Param, build func(p Params) (stockStrategy.SignalGenerator, error)) {
+	register(KindExit, name, desc, params, func(p Params) (any, error) { return build(p) })
+}
+
+// RegisterSizer 注册仓位管理器
+func RegisterSizer(name, desc string, params []Param, build func(p Params) (stockStrategy.PositionSizer, error)) {
+	register(KindSizer, name, desc, params, func(p Params) (any, error) { return build(p) })
+}
+
+// RegisterStrategy 注册完整策略
+func RegisterStrategy(name, desc string, params []Param, build func(p Params) (stockStrategy.Strategy, error)) {
+	register(KindStrategy, name, desc, params, func(p Params) (any, error) { return build(p) })
+}
+
+// Lookup 按类型和名称查找组件
+func Lookup(kind Kind, name string) (*Component, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := components[kind][name]
+	return c, ok
+}
+
+// List 指定类型的所有组件，按名称排序
+func List(kind Kind) []*Component {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]*Component, 0, len(components[kind]))
+	for _, c := range components[kind] {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Names 指定类型的所有组件名称，按名称排序
+func Names(kind Kind) []string {
+	list := List(kind)
+	names := make([]string, len(list))
+	for i, c := range list {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Validate 校验组件引用：组件存在且参数合法，返回补全默认值后的参数
+func Validate(kind Kind, spec Spec) (Params, error) {
+	c, ok := Lookup(kind, spec.Type)
+	if !ok {
+		if spec.Type == "" {
+			return Params{}, fmt.Errorf("%s 未指定 type，可选: %s", kind, strings.Join(Names(kind), ", "))
+		}
+		return Params{}, fmt.Errorf("未知的%s %q，可选: %s", kind, spec.Type, strings.Join(Names(kind), ", "))
+	}
+	p, err := c.resolve(spec.Params)
+	if err != nil {
+		return Params{}, fmt.Errorf("%s %q: %w", kind, spec.Type, err)
+	}
+	return p, nil
+}
+
+// build 校验参数并创建组件
+func build(kind Kind, spec Spec) (any, error) {
+	p, err := Validate(kind, spec)
+	if err != nil {
+		return nil, err
+	}
+	c, _ := Lookup(kind, spec.Type)
+	v, err := c.build(p)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q: %w", kind, spec.Type, err)
+	}
+	return v, nil
+}
+
+// BuildSelector 创建选股器
+func BuildSelector(spec Spec) (stockStrategy.StockSelector, error) {
+	v, err := build(KindSelector, spec)
+	if err != nil {
+		return nil, err
+	}
+	return v.(stockStrategy.StockSelector), nil
+}
+
+// BuildSignal 创建信号生成器
+func BuildSignal(spec Spec) (stockStrategy.SignalGenerator, error) {
+	v, err := build(KindSignal, spec)
+	if err != nil {
+		return nil, err
+	}
+	return v.(stockStrategy.SignalGenerator), nil
+}
+
+// BuildExit 创建卖出规则
+func BuildExit(spec Spec) (stockStrategy.SignalGenerator, error) {
+	v, err := build(KindExit, spec)
+	if err != nil {
+		return nil, err
+	}
+	return v.(stockStrategy.SignalGenerator), nil
+}
+
+// BuildSizer 创建仓位管理器
+func BuildSizer(spec Spec) (stockStrategy.PositionSizer, error) {
+	v, err := build(KindSizer, spec)
+	if err != nil {
+		return nil, err
+	}
+	return v.(stockStrategy.PositionSizer), nil
+}
+
+// BuildStrategy 创建完整策略
+func BuildStrategy(spec Spec) (stockStrategy.Strategy, error) {
+	v, err := build(KindStrategy, spec)
+	if err != nil {
+		return nil, err
+	}
+	return v.(stockStrategy.Strategy), nil
+}
@@ -0,0 +1,98 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValidateParams 测试参数校验和默认值
+func TestValidateParams(t *testing.T) {
+	p, err := Validate(KindSignal, Spec{Type: "buyHighSellLow", Params: map[string]any{"lookbackDays": 200.0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Int("lookbackDays") != 200 || p.Float("sellDropPercent") != 0.06 || p.Int("maxHoldDays") != 30 {
+		t.Errorf("参数 %v 不正确", p.Map())
+	}
+
+	cases := []struct {
+		kind Kind
+		spec Spec
+		want string
+	}{
+		{KindSignal, Spec{Type: "breakOut"}, "未知的signal \"breakOut\"，可选: breakout"},
+		{KindSignal, Spec{}, "signal 未指定 type"},
+		{KindSignal, Spec{Type: "breakout", Params: map[string]any{"lookback": 20.0}}, "未知的参数 lookback，可用参数: lookbackDays, breakoutPercent"},
+		{KindSignal, Spec{Type: "breakout", Params: map[string]any{"lookbackDays": 20.5}}, "参数 lookbackDays 应为整数"},
+		{KindSignal, Spec{Type: "breakout", Params: map[string]any{"lookbackDays": "20"}}, "参数 lookbackDays 应为整数，实际为 20"},
+		{KindSignal, Spec{Type: "breakout", Params: map[string]any{"breakoutPercent": 2.0}}, "参数 breakoutPercent 2 大于最大值 1"},
+		{KindSizer, Spec{Type: "fixedFraction", Params: map[string]any{"fraction": 0.0}}, "小于最小值"},
+		{KindExit, Spec{Type: "expr"}, "缺少必填参数 expression"},
+	}
+	for _, c := range cases {
+		_, err := Validate(c.kind, c.spec)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s %+v: 错误 %v，期望包含 %q", c.kind, c.spec, err, c.want)
+		}
+	}
+}
+
+// TestBuild 测试创建组件：信号生成器每次都是新实例，构造函数的错误带上组件名称
+func TestBuild(t *testing.T) {
+	spec := Spec{Type: "expr", Params: map[string]any{"entry": "close > ma(close, 5)"}}
+	a, err := BuildSignal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := BuildSignal(spec)
+	if a == b {
+		t.Error("每次创建应返回新的信号生成器")
+	}
+
+	if _, err := BuildSignal(Spec{Type: "maCross", Params: map[string]any{"shortDays": 60, "longDays": 20}}); err == nil ||
+		!strings.HasPrefix(err.Error(), "signal \"maCross\": shortDays(60)") {
+		t.Errorf("错误 %v 不正确", err)
+	}
+	if _, err := BuildSelector(Spec{Type: "expr", Params: map[string]any{"expression": "close +"}}); err == nil {
+		t.Error("表达式语法错误应返回错误")
+	}
+
+	for _, kind := range Kinds {
+		for _, c := range List(kind) {
+			if c.Params != nil && hasRequired(c.Params) {
+				continue
+			}
+			if _, err := build(kind, Spec{Type: c.Name}); err != nil {
+				t.Errorf("%s %s 使用默认参数创建失败: %v", kind, c.Name, err)
+			}
+		}
+	}
+}
+
+// hasRequired 是否有必填参数
+func hasRequired(params []Param) bool {
+	for _, p := range params {
+		if p.Required {
+			return true
+		}
+	}
+	return false
+}
+
+// TestRegisterDuplicate 测试重复注册和非法默认值 panic
+func TestRegisterDuplicate(t *testing.T) {
+	expectPanic := func(name string, f func()) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s 应当 panic", name)
+			}
+		}()
+		f()
+	}
+	expectPanic("重复注册", func() {
+		RegisterSizer("equalWeight", "", nil, nil)
+	})
+	expectPanic("默认值超出范围", func() {
+		RegisterSizer("badDefault", "", []Param{Float("fraction", 2, 0, 1, "")}, nil)
+	})
+}
@@ -0,0 +1,117 @@
+package sector
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/signals"
+)
+
+// BreakoutSignal 板块突破信号生成器（策略5），每个实例绑定一只票票
+// 买入条件：所属行业指数创 BreakoutDays 天新高，且该票票是行业内近期涨幅前 Leaders 名的龙头
+// 卖出条件：ExitRule（止盈、止损、移动止损、超时）；调仓日行业指数跌破 ExitMA 日均线或不再是龙头
+type BreakoutSignal struct {
+	Model          *Model
+	Code           string           // 票票代码
+	BreakoutDays   int              // 行业指数突破的回看天数，默认20
+	MomentumDays   int              // 龙头排名的涨幅天数，默认20
+	Leaders        int              // 每个行业的龙头数量，默认2
+	ExitMA         int              // 行业指数跌破该均线时卖出，默认20
+	RebalanceEvery int              // 每隔多少个交易日检查一次龙头和板块趋势，默认5
+	Exit           signals.ExitRule // 卖出规则
+
+	exitReason string // 最近一次卖出信号的原因
+}
+
+// NewBreakoutSignal 创建板块突破信号生成器
+func NewBreakoutSignal(model *Model, breakoutDays, momentumDays, leaders int, exit signals.ExitRule) *BreakoutSignal {
+	return &BreakoutSignal{
+		Model:          model,
+		BreakoutDays:   breakoutDays,
+		MomentumDays:   momentumDays,
+		Leaders:        leaders,
+		ExitMA:         20,
+		RebalanceEvery: 5,
+		Exit:           exit,
+	}
+}
+
+// ForCode 创建参数相同、绑定到指定票票的信号生成器
+func (sg *BreakoutSignal) ForCode(code string) *BreakoutSignal {
+	bound := *sg
+	bound.Code = code
+	bound.Reset()
+	return &bound
+}
+
+// Reset 重置策略状态
+func (sg *BreakoutSignal) Reset() {
+	sg.exitReason = ""
+}
+
+// GetHistoryWindow 行业数据由 Model 提供，只需要当天的K线
+func (sg *BreakoutSignal) GetHistoryWindow() int {
+	return 1
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+// 行业指数和龙头排名只使用当天及之前的数据
+func (sg *BreakoutSignal) ProcessDay(
+	ctx *stockStrategy.BarContext,
+	position *stockStrategy.Position,
+) int {
+	industry := sg.Model.IndustryOf(sg.Code)
+	if industry == "" {
+		return 0
+	}
+	date := ctx.Date()
+
+	if position == nil {
+		if sg.Model.IsBreakout(industry, date, sg.BreakoutDays) && sg.isLeader(industry, date) {
+			return 1
+		}
+		return 0
+	}
+
+	currentPrice := float32(ctx.Price(0))
+	if currentPrice > position.HighestPrice {
+		position.HighestPrice = currentPrice
+	}
+	var sell bool
+	if sell, sg.exitReason = sg.Exit.Check(currentPrice, position); sell {
+		return -1
+	}
+
+	if !sg.Model.IsRebalanceDay(date, sg.RebalanceEvery) {
+		return 0
+	}
+	if sg.Model.IsBelowMA(industry, date, sg.ExitMA) {
+		sg.exitReason = fmt.Sprintf("板块跌破%d日均线", sg.ExitMA)
+		return -1
+	}
+	if !sg.isLeader(industry, date) {
+		sg.exitReason = "不再是板块龙头"
+		return -1
+	}
+	return 0
+}
+
+// isLeader 是否为行业龙头
+func (sg *BreakoutSignal) isLeader(industry, date string) bool {
+	return contains(sg.Model.Leaders(industry, date, sg.MomentumDays, sg.Leaders), sg.Code)
+}
+
+// GetExitReason 获取最近一次卖出信号的原因
+func (sg *BreakoutSignal) GetExitReason() string {
+	return sg.exitReason
+}
+
+// GetStopLossPercent 获取止损比例（供仓位管理器按风险计算仓位）
+func (sg *BreakoutSignal) GetStopLossPercent() float64 {
+	return sg.Exit.StopLoss
+}
+
+// GetName 获取信号生成器名称
+func (sg *BreakoutSignal) GetName() string {
+	return fmt.Sprintf("板块突破(%d天新高,%d天涨幅前%d,%s)",
+		sg.BreakoutDays, sg.MomentumDays, sg.Leaders, sg.Exit)
+}
@@ -0,0 +1,288 @@
+// Package sector 板块（行业）策略
+// 按 stockList.csv 的行业分类，把同一行业的票票合成等权行业指数，
+// 支持板块突破（策略5）和板块轮动（策略6）
+package sector
+
+import (
+	"fmt"
+	"sort"
+	"stock-go/stockData"
+	"sync"
+)
+
+// Model 行业模型：行业成分、等权行业指数和个股动量
+// 第一次查询时计算全部行业指数，之后只读，可被多只票票的信号生成器共用
+// 所有查询在日期 t 只使用 t 及之前的数据
+type Model struct {
+	Industries map[string]string // 票票代码 -> 行业（nil表示从 stockList.csv 加载）
+	MinMembers int               // 行业的最少成分数量，不足的行业不参与排名，默认3
+
+	data map[string]*stockData.StockInfo // 外部注入的票票数据
+
+	once    sync.Once
+	stocks  map[string]*stockData.StockInfo // 有行业分类的票票数据
+	members map[string][]string             // 行业 -> 成分票票代码（排序后）
+	dates   []string                        // 所有交易日（排序后）
+	dateIdx map[string]int                  // 日期 -> dates 下标
+	levels  map[string][]float64            // 行业 -> 每个交易日的指数点位（成分尚未上市时为0）
+
+	mu    sync.Mutex
+	cache map[string][]string // 排名结果缓存
+}
+
+// NewModel 创建行业模型
+func NewModel(industries map[string]string) *Model {
+	return &Model{
+		Industries: industries,
+		MinMembers: 3,
+	}
+}
+
+// SetStockData 注入计算行业指数使用的票票数据（回测引擎通过策略注入）
+func (m *Model) SetStockData(data map[string]*stockData.StockInfo) *Model {
+	m.data = data
+	return m
+}
+
+// prepare 计算行业成分和等权行业指数（只使用注入的票票数据）
+func (m *Model) prepare() {
+	if m.Industries == nil {
+		m.Industries = stockData.LoadIndustryMap()
+	}
+	m.stocks = make(map[string]*stockData.StockInfo)
+	m.members = make(map[string][]string)
+	dateSet := make(map[string]bool)
+	for code, info := range m.data {
+		industry := m.Industries[code]
+		if industry == "" || info == nil || len(info.Datas.DayDatas) == 0 {
+			continue
+		}
+		m.stocks[code] = info
+		m.members[industry] = append(m.members[industry], code)
+		for _, day := range info.Datas.DayDatas {
+			dateSet[day.DataStr] = true
+		}
+	}
+	for industry := range m.members {
+		sort.Strings(m.members[industry])
+	}
+
+	m.dates = make([]string, 0, len(dateSet))
+	for date := range dateSet {
+		m.dates = append(m.dates, date)
+	}
+	sort.Strings(m.dates)
+	m.dateIdx = make(map[string]int, len(m.dates))
+	for i, date := range m.dates {
+		m.dateIdx[date] = i
+	}
+
+	m.levels = make(map[string][]float64, len(m.members))
+	for industry, codes := range m.members {
+		m.levels[industry] = m.buildIndex(codes)
+	}
+	m.cache = make(map[string][]string)
+}
+
+// buildIndex 等权行业指数：每天的涨幅为当天有交易的成分涨幅的平均值，起始点位1000
+func (m *Model) buildIndex(codes []string) []float64 {
+	sumReturn := make([]float64, len(m.dates))
+	count := make([]int, len(m.dates))
+	started := len(m.dates)
+	for _, code := range codes {
+		dayDatas := m.stocks[code].Datas.DayDatas
+		if idx := m.dateIdx[dayDatas[0].DataStr]; idx < started {
+			started = idx
+		}
+		for i := 1; i < len(dayDatas); i++ {
+			prev := float64(dayDatas[i-1].PriceEnd)
+			if prev <= 0 {
+				continue
+			}
+			idx := m.dateIdx[dayDatas[i].DataStr]
+			sumReturn[idx] += float64(dayDatas[i].PriceEnd)/prev - 1
+			count[idx]++
+		}
+	}
+
+	levels := make([]float64, len(m.dates))
+	level := 1000.0
+	for i := started; i < len(m.dates); i++ {
+		if count[i] > 0 {
+			level *= 1 + sumReturn[i]/float64(count[i])
+		}
+		levels[i] = level
+	}
+	return levels
+}
+
+// IndustryOf 获取票票所属行业（没有行业时为空）
+func (m *Model) IndustryOf(code string) string {
+	m.once.Do(m.prepare)
+	if _, ok := m.stocks[code]; !ok {
+		return ""
+	}
+	return m.Industries[code]
+}
+
+// RankedIndustries 参与排名的行业（成分数量不少于 MinMembers，排序后）
+func (m *Model) RankedIndustries() []string {
+	m.once.Do(m.prepare)
+	industries := make([]string, 0, len(m.members))
+	for industry, codes := range m.members {
+		if len(codes) >= m.MinMembers {
+			industries = append(industries, industry)
+		}
+	}
+	sort.Strings(industries)
+	return industries
+}
+
+// IndexLevel 行业指数在指定日期的点位（日期不是交易日或行业尚无数据时返回0）
+func (m *Model) IndexLevel(industry, date string) float64 {
+	m.once.Do(m.prepare)
+	idx, ok := m.dateIdx[date]
+	if !ok || m.levels[industry] == nil {
+		return 0
+	}
+	return m.levels[industry][idx]
+}
+
+// IndustryMomentum 行业指数最近 days 个交易日的涨幅，数据不足时返回false
+func (m *Model) IndustryMomentum(industry, date string, days int) (float64, bool) {
+	window, ok := m.window(industry, date, days+1)
+	if !ok {
+		return 0, false
+	}
+	return window[days]/window[0] - 1, true
+}
+
+// IsBreakout 行业指数是否创 days 个交易日新高（高于之前 days 天的最高点位）
+func (m *Model) IsBreakout(industry, date string, days int) bool {
+	window, ok := m.window(industry, date, days+1)
+	if !ok {
+		return false
+	}
+	for _, level := range window[:days] {
+		if level >= window[days] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsBelowMA 行业指数是否低于 days 日均线，数据不足时返回false
+func (m *Model) IsBelowMA(industry, date string, days int) bool {
+	window, ok := m.window(industry, date, days)
+	if !ok {
+		return false
+	}
+	sum := 0.0
+	for _, level := range window {
+		sum += level
+	}
+	return window[days-1] < sum/float64(days)
+}
+
+// window 行业指数截至指定日期（含）最近 n 个交易日的点位
+func (m *Model) window(industry, date string, n int) ([]float64, bool) {
+	m.once.Do(m.prepare)
+	idx, ok := m.dateIdx[date]
+	levels := m.levels[industry]
+	if !ok || levels == nil || idx+1 < n || levels[idx+1-n] <= 0 {
+		return nil, false
+	}
+	return levels[idx+1-n : idx+1], true
+}
+
+// StockMomentum 票票截至指定日期最近 days 根K线的涨幅，数据不足时返回false
+// 当天停牌时使用之前最近一根K线
+func (m *Model) StockMomentum(code, date string, days int) (float64, bool) {
+	m.once.Do(m.prepare)
+	info := m.stocks[code]
+	if info == nil {
+		return 0, false
+	}
+	dayDatas := info.Datas.DayDatas
+	idx := sort.Search(len(dayDatas), func(i int) bool { return dayDatas[i].DataStr > date }) - 1
+	if idx < days || dayDatas[idx-days].PriceEnd <= 0 {
+		return 0, false
+	}
+	return float64(dayDatas[idx].PriceEnd)/float64(dayDatas[idx-days].PriceEnd) - 1, true
+}
+
+// Leaders 行业内最近 days 根K线涨幅最大的 n 只票票（龙头）
+func (m *Model) Leaders(industry, date string, days, n int) []string {
+	key := fmt.Sprintf("leaders|%s|%s|%d|%d", industry, date, days, n)
+	return m.cached(key, func() []string {
+		return topN(m.members[industry], n, func(code string) (float64, bool) {
+			return m.StockMomentum(code, date, days)
+		})
+	})
+}
+
+// TopIndustries 最近 days 个交易日行业指数涨幅最大的 k 个行业
+func (m *Model) TopIndustries(date string, days, k int) []string {
+	key := fmt.Sprintf("industries|%s|%d|%d", date, days, k)
+	return m.cached(key, func() []string {
+		return topN(m.RankedIndustries(), k, func(industry string) (float64, bool) {
+			return m.IndustryMomentum(industry, date, days)
+		})
+	})
+}
+
+// IsRebalanceDay 是否为调仓日（从第一个交易日起每 every 个交易日一次）
+func (m *Model) IsRebalanceDay(date string, every int) bool {
+	m.once.Do(m.prepare)
+	idx, ok := m.dateIdx[date]
+	return ok && every > 0 && idx%every == 0
+}
+
+// cached 按 key 缓存排名结果
+func (m *Model) cached(key string, compute func() []string) []string {
+	m.once.Do(m.prepare)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if result, ok := m.cache[key]; ok {
+		return result
+	}
+	result := compute()
+	m.cache[key] = result
+	return result
+}
+
+// topN 按得分从高到低取前 n 个（得分相同时按名称排序），没有得分的不参与排名
+func topN(names []string, n int, score func(string) (float64, bool)) []string {
+	type scored struct {
+		name  string
+		score float64
+	}
+	candidates := make([]scored, 0, len(names))
+	for _, name := range names {
+		if s, ok := score(name); ok {
+			candidates = append(candidates, scored{name, s})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	result := make([]string, 0, n)
+	for i := 0; i < len(candidates) && i < n; i++ {
+		result = append(result, candidates[i].name)
+	}
+	return result
+}
+
+// contains 列表中是否包含指定值
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package sector
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// RotationSignal 板块轮动信号生成器（策略6），每个实例绑定一只票票
+// 每 RebalanceEvery 个交易日调仓一次：选出指数涨幅前 TopIndustries 的行业，
+// 每个行业取涨幅前 Leaders 的票票作为目标持仓；目标内的票票买入，不在目标内的持仓卖出
+// 两次调仓之间只检查止损
+type RotationSignal struct {
+	Model            *Model
+	Code             string  // 票票代码
+	IndustryMomentum int     // 行业排名的指数涨幅天数，默认60
+	StockMomentum    int     // 龙头排名的涨幅天数，默认20
+	TopIndustries    int     // 持有的行业数量，默认3
+	Leaders          int     // 每个行业持有的票票数量，默认2
+	RebalanceEvery   int     // 调仓间隔（交易日），默认20
+	StopLoss         float64 // 止损比例，默认0.1，0表示不止损
+
+	exitReason string // 最近一次卖出信号的原因
+}
+
+// NewRotationSignal 创建板块轮动信号生成器
+func NewRotationSignal(model *Model, topIndustries, leaders, rebalanceEvery int) *RotationSignal {
+	return &RotationSignal{
+		Model:            model,
+		IndustryMomentum: 60,
+		StockMomentum:    20,
+		TopIndustries:    topIndustries,
+		Leaders:          leaders,
+		RebalanceEvery:   rebalanceEvery,
+		StopLoss:         0.1,
+	}
+}
+
+// ForCode 创建参数相同、绑定到指定票票的信号生成器
+func (sg *RotationSignal) ForCode(code string) *RotationSignal {
+	bound := *sg
+	bound.Code = code
+	bound.Reset()
+	return &bound
+}
+
+// Reset 重置策略状态
+func (sg *RotationSignal) Reset() {
+	sg.exitReason = ""
+}
+
+// GetHistoryWindow 行业数据由 Model 提供，只需要当天的K线
+func (sg *RotationSignal) GetHistoryWindow() int {
+	return 1
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *RotationSignal) ProcessDay(
+	ctx *stockStrategy.BarContext,
+	position *stockStrategy.Position,
+) int {
+	date := ctx.Date()
+	if position != nil && sg.StopLoss > 0 &&
+		ctx.Price(0) <= float64(position.BuyPrice)*(1-sg.StopLoss) {
+		sg.exitReason = fmt.Sprintf("止损(跌幅%.2f%%)", (1-ctx.Price(0)/float64(position.BuyPrice))*100)
+		return -1
+	}
+	if !sg.Model.IsRebalanceDay(date, sg.RebalanceEvery) {
+		return 0
+	}
+
+	target := sg.IsTarget(date)
+	if position == nil && target {
+		return 1
+	}
+	if position != nil && !target {
+		sg.exitReason = "调仓卖出"
+		return -1
+	}
+	return 0
+}
+
+// IsTarget 票票在指定日期是否属于目标持仓
+func (sg *RotationSignal) IsTarget(date string) bool {
+	industry := sg.Model.IndustryOf(sg.Code)
+	if industry == "" {
+		return false
+	}
+	industries := sg.Model.TopIndustries(date, sg.IndustryMomentum, sg.TopIndustries)
+	if !contains(industries, industry) {
+		return false
+	}
+	return contains(sg.Model.Leaders(industry, date, sg.StockMomentum, sg.Leaders), sg.Code)
+}
+
+// GetExitReason 获取最近一次卖出信号的原因
+func (sg *RotationSignal) GetExitReason() string {
+	return sg.exitReason
+}
+
+// GetStopLossPercent 获取止损比例（供仓位管理器按风险计算仓位）
+func (sg *RotationSignal) GetStopLossPercent() float64 {
+	return sg.StopLoss
+}
+
+// GetName 获取信号生成器名称
+func (sg *RotationSignal) GetName() string {
+	return fmt.Sprintf("板块轮动(%d天涨幅前%d行业,每行业前%d,每%d天调仓)",
+		sg.IndustryMomentum, sg.TopIndustries, sg.Leaders, sg.RebalanceEvery)
+}
@@ -0,0 +1,212 @@
+package sector
+
+import (
+	"math"
+	"reflect"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/signals"
+	"testing"
+	"time"
+)
+
+// newStock 根据收盘价序列构造票票数据，从第 offset 个交易日开始（2016-01-04为第0个，跳过周末）
+func newStock(code string, offset int, prices []float64) *stockData.StockInfo {
+	info := &stockData.StockInfo{Code: code, Name: code}
+	date := time.Date(2016, 1, 4, 0, 0, 0, 0, time.Local)
+	for i := 0; i < offset+len(prices); i++ {
+		for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			date = date.AddDate(0, 0, 1)
+		}
+		if i >= offset {
+			p := float32(prices[i-offset])
+			info.Datas.DayDatas = append(info.Datas.DayDatas, &stockData.StockDataDay{
+				Index:      i - offset + 1,
+				DataStr:    date.Format("2006-01-02"),
+				PriceBegin: p,
+				PriceEnd:   p,
+				PriceHigh:  p,
+				PriceLow:   p,
+			})
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+	return info
+}
+
+// trend 从 start 开始每天涨跌 rate 共 days 天
+func trend(start, rate float64, days int) []float64 {
+	prices := make([]float64, days)
+	for i := range prices {
+		prices[i] = start
+		start *= 1 + rate
+	}
+	return prices
+}
+
+// testModel 两个行业：银行（A强、B弱、C第2天上市）和地产（D、E先跌后涨）
+func testModel() (*Model, map[string]*stockData.StockInfo) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newStock("sz.000001", 0, trend(10, 0.01, 60)),
+		"sz.000002": newStock("sz.000002", 0, trend(20, -0.005, 60)),
+		"sz.000003": newStock("sz.000003", 2, trend(5, 0.002, 58)),
+		"sh.600001": newStock("sh.600001", 0, append(trend(10, -0.01, 30), trend(10*math.Pow(0.99, 30), 0.03, 30)...)),
+		"sh.600002": newStock("sh.600002", 0, append(trend(8, -0.01, 30), trend(8*math.Pow(0.99, 30), 0.02, 30)...)),
+	}
+	industries := map[string]string{
+		"sz.000001": "银行", "sz.000002": "银行", "sz.000003": "银行",
+		"sh.600001": "地产", "sh.600002": "地产",
+	}
+	model := NewModel(industries).SetStockData(data)
+	model.MinMembers = 2
+	return model, data
+}
+
+// barAt 截至第 i 根K线的上下文
+func barAt(info *stockData.StockInfo, i int) *stockStrategy.BarContext {
+	return stockStrategy.NewBarContext(info.Code, info.Datas.DayDatas, i, 1)
+}
+
+func dateOf(data map[string]*stockData.StockInfo, i int) string {
+	return data["sz.000001"].Datas.DayDatas[i].DataStr
+}
+
+// TestIndustryIndex 测试等权行业指数：每天的涨幅为有交易成分涨幅的平均值
+func TestIndustryIndex(t *testing.T) {
+	model, data := testModel()
+
+	if got := model.IndexLevel("银行", dateOf(data, 0)); got != 1000 {
+		t.Errorf("起始点位 %.4f，期望 1000", got)
+	}
+	// 第1天只有A、B：(1%-0.5%)/2
+	want := 1000 * (1 + (0.01-0.005)/2)
+	if got := model.IndexLevel("银行", dateOf(data, 1)); math.Abs(got-want) > 1e-3 {
+		t.Errorf("第1天点位 %.4f，期望 %.4f", got, want)
+	}
+	// 第3天C开始计入：(1%-0.5%+0.2%)/3
+	want *= 1 + (0.01-0.005)/2
+	want *= 1 + (0.01-0.005+0.002)/3
+	if got := model.IndexLevel("银行", dateOf(data, 3)); math.Abs(got-want) > 1e-2 {
+		t.Errorf("第3天点位 %.4f，期望 %.4f", got, want)
+	}
+
+	if model.IndustryOf("sz.000003") != "银行" || model.IndustryOf("sz.999999") != "" {
+		t.Error("行业分类不正确")
+	}
+}
+
+// TestMomentumAndLeaders 测试行业动量、突破和龙头排名
+func TestMomentumAndLeaders(t *testing.T) {
+	model, data := testModel()
+
+	if _, ok := model.IndustryMomentum("银行", dateOf(data, 5), 10); ok {
+		t.Error("数据不足时不应计算行业动量")
+	}
+	if !model.IsBreakout("银行", dateOf(data, 40), 20) {
+		t.Error("银行指数持续上涨，应创20天新高")
+	}
+	if model.IsBreakout("地产", dateOf(data, 25), 20) || !model.IsBelowMA("地产", dateOf(data, 25), 20) {
+		t.Error("地产指数下跌时不应突破且应低于均线")
+	}
+
+	leaders := model.Leaders("银行", dateOf(data, 40), 20, 2)
+	if !reflect.DeepEqual(leaders, []string{"sz.000001", "sz.000003"}) {
+		t.Errorf("银行龙头 %v 不正确", leaders)
+	}
+	if got := model.TopIndustries(dateOf(data, 25), 20, 1); !reflect.DeepEqual(got, []string{"银行"}) {
+		t.Errorf("第25天最强行业 %v，期望银行", got)
+	}
+	if got := model.TopIndustries(dateOf(data, 59), 20, 1); !reflect.DeepEqual(got, []string{"地产"}) {
+		t.Errorf("第59天最强行业 %v，期望地产", got)
+	}
+
+	model.MinMembers = 3
+	if got := model.RankedIndustries(); !reflect.DeepEqual(got, []string{"银行"}) {
+		t.Errorf("成分不足的行业不应参与排名: %v", got)
+	}
+}
+
+// TestModelNoLookahead 测试截断未来数据后，历史日期的指数和排名不变
+func TestModelNoLookahead(t *testing.T) {
+	full, data := testModel()
+	for _, cut := range []int{25, 40} {
+		truncated := make(map[string]*stockData.StockInfo, len(data))
+		for code, info := range data {
+			copied := *info
+			copied.Datas.DayDatas = nil
+			for _, day := range info.Datas.DayDatas {
+				if day.DataStr <= dateOf(data, cut) {
+					copied.Datas.DayDatas = append(copied.Datas.DayDatas, day)
+				}
+			}
+			truncated[code] = &copied
+		}
+		model := NewModel(full.Industries).SetStockData(truncated)
+		model.MinMembers = 2
+
+		date := dateOf(data, cut)
+		if full.IndexLevel("地产", date) != model.IndexLevel("地产", date) {
+			t.Errorf("第%d天地产指数依赖未来数据", cut)
+		}
+		if !reflect.DeepEqual(full.TopIndustries(date, 20, 2), model.TopIndustries(date, 20, 2)) {
+			t.Errorf("第%d天行业排名依赖未来数据", cut)
+		}
+		if !reflect.DeepEqual(full.Leaders("银行", date, 10, 2), model.Leaders("银行", date, 10, 2)) {
+			t.Errorf("第%d天龙头排名依赖未来数据", cut)
+		}
+	}
+}
+
+// TestRotationSignal 测试轮动信号：调仓日买入目标、卖出非目标，其他交易日不操作
+func TestRotationSignal(t *testing.T) {
+	model, data := testModel()
+	sg := NewRotationSignal(model, 1, 1, 20)
+	sg.IndustryMomentum = 20
+	sg.StockMomentum = 20
+
+	bank := sg.ForCode("sz.000001")
+	estate := sg.ForCode("sh.600001")
+	day := func(i int) *stockStrategy.BarContext { return barAt(data["sz.000001"], i) }
+
+	if bank.ProcessDay(day(20), nil) != 1 || estate.ProcessDay(day(20), nil) != 0 {
+		t.Error("第20天应买入银行龙头")
+	}
+	position := &stockStrategy.Position{StockCode: "sz.000001", BuyPrice: data["sz.000001"].Datas.DayDatas[20].PriceBegin}
+	if bank.ProcessDay(day(30), position) != 0 {
+		t.Error("非调仓日不应卖出")
+	}
+	// 第40天地产反弹20天，成为最强行业
+	if bank.ProcessDay(day(40), position) != -1 || bank.GetExitReason() != "调仓卖出" {
+		t.Errorf("第40天应调仓卖出银行，原因 %q", bank.GetExitReason())
+	}
+	if estate.ProcessDay(day(40), nil) != 1 {
+		t.Error("第40天应买入地产龙头")
+	}
+}
+
+// TestBreakoutSignal 测试板块突破信号：行业创新高时只买入龙头
+func TestBreakoutSignal(t *testing.T) {
+	model, data := testModel()
+	sg := NewBreakoutSignal(model, 20, 10, 1, signals.NewDefaultExitRule())
+	day := func(i int) *stockStrategy.BarContext { return barAt(data["sz.000001"], i) }
+
+	if sg.ForCode("sz.000001").ProcessDay(day(40), nil) != 1 {
+		t.Error("银行创新高时应买入龙头")
+	}
+	if sg.ForCode("sz.000002").ProcessDay(day(40), nil) != 0 {
+		t.Error("非龙头不应买入")
+	}
+	if sg.ForCode("sh.600001").ProcessDay(day(20), nil) != 0 {
+		t.Error("地产下跌时不应买入")
+	}
+	if sg.ForCode("sz.999999").ProcessDay(day(40), nil) != 0 {
+		t.Error("没有行业分类的票票不应买入")
+	}
+
+	// 调仓日（第45天）地产龙头跌破行业均线前不卖出，不再是龙头时卖出
+	estate := sg.ForCode("sh.600002")
+	position := &stockStrategy.Position{StockCode: "sh.600002", BuyPrice: data["sh.600002"].Datas.DayDatas[45].PriceBegin}
+	if estate.ProcessDay(barAt(data["sh.600002"], 45), position) != -1 || estate.GetExitReason() != "不再是板块龙头" {
+		t.Errorf("不再是龙头时应卖出，原因 %q", estate.GetExitReason())
+	}
+}
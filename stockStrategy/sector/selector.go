@@ -0,0 +1,49 @@
+package sector
+
+import (
+	"stock-go/stockData"
+)
+
+// Selector 板块选股器：选择有行业分类且截至指定时间点已上市的票票
+// 票票数据由回测引擎通过 SetStockData 注入，未注入时不选择任何票票
+type Selector struct {
+	Model *Model
+
+	data map[string]*stockData.StockInfo
+}
+
+// NewSelector 创建板块选股器
+func NewSelector(model *Model) *Selector {
+	return &Selector{Model: model}
+}
+
+// SetStockData 注入选股使用的票票数据
+func (s *Selector) SetStockData(data map[string]*stockData.StockInfo) {
+	s.data = data
+}
+
+// SelectStocks 选择有行业分类的票票
+// 已废弃，请使用 SelectStocksAtDate
+func (s *Selector) SelectStocks(allCodes []string) []string {
+	return s.SelectStocksAtDate(allCodes, 0)
+}
+
+// SelectStocksAtDate 选择有行业分类且已有 endIndex+1 根K线的票票（只使用 [0, endIndex] 的数据）
+func (s *Selector) SelectStocksAtDate(allCodes []string, endIndex int) []string {
+	selected := make([]string, 0, len(allCodes))
+	for _, code := range allCodes {
+		stock := s.data[code]
+		if stock == nil || endIndex >= len(stock.Datas.DayDatas) {
+			continue
+		}
+		if s.Model.IndustryOf(code) != "" {
+			selected = append(selected, code)
+		}
+	}
+	return selected
+}
+
+// GetName 获取选股器名称
+func (s *Selector) GetName() string {
+	return "有行业分类选股"
+}
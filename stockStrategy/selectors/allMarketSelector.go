@@ -13,6 +13,11 @@ func (s *AllMarketSelector) SelectStocks(allCodes []string) []string {
 	return allCodes
 }
 
+// SelectStocksAtDate 返回所有票票代码（不使用任何数据，不存在未来数据问题）
+func (s *AllMarketSelector) SelectStocksAtDate(allCodes []string, endIndex int) []string {
+	return allCodes
+}
+
 // GetName 获取选股器名称
 func (s *AllMarketSelector) GetName() string {
 	return "全市场"
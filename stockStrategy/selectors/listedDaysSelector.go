@@ -0,0 +1,57 @@
+package selectors
+
+import (
+	"fmt"
+	"stock-go/stockData"
+)
+
+// ListedDaysSelector 上市天数选股器
+// 选择截至指定时间点已有足够K线的票票，排除新股和数据不足以计算指标的票票
+// 票票数据由回测引擎通过 SetStockData 注入，未注入时不选择任何票票
+type ListedDaysSelector struct {
+	MinDays int // 最少K线数量
+
+	data map[string]*stockData.StockInfo
+}
+
+// NewListedDaysSelector 创建上市天数选股器
+func NewListedDaysSelector(minDays int) *ListedDaysSelector {
+	return &ListedDaysSelector{MinDays: minDays}
+}
+
+// SetStockData 注入选股使用的票票数据
+func (s *ListedDaysSelector) SetStockData(data map[string]*stockData.StockInfo) {
+	s.data = data
+}
+
+// SelectStocks 选择数据量足够的票票
+// 已废弃，请使用 SelectStocksAtDate
+func (s *ListedDaysSelector) SelectStocks(allCodes []string) []string {
+	selected := make([]string, 0, len(allCodes))
+	for _, code := range allCodes {
+		if stock := s.data[code]; stock != nil && len(stock.Datas.DayDatas) >= s.MinDays {
+			selected = append(selected, code)
+		}
+	}
+	return selected
+}
+
+// SelectStocksAtDate 选择截至 endIndex 已有至少 MinDays 根K线的票票（只使用 [0, endIndex] 的数据）
+func (s *ListedDaysSelector) SelectStocksAtDate(allCodes []string, endIndex int) []string {
+	selected := make([]string, 0, len(allCodes))
+	for _, code := range allCodes {
+		stock := s.data[code]
+		if stock == nil || endIndex >= len(stock.Datas.DayDatas) {
+			continue
+		}
+		if endIndex+1 >= s.MinDays {
+			selected = append(selected, code)
+		}
+	}
+	return selected
+}
+
+// GetName 获取选股器名称
+func (s *ListedDaysSelector) GetName() string {
+	return fmt.Sprintf("上市%d天以上选股", s.MinDays)
+}
@@ -0,0 +1,120 @@
+package signals
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// BreakoutSignal 突破信号生成器（策略2）
+// 买入条件：当天价格突破过去N天最高价2%以上且高于前一天，均线多头排列（价格>MA5>MA20>MA60），RSI在30~70之间
+// 卖出条件：ExitRule（止盈、止损、移动止损、超时）
+// 只使用 BarContext 中当天及之前的K线，不访问未来数据
+type BreakoutSignal struct {
+	LookbackDays    int      // 突破的回看天数，默认20
+	BreakoutPercent float64  // 突破幅度，默认0.02（2%）
+	RSIPeriod       int      // RSI周期，默认14
+	RSILow          float64  // RSI下限，默认30
+	RSIHigh         float64  // RSI上限，默认70
+	Exit            ExitRule // 卖出规则
+
+	exitReason string // 最近一次卖出信号的原因
+}
+
+// NewBreakoutSignal 创建突破信号生成器
+func NewBreakoutSignal(lookbackDays int, breakoutPercent float64, exit ExitRule) *BreakoutSignal {
+	sg := &BreakoutSignal{
+		LookbackDays:    lookbackDays,
+		BreakoutPercent: breakoutPercent,
+		RSIPeriod:       14,
+		RSILow:          30,
+		RSIHigh:         70,
+		Exit:            exit,
+	}
+	sg.Reset()
+	return sg
+}
+
+// Clone 创建参数相同、状态独立的信号生成器
+func (sg *BreakoutSignal) Clone() *BreakoutSignal {
+	cloned := *sg
+	cloned.Reset()
+	return &cloned
+}
+
+// Reset 重置策略状态（每只票票回测前调用）
+func (sg *BreakoutSignal) Reset() {
+	sg.exitReason = ""
+}
+
+// GetHistoryWindow 需要的历史窗口：均线、RSI和突破回看天数（不含当天）中最长的
+func (sg *BreakoutSignal) GetHistoryWindow() int {
+	return max(trendLongMA, sg.RSIPeriod+1, sg.LookbackDays+1)
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *BreakoutSignal) ProcessDay(
+	ctx *stockStrategy.BarContext,
+	position *stockStrategy.Position,
+) int {
+	currentPrice := float32(ctx.Price(0))
+
+	if position == nil {
+		if sg.isBuySignal(ctx) {
+			return 1
+		}
+		return 0
+	}
+
+	if currentPrice > position.HighestPrice {
+		position.HighestPrice = currentPrice
+	}
+	var sell bool
+	if sell, sg.exitReason = sg.Exit.Check(currentPrice, position); sell {
+		return -1
+	}
+	return 0
+}
+
+// isBuySignal 买入信号：有效突破且趋势向上
+func (sg *BreakoutSignal) isBuySignal(ctx *stockStrategy.BarContext) bool {
+	if ctx.Len() < sg.GetHistoryWindow() {
+		return false
+	}
+	currentPrice := ctx.Price(0)
+
+	// 当天的最高价收盘后才确定，突破只与之前的最高价比较
+	recentHigh := ctx.Shift(1).HighestHigh(sg.LookbackDays)
+	if recentHigh <= 0 || (currentPrice-recentHigh)/recentHigh <= sg.BreakoutPercent {
+		return false
+	}
+
+	if currentPrice <= ctx.Price(1) {
+		return false
+	}
+
+	ma5 := ctx.Average(trendShortMA)
+	ma20 := ctx.Average(trendMidMA)
+	ma60 := ctx.Average(trendLongMA)
+	if !(currentPrice > ma5 && ma5 > ma20 && ma20 > ma60) {
+		return false
+	}
+
+	rsi := relativeStrength(ctx.Prices(sg.RSIPeriod + 1))
+	return rsi > sg.RSILow && rsi < sg.RSIHigh
+}
+
+// GetExitReason 获取最近一次卖出信号的原因
+func (sg *BreakoutSignal) GetExitReason() string {
+	return sg.exitReason
+}
+
+// GetStopLossPercent 获取止损比例（供仓位管理器按风险计算仓位）
+func (sg *BreakoutSignal) GetStopLossPercent() float64 {
+	return sg.Exit.StopLoss
+}
+
+// GetName 获取信号生成器名称
+func (sg *BreakoutSignal) GetName() string {
+	return fmt.Sprintf("突破(%d天高点+%.1f%%,%s)",
+		sg.LookbackDays, sg.BreakoutPercent*100, sg.Exit)
+}
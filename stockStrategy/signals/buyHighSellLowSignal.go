@@ -2,20 +2,17 @@ package signals
 
 import (
 	"fmt"
-	"stock-go/stockData"
 	"stock-go/stockStrategy"
 )
 
 // BuyHighSellLowSignal 追涨杀跌信号生成器
 // 买入条件：价格达到过去N天的最高价
 // 卖出条件：止损或超过最大持有天数
+// 历史价格从 BarContext 获取，没有内部状态，可被多只票票共用
 type BuyHighSellLowSignal struct {
 	LookbackDays    int     // 回看天数，默认300
 	SellDropPercent float64 // 止损百分比，默认0.06（6%）
 	MaxHoldDays     int     // 最大持有天数，默认30
-
-	// 内部状态(防止未来函数)
-	historyPrices []float32 // 历史价格队列
 }
 
 // NewBuyHighSellLowSignal 创建追涨杀跌信号生成器
@@ -24,48 +21,43 @@ func NewBuyHighSellLowSignal(lookbackDays int, sellDropPercent float64, maxHoldD
 		LookbackDays:    lookbackDays,
 		SellDropPercent: sellDropPercent,
 		MaxHoldDays:     maxHoldDays,
-		historyPrices:   make([]float32, 0, 500),
 	}
 }
 
-// Reset 重置策略状态（每只票票回测前调用）
-func (sg *BuyHighSellLowSignal) Reset() {
-	sg.historyPrices = sg.historyPrices[:0] // 清空历史价格
+// Reset 重置策略状态（没有内部状态）
+func (sg *BuyHighSellLowSignal) Reset() {}
+
+// GetHistoryWindow 需要的历史窗口：回看天数加当天
+func (sg *BuyHighSellLowSignal) GetHistoryWindow() int {
+	return sg.LookbackDays + 1
 }
 
 // ProcessDay 处理单日数据，返回交易信号
 func (sg *BuyHighSellLowSignal) ProcessDay(
-	dayData *stockData.StockDataDay,
-	dateIndex int,
+	ctx *stockStrategy.BarContext,
 	position *stockStrategy.Position,
 ) int {
-	currentPrice := dayData.PriceBegin
+	currentPrice := float32(ctx.Price(0))
 
-	// 1. 数据不足，先累积数据
-	if len(sg.historyPrices) < sg.LookbackDays {
-		sg.historyPrices = append(sg.historyPrices, currentPrice)
+	// 1. 数据不足（当天之前不足回看天数）
+	if ctx.Len() <= sg.LookbackDays {
 		return 0
 	}
 
 	// 2. 判断买入信号(空仓时)
 	if position == nil {
-		if sg.isBuySignal(currentPrice) {
-			sg.historyPrices = append(sg.historyPrices, currentPrice)
+		if sg.isBuySignal(ctx) {
 			return 1 // 买入
 		}
-		sg.historyPrices = append(sg.historyPrices, currentPrice)
 		return 0
 	}
 
-	// 3. 更新历史价格队列
-	sg.historyPrices = append(sg.historyPrices, currentPrice)
-
-	// 4. 更新持仓的最高价
+	// 3. 更新持仓的最高价
 	if currentPrice > position.HighestPrice {
 		position.HighestPrice = currentPrice
 	}
 
-	// 5. 判断卖出信号(持仓时)
+	// 4. 判断卖出信号(持仓时)
 	if sg.isSellSignal(currentPrice, position) {
 		return -1 // 卖出
 	}
@@ -75,28 +67,12 @@ func (sg *BuyHighSellLowSignal) ProcessDay(
 
 // isBuySignal 买入信号：当天价格达到过去N天的最高价
 // 逻辑：判断当天价格是否达到或超过过去N天（不包括今天）的最高价
-func (sg *BuyHighSellLowSignal) isBuySignal(currentPrice float32) bool {
-	historyLen := len(sg.historyPrices)
-
-	// 数据不足（至少需要回看天数的数据）
-	if historyLen < sg.LookbackDays {
-		return false
-	}
-
-	// 计算回看窗口的起始位置（从过去N天开始）
-	startIndex := historyLen - sg.LookbackDays
-
-	// 找出回看窗口内的最高价（不包括今天）
-	var highestPrice float32 = 0
-	for i := startIndex; i < historyLen; i++ {
-		if sg.historyPrices[i] > highestPrice {
-			highestPrice = sg.historyPrices[i]
-		}
-	}
+func (sg *BuyHighSellLowSignal) isBuySignal(ctx *stockStrategy.BarContext) bool {
+	highestPrice := float32(ctx.Shift(1).Highest(sg.LookbackDays))
 
 	// 判断今天的价格是否达到或超过过去的最高价
 	// 允许0.5%误差，当天创新高则买入
-	return currentPrice >= highestPrice*0.995
+	return float32(ctx.Price(0)) >= highestPrice*0.995
 }
 
 // isSellSignal 卖出信号：止损或超过最大持有天数
@@ -123,6 +99,11 @@ func (sg *BuyHighSellLowSignal) isSellSignal(currentPrice float32, position *sto
 	return false
 }
 
+// GetStopLossPercent 获取止损比例（供仓位管理器按风险计算仓位）
+func (sg *BuyHighSellLowSignal) GetStopLossPercent() float64 {
+	return sg.SellDropPercent
+}
+
 // GetName 获取信号生成器名称
 func (sg *BuyHighSellLowSignal) GetName() string {
 	return fmt.Sprintf("追涨杀跌(%d天新高,止损%.1f%%,最多持有%d天)",
@@ -0,0 +1,56 @@
+package signals
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// ExitRule 实时卖出规则：止盈、止损、移动止损和最大持有天数
+// 替代旧策略中查看未来数据的 findSellPriceOptimized，每天只根据当天价格判断
+type ExitRule struct {
+	TakeProfit       float64 // 止盈比例，默认0.15（15%）
+	StopLoss         float64 // 止损比例，默认0.04（4%）
+	TrailingActivate float64 // 最高价涨幅超过该比例后启用移动止损，默认0.05
+	TrailingStop     float64 // 移动止损的回撤比例，默认0.05
+	MaxHoldDays      int     // 最大持有天数，默认40
+}
+
+// NewDefaultExitRule 默认卖出规则（与旧策略2、3的参数一致）
+func NewDefaultExitRule() ExitRule {
+	return ExitRule{
+		TakeProfit:       0.15,
+		StopLoss:         0.04,
+		TrailingActivate: 0.05,
+		TrailingStop:     0.05,
+		MaxHoldDays:      40,
+	}
+}
+
+// Check 判断是否卖出，返回是否卖出和卖出原因
+// position.HighestPrice 需已包含当天价格
+func (r ExitRule) Check(currentPrice float32, position *stockStrategy.Position) (bool, string) {
+	buyPrice := float64(position.BuyPrice)
+	price := float64(currentPrice)
+	highestPrice := float64(position.HighestPrice)
+
+	if r.TakeProfit > 0 && price >= buyPrice*(1+r.TakeProfit) {
+		return true, fmt.Sprintf("止盈(涨幅%.2f%%)", (price/buyPrice-1)*100)
+	}
+	if r.StopLoss > 0 && price <= buyPrice*(1-r.StopLoss) {
+		return true, fmt.Sprintf("止损(跌幅%.2f%%)", (1-price/buyPrice)*100)
+	}
+	if r.TrailingStop > 0 && highestPrice > buyPrice*(1+r.TrailingActivate) &&
+		price <= highestPrice*(1-r.TrailingStop) {
+		return true, fmt.Sprintf("移动止损(回撤%.2f%%)", (1-price/highestPrice)*100)
+	}
+	if r.MaxHoldDays > 0 && position.HoldDays >= r.MaxHoldDays {
+		return true, fmt.Sprintf("持有超过%d天", r.MaxHoldDays)
+	}
+	return false, ""
+}
+
+// String 卖出规则的可读表示
+func (r ExitRule) String() string {
+	return fmt.Sprintf("止盈%.0f%%,止损%.0f%%,移动止损%.0f%%,最多持有%d天",
+		r.TakeProfit*100, r.StopLoss*100, r.TrailingStop*100, r.MaxHoldDays)
+}
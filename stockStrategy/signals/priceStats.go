@@ -0,0 +1,56 @@
+package signals
+
+import "math"
+
+// 均线周期（策略2、3共用）
+const (
+	trendShortMA = 5
+	trendMidMA   = 20
+	trendLongMA  = 60
+)
+
+// average 平均值
+func average(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// populationStdDev 总体标准差
+func populationStdDev(values []float64, mean float64) float64 {
+	sumSquares := 0.0
+	for _, v := range values {
+		sumSquares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sumSquares / float64(len(values)))
+}
+
+// relativeStrength 相对强弱指标RSI，closes 为最近 period+1 个收盘价
+func relativeStrength(closes []float64) float64 {
+	gains, losses := 0.0, 0.0
+	for i := 1; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		if change > 0 {
+			gains += change
+		} else {
+			losses -= change
+		}
+	}
+	if losses == 0 {
+		return 100
+	}
+	return 100 - 100/(1+gains/losses)
+}
+
+// downRatio 下跌天数占比，closes 为最近 period+1 个收盘价
+func downRatio(closes []float64) float64 {
+	downDays := 0
+	for i := 1; i < len(closes); i++ {
+		if closes[i] < closes[i-1] {
+			downDays++
+		}
+	}
+	return float64(downDays) / float64(len(closes)-1)
+}
@@ -0,0 +1,167 @@
+package signals
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockData/synthetic"
+	"stock-go/stockStrategy"
+	"testing"
+)
+
+// runBars 逐日运行信号生成器，模拟买入后持仓、卖出后空仓
+// 返回买入和卖出的数据索引，以及卖出原因
+func runBars(gen stockStrategy.SignalGenerator, bars []*stockData.StockDataDay) (buys, sells []int, reasons []string) {
+	gen.Reset()
+	window := stockStrategy.HistoryWindow(gen)
+	var position *stockStrategy.Position
+	for i, bar := range bars {
+		switch gen.ProcessDay(stockStrategy.NewBarContext("sz.000001", bars, i, window), position) {
+		case 1:
+			buys = append(buys, i)
+			position = &stockStrategy.Position{BuyPrice: bar.PriceBegin, BuyIndex: i, HighestPrice: bar.PriceBegin}
+		case -1:
+			sells = append(sells, i)
+			if provider, ok := gen.(stockStrategy.ExitReasonProvider); ok {
+				reasons = append(reasons, provider.GetExitReason())
+			}
+			position = nil
+		default:
+			if position != nil {
+				position.HoldDays++
+			}
+		}
+	}
+	return buys, sells, reasons
+}
+
+// breakoutPrices 80天震荡上涨，第80天向上突破，之后每天上涨1%
+func breakoutPrices() []float64 {
+	prices := make([]float64, 0, 100)
+	for i := 0; i < 80; i++ {
+		noise := 0.1
+		if i%2 == 1 {
+			noise = -0.1
+		}
+		prices = append(prices, 10+0.02*float64(i)+noise)
+	}
+	price := 12.1
+	for i := 80; i < 100; i++ {
+		prices = append(prices, price)
+		price *= 1.01
+	}
+	return prices
+}
+
+// swingPrices 100天在10附近震荡，之后连续下跌8天，再每天上涨1%
+func swingPrices() []float64 {
+	prices := make([]float64, 0, 140)
+	for i := 0; i < 100; i++ {
+		noise := 0.1
+		if i%2 == 1 {
+			noise = -0.1
+		}
+		prices = append(prices, 10+noise)
+	}
+	price := 10.0
+	for i := 100; i < 108; i++ {
+		price *= 0.985
+		prices = append(prices, price)
+	}
+	for i := 108; i < 140; i++ {
+		price *= 1.01
+		prices = append(prices, price)
+	}
+	return prices
+}
+
+// TestBreakoutSignal 测试突破当天买入、达到止盈后卖出
+func TestBreakoutSignal(t *testing.T) {
+	gen := NewBreakoutSignal(20, 0.02, NewDefaultExitRule())
+	buys, sells, reasons := runBars(gen, synthetic.Bars(breakoutPrices()))
+
+	if len(buys) != 1 || buys[0] != 80 {
+		t.Fatalf("买入索引 %v，期望 [80]", buys)
+	}
+	// 每天上涨1%，第15天涨幅超过15%
+	if len(sells) != 1 || sells[0] != 95 {
+		t.Fatalf("卖出索引 %v，期望 [95]", sells)
+	}
+	if reasons[0] != "止盈(涨幅16.10%)" {
+		t.Errorf("卖出原因 %s 不正确", reasons[0])
+	}
+}
+
+// TestSwingSignal 测试超跌后买入、反弹止盈卖出
+func TestSwingSignal(t *testing.T) {
+	gen := NewSwingSignal(60, 0.08, NewDefaultExitRule())
+	buys, sells, _ := runBars(gen, synthetic.Bars(swingPrices()))
+
+	if len(buys) != 1 || buys[0] < 100 || buys[0] >= 108 {
+		t.Fatalf("买入索引 %v，期望在下跌期间 [100, 108) 买入一次", buys)
+	}
+	if len(sells) != 1 || sells[0] <= buys[0] {
+		t.Fatalf("卖出索引 %v 不正确", sells)
+	}
+}
+
+// TestSignalResetIsolatesState 测试 Reset 和 Clone 后状态互不影响
+func TestSignalResetIsolatesState(t *testing.T) {
+	gen := NewBreakoutSignal(20, 0.02, NewDefaultExitRule())
+	bars := synthetic.Bars(breakoutPrices())
+	first, _, _ := runBars(gen, bars)
+
+	cloned := gen.Clone()
+	second, _, _ := runBars(cloned, bars)
+	if len(first) != len(second) || first[0] != second[0] {
+		t.Errorf("Clone 后信号 %v 与原信号 %v 不一致", second, first)
+	}
+}
+
+// TestExitRule 测试止盈、止损、移动止损和超时卖出
+func TestExitRule(t *testing.T) {
+	rule := NewDefaultExitRule()
+	tests := []struct {
+		name     string
+		price    float32
+		highest  float32
+		holdDays int
+		sell     bool
+	}{
+		{"持有", 10.2, 10.3, 5, false},
+		{"止盈", 11.5, 11.5, 5, true},
+		{"止损", 9.5, 10, 5, true},
+		{"移动止损", 10.4, 11, 5, true},
+		{"未启用移动止损", 9.8, 10.4, 5, false},
+		{"超时", 10.2, 10.3, 40, true},
+	}
+	for _, tt := range tests {
+		position := &stockStrategy.Position{BuyPrice: 10, HighestPrice: tt.highest, HoldDays: tt.holdDays}
+		sell, reason := rule.Check(tt.price, position)
+		if sell != tt.sell {
+			t.Errorf("%s: 卖出 %v (%s)，期望 %v", tt.name, sell, reason, tt.sell)
+		}
+	}
+}
+
+// TestSharedSignalAcrossStocks 测试同一个信号生成器交替处理多只票票时互不影响
+// 历史价格来自 BarContext，不再保存在信号生成器中
+func TestSharedSignalAcrossStocks(t *testing.T) {
+	gen := NewBuyHighSellLowSignal(20, 0.06, 30)
+	rising := synthetic.Bars(breakoutPrices())
+	flat := synthetic.Bars(swingPrices()[:100])
+	expected, _, _ := runBars(gen, rising)
+
+	var buys []int
+	for i := range rising {
+		if gen.ProcessDay(stockStrategy.NewBarContext("sz.000001", rising, i, 21), nil) == 1 {
+			buys = append(buys, i)
+		}
+		// 交替处理另一只横盘票票
+		if i < len(flat) {
+			gen.ProcessDay(stockStrategy.NewBarContext("sz.000002", flat, i, 21), nil)
+		}
+	}
+	// runBars 买入后持仓不再产生买入信号，只比较第一次买入
+	if len(buys) == 0 || len(expected) == 0 || buys[0] != expected[0] {
+		t.Errorf("交替处理时买入 %v，单独处理时买入 %v", buys, expected)
+	}
+}
@@ -0,0 +1,123 @@
+package signals
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// SwingSignal 波段信号生成器（策略3）
+// 买入条件：价格跌破布林带下轨（MA20-2倍标准差）且低于N天均价8%以上，RSI<35，
+// MA20不高于MA60的105%，最近10天下跌天数超过70%
+// 卖出条件：ExitRule（止盈、止损、移动止损、超时）
+// 只使用 BarContext 中当天及之前的K线，不访问未来数据
+type SwingSignal struct {
+	WindowDays      int      // 计算均价和标准差的天数（不含当天），默认60
+	DiscountPercent float64  // 低于均价的比例，默认0.08（8%）
+	RSIPeriod       int      // RSI周期，默认14
+	RSIMax          float64  // RSI上限，默认35
+	OversoldDays    int      // 判断超卖的天数，默认10
+	OversoldRatio   float64  // 超卖的下跌天数占比，默认0.7
+	Exit            ExitRule // 卖出规则
+
+	exitReason string // 最近一次卖出信号的原因
+}
+
+// NewSwingSignal 创建波段信号生成器
+func NewSwingSignal(windowDays int, discountPercent float64, exit ExitRule) *SwingSignal {
+	sg := &SwingSignal{
+		WindowDays:      windowDays,
+		DiscountPercent: discountPercent,
+		RSIPeriod:       14,
+		RSIMax:          35,
+		OversoldDays:    10,
+		OversoldRatio:   0.7,
+		Exit:            exit,
+	}
+	sg.Reset()
+	return sg
+}
+
+// Clone 创建参数相同、状态独立的信号生成器
+func (sg *SwingSignal) Clone() *SwingSignal {
+	cloned := *sg
+	cloned.Reset()
+	return &cloned
+}
+
+// Reset 重置策略状态（每只票票回测前调用）
+func (sg *SwingSignal) Reset() {
+	sg.exitReason = ""
+}
+
+// GetHistoryWindow 需要的历史窗口：均价、均线、RSI和超卖天数中最长的
+func (sg *SwingSignal) GetHistoryWindow() int {
+	return max(sg.WindowDays+1, trendLongMA, sg.RSIPeriod+1, sg.OversoldDays+1)
+}
+
+// ProcessDay 处理单日数据，返回交易信号
+func (sg *SwingSignal) ProcessDay(
+	ctx *stockStrategy.BarContext,
+	position *stockStrategy.Position,
+) int {
+	currentPrice := float32(ctx.Price(0))
+
+	if position == nil {
+		if sg.isBuySignal(ctx) {
+			return 1
+		}
+		return 0
+	}
+
+	if currentPrice > position.HighestPrice {
+		position.HighestPrice = currentPrice
+	}
+	var sell bool
+	if sell, sg.exitReason = sg.Exit.Check(currentPrice, position); sell {
+		return -1
+	}
+	return 0
+}
+
+// isBuySignal 买入信号：超跌到布林带下轨以下且趋势没有明显走强
+func (sg *SwingSignal) isBuySignal(ctx *stockStrategy.BarContext) bool {
+	if ctx.Len() < sg.GetHistoryWindow() {
+		return false
+	}
+	currentPrice := ctx.Price(0)
+
+	// 均价和标准差不含当天
+	window := ctx.Shift(1).Prices(sg.WindowDays)
+	mean := average(window)
+	stdDev := populationStdDev(window, mean)
+
+	ma20 := ctx.Average(trendMidMA)
+	ma60 := ctx.Average(trendLongMA)
+	lowerBollinger := ma20 - 2*stdDev
+
+	if currentPrice > lowerBollinger || currentPrice >= mean*(1-sg.DiscountPercent) {
+		return false
+	}
+	if ma20 >= ma60*1.05 {
+		return false
+	}
+	if relativeStrength(ctx.Prices(sg.RSIPeriod+1)) >= sg.RSIMax {
+		return false
+	}
+	return downRatio(ctx.Prices(sg.OversoldDays+1)) > sg.OversoldRatio
+}
+
+// GetExitReason 获取最近一次卖出信号的原因
+func (sg *SwingSignal) GetExitReason() string {
+	return sg.exitReason
+}
+
+// GetStopLossPercent 获取止损比例（供仓位管理器按风险计算仓位）
+func (sg *SwingSignal) GetStopLossPercent() float64 {
+	return sg.Exit.StopLoss
+}
+
+// GetName 获取信号生成器名称
+func (sg *SwingSignal) GetName() string {
+	return fmt.Sprintf("波段(%d天均价-%.0f%%,%s)",
+		sg.WindowDays, sg.DiscountPercent*100, sg.Exit)
+}
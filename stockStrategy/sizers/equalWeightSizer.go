@@ -0,0 +1,30 @@
+package sizers
+
+import "stock-go/stockStrategy"
+
+// EqualWeightSizer 等权仓位
+// 总资产平均分配给 MaxPositions 个持仓
+type EqualWeightSizer struct{}
+
+// NewEqualWeightSizer 创建等权仓位管理器
+func NewEqualWeightSizer() *EqualWeightSizer {
+	return &EqualWeightSizer{}
+}
+
+// Size 计算买入股数
+func (s *EqualWeightSizer) Size(ctx *stockStrategy.SizingContext) int {
+	if ctx.MaxPositions <= 0 {
+		return 0
+	}
+
+	cashToUse := ctx.TotalAssets / float64(ctx.MaxPositions)
+	if cashToUse > ctx.Cash {
+		cashToUse = ctx.Cash
+	}
+	return SharesForCash(ctx.Code, cashToUse, ctx.Price)
+}
+
+// GetName 获取仓位管理器名称
+func (s *EqualWeightSizer) GetName() string {
+	return "等权"
+}
@@ -0,0 +1,33 @@
+package sizers
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// FixedFractionSizer 固定资金比例仓位
+// 每次买入使用总资产的固定比例，不超过可用现金
+type FixedFractionSizer struct {
+	Fraction float64 // 每个持仓占总资产的比例（0-1）
+}
+
+// NewFixedFractionSizer 创建固定资金比例仓位管理器
+func NewFixedFractionSizer(fraction float64) *FixedFractionSizer {
+	return &FixedFractionSizer{
+		Fraction: fraction,
+	}
+}
+
+// Size 计算买入股数
+func (s *FixedFractionSizer) Size(ctx *stockStrategy.SizingContext) int {
+	cashToUse := ctx.TotalAssets * s.Fraction
+	if cashToUse > ctx.Cash {
+		cashToUse = ctx.Cash
+	}
+	return SharesForCash(ctx.Code, cashToUse, ctx.Price)
+}
+
+// GetName 获取仓位管理器名称
+func (s *FixedFractionSizer) GetName() string {
+	return fmt.Sprintf("固定比例(%.0f%%)", s.Fraction*100)
+}
@@ -0,0 +1,52 @@
+package sizers
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// FixedRiskSizer 固定风险仓位
+// 每笔交易触发止损时的亏损不超过总资产的 RiskPercent
+// 股数 = 总资产 * RiskPercent / (价格 * 止损比例)
+type FixedRiskSizer struct {
+	RiskPercent        float64 // 单笔交易风险占总资产的比例，如0.01表示1%
+	DefaultStopPercent float64 // 信号生成器未提供止损比例时使用的止损比例
+	MaxFraction        float64 // 单个持仓占总资产的上限比例（0-1）
+}
+
+// NewFixedRiskSizer 创建固定风险仓位管理器
+func NewFixedRiskSizer(riskPercent, defaultStopPercent, maxFraction float64) *FixedRiskSizer {
+	return &FixedRiskSizer{
+		RiskPercent:        riskPercent,
+		DefaultStopPercent: defaultStopPercent,
+		MaxFraction:        maxFraction,
+	}
+}
+
+// Size 计算买入股数
+func (s *FixedRiskSizer) Size(ctx *stockStrategy.SizingContext) int {
+	stopPercent := ctx.StopPercent
+	if stopPercent <= 0 {
+		stopPercent = s.DefaultStopPercent
+	}
+	if stopPercent <= 0 || ctx.Price <= 0 {
+		return 0
+	}
+
+	// 每股风险 = 价格 * 止损比例
+	riskPerShare := ctx.Price * stopPercent
+	cashToUse := ctx.TotalAssets * s.RiskPercent / riskPerShare * ctx.Price
+
+	if s.MaxFraction > 0 && cashToUse > ctx.TotalAssets*s.MaxFraction {
+		cashToUse = ctx.TotalAssets * s.MaxFraction
+	}
+	if cashToUse > ctx.Cash {
+		cashToUse = ctx.Cash
+	}
+	return SharesForCash(ctx.Code, cashToUse, ctx.Price)
+}
+
+// GetName 获取仓位管理器名称
+func (s *FixedRiskSizer) GetName() string {
+	return fmt.Sprintf("固定风险(%.1f%%)", s.RiskPercent*100)
+}
@@ -0,0 +1,68 @@
+package sizers
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// KellySizer 分数凯利仓位
+// 凯利比例 f = W - (1-W)/R，其中 W 为胜率，R 为平均盈利/平均亏损
+// 实际使用 f * KellyFraction，并限制在 MaxFraction 以内
+// 已完成交易不足 MinTrades 时使用 FallbackFraction
+type KellySizer struct {
+	KellyFraction    float64 // 凯利比例的使用系数，如0.5表示半凯利
+	MinTrades        int     // 使用凯利公式所需的最少已完成交易数
+	FallbackFraction float64 // 交易样本不足时使用的资金比例
+	MaxFraction      float64 // 单个持仓占总资产的上限比例（0-1）
+}
+
+// NewKellySizer 创建分数凯利仓位管理器
+func NewKellySizer(kellyFraction float64, minTrades int, fallbackFraction, maxFraction float64) *KellySizer {
+	return &KellySizer{
+		KellyFraction:    kellyFraction,
+		MinTrades:        minTrades,
+		FallbackFraction: fallbackFraction,
+		MaxFraction:      maxFraction,
+	}
+}
+
+// Size 计算买入股数
+func (s *KellySizer) Size(ctx *stockStrategy.SizingContext) int {
+	fraction := s.FallbackFraction
+	if ctx.Stats.ClosedTrades >= s.MinTrades {
+		fraction = KellyFraction(ctx.Stats) * s.KellyFraction
+	}
+
+	if s.MaxFraction > 0 && fraction > s.MaxFraction {
+		fraction = s.MaxFraction
+	}
+	if fraction <= 0 {
+		return 0 // 期望为负，不开仓
+	}
+
+	cashToUse := ctx.TotalAssets * fraction
+	if cashToUse > ctx.Cash {
+		cashToUse = ctx.Cash
+	}
+	return SharesForCash(ctx.Code, cashToUse, ctx.Price)
+}
+
+// GetName 获取仓位管理器名称
+func (s *KellySizer) GetName() string {
+	return fmt.Sprintf("凯利(x%.2f)", s.KellyFraction)
+}
+
+// KellyFraction 根据交易统计计算完整凯利比例
+// 没有亏损样本时返回胜率，没有盈利样本时返回0
+func KellyFraction(stats stockStrategy.TradeStats) float64 {
+	winRate := stats.WinRate()
+	if stats.AvgLossPct <= 0 {
+		return winRate
+	}
+	if stats.AvgWinPct <= 0 {
+		return 0
+	}
+
+	payoff := stats.AvgWinPct / stats.AvgLossPct
+	return winRate - (1-winRate)/payoff
+}
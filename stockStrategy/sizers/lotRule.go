@@ -0,0 +1,49 @@
+package sizers
+
+import "strings"
+
+// LotRule 交易单位规则
+// 主板/创业板：最少100股，按100股递增
+// 科创板(688)：最少200股，超出部分按1股递增
+// 北交所(bj)：最少100股，超出部分按1股递增
+type LotRule struct {
+	MinShares int // 最少买入股数
+	Step      int // 超出最少股数后的递增单位
+}
+
+// GetLotRule 根据票票代码获取交易单位规则
+func GetLotRule(code string) LotRule {
+	pureCode := code
+	if idx := strings.Index(code, "."); idx >= 0 {
+		pureCode = code[idx+1:]
+	}
+
+	if strings.HasPrefix(code, "bj.") {
+		return LotRule{MinShares: 100, Step: 1}
+	}
+	if strings.HasPrefix(pureCode, "688") {
+		return LotRule{MinShares: 200, Step: 1}
+	}
+	return LotRule{MinShares: 100, Step: 100}
+}
+
+// RoundDown 将股数向下取整到合法的交易数量，不足最少股数时返回0
+func (r LotRule) RoundDown(shares int) int {
+	if shares < r.MinShares {
+		return 0
+	}
+	return r.MinShares + (shares-r.MinShares)/r.Step*r.Step
+}
+
+// Reduce 在当前股数基础上减少一个递增单位，不足最少股数时返回0
+func (r LotRule) Reduce(shares int) int {
+	return r.RoundDown(shares - r.Step)
+}
+
+// SharesForCash 计算指定资金按交易单位能买入的股数
+func SharesForCash(code string, cash, price float64) int {
+	if cash <= 0 || price <= 0 {
+		return 0
+	}
+	return GetLotRule(code).RoundDown(int(cash / price))
+}
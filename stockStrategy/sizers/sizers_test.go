@@ -0,0 +1,174 @@
+package sizers
+
+import (
+	"math"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"testing"
+)
+
+// TestLotRule 测试交易单位规则
+func TestLotRule(t *testing.T) {
+	cases := []struct {
+		code   string
+		shares int
+		want   int
+	}{
+		{"sz.000001", 99, 0},
+		{"sz.000001", 250, 200},
+		{"sh.600000", 1000, 1000},
+		{"sh.688001", 199, 0},
+		{"sh.688001", 257, 257},
+		{"bj.830799", 123, 123},
+	}
+
+	for _, c := range cases {
+		got := GetLotRule(c.code).RoundDown(c.shares)
+		if got != c.want {
+			t.Errorf("%s RoundDown(%d) = %d, 期望 %d", c.code, c.shares, got, c.want)
+		}
+	}
+
+	// 科创板减少一个单位后不足200股时应返回0
+	if got := GetLotRule("sh.688001").Reduce(200); got != 0 {
+		t.Errorf("科创板 Reduce(200) = %d, 期望 0", got)
+	}
+}
+
+// TestFixedFractionSizer 测试固定比例仓位
+func TestFixedFractionSizer(t *testing.T) {
+	sizer := NewFixedFractionSizer(0.5)
+	ctx := &stockStrategy.SizingContext{
+		Code:        "sz.000001",
+		Price:       10,
+		Cash:        1000000,
+		TotalAssets: 1000000,
+	}
+
+	if got := sizer.Size(ctx); got != 50000 {
+		t.Errorf("固定比例50%%买入 %d 股, 期望 50000", got)
+	}
+
+	// 现金不足时以现金为上限
+	ctx.Cash = 100000
+	if got := sizer.Size(ctx); got != 10000 {
+		t.Errorf("现金不足时买入 %d 股, 期望 10000", got)
+	}
+}
+
+// TestEqualWeightSizer 测试等权仓位
+func TestEqualWeightSizer(t *testing.T) {
+	sizer := NewEqualWeightSizer()
+	ctx := &stockStrategy.SizingContext{
+		Code:         "sz.000001",
+		Price:        10,
+		Cash:         1000000,
+		TotalAssets:  1000000,
+		MaxPositions: 4,
+	}
+
+	if got := sizer.Size(ctx); got != 25000 {
+		t.Errorf("等权4仓买入 %d 股, 期望 25000", got)
+	}
+}
+
+// TestFixedRiskSizer 测试固定风险仓位
+func TestFixedRiskSizer(t *testing.T) {
+	sizer := NewFixedRiskSizer(0.01, 0.05, 1)
+	ctx := &stockStrategy.SizingContext{
+		Code:        "sz.000001",
+		Price:       10,
+		Cash:        1000000,
+		TotalAssets: 1000000,
+		StopPercent: 0.1,
+	}
+
+	// 风险资金 10000，每股风险 1 元，买入 10000 股
+	if got := sizer.Size(ctx); got != 10000 {
+		t.Errorf("止损10%%时买入 %d 股, 期望 10000", got)
+	}
+
+	// 未提供止损比例时使用默认值5%，每股风险0.5元
+	ctx.StopPercent = 0
+	if got := sizer.Size(ctx); got != 20000 {
+		t.Errorf("默认止损5%%时买入 %d 股, 期望 20000", got)
+	}
+}
+
+// TestVolatilitySizer 测试波动率目标仓位
+func TestVolatilitySizer(t *testing.T) {
+	// 每天最高11、最低9、收盘10，ATR=2
+	history := make([]*stockData.StockDataDay, 0, 30)
+	for i := 0; i < 30; i++ {
+		history = append(history, &stockData.StockDataDay{
+			PriceHigh: 11,
+			PriceLow:  9,
+			PriceEnd:  10,
+		})
+	}
+
+	if atr := CalculateATR(history, 20); math.Abs(atr-2) > 1e-9 {
+		t.Fatalf("ATR = %.4f, 期望 2", atr)
+	}
+
+	sizer := NewVolatilitySizer(20, 0.01, 1)
+	ctx := &stockStrategy.SizingContext{
+		Code:        "sz.000001",
+		Price:       10,
+		Cash:        1000000,
+		TotalAssets: 1000000,
+		History:     history,
+	}
+
+	// 目标日波动 10000 元，ATR 2 元，买入 5000 股
+	if got := sizer.Size(ctx); got != 5000 {
+		t.Errorf("波动率目标买入 %d 股, 期望 5000", got)
+	}
+
+	// 历史数据不足时不买入
+	ctx.History = history[:10]
+	if got := sizer.Size(ctx); got != 0 {
+		t.Errorf("历史数据不足时买入 %d 股, 期望 0", got)
+	}
+}
+
+// TestKellySizer 测试分数凯利仓位
+func TestKellySizer(t *testing.T) {
+	stats := stockStrategy.TradeStats{
+		ClosedTrades: 20,
+		WinCount:     10,
+		AvgWinPct:    0.10,
+		AvgLossPct:   0.05,
+	}
+
+	// W=0.5, R=2, f = 0.5 - 0.5/2 = 0.25
+	if f := KellyFraction(stats); math.Abs(f-0.25) > 1e-9 {
+		t.Fatalf("凯利比例 = %.4f, 期望 0.25", f)
+	}
+
+	sizer := NewKellySizer(0.5, 10, 0.1, 0.5)
+	ctx := &stockStrategy.SizingContext{
+		Code:        "sz.000001",
+		Price:       10,
+		Cash:        1000000,
+		TotalAssets: 1000000,
+		Stats:       stats,
+	}
+
+	// 半凯利 12.5%
+	if got := sizer.Size(ctx); got != 12500 {
+		t.Errorf("半凯利买入 %d 股, 期望 12500", got)
+	}
+
+	// 样本不足时使用兜底比例10%
+	ctx.Stats.ClosedTrades = 5
+	if got := sizer.Size(ctx); got != 10000 {
+		t.Errorf("样本不足时买入 %d 股, 期望 10000", got)
+	}
+
+	// 期望为负时不开仓
+	ctx.Stats = stockStrategy.TradeStats{ClosedTrades: 20, WinCount: 2, AvgWinPct: 0.05, AvgLossPct: 0.05}
+	if got := sizer.Size(ctx); got != 0 {
+		t.Errorf("负期望时买入 %d 股, 期望 0", got)
+	}
+}
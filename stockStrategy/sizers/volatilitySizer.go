@@ -0,0 +1,71 @@
+package sizers
+
+import (
+	"fmt"
+	"math"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+)
+
+// VolatilitySizer 波动率目标仓位（ATR）
+// 使每个持仓的日波动（股数 * ATR）等于总资产的 TargetPercent
+// 股数 = 总资产 * TargetPercent / ATR
+type VolatilitySizer struct {
+	ATRPeriod     int     // ATR计算周期，默认20
+	TargetPercent float64 // 单个持仓日波动占总资产的目标比例，如0.01表示1%
+	MaxFraction   float64 // 单个持仓占总资产的上限比例（0-1）
+}
+
+// NewVolatilitySizer 创建波动率目标仓位管理器
+func NewVolatilitySizer(atrPeriod int, targetPercent, maxFraction float64) *VolatilitySizer {
+	return &VolatilitySizer{
+		ATRPeriod:     atrPeriod,
+		TargetPercent: targetPercent,
+		MaxFraction:   maxFraction,
+	}
+}
+
+// Size 计算买入股数
+func (s *VolatilitySizer) Size(ctx *stockStrategy.SizingContext) int {
+	atr := CalculateATR(ctx.History, s.ATRPeriod)
+	if atr <= 0 {
+		return 0 // 历史数据不足，无法估计波动
+	}
+
+	cashToUse := ctx.TotalAssets * s.TargetPercent / atr * ctx.Price
+
+	if s.MaxFraction > 0 && cashToUse > ctx.TotalAssets*s.MaxFraction {
+		cashToUse = ctx.TotalAssets * s.MaxFraction
+	}
+	if cashToUse > ctx.Cash {
+		cashToUse = ctx.Cash
+	}
+	return SharesForCash(ctx.Code, cashToUse, ctx.Price)
+}
+
+// GetName 获取仓位管理器名称
+func (s *VolatilitySizer) GetName() string {
+	return fmt.Sprintf("波动率目标(ATR%d,%.1f%%)", s.ATRPeriod, s.TargetPercent*100)
+}
+
+// CalculateATR 计算最近 period 天的平均真实波幅
+// 真实波幅 = max(最高-最低, |最高-昨收|, |最低-昨收|)
+// 数据不足 period+1 天时返回0
+func CalculateATR(history []*stockData.StockDataDay, period int) float64 {
+	if period <= 0 || len(history) < period+1 {
+		return 0
+	}
+
+	sum := 0.0
+	for i := len(history) - period; i < len(history); i++ {
+		high := float64(history[i].PriceHigh)
+		low := float64(history[i].PriceLow)
+		prevClose := float64(history[i-1].PriceEnd)
+
+		trueRange := high - low
+		trueRange = math.Max(trueRange, math.Abs(high-prevClose))
+		trueRange = math.Max(trueRange, math.Abs(low-prevClose))
+		sum += trueRange
+	}
+	return sum / float64(period)
+}
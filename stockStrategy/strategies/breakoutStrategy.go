@@ -0,0 +1,70 @@
+package strategies
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/selectors"
+	"stock-go/stockStrategy/signals"
+)
+
+// BreakoutStrategy 突破策略（策略2）
+// 选股：上市时间足够计算均线的票票
+// 交易：有效突破近期高点且均线多头排列时买入，止盈、止损、移动止损或超时卖出
+type BreakoutStrategy struct {
+	selector  stockStrategy.StockSelector
+	signalGen *signals.BreakoutSignal
+	sizer     stockStrategy.PositionSizer // 仓位管理器，nil表示使用回测引擎默认值
+}
+
+// NewBreakoutStrategy 创建突破策略（使用默认参数）
+func NewBreakoutStrategy() *BreakoutStrategy {
+	return &BreakoutStrategy{
+		selector:  selectors.NewListedDaysSelector(80),
+		signalGen: signals.NewBreakoutSignal(20, 0.02, signals.NewDefaultExitRule()),
+	}
+}
+
+// NewBreakoutStrategyWithParams 创建突破策略（自定义参数）
+func NewBreakoutStrategyWithParams(
+	minListedDays int,
+	lookbackDays int,
+	breakoutPercent float64,
+	exit signals.ExitRule,
+) *BreakoutStrategy {
+	return &BreakoutStrategy{
+		selector:  selectors.NewListedDaysSelector(minListedDays),
+		signalGen: signals.NewBreakoutSignal(lookbackDays, breakoutPercent, exit),
+	}
+}
+
+// GetSelector 获取选股器
+func (s *BreakoutStrategy) GetSelector() stockStrategy.StockSelector {
+	return s.selector
+}
+
+// GetSignalGenerator 获取信号生成器
+func (s *BreakoutStrategy) GetSignalGenerator() stockStrategy.SignalGenerator {
+	return s.signalGen
+}
+
+// NewSignalGenerator 为每只票票创建独立的信号生成器
+func (s *BreakoutStrategy) NewSignalGenerator(code string) stockStrategy.SignalGenerator {
+	return s.signalGen.Clone()
+}
+
+// WithSizer 设置策略使用的仓位管理器
+func (s *BreakoutStrategy) WithSizer(sizer stockStrategy.PositionSizer) *BreakoutStrategy {
+	s.sizer = sizer
+	return s
+}
+
+// GetSizer 获取仓位管理器（nil表示使用回测引擎默认值）
+func (s *BreakoutStrategy) GetSizer() stockStrategy.PositionSizer {
+	return s.sizer
+}
+
+// GetName 获取策略名称
+func (s *BreakoutStrategy) GetName() string {
+	return fmt.Sprintf("策略2[%s + %s]",
+		s.selector.GetName(), s.signalGen.GetName())
+}
@@ -13,6 +13,7 @@ import (
 type BuyHighSellLowStrategy struct {
 	selector  stockStrategy.StockSelector
 	signalGen stockStrategy.SignalGenerator
+	sizer     stockStrategy.PositionSizer // 仓位管理器，nil表示使用回测引擎默认值
 }
 
 // NewBuyHighSellLowStrategy 创建追涨杀跌策略（使用默认参数）
@@ -47,6 +48,17 @@ func (s *BuyHighSellLowStrategy) GetSignalGenerator() stockStrategy.SignalGenera
 	return s.signalGen
 }
 
+// WithSizer 设置策略使用的仓位管理器
+func (s *BuyHighSellLowStrategy) WithSizer(sizer stockStrategy.PositionSizer) *BuyHighSellLowStrategy {
+	s.sizer = sizer
+	return s
+}
+
+// GetSizer 获取仓位管理器（nil表示使用回测引擎默认值）
+func (s *BuyHighSellLowStrategy) GetSizer() stockStrategy.PositionSizer {
+	return s.sizer
+}
+
 // GetName 获取策略名称
 func (s *BuyHighSellLowStrategy) GetName() string {
 	return fmt.Sprintf("策略1[%s + %s]",
@@ -0,0 +1,68 @@
+package strategies
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+)
+
+// ComposedStrategy 组合策略：选股器 + 由 combine 包组合出的信号生成器
+// build 每次调用返回一个新的组合，回测时每只票票使用独立的组合，组合器的状态互不影响
+type ComposedStrategy struct {
+	name     string
+	selector stockStrategy.StockSelector
+	build    func() stockStrategy.SignalGenerator
+	sizer    stockStrategy.PositionSizer // 仓位管理器，nil表示使用回测引擎默认值
+}
+
+// NewComposedStrategy 创建组合策略
+// 示例:
+//
+//	strategy := strategies.NewComposedStrategy("突破且趋势向上", selectors.NewListedDaysSelector(80),
+//		func() stockStrategy.SignalGenerator {
+//			return combine.EntryExit(
+//				combine.And(combine.When(combine.Breakout(20)), combine.When(combine.MAAbove(20, 60))),
+//				combine.Exit(signals.NewDefaultExitRule()),
+//			)
+//		})
+func NewComposedStrategy(
+	name string,
+	selector stockStrategy.StockSelector,
+	build func() stockStrategy.SignalGenerator,
+) *ComposedStrategy {
+	return &ComposedStrategy{
+		name:     name,
+		selector: selector,
+		build:    build,
+	}
+}
+
+// GetSelector 获取选股器
+func (s *ComposedStrategy) GetSelector() stockStrategy.StockSelector {
+	return s.selector
+}
+
+// GetSignalGenerator 获取一个新的信号生成器
+func (s *ComposedStrategy) GetSignalGenerator() stockStrategy.SignalGenerator {
+	return s.build()
+}
+
+// NewSignalGenerator 为每只票票创建独立的信号生成器
+func (s *ComposedStrategy) NewSignalGenerator(code string) stockStrategy.SignalGenerator {
+	return s.build()
+}
+
+// WithSizer 设置策略使用的仓位管理器
+func (s *ComposedStrategy) WithSizer(sizer stockStrategy.PositionSizer) *ComposedStrategy {
+	s.sizer = sizer
+	return s
+}
+
+// GetSizer 获取仓位管理器（nil表示使用回测引擎默认值）
+func (s *ComposedStrategy) GetSizer() stockStrategy.PositionSizer {
+	return s.sizer
+}
+
+// GetName 获取策略名称
+func (s *ComposedStrategy) GetName() string {
+	return fmt.Sprintf("%s[%s + %s]", s.name, s.selector.GetName(), s.build().GetName())
+}
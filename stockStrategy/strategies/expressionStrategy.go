@@ -0,0 +1,65 @@
+package strategies
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/combine"
+	"stock-go/stockStrategy/expr"
+	"stock-go/stockStrategy/selectors"
+	"stock-go/stockStrategy/signals"
+)
+
+// ExpressionStrategy 表达式策略：选股、买入、卖出条件都用表达式描述，不需要编写Go代码
+// 信号生成器没有内部状态，所有票票共用
+type ExpressionStrategy struct {
+	selector  stockStrategy.StockSelector
+	signalGen stockStrategy.SignalGenerator
+	sizer     stockStrategy.PositionSizer // 仓位管理器，nil表示使用回测引擎默认值
+}
+
+// NewExpressionStrategy 编译表达式，创建表达式策略
+// selection 为空时选择K线数量足够计算买入表达式的票票；exit 为空时使用默认卖出规则（止盈、止损、移动止损、超时）
+// 示例: NewExpressionStrategy("", "close >= highest(close, 300) * 0.995 and rsi(14) < 80", "drawdown > 0.05")
+func NewExpressionStrategy(selection, entry, exit string) (*ExpressionStrategy, error) {
+	signal, err := expr.NewSignal(entry, exit)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ExpressionStrategy{signalGen: signal}
+	if exit == "" {
+		s.signalGen = combine.EntryExit(signal, combine.Exit(signals.NewDefaultExitRule()))
+	}
+	if selection == "" {
+		s.selector = selectors.NewListedDaysSelector(signal.GetHistoryWindow())
+	} else if s.selector, err = expr.NewSelector(selection); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetSelector 获取选股器
+func (s *ExpressionStrategy) GetSelector() stockStrategy.StockSelector {
+	return s.selector
+}
+
+// GetSignalGenerator 获取信号生成器
+func (s *ExpressionStrategy) GetSignalGenerator() stockStrategy.SignalGenerator {
+	return s.signalGen
+}
+
+// WithSizer 设置策略使用的仓位管理器
+func (s *ExpressionStrategy) WithSizer(sizer stockStrategy.PositionSizer) *ExpressionStrategy {
+	s.sizer = sizer
+	return s
+}
+
+// GetSizer 获取仓位管理器（nil表示使用回测引擎默认值）
+func (s *ExpressionStrategy) GetSizer() stockStrategy.PositionSizer {
+	return s.sizer
+}
+
+// GetName 获取策略名称
+func (s *ExpressionStrategy) GetName() string {
+	return fmt.Sprintf("表达式策略[%s + %s]", s.selector.GetName(), s.signalGen.GetName())
+}
@@ -0,0 +1,89 @@
+package strategies
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockData/synthetic"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/combine"
+	"stock-go/stockStrategy/lookahead"
+	"stock-go/stockStrategy/sector"
+	"stock-go/stockStrategy/selectors"
+	"stock-go/stockStrategy/signals"
+	"testing"
+)
+
+// lookaheadTestData 构造未来函数检测使用的测试数据（固定种子的随机游走，涨跌足以触发各策略的买卖信号）
+func lookaheadTestData() map[string]*stockData.StockInfo {
+	return map[string]*stockData.StockInfo{
+		"sz.000001": synthetic.FromPrices("sz.000001", synthetic.RandomWalk(5, 300)),
+		"sz.000002": synthetic.FromPrices("sz.000002", synthetic.RandomWalk(8, 300)),
+	}
+}
+
+// TestBuyHighSellLowStrategyNoLookahead 测试追涨杀跌策略没有未来函数
+func TestBuyHighSellLowStrategyNoLookahead(t *testing.T) {
+	lookahead.AssertStrategy(t, func() stockStrategy.Strategy {
+		return NewBuyHighSellLowStrategyWithParams(100, 10, 50, 0.05, 15)
+	}, lookaheadTestData(), lookahead.NewDefaultOptions())
+}
+
+// TestBreakoutStrategyNoLookahead 测试突破策略（策略2）没有未来函数
+func TestBreakoutStrategyNoLookahead(t *testing.T) {
+	lookahead.AssertStrategy(t, func() stockStrategy.Strategy {
+		return NewBreakoutStrategy()
+	}, lookaheadTestData(), lookahead.NewDefaultOptions())
+}
+
+// TestSwingStrategyNoLookahead 测试波段策略（策略3）没有未来函数
+func TestSwingStrategyNoLookahead(t *testing.T) {
+	lookahead.AssertStrategy(t, func() stockStrategy.Strategy {
+		return NewSwingStrategy()
+	}, lookaheadTestData(), lookahead.NewDefaultOptions())
+}
+
+// sectorTestModel 测试数据的两只票票属于同一行业
+func sectorTestModel() *sector.Model {
+	model := sector.NewModel(map[string]string{"sz.000001": "银行", "sz.000002": "银行"})
+	model.MinMembers = 1
+	return model
+}
+
+// TestSectorBreakoutStrategyNoLookahead 测试板块突破策略（策略5）没有未来函数
+func TestSectorBreakoutStrategyNoLookahead(t *testing.T) {
+	lookahead.AssertStrategy(t, func() stockStrategy.Strategy {
+		return NewSectorBreakoutStrategyWithParams(sectorTestModel(), 20, 20, 1, signals.NewDefaultExitRule())
+	}, lookaheadTestData(), lookahead.NewDefaultOptions())
+}
+
+// TestSectorRotationStrategyNoLookahead 测试板块轮动策略（策略6）没有未来函数
+func TestSectorRotationStrategyNoLookahead(t *testing.T) {
+	lookahead.AssertStrategy(t, func() stockStrategy.Strategy {
+		return NewSectorRotationStrategyWithParams(sectorTestModel(), 1, 1, 20)
+	}, lookaheadTestData(), lookahead.NewDefaultOptions())
+}
+
+// TestComposedStrategyNoLookahead 测试组合策略（含有状态的确认组合器）没有未来函数
+func TestComposedStrategyNoLookahead(t *testing.T) {
+	lookahead.AssertStrategy(t, func() stockStrategy.Strategy {
+		return NewComposedStrategy("突破确认", selectors.NewListedDaysSelector(60), func() stockStrategy.SignalGenerator {
+			return combine.EntryExit(
+				combine.And(
+					combine.Confirm(combine.When(combine.Breakout(20)), combine.When(combine.MAAbove(5, 20)), 5),
+					combine.Not(combine.When(combine.RecentSurge(5, 0.07))),
+				),
+				combine.Or(combine.Exit(signals.NewDefaultExitRule()), combine.When(combine.PriceBelowMA(20))),
+			)
+		})
+	}, lookaheadTestData(), lookahead.NewDefaultOptions())
+}
+
+// TestExpressionStrategyNoLookahead 测试表达式策略没有未来函数
+func TestExpressionStrategyNoLookahead(t *testing.T) {
+	lookahead.AssertStrategy(t, func() stockStrategy.Strategy {
+		strategy, err := NewExpressionStrategy("close > sma(close, 20)", "close >= highest(close, 60) * 0.995 and rsi(14) < 80", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strategy
+	}, lookaheadTestData(), lookahead.NewDefaultOptions())
+}
@@ -0,0 +1,103 @@
+package strategies
+
+import (
+	"fmt"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/regime"
+	"stock-go/stockStrategy/sizers"
+)
+
+// MarketRegimeStrategy 大盘策略（策略4）
+// 包装任意策略：按市场状态禁止开仓、收紧止损、降低仓位，选股沿用被包装的策略
+type MarketRegimeStrategy struct {
+	inner     stockStrategy.Strategy
+	filter    *regime.Filter
+	signalGen stockStrategy.SignalGenerator
+}
+
+// NewMarketRegimeStrategy 用市场状态过滤器包装策略
+func NewMarketRegimeStrategy(inner stockStrategy.Strategy, filter *regime.Filter) *MarketRegimeStrategy {
+	return &MarketRegimeStrategy{
+		inner:     inner,
+		filter:    filter,
+		signalGen: regime.NewFilteredSignal(inner.GetSignalGenerator(), filter),
+	}
+}
+
+// GetSelector 获取选股器（沿用被包装的策略）
+func (s *MarketRegimeStrategy) GetSelector() stockStrategy.StockSelector {
+	return s.inner.GetSelector()
+}
+
+// GetSignalGenerator 获取信号生成器
+func (s *MarketRegimeStrategy) GetSignalGenerator() stockStrategy.SignalGenerator {
+	return s.signalGen
+}
+
+// NewSignalGenerator 为每只票票创建独立的信号生成器
+// 被包装的策略不支持时，与 GetSignalGenerator 共用同一个被包装的信号生成器
+func (s *MarketRegimeStrategy) NewSignalGenerator(code string) stockStrategy.SignalGenerator {
+	if factory, ok := s.inner.(stockStrategy.SignalGeneratorFactory); ok {
+		return regime.NewFilteredSignal(factory.NewSignalGenerator(code), s.filter)
+	}
+	return regime.NewFilteredSignal(s.inner.GetSignalGenerator(), s.filter)
+}
+
+// GetSizer 获取仓位管理器（沿用被包装的策略，nil表示使用回测引擎默认值）
+func (s *MarketRegimeStrategy) GetSizer() stockStrategy.PositionSizer {
+	if sized, ok := s.inner.(stockStrategy.SizedStrategy); ok {
+		return sized.GetSizer()
+	}
+	return nil
+}
+
+// AdjustSize 按当天市场状态的仓位比例缩放买入股数
+func (s *MarketRegimeStrategy) AdjustSize(ctx *stockStrategy.SizingContext, stockNum int) int {
+	if adjuster, ok := s.inner.(stockStrategy.SizeAdjuster); ok {
+		stockNum = adjuster.AdjustSize(ctx, stockNum)
+	}
+
+	rule := s.filter.RuleAt(ctx.Date)
+	if !rule.AllowBuys || rule.SizeScale <= 0 {
+		return 0
+	}
+	if rule.SizeScale >= 1 {
+		return stockNum
+	}
+	return sizers.GetLotRule(ctx.Code).RoundDown(int(float64(stockNum) * rule.SizeScale))
+}
+
+// RegimeAt 获取指定日期的市场状态
+func (s *MarketRegimeStrategy) RegimeAt(date string) string {
+	return s.filter.RegimeAt(date).String()
+}
+
+// SetStockData 注入市场宽度使用的全市场数据，被包装的策略需要数据时一并注入
+func (s *MarketRegimeStrategy) SetStockData(data map[string]*stockData.StockInfo) {
+	s.filter.SetUniverse(data)
+	if aware, ok := s.inner.(stockStrategy.DataAware); ok {
+		aware.SetStockData(data)
+	}
+}
+
+// CheckStockData 检查市场状态指数的数据，被包装的策略需要检查时一并检查
+func (s *MarketRegimeStrategy) CheckStockData() error {
+	if err := s.filter.CheckStockData(); err != nil {
+		return err
+	}
+	if checker, ok := s.inner.(stockStrategy.DataChecker); ok {
+		return checker.CheckStockData()
+	}
+	return nil
+}
+
+// GetFilter 获取市场状态过滤器
+func (s *MarketRegimeStrategy) GetFilter() *regime.Filter {
+	return s.filter
+}
+
+// GetName 获取策略名称
+func (s *MarketRegimeStrategy) GetName() string {
+	return fmt.Sprintf("策略4[%s过滤 + %s]", s.filter.GetName(), s.inner.GetName())
+}
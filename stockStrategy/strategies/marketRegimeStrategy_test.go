@@ -0,0 +1,65 @@
+package strategies
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/lookahead"
+	"stock-go/stockStrategy/regime"
+	"testing"
+)
+
+// constantClassifier 每天返回固定市场状态的测试分类器
+type constantClassifier struct {
+	regime regime.Regime
+}
+
+func (c constantClassifier) Classify(index []*stockData.StockDataDay, universe map[string]*stockData.StockInfo) map[string]regime.Regime {
+	regimes := make(map[string]regime.Regime, len(index))
+	for _, day := range index {
+		regimes[day.DataStr] = c.regime
+	}
+	return regimes
+}
+
+func (c constantClassifier) GetName() string { return c.regime.String() }
+
+// TestMarketRegimeStrategyAdjustSize 测试按市场状态缩放买入股数
+func TestMarketRegimeStrategyAdjustSize(t *testing.T) {
+	index := lookaheadTestData()["sz.000001"]
+	date := index.Datas.DayDatas[10].DataStr
+	ctx := &stockStrategy.SizingContext{Code: "sz.000001", Date: date}
+
+	tests := []struct {
+		regime regime.Regime
+		want   int
+	}{
+		{regime.Bull, 1000},
+		{regime.Range, 500},
+		{regime.Bear, 0},
+	}
+	for _, tt := range tests {
+		filter := regime.NewFilter(constantClassifier{tt.regime}, "").SetIndexData(index)
+		strategy := NewMarketRegimeStrategy(NewBreakoutStrategy(), filter)
+		if got := strategy.AdjustSize(ctx, 1000); got != tt.want {
+			t.Errorf("%s: 买入 %d 股，期望 %d", tt.regime, got, tt.want)
+		}
+		if strategy.RegimeAt(date) != tt.regime.String() {
+			t.Errorf("市场状态 %s，期望 %s", strategy.RegimeAt(date), tt.regime)
+		}
+	}
+
+	// 震荡半仓后不足一手
+	filter := regime.NewFilter(constantClassifier{regime.Range}, "").SetIndexData(index)
+	if got := NewMarketRegimeStrategy(NewBreakoutStrategy(), filter).AdjustSize(ctx, 100); got != 0 {
+		t.Errorf("半仓后 %d 股，期望 0", got)
+	}
+}
+
+// TestMarketRegimeStrategyNoLookahead 测试大盘策略（策略4）没有未来函数
+// 指数从注入的数据中按代码查找，检测时同样被截断
+func TestMarketRegimeStrategyNoLookahead(t *testing.T) {
+	lookahead.AssertStrategy(t, func() stockStrategy.Strategy {
+		filter := regime.NewFilter(regime.NewTrendClassifier(20, 5, 0.01), "sz.000002")
+		return NewMarketRegimeStrategy(NewBuyHighSellLowStrategyWithParams(100, 10, 50, 0.05, 15), filter)
+	}, lookaheadTestData(), lookahead.NewDefaultOptions())
+}
@@ -0,0 +1,28 @@
+package strategies
+
+import (
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/regime"
+)
+
+// NewModeStrategy 按策略模式创建新架构的策略，可直接用于 TimeBasedBacktestEngine
+// 未知的模式返回nil
+func NewModeStrategy(strategyMode int) stockStrategy.Strategy {
+	switch strategyMode {
+	case stockStrategy.Strategy_Mode_1:
+		return NewBuyHighSellLowStrategy()
+	case stockStrategy.Strategy_Mode_2:
+		return NewBreakoutStrategy()
+	case stockStrategy.Strategy_Mode_3:
+		return NewSwingStrategy()
+	case stockStrategy.Strategy_Mode_4:
+		return NewMarketRegimeStrategy(NewBuyHighSellLowStrategy(), regime.NewDefaultFilter())
+	case stockStrategy.Strategy_Mode_5:
+		return NewSectorBreakoutStrategy()
+	case stockStrategy.Strategy_Mode_6:
+		return NewSectorRotationStrategy()
+	case stockStrategy.Strategy_Mode_7:
+		return NewMomentumRankStrategy()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package strategies
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/selectors"
+)
+
+// MomentumRankStrategy 动量排名策略（策略7，横截面策略）
+// 选股：上市时间足够计算动量的票票
+// 交易：每隔 RebalanceEvery 个交易日按 LookbackDays 日涨幅给全部候选票票排名，
+// 等权持有涨幅最大的 TopN 只；涨幅不超过 MinMomentum 的票票不买入，都不满足时空仓
+type MomentumRankStrategy struct {
+	LookbackDays   int     // 动量回看天数
+	TopN           int     // 持有票票数
+	RebalanceEvery int     // 调仓间隔（交易日）
+	MinMomentum    float64 // 最低涨幅，如0表示只买入上涨的票票
+
+	selector stockStrategy.StockSelector
+}
+
+// NewMomentumRankStrategy 创建动量排名策略（使用默认参数）
+func NewMomentumRankStrategy() *MomentumRankStrategy {
+	return NewMomentumRankStrategyWithParams(60, 5, 20)
+}
+
+// NewMomentumRankStrategyWithParams 创建动量排名策略（自定义参数）
+func NewMomentumRankStrategyWithParams(lookbackDays, topN, rebalanceEvery int) *MomentumRankStrategy {
+	return &MomentumRankStrategy{
+		LookbackDays:   lookbackDays,
+		TopN:           topN,
+		RebalanceEvery: max(rebalanceEvery, 1),
+		selector:       selectors.NewListedDaysSelector(lookbackDays + 1),
+	}
+}
+
+// GetSelector 获取选股器
+func (s *MomentumRankStrategy) GetSelector() stockStrategy.StockSelector {
+	return s.selector
+}
+
+// GetSignalGenerator 没有逐只票票的信号生成器，只按调仓买卖
+func (s *MomentumRankStrategy) GetSignalGenerator() stockStrategy.SignalGenerator {
+	return nil
+}
+
+// GetHistoryWindow 计算动量需要的历史窗口
+func (s *MomentumRankStrategy) GetHistoryWindow() int {
+	return s.LookbackDays + 1
+}
+
+// Rebalance 调仓日返回动量最强的 TopN 只票票（等权），其他日期不调仓
+func (s *MomentumRankStrategy) Rebalance(ctx *stockStrategy.PortfolioContext) []stockStrategy.TargetWeight {
+	if ctx.DayIndex%s.RebalanceEvery != 0 {
+		return nil
+	}
+	top := ctx.RankTop(s.TopN, s.momentum)
+	return stockStrategy.EqualWeights(top, 1)
+}
+
+// momentum 票票的 LookbackDays 日涨幅，历史不足或不满足最低涨幅时返回false
+func (s *MomentumRankStrategy) momentum(bars *stockStrategy.BarContext) (float64, bool) {
+	if bars.Len() <= s.LookbackDays {
+		return 0, false
+	}
+	base := bars.Price(s.LookbackDays)
+	if base <= 0 {
+		return 0, false
+	}
+	change := bars.Price(0)/base - 1
+	return change, change > s.MinMomentum
+}
+
+// GetName 获取策略名称
+func (s *MomentumRankStrategy) GetName() string {
+	return fmt.Sprintf("策略7[%s + 动量排名(%d日涨幅前%d名,每%d日调仓)]",
+		s.selector.GetName(), s.LookbackDays, s.TopN, s.RebalanceEvery)
+}
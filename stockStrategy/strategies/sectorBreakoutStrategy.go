@@ -0,0 +1,81 @@
+package strategies
+
+import (
+	"fmt"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/sector"
+	"stock-go/stockStrategy/signals"
+)
+
+// SectorBreakoutStrategy 板块突破策略（策略5）
+// 选股：有行业分类的票票
+// 交易：行业指数创新高时买入行业龙头，止盈、止损、板块走弱或不再是龙头时卖出
+type SectorBreakoutStrategy struct {
+	model     *sector.Model
+	selector  stockStrategy.StockSelector
+	signalGen *sector.BreakoutSignal
+	sizer     stockStrategy.PositionSizer // 仓位管理器，nil表示使用回测引擎默认值
+}
+
+// NewSectorBreakoutStrategy 创建板块突破策略（使用默认参数，行业分类从 stockList.csv 加载）
+func NewSectorBreakoutStrategy() *SectorBreakoutStrategy {
+	return NewSectorBreakoutStrategyWithParams(sector.NewModel(nil), 20, 20, 2, signals.NewDefaultExitRule())
+}
+
+// NewSectorBreakoutStrategyWithParams 创建板块突破策略（自定义参数）
+func NewSectorBreakoutStrategyWithParams(
+	model *sector.Model,
+	breakoutDays int,
+	momentumDays int,
+	leaders int,
+	exit signals.ExitRule,
+) *SectorBreakoutStrategy {
+	return &SectorBreakoutStrategy{
+		model:     model,
+		selector:  sector.NewSelector(model),
+		signalGen: sector.NewBreakoutSignal(model, breakoutDays, momentumDays, leaders, exit),
+	}
+}
+
+// GetSelector 获取选股器
+func (s *SectorBreakoutStrategy) GetSelector() stockStrategy.StockSelector {
+	return s.selector
+}
+
+// GetSignalGenerator 获取信号生成器（未绑定票票，回测请使用 NewSignalGenerator）
+func (s *SectorBreakoutStrategy) GetSignalGenerator() stockStrategy.SignalGenerator {
+	return s.signalGen
+}
+
+// NewSignalGenerator 为每只票票创建独立的信号生成器
+func (s *SectorBreakoutStrategy) NewSignalGenerator(code string) stockStrategy.SignalGenerator {
+	return s.signalGen.ForCode(code)
+}
+
+// SetStockData 注入行业模型使用的票票数据
+func (s *SectorBreakoutStrategy) SetStockData(data map[string]*stockData.StockInfo) {
+	s.model.SetStockData(data)
+}
+
+// GetModel 获取行业模型
+func (s *SectorBreakoutStrategy) GetModel() *sector.Model {
+	return s.model
+}
+
+// WithSizer 设置策略使用的仓位管理器
+func (s *SectorBreakoutStrategy) WithSizer(sizer stockStrategy.PositionSizer) *SectorBreakoutStrategy {
+	s.sizer = sizer
+	return s
+}
+
+// GetSizer 获取仓位管理器（nil表示使用回测引擎默认值）
+func (s *SectorBreakoutStrategy) GetSizer() stockStrategy.PositionSizer {
+	return s.sizer
+}
+
+// GetName 获取策略名称
+func (s *SectorBreakoutStrategy) GetName() string {
+	return fmt.Sprintf("策略5[%s + %s]",
+		s.selector.GetName(), s.signalGen.GetName())
+}
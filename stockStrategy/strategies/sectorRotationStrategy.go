@@ -0,0 +1,79 @@
+package strategies
+
+import (
+	"fmt"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/sector"
+)
+
+// SectorRotationStrategy 板块轮动策略（策略6）
+// 选股：有行业分类的票票
+// 交易：定期调仓，持有近期最强的几个行业中涨幅最大的票票
+type SectorRotationStrategy struct {
+	model     *sector.Model
+	selector  stockStrategy.StockSelector
+	signalGen *sector.RotationSignal
+	sizer     stockStrategy.PositionSizer // 仓位管理器，nil表示使用回测引擎默认值
+}
+
+// NewSectorRotationStrategy 创建板块轮动策略（使用默认参数，行业分类从 stockList.csv 加载）
+func NewSectorRotationStrategy() *SectorRotationStrategy {
+	return NewSectorRotationStrategyWithParams(sector.NewModel(nil), 3, 2, 20)
+}
+
+// NewSectorRotationStrategyWithParams 创建板块轮动策略（自定义参数）
+func NewSectorRotationStrategyWithParams(
+	model *sector.Model,
+	topIndustries int,
+	leaders int,
+	rebalanceEvery int,
+) *SectorRotationStrategy {
+	return &SectorRotationStrategy{
+		model:     model,
+		selector:  sector.NewSelector(model),
+		signalGen: sector.NewRotationSignal(model, topIndustries, leaders, rebalanceEvery),
+	}
+}
+
+// GetSelector 获取选股器
+func (s *SectorRotationStrategy) GetSelector() stockStrategy.StockSelector {
+	return s.selector
+}
+
+// GetSignalGenerator 获取信号生成器（未绑定票票，回测请使用 NewSignalGenerator）
+func (s *SectorRotationStrategy) GetSignalGenerator() stockStrategy.SignalGenerator {
+	return s.signalGen
+}
+
+// NewSignalGenerator 为每只票票创建独立的信号生成器
+func (s *SectorRotationStrategy) NewSignalGenerator(code string) stockStrategy.SignalGenerator {
+	return s.signalGen.ForCode(code)
+}
+
+// SetStockData 注入行业模型使用的票票数据
+func (s *SectorRotationStrategy) SetStockData(data map[string]*stockData.StockInfo) {
+	s.model.SetStockData(data)
+}
+
+// GetModel 获取行业模型
+func (s *SectorRotationStrategy) GetModel() *sector.Model {
+	return s.model
+}
+
+// WithSizer 设置策略使用的仓位管理器
+func (s *SectorRotationStrategy) WithSizer(sizer stockStrategy.PositionSizer) *SectorRotationStrategy {
+	s.sizer = sizer
+	return s
+}
+
+// GetSizer 获取仓位管理器（nil表示使用回测引擎默认值）
+func (s *SectorRotationStrategy) GetSizer() stockStrategy.PositionSizer {
+	return s.sizer
+}
+
+// GetName 获取策略名称
+func (s *SectorRotationStrategy) GetName() string {
+	return fmt.Sprintf("策略6[%s + %s]",
+		s.selector.GetName(), s.signalGen.GetName())
+}
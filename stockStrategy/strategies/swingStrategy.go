@@ -0,0 +1,70 @@
+package strategies
+
+import (
+	"fmt"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/selectors"
+	"stock-go/stockStrategy/signals"
+)
+
+// SwingStrategy 波段策略（策略3）
+// 选股：上市时间足够计算均线的票票
+// 交易：超跌到布林带下轨以下时买入，止盈、止损、移动止损或超时卖出
+type SwingStrategy struct {
+	selector  stockStrategy.StockSelector
+	signalGen *signals.SwingSignal
+	sizer     stockStrategy.PositionSizer // 仓位管理器，nil表示使用回测引擎默认值
+}
+
+// NewSwingStrategy 创建波段策略（使用默认参数）
+func NewSwingStrategy() *SwingStrategy {
+	return &SwingStrategy{
+		selector:  selectors.NewListedDaysSelector(120),
+		signalGen: signals.NewSwingSignal(60, 0.08, signals.NewDefaultExitRule()),
+	}
+}
+
+// NewSwingStrategyWithParams 创建波段策略（自定义参数）
+func NewSwingStrategyWithParams(
+	minListedDays int,
+	windowDays int,
+	discountPercent float64,
+	exit signals.ExitRule,
+) *SwingStrategy {
+	return &SwingStrategy{
+		selector:  selectors.NewListedDaysSelector(minListedDays),
+		signalGen: signals.NewSwingSignal(windowDays, discountPercent, exit),
+	}
+}
+
+// GetSelector 获取选股器
+func (s *SwingStrategy) GetSelector() stockStrategy.StockSelector {
+	return s.selector
+}
+
+// GetSignalGenerator 获取信号生成器
+func (s *SwingStrategy) GetSignalGenerator() stockStrategy.SignalGenerator {
+	return s.signalGen
+}
+
+// NewSignalGenerator 为每只票票创建独立的信号生成器
+func (s *SwingStrategy) NewSignalGenerator(code string) stockStrategy.SignalGenerator {
+	return s.signalGen.Clone()
+}
+
+// WithSizer 设置策略使用的仓位管理器
+func (s *SwingStrategy) WithSizer(sizer stockStrategy.PositionSizer) *SwingStrategy {
+	s.sizer = sizer
+	return s
+}
+
+// GetSizer 获取仓位管理器（nil表示使用回测引擎默认值）
+func (s *SwingStrategy) GetSizer() stockStrategy.PositionSizer {
+	return s.sizer
+}
+
+// GetName 获取策略名称
+func (s *SwingStrategy) GetName() string {
+	return fmt.Sprintf("策略3[%s + %s]",
+		s.selector.GetName(), s.signalGen.GetName())
+}
@@ -18,6 +18,7 @@ const (
 	Strategy_Mode_4 = 4 // 大盘策略
 	Strategy_Mode_5 = 5 // 板块突破
 	Strategy_Mode_6 = 6 // 板块轮动
+	Strategy_Mode_7 = 7 // 动量排名
 )
 
 // StockData 旧版本的票票数据结构（用于CSV加载）
@@ -62,15 +63,17 @@ func NewStrategy(strategyMode int) StockStrategy {
 	case Strategy_Mode_1:
 		return NewBuyHighSellLowStrategy()
 	case Strategy_Mode_2:
-		//return NewBreakoutStrategy()
+		// 已重构为 strategies.NewBreakoutStrategy，请使用 TimeBasedBacktestEngine
 	case Strategy_Mode_3:
-		//return NewSwingStrategy()
+		// 已重构为 strategies.NewSwingStrategy，请使用 TimeBasedBacktestEngine
 	case Strategy_Mode_4:
-		//return New大盘Strategy()
+		// 已实现为 strategies.NewMarketRegimeStrategy（包装任意策略），请使用 TimeBasedBacktestEngine
 	case Strategy_Mode_5:
-		//return New板块突破Strategy()
+		// 已实现为 strategies.NewSectorBreakoutStrategy，请使用 TimeBasedBacktestEngine
 	case Strategy_Mode_6:
-		//return New板块轮动Strategy()
+		// 已实现为 strategies.NewSectorRotationStrategy，请使用 TimeBasedBacktestEngine
+	case Strategy_Mode_7:
+		// 已实现为 strategies.NewMomentumRankStrategy（横截面策略），请使用 TimeBasedBacktestEngine
 	}
 
 	return nil
@@ -178,8 +181,9 @@ func dealStrategysMode1Old(code string) map[string]OperateRecord {
 	return operates
 }
 
-// dealStrategysMode2 突破策略（待重构为新策略模式）
-// TODO: 重构为独立的策略结构体，避免未来函数问题
+// dealStrategysMode2 突破策略的旧实现，保留作为参考
+// 已废弃 - 请使用 strategies.NewBreakoutStrategy（signals.BreakoutSignal 实时判断卖出）
+// 警告：此实现存在未来函数问题（findSellPriceOptimized会查看未来数据）
 func dealStrategysMode2(code string, strategyMode int) map[string]OperateRecord {
 	operates := make(map[string]OperateRecord)
 
@@ -261,8 +265,9 @@ func dealStrategysMode2(code string, strategyMode int) map[string]OperateRecord
 	return operates
 }
 
-// dealStrategysMode3 波段策略（待重构为新策略模式）
-// TODO: 重构为独立的策略结构体，避免未来函数问题
+// dealStrategysMode3 波段策略的旧实现，保留作为参考
+// 已废弃 - 请使用 strategies.NewSwingStrategy（signals.SwingSignal 实时判断卖出）
+// 警告：此实现存在未来函数问题（findSellPriceOptimized会查看未来数据）
 func dealStrategysMode3(code string, strategyMode int) map[string]OperateRecord {
 	operates := make(map[string]OperateRecord)
 
@@ -347,22 +352,22 @@ func dealStrategysMode3(code string, strategyMode int) map[string]OperateRecord
 	return operates
 }
 
-// dealStrategysMode4 大盘策略（待实现）
-// TODO: 实现为独立的策略结构体
+// dealStrategysMode4 大盘策略
+// 已实现为 strategies.NewMarketRegimeStrategy，此函数仅保留兼容
 func dealStrategysMode4(code string, strategyMode int) map[string]OperateRecord {
 	operates := make(map[string]OperateRecord)
 	return operates
 }
 
-// dealStrategysMode5 板块突破策略（待实现）
-// TODO: 实现为独立的策略结构体
+// dealStrategysMode5 板块突破策略
+// 已实现为 strategies.NewSectorBreakoutStrategy，此函数仅保留兼容
 func dealStrategysMode5(code string, strategyMode int) map[string]OperateRecord {
 	operates := make(map[string]OperateRecord)
 	return operates
 }
 
-// dealStrategysMode6 板块轮动策略（待实现）
-// TODO: 实现为独立的策略结构体
+// dealStrategysMode6 板块轮动策略
+// 已实现为 strategies.NewSectorRotationStrategy，此函数仅保留兼容
 func dealStrategysMode6(code string, strategyMode int) map[string]OperateRecord {
 	operates := make(map[string]OperateRecord)
 	return operates
@@ -452,6 +457,7 @@ func findSellPriceOptimized(stockData []StockData, startIndex int, buyPrice floa
 }
 
 // calculateMA 计算移动平均线（MA）
+// 新代码请使用 indicators.SMA（流式）或 indicators.CalculateSMA（批量）
 func calculateMA(stockData []StockData, index int, period int) float64 {
 	if index < period-1 {
 		return 0
@@ -465,6 +471,7 @@ func calculateMA(stockData []StockData, index int, period int) float64 {
 }
 
 // calculateRSI 计算相对强弱指标（RSI）
+// 新代码请使用 indicators.RSI（流式）或 indicators.CalculateRSI（批量）
 func calculateRSI(stockData []StockData, index int, period int) float64 {
 	if index < period {
 		return 50
@@ -572,8 +579,9 @@ func calculateStdDev(values []float64, mean float64) float64 {
 //
 // 主要变化：
 // 1. 策略1已重构，使用新的BuyHighSellLowStrategy，避免了未来函数问题
-// 2. 策略2-3保留旧实现，但标记为待重构（存在未来函数问题）
-// 3. 策略4-6尚未实现
+// 2. 策略2-3已重构为 strategies.BreakoutStrategy 和 strategies.SwingStrategy，旧实现仅保留作为参考
+// 3. 策略4-7只有新实现：strategies.MarketRegimeStrategy（大盘）、SectorBreakoutStrategy（板块突破）、
+//    SectorRotationStrategy（板块轮动）和 MomentumRankStrategy（动量排名），需使用 TimeBasedBacktestEngine
 // 4. 辅助函数保留供各策略共用
 //
 // 未来规划：
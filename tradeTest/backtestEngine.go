@@ -57,6 +57,8 @@ func (engine *BacktestEngine) Run() BacktestResult {
 	selectDateIndex := engine.getSelectDateIndex(allCodes)
 	fmt.Printf("选股时间点索引: %d\n", selectDateIndex)
 
+	// 旧引擎从全局缓存加载数据，选股器使用同一份数据
+	stockStrategy.InjectStockData(engine.strategy, stockData.StocksRaw)
 	selectedCodes := engine.strategy.GetSelector().SelectStocksAtDate(allCodes, selectDateIndex)
 	fmt.Printf("策略[%s]选股结果: %d只票票\n",
 		engine.strategy.GetName(), len(selectedCodes))
@@ -125,6 +127,7 @@ func (engine *BacktestEngine) backtestSingleStock(
 
 	// 2. 重置策略状态
 	signalGen.Reset()
+	window := stockStrategy.HistoryWindow(signalGen)
 
 	// 3. 逐日循环
 	var position *stockStrategy.Position = nil
@@ -134,7 +137,7 @@ func (engine *BacktestEngine) backtestSingleStock(
 		dayData := dayDatas[i]
 
 		// 4. 获取交易信号
-		signal := signalGen.ProcessDay(dayData, i, position)
+		signal := signalGen.ProcessDay(stockStrategy.NewBarContext(code, dayDatas, i, window), position)
 
 		// 5. 执行交易（当天开盘价执行）
 		if signal == 1 && position == nil { // 买入信号且当前空仓
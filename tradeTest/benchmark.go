@@ -0,0 +1,137 @@
+package tradeTest
+
+import (
+	"fmt"
+	"stock-go/logger"
+	"stock-go/stockData"
+	"stock-go/tradeTest/performance"
+)
+
+// 常用基准指数代码（数据文件为 DATA_PATH + 代码 + "_ALL.csv"）
+const (
+	BenchmarkSSEComposite = "sh.000001" // 上证指数
+	BenchmarkCSI300       = "sh.000300" // 沪深300
+	BenchmarkCSI500       = "sh.000905" // 中证500
+)
+
+// BenchmarkEquity 与 DailyEquity 按日期对齐的基准权益（从基准有数据的第一个日期开始）
+type BenchmarkEquity struct {
+	Date   string  // 日期
+	Price  float64 // 基准收盘点位（停牌或缺失时沿用前一日）
+	Equity float64 // 第一个共同日期以初始资金买入基准的权益
+}
+
+// SetBenchmark 设置基准指数代码，回测结束后计算相对基准的绩效
+// 基准数据需要通过 SetBenchmarkData 注入或包含在注入的票票数据中，缺少时不运行回测
+func (e *TimeBasedBacktestEngine) SetBenchmark(code string) {
+	e.benchmarkCode = code
+}
+
+// SetBenchmarkData 注入基准数据
+func (e *TimeBasedBacktestEngine) SetBenchmarkData(info *stockData.StockInfo) {
+	e.benchmarkData = info
+	if info != nil && e.benchmarkCode == "" {
+		e.benchmarkCode = info.Code
+	}
+}
+
+// loadBenchmark 查找基准数据：优先使用注入的基准数据，其次从注入的票票数据中按代码查找
+// 设置了基准却没有数据时返回错误
+func (e *TimeBasedBacktestEngine) loadBenchmark() error {
+	if e.benchmarkData == nil && e.benchmarkCode == "" {
+		return nil
+	}
+	if e.benchmarkData == nil {
+		e.benchmarkData = e.presetData[e.benchmarkCode]
+	}
+	if e.benchmarkData == nil || len(e.benchmarkData.Datas.DayDatas) == 0 {
+		return fmt.Errorf("缺少基准 %s 的数据，请通过 SetBenchmarkData 注入", e.benchmarkCode)
+	}
+	return nil
+}
+
+// buildBenchmarkEquity 生成与每日权益对齐的基准权益序列
+// 从基准有数据的第一个日期开始（之前的日期没有基准，不参与对比）；之后基准缺失的日期沿用前一日点位
+func (e *TimeBasedBacktestEngine) buildBenchmarkEquity(info *stockData.StockInfo) []BenchmarkEquity {
+	dayDatas := info.Datas.DayDatas
+	if len(dayDatas) == 0 || len(e.dailyEquity) == 0 {
+		return nil
+	}
+
+	series := make([]BenchmarkEquity, 0, len(e.dailyEquity))
+	idx := 0
+	price := 0.0
+	for _, day := range e.dailyEquity {
+		// 日期均为 2006-01-02 格式，可直接按字符串比较
+		for idx < len(dayDatas) && dayDatas[idx].DataStr <= day.Date {
+			price = float64(dayDatas[idx].PriceEnd)
+			idx++
+		}
+		if idx == 0 {
+			continue
+		}
+		series = append(series, BenchmarkEquity{Date: day.Date, Price: price})
+	}
+	if len(series) == 0 {
+		return nil
+	}
+
+	basePrice := series[0].Price
+	for i := range series {
+		if basePrice > 0 {
+			series[i].Equity = e.initialCash * series[i].Price / basePrice
+		}
+	}
+	return series
+}
+
+// compareBenchmark 计算相对基准的绩效并写入回测结果
+func (e *TimeBasedBacktestEngine) compareBenchmark(result *TimeBasedBacktestResult) {
+	info := e.benchmarkData
+	if info == nil {
+		return
+	}
+
+	result.BenchmarkCode = e.benchmarkCode
+	result.BenchmarkEquity = e.buildBenchmarkEquity(info)
+	if len(result.BenchmarkEquity) == 0 {
+		return
+	}
+
+	// 从第一个共同日期开始对比
+	start := result.BenchmarkEquity[0].Date
+	strategy := make([]performance.EquityPoint, 0, len(result.BenchmarkEquity))
+	for _, day := range result.DailyEquity {
+		if day.Date >= start {
+			strategy = append(strategy, performance.EquityPoint{Date: day.Date, TotalAssets: day.TotalAssets})
+		}
+	}
+	benchmark := make([]performance.EquityPoint, 0, len(result.BenchmarkEquity))
+	for _, day := range result.BenchmarkEquity {
+		benchmark = append(benchmark, performance.EquityPoint{Date: day.Date, TotalAssets: day.Equity})
+	}
+
+	result.Benchmark = performance.CompareBenchmark(strategy, benchmark, performance.NewDefaultConfig())
+}
+
+// printBenchmark 打印相对基准的绩效
+func printBenchmark(result *TimeBasedBacktestResult) {
+	rel := result.Benchmark
+	if rel == nil {
+		return
+	}
+
+	logger.Infof("")
+	logger.Infof("相对基准 %s:", result.BenchmarkCode)
+	logger.Infof("  基准收益率: %.2f%% (年化 %.2f%%)", rel.BenchmarkReturn, rel.BenchmarkAnnualized)
+	logger.Infof("  超额收益率: %.2f%%", rel.ExcessReturn)
+	logger.Infof("  阿尔法(年化): %.2f%%", rel.Alpha)
+	logger.Infof("  贝塔: %.2f", rel.Beta)
+	logger.Infof("  跟踪误差: %.2f%%", rel.TrackingError)
+	logger.Infof("  信息比率: %.2f", rel.InformationRatio)
+	logger.Infof("  上行捕获率: %.2f%%", rel.UpCapture)
+	logger.Infof("  下行捕获率: %.2f%%", rel.DownCapture)
+	for _, yr := range rel.YearlyExcess {
+		logger.Infof("  %s年: 策略 %.2f%% 基准 %.2f%% 超额 %.2f%%", yr.Year, yr.Return, yr.BenchmarkReturn, yr.Excess)
+	}
+}
@@ -0,0 +1,77 @@
+package tradeTest
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockStrategy/strategies"
+	"strings"
+	"testing"
+)
+
+// TestTimeBasedBacktestEngineBenchmark 测试基准权益与每日权益对齐并计算相对绩效
+func TestTimeBasedBacktestEngineBenchmark(t *testing.T) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "测试1", risingPrices(900, 10, 0.002)),
+	}
+	// 基准缺少前600天的数据，从基准的第一个日期开始对比
+	index := newSyntheticStock(BenchmarkCSI300, "沪深300", risingPrices(900, 1000, 0.001))
+	index.Datas.DayDatas = index.Datas.DayDatas[600:]
+
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 1, 1.0)
+	engine.SetStockData(data)
+	engine.SetBenchmarkData(index)
+
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	if result.BenchmarkCode != BenchmarkCSI300 {
+		t.Errorf("基准代码 %s，期望 %s", result.BenchmarkCode, BenchmarkCSI300)
+	}
+	start := index.Datas.DayDatas[0].DataStr
+	offset := 0
+	for offset < len(result.DailyEquity) && result.DailyEquity[offset].Date < start {
+		offset++
+	}
+	if offset == 0 || len(result.BenchmarkEquity) != len(result.DailyEquity)-offset {
+		t.Fatalf("基准权益长度 %d，期望从第%d天（%s）开始到每日权益结束（%d天）", len(result.BenchmarkEquity), offset, start, len(result.DailyEquity))
+	}
+	for i, day := range result.BenchmarkEquity {
+		if day.Date != result.DailyEquity[offset+i].Date {
+			t.Fatalf("第%d天基准日期 %s 与权益日期 %s 不一致", i, day.Date, result.DailyEquity[offset+i].Date)
+		}
+	}
+
+	// 基准开始之前没有点位，不使用基准之后的价格填充
+	first := result.BenchmarkEquity[0]
+	if first.Date != start || first.Equity != 1000000 || first.Price != float64(index.Datas.DayDatas[0].PriceEnd) {
+		t.Errorf("基准起始权益 %+v 不正确", first)
+	}
+
+	if result.Benchmark == nil {
+		t.Fatal("相对基准的绩效为空")
+	}
+	if result.Benchmark.BenchmarkReturn <= 0 {
+		t.Errorf("基准收益率 %.2f%% 应为正", result.Benchmark.BenchmarkReturn)
+	}
+}
+
+// TestBenchmarkDataRequired 测试设置了基准却没有注入数据时不运行回测并返回原因
+func TestBenchmarkDataRequired(t *testing.T) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "测试1", risingPrices(900, 10, 0.002)),
+	}
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 1, 1.0)
+	engine.SetStockData(data)
+	engine.SetQuiet(true)
+	engine.SetBenchmark(BenchmarkCSI300)
+	if result := engine.Run(); result != nil || engine.Err() == nil || !strings.Contains(engine.Err().Error(), BenchmarkCSI300) {
+		t.Fatalf("缺少基准数据时应返回错误，结果 %v 错误 %v", result != nil, engine.Err())
+	}
+
+	// 注入的票票数据包含基准时直接使用
+	data[BenchmarkCSI300] = newSyntheticStock(BenchmarkCSI300, "沪深300", risingPrices(900, 1000, 0.001))
+	if result := engine.Run(); result == nil || result.Benchmark == nil {
+		t.Fatalf("应使用票票数据中的基准: %v", engine.Err())
+	}
+}
@@ -0,0 +1,56 @@
+package tradeTest
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockStrategy/strategies"
+	"testing"
+)
+
+// TestTimeBasedBacktestEngineDateRange 测试只在指定日期范围内交易和记录权益
+func TestTimeBasedBacktestEngineDateRange(t *testing.T) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "测试1", risingPrices(900, 10, 0.002)),
+	}
+	days := TradingDays(data)
+	if len(days) != 400 {
+		t.Fatalf("交易日数量 %d，期望 400", len(days))
+	}
+	// 信号需要300天预热，日期范围从第320个交易日开始
+	startDate, endDate := days[320], days[379]
+
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 1, 1.0)
+	engine.SetStockData(data)
+	engine.SetDateRange(startDate, endDate)
+
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	if len(result.DailyEquity) != 60 {
+		t.Fatalf("权益记录 %d 天，期望 60", len(result.DailyEquity))
+	}
+	if result.DailyEquity[0].Date != startDate || result.DailyEquity[59].Date != endDate {
+		t.Errorf("权益日期范围 %s~%s，期望 %s~%s",
+			result.DailyEquity[0].Date, result.DailyEquity[59].Date, startDate, endDate)
+	}
+
+	// 预热后开始日期即可产生买入信号，所有交易都在范围内，结束日强制平仓
+	if findFirstTrade(result, "buy") == nil {
+		t.Fatal("日期范围内没有买入")
+	}
+	held := 0
+	for _, record := range result.TradeRecords {
+		if record.Date < startDate || record.Date > endDate {
+			t.Errorf("交易日期 %s 超出范围", record.Date)
+		}
+		if record.Action == "buy" {
+			held += record.StockNum
+		} else {
+			held -= record.StockNum
+		}
+	}
+	if held != 0 {
+		t.Errorf("结束日未平仓，剩余 %d 股", held)
+	}
+}
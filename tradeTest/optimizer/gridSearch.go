@@ -0,0 +1,176 @@
+package optimizer
+
+import (
+	"runtime"
+	"sort"
+	"stock-go/logger"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/strategies"
+	"stock-go/tradeTest"
+	"sync"
+	"time"
+)
+
+// StrategyFactory 根据参数创建策略
+// 每次回测都会调用一次，必须返回新的策略实例（信号生成器带有内部状态，不能在并发回测间共享）
+type StrategyFactory func(params ParamSet) stockStrategy.Strategy
+
+// EngineOption 创建回测引擎后的额外设置（如换仓、加仓、基准等）
+type EngineOption func(engine *tradeTest.TimeBasedBacktestEngine, params ParamSet)
+
+// BuyHighSellLowFactory 追涨杀跌策略的工厂函数
+// 支持的参数：selectorLookback、selectorRecent、signalLookback、signalDropPercent、signalMaxHoldDays，未指定的使用默认值
+func BuyHighSellLowFactory(params ParamSet) stockStrategy.Strategy {
+	return strategies.NewBuyHighSellLowStrategyWithParams(
+		params.Int("selectorLookback", 500),
+		params.Int("selectorRecent", 15),
+		params.Int("signalLookback", 300),
+		params.Float("signalDropPercent", 0.06),
+		params.Int("signalMaxHoldDays", 30),
+	)
+}
+
+// RunResult 单组参数的回测结果
+type RunResult struct {
+	Params ParamSet
+	Score  float64                            // 目标函数得分
+	Result *tradeTest.TimeBasedBacktestResult // 回测结果（回测失败时为nil）
+}
+
+// GridSearch 网格搜索参数寻优
+// 对所有参数组合在有限数量的协程中并发回测，所有回测共享同一份只读票票数据
+type GridSearch struct {
+	Ranges          []ParamRange                    // 参数范围
+	Factory         StrategyFactory                 // 策略工厂
+	Data            map[string]*stockData.StockInfo // 共享的票票数据（只读）
+	Benchmark       *stockData.StockInfo            // 共享的基准数据（只读，可为nil）
+	InitialCash     float64                         // 初始资金
+	MaxPositions    int                             // 最大持仓数
+	CashPerPosition float64                         // 每仓位资金比例
+	Workers         int                             // 并发协程数，默认CPU核数
+	Objective       Objective                       // 排序依据
+	Options         []EngineOption                  // 引擎额外设置
+	StartDate       string                          // 回测开始日期（空表示不限制）
+	EndDate         string                          // 回测结束日期（空表示不限制）
+}
+
+// NewGridSearch 创建网格搜索（默认100万资金、4只持仓、每仓100%、按夏普排序）
+func NewGridSearch(factory StrategyFactory, data map[string]*stockData.StockInfo, ranges ...ParamRange) *GridSearch {
+	return &GridSearch{
+		Ranges:          ranges,
+		Factory:         factory,
+		Data:            data,
+		InitialCash:     1000000,
+		MaxPositions:    4,
+		CashPerPosition: 1.0,
+		Workers:         runtime.NumCPU(),
+		Objective:       ObjectiveSharpe,
+	}
+}
+
+// WithOption 增加引擎设置
+func (g *GridSearch) WithOption(option EngineOption) *GridSearch {
+	g.Options = append(g.Options, option)
+	return g
+}
+
+// Run 执行网格搜索，返回按得分从高到低排序的结果
+func (g *GridSearch) Run() []RunResult {
+	combos := Grid(g.Ranges)
+	results := make([]RunResult, len(combos))
+
+	workers := g.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(combos) {
+		workers = len(combos)
+	}
+
+	logger.Infof("网格搜索: %d 组参数, %d 个协程, 目标: %s", len(combos), workers, g.Objective.GetName())
+	start := time.Now()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = g.runOne(combos[i])
+
+				mu.Lock()
+				done++
+				logger.Infof("网格搜索进度 %d/%d: %s 得分 %.4f", done, len(combos), combos[i].String(), results[i].Score)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range combos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	SortResults(results)
+	logger.Infof("网格搜索完成，耗时 %v", time.Since(start))
+	return results
+}
+
+// runOne 使用一组参数回测
+func (g *GridSearch) runOne(params ParamSet) RunResult {
+	engine := g.newEngine(params)
+	result := engine.Run()
+	return RunResult{
+		Params: params,
+		Score:  g.Objective.Score(result),
+		Result: result,
+	}
+}
+
+// newEngine 按搜索配置创建回测引擎
+func (g *GridSearch) newEngine(params ParamSet) *tradeTest.TimeBasedBacktestEngine {
+	engine := tradeTest.NewTimeBasedBacktestEngine(g.InitialCash, g.Factory(params), g.MaxPositions, g.CashPerPosition)
+	engine.SetStockData(g.Data)
+	engine.SetQuiet(true)
+	engine.SetDateRange(g.StartDate, g.EndDate)
+	if g.Benchmark != nil {
+		engine.SetBenchmarkData(g.Benchmark)
+	}
+	for _, option := range g.Options {
+		option(engine, params)
+	}
+	return engine
+}
+
+// SortResults 按得分从高到低排序（回测失败的排在最后）
+func SortResults(results []RunResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Result == nil) != (results[j].Result == nil) {
+			return results[i].Result != nil
+		}
+		return results[i].Score > results[j].Score
+	})
+}
+
+// LoadStockData 顺序加载票票数据，供多个回测共享
+// 需在并发回测前调用：全局数据缓存不支持并发写入
+func LoadStockData(codes []string) map[string]*stockData.StockInfo {
+	data := make(map[string]*stockData.StockInfo, len(codes))
+	for _, code := range codes {
+		info := stockData.GetStockRawBycode(code)
+		if info == nil || len(info.Datas.DayDatas) == 0 {
+			continue
+		}
+		if info.Name == "" {
+			info.Name = stockData.StockList[code]
+		}
+		data[code] = info
+	}
+	return data
+}
@@ -0,0 +1,54 @@
+package optimizer
+
+import (
+	"math"
+	"stock-go/tradeTest"
+)
+
+// Objective 参数寻优的目标函数
+type Objective int
+
+const (
+	ObjectiveSharpe Objective = iota // 夏普比率
+	ObjectiveCAGR                    // 年化收益率
+	ObjectiveCalmar                  // 卡玛比率（年化收益率/最大回撤）
+)
+
+// GetName 获取目标函数名称
+func (o Objective) GetName() string {
+	switch o {
+	case ObjectiveCAGR:
+		return "年化收益率"
+	case ObjectiveCalmar:
+		return "卡玛比率"
+	}
+	return "夏普比率"
+}
+
+// maxCalmar 卡玛比率得分的上限：没有回撤或回撤极小时卡玛比率没有意义，按上限计分
+const maxCalmar = 100.0
+
+// Score 计算回测结果在目标函数下的得分（越大越好）
+// 没有回撤且年化收益为正时卡玛比率得分为 maxCalmar（而不是0）；指标为 NaN 等非有限值时得分为0，避免打乱排序
+func (o Objective) Score(result *tradeTest.TimeBasedBacktestResult) float64 {
+	if result == nil || result.Performance == nil {
+		return 0
+	}
+
+	perf := result.Performance
+	score := perf.SharpeRatio
+	switch o {
+	case ObjectiveCAGR:
+		score = perf.AnnualizedReturn
+	case ObjectiveCalmar:
+		score = perf.CalmarRatio
+		if perf.MaxDrawdown <= 0 && perf.AnnualizedReturn > 0 {
+			score = maxCalmar
+		}
+		score = min(score, maxCalmar)
+	}
+	if math.IsInf(score, 0) || math.IsNaN(score) {
+		return 0
+	}
+	return score
+}
@@ -0,0 +1,147 @@
+package optimizer
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"path/filepath"
+	"stock-go/stockData"
+	"stock-go/stockData/synthetic"
+	"stock-go/tradeTest"
+	"stock-go/tradeTest/performance"
+	"testing"
+)
+
+// syntheticStock 构造测试用票票数据（价格按固定比例上涨并带周期波动）
+func syntheticStock(code string, days int, start, rate float64) *stockData.StockInfo {
+	prices := make([]float64, days)
+	price := start
+	for i := range prices {
+		// 每40天一个周期：前30天上涨，后10天回落
+		if i%40 < 30 {
+			price *= 1 + rate
+		} else {
+			price *= 1 - 2*rate
+		}
+		prices[i] = price
+	}
+	return synthetic.FromPrices(code, prices)
+}
+
+func testData() map[string]*stockData.StockInfo {
+	return map[string]*stockData.StockInfo{
+		"sz.000001": syntheticStock("sz.000001", 900, 10, 0.004),
+		"sz.000002": syntheticStock("sz.000002", 900, 20, 0.003),
+	}
+}
+
+// TestObjectiveScore 测试卡玛比率得分：没有回撤时按上限计分，非有限值得分为0
+func TestObjectiveScore(t *testing.T) {
+	score := func(o Objective, report performance.Report) float64 {
+		return o.Score(&tradeTest.TimeBasedBacktestResult{Performance: &report})
+	}
+	if got := score(ObjectiveCalmar, performance.Report{AnnualizedReturn: 12, MaxDrawdown: 6, CalmarRatio: 2}); got != 2 {
+		t.Errorf("卡玛比率得分 %v，期望2", got)
+	}
+	if got := score(ObjectiveCalmar, performance.Report{AnnualizedReturn: 12}); got != maxCalmar {
+		t.Errorf("没有回撤时得分 %v，期望 %v", got, maxCalmar)
+	}
+	if got := score(ObjectiveCalmar, performance.Report{AnnualizedReturn: 12, MaxDrawdown: 0.01, CalmarRatio: 1200}); got != maxCalmar {
+		t.Errorf("回撤极小时得分 %v，期望 %v", got, maxCalmar)
+	}
+	if got := score(ObjectiveCalmar, performance.Report{}); got != 0 {
+		t.Errorf("没有收益也没有回撤时得分 %v，期望0", got)
+	}
+	if got := score(ObjectiveSharpe, performance.Report{SharpeRatio: math.NaN()}); got != 0 {
+		t.Errorf("NaN 的得分 %v，期望0", got)
+	}
+}
+
+// TestGrid 测试参数组合生成
+func TestGrid(t *testing.T) {
+	r := NewRange("signalDropPercent", 0.04, 0.1, 0.02)
+	if len(r.Values) != 4 || r.Values[3] != 0.1 {
+		t.Fatalf("取值范围 %v 不正确", r.Values)
+	}
+
+	combos := Grid([]ParamRange{r, NewValues("signalMaxHoldDays", 20, 30, 40)})
+	if len(combos) != 12 {
+		t.Fatalf("参数组合数 %d，期望 12", len(combos))
+	}
+	if combos[0].Float("signalDropPercent", 0) != 0.04 || combos[0].Int("signalMaxHoldDays", 0) != 20 {
+		t.Errorf("第一组参数 %v 不正确", combos[0])
+	}
+	if combos[0].Int("signalLookback", 300) != 300 {
+		t.Errorf("未指定的参数应返回默认值")
+	}
+}
+
+// TestGridSearchConcurrent 测试并发寻优结果与顺序执行一致
+func TestGridSearchConcurrent(t *testing.T) {
+	ranges := []ParamRange{
+		NewValues("signalLookback", 100, 200),
+		NewValues("signalMaxHoldDays", 10, 30),
+		NewValues("signalDropPercent", 0.03, 0.06),
+	}
+	data := testData()
+
+	sequential := NewGridSearch(BuyHighSellLowFactory, data, ranges...)
+	sequential.Workers = 1
+	expected := sequential.Run()
+
+	concurrent := NewGridSearch(BuyHighSellLowFactory, data, ranges...)
+	concurrent.Workers = 4
+	results := concurrent.Run()
+
+	if len(results) != 8 || len(expected) != 8 {
+		t.Fatalf("结果数量 %d/%d，期望 8", len(results), len(expected))
+	}
+
+	for i := range results {
+		if results[i].Result == nil {
+			t.Fatalf("第%d组回测失败", i+1)
+		}
+		if i > 0 && results[i].Score > results[i-1].Score {
+			t.Errorf("结果未按得分排序: %.4f > %.4f", results[i].Score, results[i-1].Score)
+		}
+		if results[i].Params.String() != expected[i].Params.String() ||
+			results[i].Result.FinalAssets != expected[i].Result.FinalAssets {
+			t.Errorf("第%d组并发结果与顺序结果不一致: %s %.2f vs %s %.2f", i+1,
+				results[i].Params.String(), results[i].Result.FinalAssets,
+				expected[i].Params.String(), expected[i].Result.FinalAssets)
+		}
+	}
+
+	// 共享数据未被修改
+	if len(data["sz.000001"].Datas.DayDatas) != 900 {
+		t.Errorf("共享数据被修改")
+	}
+}
+
+// TestWriteResults 测试写出结果表
+func TestWriteResults(t *testing.T) {
+	search := NewGridSearch(BuyHighSellLowFactory, testData(), NewValues("signalMaxHoldDays", 10, 30))
+	search.Objective = ObjectiveCalmar
+	results := search.Run()
+
+	fileName := filepath.Join(t.TempDir(), "grid.csv")
+	if err := WriteResults(fileName, results); err != nil {
+		t.Fatalf("写出结果失败: %v", err)
+	}
+
+	fs, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+	rows, err := csv.NewReader(fs).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("结果表行数 %d，期望 3", len(rows))
+	}
+	if rows[0][0] != "rank" || rows[0][1] != "signalMaxHoldDays" || rows[0][2] != "score" {
+		t.Errorf("表头 %v 不正确", rows[0])
+	}
+}
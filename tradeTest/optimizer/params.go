@@ -0,0 +1,99 @@
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ParamRange 单个参数的取值范围
+type ParamRange struct {
+	Name   string    // 参数名
+	Values []float64 // 所有候选取值
+}
+
+// NewRange 创建等差取值范围 [min, max]，步长为 step
+func NewRange(name string, min, max, step float64) ParamRange {
+	values := make([]float64, 0)
+	if step <= 0 {
+		return ParamRange{Name: name, Values: []float64{min}}
+	}
+	// 按步数生成，避免浮点累加误差
+	steps := int(math.Floor((max-min)/step + 1e-9))
+	for i := 0; i <= steps; i++ {
+		values = append(values, roundValue(min+float64(i)*step))
+	}
+	return ParamRange{Name: name, Values: values}
+}
+
+// NewValues 创建指定取值列表
+func NewValues(name string, values ...float64) ParamRange {
+	return ParamRange{Name: name, Values: values}
+}
+
+// ParamSet 一组参数取值（key: 参数名）
+type ParamSet map[string]float64
+
+// Int 获取整数参数，不存在时返回默认值
+func (p ParamSet) Int(name string, defaultValue int) int {
+	if v, ok := p[name]; ok {
+		return int(math.Round(v))
+	}
+	return defaultValue
+}
+
+// Float 获取浮点参数，不存在时返回默认值
+func (p ParamSet) Float(name string, defaultValue float64) float64 {
+	if v, ok := p[name]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+// Names 按名称排序的参数名列表
+func (p ParamSet) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String 参数的可读表示，如 signalLookback=300 signalMaxHoldDays=30
+func (p ParamSet) String() string {
+	parts := make([]string, 0, len(p))
+	for _, name := range p.Names() {
+		parts = append(parts, fmt.Sprintf("%s=%g", name, p[name]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Grid 生成所有参数组合（笛卡尔积），顺序固定
+func Grid(ranges []ParamRange) []ParamSet {
+	combos := []ParamSet{{}}
+	for _, r := range ranges {
+		if len(r.Values) == 0 {
+			continue
+		}
+		next := make([]ParamSet, 0, len(combos)*len(r.Values))
+		for _, combo := range combos {
+			for _, v := range r.Values {
+				params := make(ParamSet, len(combo)+1)
+				for k, val := range combo {
+					params[k] = val
+				}
+				params[r.Name] = v
+				next = append(next, params)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// roundValue 去除浮点误差（保留10位小数）
+func roundValue(v float64) float64 {
+	return math.Round(v*1e10) / 1e10
+}
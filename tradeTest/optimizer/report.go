@@ -0,0 +1,105 @@
+package optimizer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"stock-go/logger"
+	"strconv"
+)
+
+// resultColumns 结果表的指标列
+var resultColumns = []string{
+	"score", "total_return", "cagr", "sharpe", "sortino", "calmar",
+	"max_drawdown", "win_rate", "trades", "profit_factor", "exposure",
+}
+
+// WriteResults 将寻优结果写入CSV文件（每组参数一行，按得分排序）
+func WriteResults(fileName string, results []RunResult) error {
+	fs, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	w := csv.NewWriter(fs)
+
+	paramNames := make([]string, 0)
+	if len(results) > 0 {
+		paramNames = results[0].Params.Names()
+	}
+
+	header := append([]string{"rank"}, paramNames...)
+	header = append(header, resultColumns...)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for i, r := range results {
+		row := []string{strconv.Itoa(i + 1)}
+		for _, name := range paramNames {
+			row = append(row, strconv.FormatFloat(r.Params[name], 'g', -1, 64))
+		}
+		row = append(row, metricRow(r)...)
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// metricRow 一组参数的指标列
+func metricRow(r RunResult) []string {
+	if r.Result == nil || r.Result.Performance == nil {
+		row := make([]string, len(resultColumns))
+		row[0] = "NaN"
+		return row
+	}
+
+	perf := r.Result.Performance
+	f := func(v float64) string {
+		// 没有亏损交易时盈亏比为 +Inf，写为 inf 便于表格工具识别
+		if math.IsInf(v, 1) {
+			return "inf"
+		}
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	}
+	return []string{
+		f(r.Score),
+		f(perf.TotalReturn),
+		f(perf.AnnualizedReturn),
+		f(perf.SharpeRatio),
+		f(perf.SortinoRatio),
+		f(perf.CalmarRatio),
+		f(perf.MaxDrawdown),
+		f(r.Result.WinRate),
+		strconv.Itoa(r.Result.TotalTrades),
+		f(perf.ProfitFactor),
+		f(perf.Exposure),
+	}
+}
+
+// PrintTop 打印得分最高的前N组参数
+func PrintTop(results []RunResult, n int) {
+	if n > len(results) {
+		n = len(results)
+	}
+
+	logger.Infof("========================================")
+	logger.Infof("参数寻优结果（前 %d 组）", n)
+	logger.Infof("========================================")
+	for i := 0; i < n; i++ {
+		r := results[i]
+		summary := "回测失败"
+		if r.Result != nil && r.Result.Performance != nil {
+			perf := r.Result.Performance
+			summary = fmt.Sprintf("收益 %.2f%% 年化 %.2f%% 夏普 %.2f 卡玛 %.2f 回撤 %.2f%% 交易 %d",
+				perf.TotalReturn, perf.AnnualizedReturn, perf.SharpeRatio, perf.CalmarRatio,
+				perf.MaxDrawdown, r.Result.TotalTrades)
+		}
+		logger.Infof("%d. [%.4f] %s | %s", i+1, r.Score, r.Params.String(), summary)
+	}
+}
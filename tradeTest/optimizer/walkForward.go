@@ -0,0 +1,265 @@
+package optimizer
+
+import (
+	"math"
+	"stock-go/logger"
+	"stock-go/tradeTest"
+	"stock-go/tradeTest/performance"
+)
+
+// WindowMode 样本内窗口的滚动方式
+type WindowMode int
+
+const (
+	WindowRolling  WindowMode = iota // 滚动窗口：样本内窗口长度固定，随时间向后移动
+	WindowAnchored                   // 锚定窗口：样本内窗口起点固定在第一个交易日，长度逐步增加
+)
+
+// WalkForward 滚动前推优化
+// 在每个样本内窗口上网格搜索最优参数，然后用该参数回测紧接着的样本外窗口，
+// 所有样本外窗口的权益曲线首尾相接，作为对策略真实表现的估计
+type WalkForward struct {
+	Search        *GridSearch // 参数范围、策略工厂、数据、目标函数等配置
+	InSampleDays  int         // 样本内窗口交易日数（锚定模式为第一个窗口的长度）
+	OutSampleDays int         // 样本外窗口交易日数（同时是窗口的前推步长）
+	Mode          WindowMode  // 窗口方式
+}
+
+// WalkForwardWindow 单个前推窗口的结果
+type WalkForwardWindow struct {
+	InSampleStart  string
+	InSampleEnd    string
+	OutSampleStart string
+	OutSampleEnd   string
+	BestParams     ParamSet                           // 样本内最优参数
+	InSampleScore  float64                            // 样本内得分
+	OutSampleScore float64                            // 样本外得分
+	OutSample      *tradeTest.TimeBasedBacktestResult // 样本外回测结果（窗口结束时强制平仓）
+}
+
+// ParamStability 参数在各窗口间的稳定性
+type ParamStability struct {
+	Name   string
+	Values []float64 // 各窗口的最优取值
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Max    float64
+	CV     float64 // 变异系数（标准差/均值的绝对值），越小越稳定
+}
+
+// WalkForwardResult 滚动前推优化结果
+type WalkForwardResult struct {
+	Windows        []WalkForwardWindow
+	Equity         []tradeTest.DailyEquity // 拼接后的样本外权益曲线（以初始资金开始）
+	Performance    *performance.Report     // 拼接后样本外权益的绩效
+	ParamStability []ParamStability        // 参数稳定性表
+	Efficiency     float64                 // 前推效率：样本外平均得分/样本内平均得分，样本内平均得分不为正时为 NaN
+}
+
+// NewWalkForward 创建滚动前推优化
+func NewWalkForward(search *GridSearch, inSampleDays, outSampleDays int, mode WindowMode) *WalkForward {
+	return &WalkForward{
+		Search:        search,
+		InSampleDays:  inSampleDays,
+		OutSampleDays: outSampleDays,
+		Mode:          mode,
+	}
+}
+
+// windowBounds 窗口在交易日列表中的下标 [isStart, isEnd] 和 [oosStart, oosEnd]
+type windowBounds struct {
+	isStart, isEnd, oosStart, oosEnd int
+}
+
+// windows 根据交易日数量划分前推窗口，最后一个样本外窗口不足时截断到最后一天
+func (w *WalkForward) windows(totalDays int) []windowBounds {
+	bounds := make([]windowBounds, 0)
+	if w.InSampleDays <= 0 || w.OutSampleDays <= 0 {
+		return bounds
+	}
+
+	for oosStart := w.InSampleDays; oosStart < totalDays; oosStart += w.OutSampleDays {
+		b := windowBounds{
+			isStart:  oosStart - w.InSampleDays,
+			isEnd:    oosStart - 1,
+			oosStart: oosStart,
+			oosEnd:   oosStart + w.OutSampleDays - 1,
+		}
+		if w.Mode == WindowAnchored {
+			b.isStart = 0
+		}
+		if b.oosEnd >= totalDays {
+			b.oosEnd = totalDays - 1
+		}
+		bounds = append(bounds, b)
+	}
+	return bounds
+}
+
+// Run 执行滚动前推优化
+func (w *WalkForward) Run() *WalkForwardResult {
+	days := tradeTest.TradingDays(w.Search.Data)
+	bounds := w.windows(len(days))
+	result := &WalkForwardResult{}
+	logger.Infof("滚动前推优化: %d 个交易日, %d 个窗口 (样本内 %d 天, 样本外 %d 天)",
+		len(days), len(bounds), w.InSampleDays, w.OutSampleDays)
+
+	for i, b := range bounds {
+		window := WalkForwardWindow{
+			InSampleStart:  days[b.isStart],
+			InSampleEnd:    days[b.isEnd],
+			OutSampleStart: days[b.oosStart],
+			OutSampleEnd:   days[b.oosEnd],
+		}
+
+		// 1. 样本内寻优
+		inSample := *w.Search
+		inSample.StartDate, inSample.EndDate = window.InSampleStart, window.InSampleEnd
+		ranked := inSample.Run()
+		if len(ranked) == 0 || ranked[0].Result == nil {
+			logger.Infof("窗口 %d 样本内寻优失败，跳过", i+1)
+			continue
+		}
+		window.BestParams = ranked[0].Params
+		window.InSampleScore = ranked[0].Score
+
+		// 2. 最优参数应用到样本外窗口
+		outSample := *w.Search
+		outSample.StartDate, outSample.EndDate = window.OutSampleStart, window.OutSampleEnd
+		oos := outSample.runOne(window.BestParams)
+		window.OutSample = oos.Result
+		window.OutSampleScore = oos.Score
+
+		logger.Infof("窗口 %d: 样本内 %s~%s 最优 %s (%.4f) | 样本外 %s~%s 得分 %.4f",
+			i+1, window.InSampleStart, window.InSampleEnd, window.BestParams.String(), window.InSampleScore,
+			window.OutSampleStart, window.OutSampleEnd, window.OutSampleScore)
+		result.Windows = append(result.Windows, window)
+	}
+
+	result.Equity = stitchEquity(result.Windows, w.Search.InitialCash)
+	result.Performance = analyzeStitched(result.Equity, result.Windows)
+	result.ParamStability = paramStability(result.Windows)
+	result.Efficiency = efficiency(result.Windows)
+	return result
+}
+
+// stitchEquity 拼接各样本外窗口的权益曲线：每个窗口按上一个窗口的期末权益等比例缩放
+func stitchEquity(windows []WalkForwardWindow, initialCash float64) []tradeTest.DailyEquity {
+	equity := make([]tradeTest.DailyEquity, 0)
+	capital := initialCash
+	for _, window := range windows {
+		if window.OutSample == nil || len(window.OutSample.DailyEquity) == 0 || window.OutSample.InitialCash <= 0 {
+			continue
+		}
+
+		scale := capital / window.OutSample.InitialCash
+		for _, day := range window.OutSample.DailyEquity {
+			equity = append(equity, tradeTest.DailyEquity{
+				Date:          day.Date,
+				Cash:          day.Cash * scale,
+				PositionValue: day.PositionValue * scale,
+				TotalAssets:   day.TotalAssets * scale,
+				PositionCount: day.PositionCount,
+			})
+		}
+		// 窗口结束时强制平仓，以扣除平仓手续费后的现金作为下一个窗口的起始资金
+		capital = window.OutSample.FinalCash * scale
+	}
+	return equity
+}
+
+// analyzeStitched 计算拼接后样本外权益的绩效
+func analyzeStitched(equity []tradeTest.DailyEquity, windows []WalkForwardWindow) *performance.Report {
+	points := tradeTest.EquityPoints(equity)
+
+	trades := make([]performance.Trade, 0)
+	for _, window := range windows {
+		if window.OutSample == nil || window.OutSample.Performance == nil {
+			continue
+		}
+		trades = append(trades, tradeTest.PerformanceTrades(window.OutSample.ClosedTrades)...)
+	}
+
+	return performance.Analyze(points, trades, performance.NewDefaultConfig())
+}
+
+// paramStability 统计每个参数在各窗口最优取值的分布
+func paramStability(windows []WalkForwardWindow) []ParamStability {
+	if len(windows) == 0 {
+		return nil
+	}
+
+	table := make([]ParamStability, 0)
+	for _, name := range windows[0].BestParams.Names() {
+		ps := ParamStability{Name: name, Min: math.Inf(1), Max: math.Inf(-1)}
+		for _, window := range windows {
+			v := window.BestParams[name]
+			ps.Values = append(ps.Values, v)
+			ps.Mean += v
+			ps.Min = math.Min(ps.Min, v)
+			ps.Max = math.Max(ps.Max, v)
+		}
+		ps.Mean /= float64(len(ps.Values))
+
+		for _, v := range ps.Values {
+			ps.StdDev += (v - ps.Mean) * (v - ps.Mean)
+		}
+		ps.StdDev = math.Sqrt(ps.StdDev / float64(len(ps.Values)))
+		if ps.Mean != 0 {
+			ps.CV = ps.StdDev / math.Abs(ps.Mean)
+		}
+		table = append(table, ps)
+	}
+	return table
+}
+
+// efficiency 前推效率：样本外平均得分/样本内平均得分
+// 样本内平均得分不为正时比值没有意义，返回 NaN
+func efficiency(windows []WalkForwardWindow) float64 {
+	if len(windows) == 0 {
+		return math.NaN()
+	}
+
+	inSample, outSample := 0.0, 0.0
+	for _, window := range windows {
+		inSample += window.InSampleScore
+		outSample += window.OutSampleScore
+	}
+	if inSample <= 0 {
+		return math.NaN()
+	}
+	return outSample / inSample
+}
+
+// PrintWalkForward 打印滚动前推优化结果
+func PrintWalkForward(result *WalkForwardResult) {
+	logger.Infof("========================================")
+	logger.Infof("滚动前推优化结果")
+	logger.Infof("========================================")
+	for i, window := range result.Windows {
+		logger.Infof("窗口 %d: 样本外 %s~%s 参数 %s 样本内 %.4f 样本外 %.4f",
+			i+1, window.OutSampleStart, window.OutSampleEnd, window.BestParams.String(),
+			window.InSampleScore, window.OutSampleScore)
+	}
+
+	if perf := result.Performance; perf != nil {
+		logger.Infof("")
+		logger.Infof("样本外拼接绩效:")
+		logger.Infof("  总收益率: %.2f%% 年化: %.2f%%", perf.TotalReturn, perf.AnnualizedReturn)
+		logger.Infof("  夏普: %.2f 卡玛: %.2f 最大回撤: %.2f%%", perf.SharpeRatio, perf.CalmarRatio, perf.MaxDrawdown)
+		if math.IsNaN(result.Efficiency) {
+			logger.Infof("  前推效率: 无（样本内平均得分不为正）")
+		} else {
+			logger.Infof("  前推效率: %.2f", result.Efficiency)
+		}
+	}
+
+	logger.Infof("")
+	logger.Infof("参数稳定性:")
+	for _, ps := range result.ParamStability {
+		logger.Infof("  %s: 均值 %.4g 标准差 %.4g 范围 [%.4g, %.4g] 变异系数 %.2f 取值 %v",
+			ps.Name, ps.Mean, ps.StdDev, ps.Min, ps.Max, ps.CV, ps.Values)
+	}
+	logger.Infof("========================================")
+}
@@ -0,0 +1,92 @@
+package optimizer
+
+import (
+	"math"
+	"stock-go/tradeTest"
+	"testing"
+)
+
+// TestWalkForwardWindows 测试滚动和锚定窗口划分
+func TestWalkForwardWindows(t *testing.T) {
+	rolling := NewWalkForward(nil, 100, 50, WindowRolling).windows(260)
+	if len(rolling) != 4 {
+		t.Fatalf("滚动窗口数 %d，期望 4", len(rolling))
+	}
+	if rolling[1].isStart != 50 || rolling[1].isEnd != 149 || rolling[1].oosStart != 150 || rolling[1].oosEnd != 199 {
+		t.Errorf("第二个滚动窗口 %+v 不正确", rolling[1])
+	}
+	// 最后一个样本外窗口截断到最后一天
+	if rolling[3].oosStart != 250 || rolling[3].oosEnd != 259 {
+		t.Errorf("最后一个窗口 %+v 不正确", rolling[3])
+	}
+
+	anchored := NewWalkForward(nil, 100, 50, WindowAnchored).windows(260)
+	for _, b := range anchored {
+		if b.isStart != 0 || b.isEnd != b.oosStart-1 {
+			t.Errorf("锚定窗口 %+v 不正确", b)
+		}
+	}
+}
+
+// TestWalkForwardRun 测试样本外权益拼接和参数稳定性表
+func TestWalkForwardRun(t *testing.T) {
+	data := testData()
+	search := NewGridSearch(BuyHighSellLowFactory, data,
+		NewValues("signalLookback", 60, 120),
+		NewValues("signalMaxHoldDays", 10, 30),
+	)
+	search.Workers = 2
+
+	result := NewWalkForward(search, 200, 100, WindowRolling).Run()
+	if len(result.Windows) != 2 {
+		t.Fatalf("窗口数 %d，期望 2", len(result.Windows))
+	}
+
+	days := tradeTest.TradingDays(data)
+	if len(result.Equity) != 200 {
+		t.Fatalf("拼接权益 %d 天，期望 200", len(result.Equity))
+	}
+	for i, day := range result.Equity {
+		if day.Date != days[200+i] {
+			t.Fatalf("第%d天拼接日期 %s，期望 %s", i, day.Date, days[200+i])
+		}
+	}
+
+	// 第二个窗口的起点按第一个窗口强制平仓后的现金缩放
+	first := result.Windows[0].OutSample
+	second := result.Windows[1].OutSample
+	scale := first.FinalCash / first.InitialCash
+	expected := second.DailyEquity[0].TotalAssets * scale
+	if diff := result.Equity[100].TotalAssets - expected; diff > 1e-6 || diff < -1e-6 {
+		t.Errorf("第二个窗口起点 %.4f，期望 %.4f", result.Equity[100].TotalAssets, expected)
+	}
+
+	if len(result.ParamStability) != 2 || len(result.ParamStability[0].Values) != 2 {
+		t.Fatalf("参数稳定性表 %+v 不正确", result.ParamStability)
+	}
+	for _, ps := range result.ParamStability {
+		if ps.Min > ps.Mean || ps.Mean > ps.Max {
+			t.Errorf("参数 %s 统计不正确: %+v", ps.Name, ps)
+		}
+	}
+	if result.Performance == nil || result.Performance.TradingDays != 200 {
+		t.Errorf("拼接绩效不正确: %+v", result.Performance)
+	}
+}
+
+// TestEfficiency 测试样本内平均得分不为正时前推效率为 NaN
+func TestEfficiency(t *testing.T) {
+	windows := []WalkForwardWindow{{InSampleScore: 2, OutSampleScore: 1}, {InSampleScore: 2, OutSampleScore: 0}}
+	if e := efficiency(windows); math.Abs(e-0.25) > 1e-9 {
+		t.Errorf("前推效率 %.4f，期望 0.25", e)
+	}
+	for _, windows := range [][]WalkForwardWindow{
+		nil,
+		{{InSampleScore: 0, OutSampleScore: 1}},
+		{{InSampleScore: -1, OutSampleScore: -2}},
+	} {
+		if e := efficiency(windows); !math.IsNaN(e) {
+			t.Errorf("样本内得分 %+v 时前推效率 %.4f，期望 NaN", windows, e)
+		}
+	}
+}
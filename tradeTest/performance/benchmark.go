@@ -0,0 +1,140 @@
+package performance
+
+import "math"
+
+// Relative 相对基准（指数）的绩效分析（收益率、跟踪误差等比例均为百分比）
+type Relative struct {
+	BenchmarkReturn     float64 // 基准总收益率
+	BenchmarkAnnualized float64 // 基准年化收益率
+	ExcessReturn        float64 // 超额收益率（策略总收益率-基准总收益率）
+
+	Alpha            float64 // 年化阿尔法（詹森阿尔法）
+	Beta             float64 // 贝塔
+	Correlation      float64 // 日收益率相关系数
+	TrackingError    float64 // 年化跟踪误差
+	InformationRatio float64 // 信息比率（年化超额收益/跟踪误差）
+	UpCapture        float64 // 上行捕获率（基准上涨日策略平均收益/基准平均收益）
+	DownCapture      float64 // 下行捕获率（基准下跌日策略平均收益/基准平均收益）
+
+	YearlyExcess []YearlyExcess // 分年度超额收益
+}
+
+// YearlyExcess 某一年的策略收益、基准收益和超额收益
+type YearlyExcess struct {
+	Year            string
+	Return          float64 // 策略收益率
+	BenchmarkReturn float64 // 基准收益率
+	Excess          float64 // 超额收益率
+}
+
+// CompareBenchmark 计算策略相对基准的绩效
+// strategy 和 benchmark 需按日期一一对齐（长度相同、日期相同）
+func CompareBenchmark(strategy, benchmark []EquityPoint, cfg Config) *Relative {
+	if cfg.TradingDaysPerYear <= 0 {
+		cfg.TradingDaysPerYear = 252
+	}
+
+	rel := &Relative{}
+	if len(strategy) == 0 || len(strategy) != len(benchmark) {
+		return rel
+	}
+
+	strategyReport := Analyze(strategy, nil, cfg)
+	benchmarkReport := Analyze(benchmark, nil, cfg)
+	rel.BenchmarkReturn = benchmarkReport.TotalReturn
+	rel.BenchmarkAnnualized = benchmarkReport.AnnualizedReturn
+	rel.ExcessReturn = strategyReport.TotalReturn - benchmarkReport.TotalReturn
+	rel.YearlyExcess = yearlyExcess(strategyReport.YearlyReturns, benchmarkReport.YearlyReturns)
+
+	rs := DailyReturns(strategy)
+	rb := DailyReturns(benchmark)
+	if len(rs) < 2 {
+		return rel
+	}
+
+	n := float64(len(rs))
+	days := float64(cfg.TradingDaysPerYear)
+	dailyRiskFree := cfg.RiskFreeRate / days
+
+	meanS, meanB := Mean(rs), Mean(rb)
+	covSB, varS, varB := 0.0, 0.0, 0.0
+	for i := range rs {
+		covSB += (rs[i] - meanS) * (rb[i] - meanB)
+		varS += (rs[i] - meanS) * (rs[i] - meanS)
+		varB += (rb[i] - meanB) * (rb[i] - meanB)
+	}
+	covSB /= n - 1
+	varS /= n - 1
+	varB /= n - 1
+
+	if varB > 0 {
+		rel.Beta = covSB / varB
+	}
+	if varS > 0 && varB > 0 {
+		rel.Correlation = covSB / math.Sqrt(varS*varB)
+	}
+	rel.Alpha = ((meanS - dailyRiskFree) - rel.Beta*(meanB-dailyRiskFree)) * days * 100
+
+	// 跟踪误差和信息比率
+	active := make([]float64, len(rs))
+	for i := range rs {
+		active[i] = rs[i] - rb[i]
+	}
+	activeStd := StdDev(active)
+	rel.TrackingError = activeStd * math.Sqrt(days) * 100
+	if activeStd > 0 {
+		rel.InformationRatio = Mean(active) / activeStd * math.Sqrt(days)
+	}
+
+	rel.UpCapture = captureRatio(rs, rb, func(r float64) bool { return r > 0 })
+	rel.DownCapture = captureRatio(rs, rb, func(r float64) bool { return r < 0 })
+
+	return rel
+}
+
+// captureRatio 基准满足条件的交易日中，策略平均收益与基准平均收益之比（百分比）
+func captureRatio(rs, rb []float64, match func(float64) bool) float64 {
+	sumS, sumB := 0.0, 0.0
+	for i := range rb {
+		if match(rb[i]) {
+			sumS += rs[i]
+			sumB += rb[i]
+		}
+	}
+	if sumB == 0 {
+		return 0
+	}
+	return sumS / sumB * 100
+}
+
+// yearlyExcess 按年份对齐策略和基准的年度收益
+func yearlyExcess(strategy, benchmark []PeriodReturn) []YearlyExcess {
+	benchmarkByYear := make(map[string]float64, len(benchmark))
+	for _, yr := range benchmark {
+		benchmarkByYear[yr.Period] = yr.Return
+	}
+
+	result := make([]YearlyExcess, 0, len(strategy))
+	for _, yr := range strategy {
+		br := benchmarkByYear[yr.Period]
+		result = append(result, YearlyExcess{
+			Year:            yr.Period,
+			Return:          yr.Return,
+			BenchmarkReturn: br,
+			Excess:          yr.Return - br,
+		})
+	}
+	return result
+}
+
+// Mean 平均值
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,68 @@
+package performance
+
+import "testing"
+
+// leveragedSeries 根据基准日收益率生成 beta 倍杠杆的权益序列
+func leveragedSeries(benchmark []EquityPoint, beta float64) []EquityPoint {
+	series := make([]EquityPoint, len(benchmark))
+	series[0] = EquityPoint{Date: benchmark[0].Date, TotalAssets: benchmark[0].TotalAssets}
+	for i := 1; i < len(benchmark); i++ {
+		r := benchmark[i].TotalAssets/benchmark[i-1].TotalAssets - 1
+		series[i] = EquityPoint{Date: benchmark[i].Date, TotalAssets: series[i-1].TotalAssets * (1 + beta*r)}
+	}
+	return series
+}
+
+// TestCompareBenchmarkBeta 测试两倍杠杆策略的贝塔和捕获率
+func TestCompareBenchmarkBeta(t *testing.T) {
+	benchmark := []EquityPoint{
+		{Date: "2020-12-30", TotalAssets: 100},
+		{Date: "2020-12-31", TotalAssets: 101},
+		{Date: "2021-01-04", TotalAssets: 99},
+		{Date: "2021-01-05", TotalAssets: 102},
+		{Date: "2021-01-06", TotalAssets: 100},
+	}
+	strategy := leveragedSeries(benchmark, 2)
+
+	rel := CompareBenchmark(strategy, benchmark, NewDefaultConfig())
+	if !almostEqual(rel.Beta, 2) {
+		t.Errorf("贝塔 %.4f，期望 2", rel.Beta)
+	}
+	if !almostEqual(rel.Correlation, 1) {
+		t.Errorf("相关系数 %.4f，期望 1", rel.Correlation)
+	}
+	if !almostEqual(rel.Alpha, 0) {
+		t.Errorf("阿尔法 %.4f，期望 0", rel.Alpha)
+	}
+	if !almostEqual(rel.UpCapture, 200) || !almostEqual(rel.DownCapture, 200) {
+		t.Errorf("捕获率 %.4f/%.4f，期望 200/200", rel.UpCapture, rel.DownCapture)
+	}
+	if len(rel.YearlyExcess) != 2 || rel.YearlyExcess[0].Year != "2020" {
+		t.Fatalf("分年度超额收益 %+v 不正确", rel.YearlyExcess)
+	}
+	if !almostEqual(rel.YearlyExcess[0].BenchmarkReturn, 1) || !almostEqual(rel.YearlyExcess[0].Excess, 1) {
+		t.Errorf("2020年超额收益 %+v 不正确", rel.YearlyExcess[0])
+	}
+}
+
+// TestCompareBenchmarkSame 测试策略与基准相同时无超额、无跟踪误差
+func TestCompareBenchmarkSame(t *testing.T) {
+	benchmark := []EquityPoint{
+		{Date: "2021-01-04", TotalAssets: 100},
+		{Date: "2021-01-05", TotalAssets: 103},
+		{Date: "2021-01-06", TotalAssets: 101},
+	}
+
+	rel := CompareBenchmark(benchmark, benchmark, NewDefaultConfig())
+	if !almostEqual(rel.ExcessReturn, 0) || !almostEqual(rel.TrackingError, 0) || rel.InformationRatio != 0 {
+		t.Errorf("相同序列的相对绩效不正确: %+v", rel)
+	}
+	if !almostEqual(rel.BenchmarkReturn, 1) {
+		t.Errorf("基准收益率 %.4f，期望 1", rel.BenchmarkReturn)
+	}
+
+	// 长度不一致时返回空结果
+	if rel := CompareBenchmark(benchmark, benchmark[:2], NewDefaultConfig()); rel.Beta != 0 {
+		t.Errorf("长度不一致时应返回空结果: %+v", rel)
+	}
+}
@@ -0,0 +1,289 @@
+package performance
+
+import "math"
+
+// EquityPoint 每日权益
+type EquityPoint struct {
+	Date          string  // 日期（2006-01-02）
+	TotalAssets   float64 // 总资产
+	PositionValue float64 // 持仓市值
+}
+
+// Trade 一笔完整交易（买入到卖出）
+type Trade struct {
+	EntryDate   string  // 买入日期
+	ExitDate    string  // 卖出日期
+	EntryAmount float64 // 买入金额
+	ExitAmount  float64 // 卖出金额
+	NetPnL      float64 // 净盈亏（扣除手续费）
+	ReturnPct   float64 // 净收益率（百分比）
+	HoldDays    int     // 持有交易日数
+}
+
+// Config 绩效计算参数
+type Config struct {
+	RiskFreeRate       float64 // 年化无风险利率，如0.02表示2%
+	TradingDaysPerYear int     // 每年交易日数
+}
+
+// NewDefaultConfig 默认参数：无风险利率0，每年252个交易日
+func NewDefaultConfig() Config {
+	return Config{
+		RiskFreeRate:       0,
+		TradingDaysPerYear: 252,
+	}
+}
+
+// Report 绩效报告（收益率、回撤等比例均为百分比）
+type Report struct {
+	StartDate   string
+	EndDate     string
+	TradingDays int
+
+	TotalReturn      float64 // 总收益率
+	AnnualizedReturn float64 // 年化收益率
+	Volatility       float64 // 年化波动率
+	SharpeRatio      float64 // 夏普比率
+	SortinoRatio     float64 // 索提诺比率
+	CalmarRatio      float64 // 卡玛比率（年化收益率/最大回撤）
+
+	MaxDrawdown         float64 // 最大回撤
+	MaxDrawdownPeak     string  // 最大回撤的起点（前高）日期
+	MaxDrawdownTrough   string  // 最大回撤的谷底日期
+	MaxDrawdownDuration int     // 最长水下时间（从前高到收复前高的交易日数，未收复则算到最后一天）
+	RecoveryDays        int     // 最大回撤从谷底到收复前高的交易日数，未收复为-1
+
+	TotalTrades   int     // 完整交易笔数
+	ProfitFactor  float64 // 盈亏比（总盈利/总亏损，有盈利无亏损时为 +Inf，没有盈利时为0）
+	Expectancy    float64 // 每笔交易的期望净盈亏
+	ExpectancyPct float64 // 每笔交易的期望净收益率
+	Exposure      float64 // 持仓时间占比（有持仓的交易日比例）
+	AvgExposure   float64 // 平均仓位（持仓市值/总资产的日均值）
+	Turnover      float64 // 年化换手率（单边成交金额/平均总资产，倍数）
+
+	MonthlyReturns []PeriodReturn // 月度收益
+	YearlyReturns  []PeriodReturn // 年度收益
+}
+
+// Analyze 根据每日权益和完整交易计算绩效报告
+func Analyze(equity []EquityPoint, trades []Trade, cfg Config) *Report {
+	if cfg.TradingDaysPerYear <= 0 {
+		cfg.TradingDaysPerYear = 252
+	}
+
+	report := &Report{TotalTrades: len(trades)}
+	if len(equity) == 0 {
+		return report
+	}
+
+	report.StartDate = equity[0].Date
+	report.EndDate = equity[len(equity)-1].Date
+	report.TradingDays = len(equity)
+
+	returns := DailyReturns(equity)
+	analyzeReturns(report, equity, returns, cfg)
+	analyzeDrawdown(report, equity)
+	analyzeTrades(report, trades)
+	analyzeExposure(report, equity, trades, cfg)
+
+	if report.MaxDrawdown > 0 {
+		report.CalmarRatio = report.AnnualizedReturn / report.MaxDrawdown
+	}
+
+	report.MonthlyReturns = PeriodReturns(equity, 7)
+	report.YearlyReturns = PeriodReturns(equity, 4)
+
+	return report
+}
+
+// DailyReturns 每日收益率（第一天之后每天一个）
+func DailyReturns(equity []EquityPoint) []float64 {
+	if len(equity) < 2 {
+		return nil
+	}
+
+	returns := make([]float64, 0, len(equity)-1)
+	for i := 1; i < len(equity); i++ {
+		prev := equity[i-1].TotalAssets
+		if prev <= 0 {
+			returns = append(returns, 0)
+			continue
+		}
+		returns = append(returns, equity[i].TotalAssets/prev-1)
+	}
+	return returns
+}
+
+// analyzeReturns 计算收益率、波动率、夏普和索提诺
+func analyzeReturns(report *Report, equity []EquityPoint, returns []float64, cfg Config) {
+	initial := equity[0].TotalAssets
+	final := equity[len(equity)-1].TotalAssets
+	if initial <= 0 {
+		return
+	}
+
+	growth := final / initial
+	report.TotalReturn = (growth - 1) * 100
+
+	if len(returns) == 0 {
+		return
+	}
+
+	years := float64(len(returns)) / float64(cfg.TradingDaysPerYear)
+	if growth > 0 {
+		report.AnnualizedReturn = (math.Pow(growth, 1/years) - 1) * 100
+	} else {
+		report.AnnualizedReturn = -100
+	}
+
+	dailyRiskFree := cfg.RiskFreeRate / float64(cfg.TradingDaysPerYear)
+	meanExcess := 0.0
+	for _, r := range returns {
+		meanExcess += r - dailyRiskFree
+	}
+	meanExcess /= float64(len(returns))
+
+	std := StdDev(returns)
+	annualFactor := math.Sqrt(float64(cfg.TradingDaysPerYear))
+	report.Volatility = std * annualFactor * 100
+	if std > 0 {
+		report.SharpeRatio = meanExcess / std * annualFactor
+	}
+
+	// 下行偏差：只统计低于无风险收益的部分
+	downside := 0.0
+	for _, r := range returns {
+		if d := r - dailyRiskFree; d < 0 {
+			downside += d * d
+		}
+	}
+	downside = math.Sqrt(downside / float64(len(returns)))
+	if downside > 0 {
+		report.SortinoRatio = meanExcess / downside * annualFactor
+	}
+}
+
+// analyzeDrawdown 计算最大回撤、最长水下时间和恢复时间
+func analyzeDrawdown(report *Report, equity []EquityPoint) {
+	peakIdx := 0
+	maxDrawdown := 0.0
+	maxPeakIdx, maxTroughIdx := 0, 0
+	longestUnderwater := 0
+
+	for i, point := range equity {
+		if point.TotalAssets >= equity[peakIdx].TotalAssets {
+			// 创新高（或收复前高），结束一段水下时间
+			if i-peakIdx > longestUnderwater {
+				longestUnderwater = i - peakIdx
+			}
+			peakIdx = i
+			continue
+		}
+
+		peak := equity[peakIdx].TotalAssets
+		if peak <= 0 {
+			continue
+		}
+		drawdown := (peak - point.TotalAssets) / peak
+		if drawdown > maxDrawdown {
+			maxDrawdown = drawdown
+			maxPeakIdx = peakIdx
+			maxTroughIdx = i
+		}
+	}
+	// 最后一段未收复的水下时间
+	if last := len(equity) - 1; last-peakIdx > longestUnderwater {
+		longestUnderwater = last - peakIdx
+	}
+
+	report.MaxDrawdown = maxDrawdown * 100
+	report.MaxDrawdownDuration = longestUnderwater
+	report.RecoveryDays = -1
+	if maxDrawdown == 0 {
+		report.RecoveryDays = 0
+		return
+	}
+
+	report.MaxDrawdownPeak = equity[maxPeakIdx].Date
+	report.MaxDrawdownTrough = equity[maxTroughIdx].Date
+	peak := equity[maxPeakIdx].TotalAssets
+	for i := maxTroughIdx + 1; i < len(equity); i++ {
+		if equity[i].TotalAssets >= peak {
+			report.RecoveryDays = i - maxTroughIdx
+			break
+		}
+	}
+}
+
+// analyzeTrades 计算盈亏比和期望
+func analyzeTrades(report *Report, trades []Trade) {
+	if len(trades) == 0 {
+		return
+	}
+
+	grossWin, grossLoss := 0.0, 0.0
+	totalPnL, totalPct := 0.0, 0.0
+	for _, trade := range trades {
+		totalPnL += trade.NetPnL
+		totalPct += trade.ReturnPct
+		if trade.NetPnL > 0 {
+			grossWin += trade.NetPnL
+		} else {
+			grossLoss -= trade.NetPnL
+		}
+	}
+
+	if grossLoss > 0 {
+		report.ProfitFactor = grossWin / grossLoss
+	} else if grossWin > 0 {
+		report.ProfitFactor = math.Inf(1)
+	}
+	report.Expectancy = totalPnL / float64(len(trades))
+	report.ExpectancyPct = totalPct / float64(len(trades))
+}
+
+// analyzeExposure 计算持仓时间占比、平均仓位和年化换手率
+func analyzeExposure(report *Report, equity []EquityPoint, trades []Trade, cfg Config) {
+	investedDays := 0
+	exposureSum := 0.0
+	assetsSum := 0.0
+	for _, point := range equity {
+		if point.PositionValue > 0 {
+			investedDays++
+		}
+		if point.TotalAssets > 0 {
+			exposureSum += point.PositionValue / point.TotalAssets
+		}
+		assetsSum += point.TotalAssets
+	}
+
+	report.Exposure = float64(investedDays) / float64(len(equity)) * 100
+	report.AvgExposure = exposureSum / float64(len(equity)) * 100
+
+	avgAssets := assetsSum / float64(len(equity))
+	if avgAssets <= 0 {
+		return
+	}
+
+	// 单边成交金额：买入和卖出的平均值
+	traded := 0.0
+	for _, trade := range trades {
+		traded += (trade.EntryAmount + trade.ExitAmount) / 2
+	}
+	years := float64(len(equity)) / float64(cfg.TradingDaysPerYear)
+	report.Turnover = traded / avgAssets / years
+}
+
+// StdDev 样本标准差
+func StdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	mean := Mean(values)
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)-1))
+}
@@ -0,0 +1,141 @@
+package performance
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+// TestAnalyzeDrawdown 测试最大回撤、水下时间和恢复时间
+func TestAnalyzeDrawdown(t *testing.T) {
+	equity := []EquityPoint{
+		{Date: "2020-01-02", TotalAssets: 100},
+		{Date: "2020-01-03", TotalAssets: 120}, // 前高
+		{Date: "2020-01-06", TotalAssets: 90},  // 谷底，回撤25%
+		{Date: "2020-01-07", TotalAssets: 100},
+		{Date: "2020-01-08", TotalAssets: 121}, // 收复前高
+		{Date: "2020-01-09", TotalAssets: 110},
+	}
+
+	report := Analyze(equity, nil, NewDefaultConfig())
+	if !almostEqual(report.MaxDrawdown, 25) {
+		t.Errorf("最大回撤 %.4f，期望 25", report.MaxDrawdown)
+	}
+	if report.MaxDrawdownPeak != "2020-01-03" || report.MaxDrawdownTrough != "2020-01-06" {
+		t.Errorf("回撤区间 %s ~ %s 不正确", report.MaxDrawdownPeak, report.MaxDrawdownTrough)
+	}
+	if report.MaxDrawdownDuration != 3 {
+		t.Errorf("最长水下时间 %d，期望 3", report.MaxDrawdownDuration)
+	}
+	if report.RecoveryDays != 2 {
+		t.Errorf("恢复时间 %d，期望 2", report.RecoveryDays)
+	}
+	if !almostEqual(report.TotalReturn, 10) {
+		t.Errorf("总收益率 %.4f，期望 10", report.TotalReturn)
+	}
+}
+
+// TestAnalyzeReturns 测试年化收益率、波动率和夏普
+func TestAnalyzeReturns(t *testing.T) {
+	// 每天固定上涨0.1%，波动率为0
+	equity := make([]EquityPoint, 253)
+	assets := 100.0
+	for i := range equity {
+		equity[i] = EquityPoint{Date: "2020-01-02", TotalAssets: assets}
+		assets *= 1.001
+	}
+
+	report := Analyze(equity, nil, NewDefaultConfig())
+	expected := (math.Pow(1.001, 252) - 1) * 100
+	if !almostEqual(report.AnnualizedReturn, expected) {
+		t.Errorf("年化收益率 %.4f，期望 %.4f", report.AnnualizedReturn, expected)
+	}
+	if report.Volatility > 1e-6 || report.SharpeRatio != 0 || report.SortinoRatio != 0 {
+		t.Errorf("无波动时波动率/夏普/索提诺应为0: %+v", report)
+	}
+
+	// 涨跌交替
+	equity = []EquityPoint{
+		{Date: "2020-01-02", TotalAssets: 100},
+		{Date: "2020-01-03", TotalAssets: 102},
+		{Date: "2020-01-06", TotalAssets: 101},
+		{Date: "2020-01-07", TotalAssets: 104},
+	}
+	report = Analyze(equity, nil, NewDefaultConfig())
+	if report.SharpeRatio <= 0 || report.SortinoRatio <= report.SharpeRatio {
+		t.Errorf("夏普 %.4f 索提诺 %.4f 不符合预期", report.SharpeRatio, report.SortinoRatio)
+	}
+}
+
+// TestAnalyzeTrades 测试盈亏比、期望、持仓占比和换手率
+func TestAnalyzeTrades(t *testing.T) {
+	equity := []EquityPoint{
+		{Date: "2020-01-02", TotalAssets: 1000, PositionValue: 0},
+		{Date: "2020-01-03", TotalAssets: 1000, PositionValue: 500},
+		{Date: "2020-01-06", TotalAssets: 1000, PositionValue: 1000},
+		{Date: "2020-01-07", TotalAssets: 1000, PositionValue: 0},
+	}
+	trades := []Trade{
+		{EntryAmount: 500, ExitAmount: 600, NetPnL: 100, ReturnPct: 20},
+		{EntryAmount: 500, ExitAmount: 450, NetPnL: -50, ReturnPct: -10},
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.TradingDaysPerYear = 4
+	report := Analyze(equity, trades, cfg)
+
+	if !almostEqual(report.ProfitFactor, 2) {
+		t.Errorf("盈亏比 %.4f，期望 2", report.ProfitFactor)
+	}
+	// 没有亏损交易时盈亏比为 +Inf
+	if pf := Analyze(equity, trades[:1], cfg).ProfitFactor; !math.IsInf(pf, 1) {
+		t.Errorf("没有亏损时盈亏比 %.4f，期望 +Inf", pf)
+	}
+	if !almostEqual(report.Expectancy, 25) || !almostEqual(report.ExpectancyPct, 5) {
+		t.Errorf("期望 %.4f (%.4f%%)，期望 25 (5%%)", report.Expectancy, report.ExpectancyPct)
+	}
+	if !almostEqual(report.Exposure, 50) || !almostEqual(report.AvgExposure, 37.5) {
+		t.Errorf("持仓占比 %.4f 平均仓位 %.4f，期望 50 和 37.5", report.Exposure, report.AvgExposure)
+	}
+	// 单边成交 (550+475)/1000，回测期正好一年
+	if !almostEqual(report.Turnover, 1.025) {
+		t.Errorf("换手率 %.4f，期望 1.025", report.Turnover)
+	}
+}
+
+// TestPeriodReturns 测试月度和年度收益
+func TestPeriodReturns(t *testing.T) {
+	equity := []EquityPoint{
+		{Date: "2020-11-30", TotalAssets: 100},
+		{Date: "2020-12-31", TotalAssets: 110},
+		{Date: "2021-01-04", TotalAssets: 99},
+		{Date: "2021-01-29", TotalAssets: 121},
+	}
+
+	report := Analyze(equity, nil, NewDefaultConfig())
+	monthly := report.MonthlyReturns
+	if len(monthly) != 3 {
+		t.Fatalf("月度收益数量 %d，期望 3", len(monthly))
+	}
+	if monthly[0].Period != "2020-11" || !almostEqual(monthly[0].Return, 0) {
+		t.Errorf("11月收益 %+v 不正确", monthly[0])
+	}
+	if monthly[1].Period != "2020-12" || !almostEqual(monthly[1].Return, 10) {
+		t.Errorf("12月收益 %+v 不正确", monthly[1])
+	}
+	if monthly[2].Period != "2021-01" || !almostEqual(monthly[2].Return, 10) {
+		t.Errorf("1月收益 %+v 不正确", monthly[2])
+	}
+
+	yearly := report.YearlyReturns
+	if len(yearly) != 2 || !almostEqual(yearly[0].Return, 10) || !almostEqual(yearly[1].Return, 10) {
+		t.Errorf("年度收益 %+v 不正确", yearly)
+	}
+
+	if lines := report.MonthlyTable(); len(lines) != 3 {
+		t.Errorf("月度收益表行数 %d，期望 3", len(lines))
+	}
+}
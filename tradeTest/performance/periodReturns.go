@@ -0,0 +1,82 @@
+package performance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PeriodReturn 某个周期（月或年）的收益率
+type PeriodReturn struct {
+	Period string  // 周期：月度为 2006-01，年度为 2006
+	Return float64 // 收益率（百分比）
+}
+
+// PeriodReturns 按日期前缀分组计算周期收益率
+// prefixLen 为日期前缀长度：7 按月（2006-01），4 按年（2006）
+// 每个周期以上一周期最后一天的总资产为基准，第一个周期以第一天为基准
+func PeriodReturns(equity []EquityPoint, prefixLen int) []PeriodReturn {
+	periods := make([]PeriodReturn, 0)
+	if len(equity) == 0 {
+		return periods
+	}
+
+	base := equity[0].TotalAssets
+	for i, point := range equity {
+		if len(point.Date) < prefixLen {
+			continue
+		}
+		period := point.Date[:prefixLen]
+
+		// 周期的最后一天：下一天属于另一个周期或已是最后一天
+		isLast := i == len(equity)-1 || len(equity[i+1].Date) < prefixLen || equity[i+1].Date[:prefixLen] != period
+		if !isLast {
+			continue
+		}
+
+		ret := 0.0
+		if base > 0 {
+			ret = (point.TotalAssets/base - 1) * 100
+		}
+		periods = append(periods, PeriodReturn{Period: period, Return: ret})
+		base = point.TotalAssets
+	}
+
+	return periods
+}
+
+// MonthlyTable 生成月度收益表（每年一行，1-12月和全年）
+func (r *Report) MonthlyTable() []string {
+	yearly := make(map[string]float64, len(r.YearlyReturns))
+	for _, yr := range r.YearlyReturns {
+		yearly[yr.Period] = yr.Return
+	}
+
+	monthly := make(map[string]float64, len(r.MonthlyReturns))
+	for _, mr := range r.MonthlyReturns {
+		monthly[mr.Period] = mr.Return
+	}
+
+	header := "年份"
+	for m := 1; m <= 12; m++ {
+		header += fmt.Sprintf(" %7s", fmt.Sprintf("%d月", m))
+	}
+	header += fmt.Sprintf(" %8s", "全年")
+
+	lines := []string{header}
+	for _, yr := range r.YearlyReturns {
+		var sb strings.Builder
+		sb.WriteString(yr.Period)
+		for m := 1; m <= 12; m++ {
+			key := fmt.Sprintf("%s-%02d", yr.Period, m)
+			if ret, ok := monthly[key]; ok {
+				sb.WriteString(fmt.Sprintf(" %6.2f%%", ret))
+			} else {
+				sb.WriteString(fmt.Sprintf(" %7s", "-"))
+			}
+		}
+		sb.WriteString(fmt.Sprintf(" %7.2f%%", yearly[yr.Period]))
+		lines = append(lines, sb.String())
+	}
+
+	return lines
+}
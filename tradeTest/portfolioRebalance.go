@@ -0,0 +1,175 @@
+package tradeTest
+
+import (
+	"math"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/sizers"
+)
+
+// rebalanceTolerance 已持仓票票的权重与目标相差不超过该值时不调整，避免频繁的小额交易
+const rebalanceTolerance = 0.02
+
+// processRebalance 横截面策略调仓（代替逐只票票的买入信号）
+// 顺序：卖出不在目标中的持仓 -> 超配的持仓减仓 -> 按目标顺序加仓和买入新票票
+func (e *TimeBasedBacktestEngine) processRebalance(strategy stockStrategy.PortfolioStrategy, candidateCodes []string, dayIdx int) {
+	ctx := e.portfolioContext(strategy, candidateCodes)
+	e.portfolioDays++
+
+	targets := normalizeTargets(strategy.Rebalance(ctx))
+	if targets == nil {
+		return // 当天不调仓
+	}
+	weights := make(map[string]float64, len(targets))
+	for _, target := range targets {
+		weights[target.Code] = target.Weight
+	}
+
+	// 1. 卖出不在目标中的持仓
+	for _, code := range e.sortedPositionCodes() {
+		if _, ok := weights[code]; !ok {
+			e.executeSell(e.positions[code], "调仓卖出")
+		}
+	}
+
+	// 2. 超配的持仓减仓
+	for _, target := range targets {
+		pos, exists := e.positions[target.Code]
+		holding, held := ctx.Holdings[target.Code]
+		if !exists || !held || holding.Weight-target.Weight <= rebalanceTolerance {
+			continue
+		}
+		price := holding.Value / float64(holding.StockNum)
+		excess := sizers.GetLotRule(target.Code).RoundDown(int((holding.Weight - target.Weight) * ctx.TotalAssets / price))
+		if excess >= pos.StockNum {
+			e.executeSell(pos, "调仓卖出")
+		} else if excess > 0 {
+			e.sellShares(pos, excess, "调仓减仓")
+		}
+	}
+
+	// 3. 按目标顺序加仓和买入，资金不足时排在后面的目标少买或不买
+	for _, target := range targets {
+		dayData := e.getDayData(target.Code, e.currentDate)
+		if dayData == nil {
+			continue
+		}
+		price := float64(dayData.PriceBegin)
+		targetNum := int(target.Weight * ctx.TotalAssets / price)
+
+		if pos, exists := e.positions[target.Code]; exists {
+			holding := ctx.Holdings[target.Code]
+			if target.Weight-holding.Weight <= rebalanceTolerance {
+				continue
+			}
+			e.executeBuy(target.Code, dayIdx, targetNum-pos.StockNum, "调仓加仓")
+			continue
+		}
+
+		if len(e.positions) >= e.maxPositions || !e.canRebalanceBuy(target.Code, dayIdx) {
+			continue
+		}
+		e.executeBuy(target.Code, dayIdx, targetNum, "调仓买入")
+	}
+}
+
+// canRebalanceBuy 调仓时能否买入新票票：不在冷却期内，当天没有按信号卖出，
+// 且与逐只票票买入一样，最近5天没有单日涨幅超过7%（否则加入冷却期50天）
+func (e *TimeBasedBacktestEngine) canRebalanceBuy(code string, dayIdx int) bool {
+	if cooldownEnd, inCooldown := e.buyCooldowns[code]; inCooldown {
+		if dayIdx < cooldownEnd {
+			return false
+		}
+		delete(e.buyCooldowns, code)
+	}
+	if signalDay, ok := e.signalDays[code]; ok && signalDay == dayIdx {
+		return false
+	}
+	if e.checkRecentHighRisk(code, e.currentDate) {
+		e.buyCooldowns[code] = dayIdx + 50
+		return false
+	}
+	return true
+}
+
+// portfolioContext 构建横截面策略当天可见的数据（候选票票和持仓票票截至当天的K线）
+func (e *TimeBasedBacktestEngine) portfolioContext(strategy stockStrategy.PortfolioStrategy, candidateCodes []string) *stockStrategy.PortfolioContext {
+	window := stockStrategy.DefaultHistoryWindow
+	if provider, ok := strategy.(stockStrategy.HistoryWindowProvider); ok {
+		window = provider.GetHistoryWindow()
+	}
+
+	ctx := &stockStrategy.PortfolioContext{
+		Date:     e.currentDate,
+		DayIndex: e.portfolioDays,
+		Bars:     make(map[string]*stockStrategy.BarContext, len(candidateCodes)),
+		Holdings: make(map[string]stockStrategy.Holding, len(e.positions)),
+		Cash:     e.wallet.Cash,
+	}
+
+	addBars := func(code string) {
+		if _, exists := ctx.Bars[code]; exists {
+			return
+		}
+		if i := e.findDayIndex(code, e.currentDate); i >= 0 {
+			ctx.Bars[code] = stockStrategy.NewBarContext(code, e.allStockData[code].Datas.DayDatas, i, window)
+		}
+	}
+	for _, code := range candidateCodes {
+		addBars(code)
+	}
+
+	// 持仓按当天价格计算市值（当天停牌的按最近价格）
+	totalAssets := e.wallet.Cash
+	for _, code := range e.sortedPositionCodes() {
+		addBars(code)
+		pos := e.positions[code]
+		price := pos.CurrentPrice
+		if dayData := e.getDayData(code, e.currentDate); dayData != nil {
+			price = float64(dayData.PriceBegin)
+		}
+		value := price * float64(pos.StockNum)
+		totalAssets += value
+		ctx.Holdings[code] = stockStrategy.Holding{
+			StockNum: pos.StockNum,
+			BuyPrice: pos.BuyPrice,
+			HoldDays: pos.HoldDays,
+			Value:    value,
+		}
+	}
+	ctx.TotalAssets = totalAssets
+
+	for code, holding := range ctx.Holdings {
+		if totalAssets > 0 {
+			holding.Weight = holding.Value / totalAssets
+			ctx.Holdings[code] = holding
+		}
+	}
+	return ctx
+}
+
+// normalizeTargets 整理目标权重：去掉重复和非正的权重，总和超过1时按比例缩小
+// nil 原样返回（当天不调仓）
+func normalizeTargets(targets []stockStrategy.TargetWeight) []stockStrategy.TargetWeight {
+	if targets == nil {
+		return nil
+	}
+
+	result := make([]stockStrategy.TargetWeight, 0, len(targets))
+	seen := make(map[string]bool, len(targets))
+	total := 0.0
+	for _, target := range targets {
+		if seen[target.Code] || !(target.Weight > 0) || math.IsInf(target.Weight, 0) {
+			continue
+		}
+		seen[target.Code] = true
+		result = append(result, target)
+		total += target.Weight
+	}
+
+	if total > 1 {
+		for i := range result {
+			result[i].Weight /= total
+		}
+	}
+	return result
+}
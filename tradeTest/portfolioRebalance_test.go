@@ -0,0 +1,173 @@
+package tradeTest
+
+import (
+	"math"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/selectors"
+	"stock-go/stockStrategy/strategies"
+	"testing"
+)
+
+// fixedTargetStrategy 按交易日序号返回预设目标权重的横截面策略（测试用）
+type fixedTargetStrategy struct {
+	targets  map[int][]stockStrategy.TargetWeight
+	contexts []*stockStrategy.PortfolioContext
+}
+
+func (s *fixedTargetStrategy) GetSelector() stockStrategy.StockSelector {
+	return selectors.NewAllMarketSelector()
+}
+
+func (s *fixedTargetStrategy) GetSignalGenerator() stockStrategy.SignalGenerator {
+	return nil
+}
+
+func (s *fixedTargetStrategy) Rebalance(ctx *stockStrategy.PortfolioContext) []stockStrategy.TargetWeight {
+	s.contexts = append(s.contexts, ctx)
+	return s.targets[ctx.DayIndex]
+}
+
+func (s *fixedTargetStrategy) GetName() string {
+	return "固定目标权重"
+}
+
+// runPortfolioBacktest 用合成数据运行横截面策略回测
+func runPortfolioBacktest(t *testing.T, strategy stockStrategy.Strategy, data map[string]*stockData.StockInfo, maxPositions int) *TimeBasedBacktestResult {
+	t.Helper()
+	engine := NewTimeBasedBacktestEngine(1000000, strategy, maxPositions, 0.2)
+	engine.SetStockData(data)
+	engine.SetQuiet(true)
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+	return result
+}
+
+// TestMomentumRankBacktest 测试动量排名在调仓日换入动量最强的票票，不买入下跌的票票
+func TestMomentumRankBacktest(t *testing.T) {
+	late := append(risingPrices(600, 20, -0.001), risingPrices(100, 20*0.5488, 0.008)...)
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "最强", risingPrices(700, 10, 0.003)),
+		"sz.000002": newSyntheticStock("sz.000002", "次强", risingPrices(700, 10, 0.002)),
+		"sh.600001": newSyntheticStock("sh.600001", "后起", late),
+		"sh.600002": newSyntheticStock("sh.600002", "下跌", risingPrices(700, 10, -0.001)),
+	}
+	result := runPortfolioBacktest(t, strategies.NewMomentumRankStrategyWithParams(20, 2, 20), data, 5)
+
+	if len(result.TradeRecords) < 4 {
+		t.Fatalf("交易记录不足: %+v", result.TradeRecords)
+	}
+	first, second := result.TradeRecords[0], result.TradeRecords[1]
+	if first.Code != "sz.000001" || second.Code != "sz.000002" || first.Reason != "调仓买入" {
+		t.Errorf("应按动量顺序买入前两名: %+v %+v", first, second)
+	}
+	if math.Abs(first.Amount-second.Amount) > first.Price*100 {
+		t.Errorf("应等权买入: %.2f %.2f", first.Amount, second.Amount)
+	}
+
+	rebalanceDays := make(map[string]bool)
+	for i := 500; i < len(data["sz.000001"].Datas.DayDatas); i += 20 {
+		rebalanceDays[data["sz.000001"].Datas.DayDatas[i].DataStr] = true
+	}
+	swapped := false
+	for _, record := range result.TradeRecords {
+		if record.Code == "sh.600002" {
+			t.Errorf("不应买入下跌的票票: %+v", record)
+		}
+		if record.Reason != "回测结束强制平仓" && !rebalanceDays[record.Date] {
+			t.Errorf("%s 不是调仓日: %+v", record.Date, record)
+		}
+		if record.Code == "sz.000002" && record.Reason == "调仓卖出" {
+			swapped = true
+		}
+	}
+	if !swapped {
+		t.Error("后起的票票动量超过次强后应换仓")
+	}
+	for _, equity := range result.DailyEquity {
+		if equity.PositionCount > 2 {
+			t.Fatalf("%s 持仓 %d 只，超过目标数量", equity.Date, equity.PositionCount)
+		}
+	}
+}
+
+// TestRebalanceResizesPositions 测试按目标权重减仓、加仓，空目标清仓，nil 不调仓
+func TestRebalanceResizesPositions(t *testing.T) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "甲", risingPrices(600, 10, 0)),
+		"sz.000002": newSyntheticStock("sz.000002", "乙", risingPrices(600, 10, 0)),
+	}
+	strategy := &fixedTargetStrategy{targets: map[int][]stockStrategy.TargetWeight{
+		0:  {{Code: "sz.000001", Weight: 0.6}, {Code: "sz.000002", Weight: 0.3}},
+		10: {{Code: "sz.000001", Weight: 0.3}, {Code: "sz.000002", Weight: 0.31}},
+		20: {{Code: "sz.000001", Weight: 0.5}, {Code: "sz.000002", Weight: 0.3}},
+		30: {},
+	}}
+	result := runPortfolioBacktest(t, strategy, data, 5)
+
+	var reasons []string
+	for _, record := range result.TradeRecords {
+		reasons = append(reasons, record.Code+" "+record.Reason)
+	}
+	want := []string{
+		"sz.000001 调仓买入", "sz.000002 调仓买入",
+		"sz.000001 调仓减仓",
+		"sz.000001 调仓加仓",
+		"sz.000001 调仓卖出", "sz.000002 调仓卖出",
+	}
+	if len(reasons) != len(want) {
+		t.Fatalf("交易 %v, 期望 %v", reasons, want)
+	}
+	for i := range want {
+		if reasons[i] != want[i] {
+			t.Errorf("第%d笔交易 %s, 期望 %s", i+1, reasons[i], want[i])
+		}
+	}
+
+	// 价格不变，减仓和加仓后的持仓接近目标权重
+	if sold := result.TradeRecords[2].StockNum; sold != 30000 {
+		t.Errorf("减仓 %d 股, 期望 30000", sold)
+	}
+	if added := result.TradeRecords[3].StockNum; added < 19900 || added > 20000 {
+		t.Errorf("加仓 %d 股, 期望约 20000", added)
+	}
+
+	ctx := strategy.contexts[10]
+	if holding := ctx.Holdings["sz.000001"]; math.Abs(holding.Weight-0.6) > 0.001 || holding.StockNum != 60000 {
+		t.Errorf("持仓权重 %+v, 期望 0.6", holding)
+	}
+	if len(ctx.Codes()) != 2 || ctx.Bars["sz.000001"].Date() != ctx.Date {
+		t.Errorf("上下文应包含两只票票截至当天的K线: %v", ctx.Codes())
+	}
+}
+
+// TestRebalanceSkipsRecentHighRisk 测试调仓买入与逐只票票买入一样跳过最近5天有大涨的票票
+func TestRebalanceSkipsRecentHighRisk(t *testing.T) {
+	jump := risingPrices(600, 10, 0)
+	for i := 497; i < len(jump); i++ {
+		jump[i] = 11 // 第一个交易日之前3天单日上涨10%
+	}
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "平稳", risingPrices(600, 10, 0)),
+		"sz.000002": newSyntheticStock("sz.000002", "大涨", jump),
+	}
+	strategy := &fixedTargetStrategy{targets: map[int][]stockStrategy.TargetWeight{
+		0:  {{Code: "sz.000001", Weight: 0.4}, {Code: "sz.000002", Weight: 0.4}},
+		60: {{Code: "sz.000001", Weight: 0.4}, {Code: "sz.000002", Weight: 0.4}},
+	}}
+	result := runPortfolioBacktest(t, strategy, data, 5)
+
+	var buys []string
+	for _, record := range result.TradeRecords {
+		if record.Action == "buy" {
+			buys = append(buys, record.Code+" "+record.Date)
+		}
+	}
+	days := data["sz.000001"].Datas.DayDatas
+	want := []string{"sz.000001 " + days[500].DataStr, "sz.000002 " + days[560].DataStr}
+	if len(buys) != len(want) || buys[0] != want[0] || buys[1] != want[1] {
+		t.Errorf("买入 %v，期望 %v", buys, want)
+	}
+}
@@ -0,0 +1,88 @@
+package tradeTest
+
+// PositionLot 持仓批次（一次买入或加仓形成一个批次）
+type PositionLot struct {
+	BuyDate  string  // 买入日期
+	BuyIndex int     // 买入时的交易日索引
+	BuyPrice float64 // 买入价格
+	StockNum int     // 剩余数量
+	BuyFee   float64 // 剩余数量对应的买入手续费（佣金+过户费）
+	HoldDays int     // 持有天数
+}
+
+// LotFill 卖出时从某个批次中扣减的部分
+type LotFill struct {
+	BuyDate  string  // 批次买入日期
+	BuyPrice float64 // 批次买入价格
+	StockNum int     // 本次从该批次卖出的数量
+	BuyFee   float64 // 分摊的买入手续费
+	HoldDays int     // 该批次的持有天数
+}
+
+// addLot 增加一个持仓批次，并更新总数量和加权平均成本
+func (pos *PositionState) addLot(lot *PositionLot) {
+	totalCost := pos.BuyPrice*float64(pos.StockNum) + lot.BuyPrice*float64(lot.StockNum)
+	pos.Lots = append(pos.Lots, lot)
+	pos.StockNum += lot.StockNum
+	pos.BuyPrice = totalCost / float64(pos.StockNum)
+}
+
+// removeShares 按先进先出从批次中扣减指定数量
+// 返回扣减明细和对应的成本（买入金额+分摊的买入手续费）
+func (pos *PositionState) removeShares(stockNum int) ([]LotFill, float64) {
+	fills := make([]LotFill, 0, 1)
+	costBasis := 0.0
+	remaining := stockNum
+
+	for remaining > 0 && len(pos.Lots) > 0 {
+		lot := pos.Lots[0]
+		num := lot.StockNum
+		if num > remaining {
+			num = remaining
+		}
+
+		// 按数量比例分摊买入手续费
+		fee := lot.BuyFee * float64(num) / float64(lot.StockNum)
+		fills = append(fills, LotFill{
+			BuyDate:  lot.BuyDate,
+			BuyPrice: lot.BuyPrice,
+			StockNum: num,
+			BuyFee:   fee,
+			HoldDays: lot.HoldDays,
+		})
+		costBasis += lot.BuyPrice*float64(num) + fee
+
+		lot.StockNum -= num
+		lot.BuyFee -= fee
+		remaining -= num
+		if lot.StockNum == 0 {
+			pos.Lots = pos.Lots[1:]
+		}
+	}
+
+	pos.StockNum -= stockNum - remaining
+	pos.BuyPrice = pos.lotsAvgPrice()
+	return fills, costBasis
+}
+
+// lotsAvgPrice 根据剩余批次计算加权平均买入价格（不含手续费）
+func (pos *PositionState) lotsAvgPrice() float64 {
+	totalNum := 0
+	totalCost := 0.0
+	for _, lot := range pos.Lots {
+		totalNum += lot.StockNum
+		totalCost += lot.BuyPrice * float64(lot.StockNum)
+	}
+	if totalNum == 0 {
+		return pos.BuyPrice
+	}
+	return totalCost / float64(totalNum)
+}
+
+// incrementHoldDays 持有天数加一（整体和每个批次）
+func (pos *PositionState) incrementHoldDays() {
+	pos.HoldDays++
+	for _, lot := range pos.Lots {
+		lot.HoldDays++
+	}
+}
@@ -0,0 +1,70 @@
+package tradeTest
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockStrategy/sizers"
+	"stock-go/stockStrategy/strategies"
+	"testing"
+)
+
+// TestTimeBasedBacktestEngineCashPerPosition 测试引擎按每仓位资金比例买入
+func TestTimeBasedBacktestEngineCashPerPosition(t *testing.T) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "测试1", risingPrices(900, 10, 0.002)),
+	}
+
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 2, 0.5)
+	engine.SetStockData(data)
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	firstBuy := findFirstTrade(result, "buy")
+	if firstBuy == nil {
+		t.Fatal("没有产生买入交易")
+	}
+
+	// 50%仓位：首笔买入金额不超过初始资金的一半，且不少于一半减去一手
+	if firstBuy.Amount > 500000 || firstBuy.Amount < 500000-firstBuy.Price*100 {
+		t.Errorf("首笔买入金额 %.2f 不符合50%%仓位", firstBuy.Amount)
+	}
+	if firstBuy.StockNum%100 != 0 {
+		t.Errorf("买入数量 %d 不是整手", firstBuy.StockNum)
+	}
+}
+
+// TestTimeBasedBacktestEngineStrategySizer 测试策略自带的仓位管理器优先于引擎默认值
+func TestTimeBasedBacktestEngineStrategySizer(t *testing.T) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "测试1", risingPrices(900, 10, 0.002)),
+	}
+
+	strategy := strategies.NewBuyHighSellLowStrategy().WithSizer(sizers.NewEqualWeightSizer())
+	engine := NewTimeBasedBacktestEngine(1000000, strategy, 4, 1.0)
+	engine.SetStockData(data)
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	firstBuy := findFirstTrade(result, "buy")
+	if firstBuy == nil {
+		t.Fatal("没有产生买入交易")
+	}
+
+	// 等权4仓：首笔买入金额不超过初始资金的1/4
+	if firstBuy.Amount > 250000 {
+		t.Errorf("首笔买入金额 %.2f 超过等权仓位 250000", firstBuy.Amount)
+	}
+}
+
+// findFirstTrade 查找第一笔指定动作的交易
+func findFirstTrade(result *TimeBasedBacktestResult, action string) *TradeRecord {
+	for i := range result.TradeRecords {
+		if result.TradeRecords[i].Action == action {
+			return &result.TradeRecords[i]
+		}
+	}
+	return nil
+}
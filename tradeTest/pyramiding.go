@@ -0,0 +1,120 @@
+package tradeTest
+
+import (
+	"fmt"
+	"stock-go/stockStrategy/sizers"
+)
+
+// ScaleOutTarget 分批止盈档位
+type ScaleOutTarget struct {
+	ProfitPercent float64 // 相对平均成本的盈利比例，如0.10表示盈利10%
+	SellFraction  float64 // 卖出当前持仓的比例（0-1）
+}
+
+// PyramidPolicy 金字塔加仓与分批止盈策略
+// 加仓（海龟式）：价格每比上次买入价上涨 AddStepATR 个N（ATR），加仓一个单位，最多 MaxUnits 个单位
+// 减仓：盈利达到各档位时按比例卖出，开始减仓后不再加仓
+type PyramidPolicy struct {
+	MaxUnits        int              // 最多持有单位数（含首次买入），海龟默认4
+	AddStepATR      float64          // 加仓间隔（N的倍数），海龟默认0.5
+	ATRPeriod       int              // N（ATR）的计算周期，默认20
+	AddStepPercent  float64          // 无法计算ATR时的加仓间隔（百分比）
+	ScaleOutTargets []ScaleOutTarget // 分批止盈档位（按盈利比例从低到高）
+}
+
+// NewPyramidPolicy 创建海龟式加仓策略（不分批止盈）
+func NewPyramidPolicy(maxUnits int, addStepATR float64) *PyramidPolicy {
+	return &PyramidPolicy{
+		MaxUnits:       maxUnits,
+		AddStepATR:     addStepATR,
+		ATRPeriod:      20,
+		AddStepPercent: 0.05,
+	}
+}
+
+// WithScaleOut 设置分批止盈档位
+func (p *PyramidPolicy) WithScaleOut(targets ...ScaleOutTarget) *PyramidPolicy {
+	p.ScaleOutTargets = targets
+	return p
+}
+
+// GetName 获取策略名称
+func (p *PyramidPolicy) GetName() string {
+	return fmt.Sprintf("金字塔加仓(最多%d单位,每%.1fN加仓,%d档止盈)",
+		p.MaxUnits, p.AddStepATR, len(p.ScaleOutTargets))
+}
+
+// SetPyramidPolicy 设置加仓/减仓策略，nil表示不加仓也不分批止盈
+func (e *TimeBasedBacktestEngine) SetPyramidPolicy(policy *PyramidPolicy) {
+	e.pyramid = policy
+}
+
+// processScaling 处理分批止盈和金字塔加仓（在卖出之后、新开仓之前执行）
+func (e *TimeBasedBacktestEngine) processScaling(dayIdx int) {
+	if e.pyramid == nil {
+		return
+	}
+
+	for _, code := range e.sortedPositionCodes() {
+		pos := e.positions[code]
+		dayData := e.getDayData(code, e.currentDate)
+		if dayData == nil {
+			continue
+		}
+		price := float64(dayData.PriceBegin)
+
+		// 1. 分批止盈
+		if e.scaleOut(pos, price) {
+			continue
+		}
+
+		// 2. 金字塔加仓
+		e.scaleIn(pos, price, dayIdx)
+	}
+}
+
+// scaleOut 检查分批止盈档位，返回 true 表示当天执行了减仓
+func (e *TimeBasedBacktestEngine) scaleOut(pos *PositionState, price float64) bool {
+	if pos.ScaleOutDone >= len(e.pyramid.ScaleOutTargets) {
+		return false
+	}
+
+	target := e.pyramid.ScaleOutTargets[pos.ScaleOutDone]
+	profitPercent := (price - pos.BuyPrice) / pos.BuyPrice
+	if profitPercent < target.ProfitPercent {
+		return false
+	}
+
+	sellNum := sizers.GetLotRule(pos.Code).RoundDown(int(float64(pos.StockNum) * target.SellFraction))
+	if sellNum <= 0 || sellNum >= pos.StockNum {
+		// 不足最少交易数量，或会全部卖出，跳过该档位
+		pos.ScaleOutDone++
+		return false
+	}
+
+	reason := fmt.Sprintf("分批止盈(第%d档,盈利%.2f%%)", pos.ScaleOutDone+1, profitPercent*100)
+	if _, ok := e.sellShares(pos, sellNum, reason); !ok {
+		return false
+	}
+	pos.ScaleOutDone++
+	return true
+}
+
+// scaleIn 检查加仓条件并加仓一个单位
+func (e *TimeBasedBacktestEngine) scaleIn(pos *PositionState, price float64, dayIdx int) {
+	// 已开始减仓或已达到最大单位数时不再加仓
+	if pos.ScaleOutDone > 0 || len(pos.Lots) >= e.pyramid.MaxUnits {
+		return
+	}
+
+	step := pos.LastAddPrice * e.pyramid.AddStepPercent
+	if atr := sizers.CalculateATR(e.getHistory(pos.Code, e.currentDate), e.pyramid.ATRPeriod); atr > 0 {
+		step = atr * e.pyramid.AddStepATR
+	}
+	if price < pos.LastAddPrice+step {
+		return
+	}
+
+	reason := fmt.Sprintf("加仓(第%d单位)", len(pos.Lots)+1)
+	e.executeBuy(pos.Code, dayIdx, pos.UnitNum, reason)
+}
@@ -0,0 +1,139 @@
+package tradeTest
+
+import (
+	"math"
+	"stock-go/stockData"
+	"stock-go/stockStrategy/strategies"
+	"testing"
+)
+
+// pyramidTestData 构造加仓测试数据：一只持续上涨的票票
+func pyramidTestData() map[string]*stockData.StockInfo {
+	return map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "测试A", risingPrices(900, 10, 0.003)),
+	}
+}
+
+// firstHoldingPeriod 返回首次建仓到清仓之间的交易记录
+func firstHoldingPeriod(result *TimeBasedBacktestResult) []TradeRecord {
+	records := make([]TradeRecord, 0)
+	held := 0
+	for _, record := range result.TradeRecords {
+		records = append(records, record)
+		if record.Action == "buy" {
+			held += record.StockNum
+			continue
+		}
+		held -= record.StockNum
+		if held == 0 {
+			break
+		}
+	}
+	return records
+}
+
+// TestPyramidPolicyScaleIn 测试持续上涨时按单位加仓且不超过最大单位数
+func TestPyramidPolicyScaleIn(t *testing.T) {
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 1, 0.2)
+	engine.SetStockData(pyramidTestData())
+	engine.SetPyramidPolicy(NewPyramidPolicy(4, 0.5))
+
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	firstBuy := findFirstTrade(result, "buy")
+	if firstBuy == nil {
+		t.Fatal("没有买入交易")
+	}
+
+	buyCount := 0
+	for _, record := range firstHoldingPeriod(result) {
+		if record.Action != "buy" {
+			continue
+		}
+		buyCount++
+		// 加仓数量为一个单位（资金不足时减少）
+		if record.StockNum > firstBuy.StockNum {
+			t.Errorf("加仓数量 %d 超过首次买入数量 %d", record.StockNum, firstBuy.StockNum)
+		}
+	}
+	if buyCount < 2 {
+		t.Fatalf("没有发生加仓，买入次数 %d", buyCount)
+	}
+	if buyCount > 4 {
+		t.Errorf("加仓超过最大单位数，买入次数 %d", buyCount)
+	}
+}
+
+// TestPyramidPolicyScaleOut 测试盈利达到档位时分批卖出，并按先进先出记录批次
+func TestPyramidPolicyScaleOut(t *testing.T) {
+	policy := NewPyramidPolicy(1, 0.5).WithScaleOut(
+		ScaleOutTarget{ProfitPercent: 0.03, SellFraction: 0.5},
+		ScaleOutTarget{ProfitPercent: 0.06, SellFraction: 0.5},
+	)
+
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 1, 0.2)
+	engine.SetStockData(pyramidTestData())
+	engine.SetPyramidPolicy(policy)
+
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	firstBuy := findFirstTrade(result, "buy")
+	if firstBuy == nil {
+		t.Fatal("没有买入交易")
+	}
+
+	records := firstHoldingPeriod(result)
+	scaleOuts := 0
+	soldNum := 0
+	for _, record := range records {
+		if record.Action != "sell" {
+			continue
+		}
+		soldNum += record.StockNum
+
+		fillNum := 0
+		fillCost := 0.0
+		for _, fill := range record.LotFills {
+			fillNum += fill.StockNum
+			fillCost += fill.BuyPrice*float64(fill.StockNum) + fill.BuyFee
+			if fill.BuyDate != firstBuy.Date {
+				t.Errorf("批次买入日期 %s 与首次买入日期 %s 不一致", fill.BuyDate, firstBuy.Date)
+			}
+		}
+		if fillNum != record.StockNum {
+			t.Errorf("批次数量合计 %d 与卖出数量 %d 不一致", fillNum, record.StockNum)
+		}
+		if math.Abs(fillCost-record.CostBasis) > 1e-6 {
+			t.Errorf("批次成本合计 %.4f 与卖出成本 %.4f 不一致", fillCost, record.CostBasis)
+		}
+
+		if soldNum < firstBuy.StockNum {
+			scaleOuts++
+		}
+	}
+
+	if scaleOuts != 2 {
+		t.Errorf("分批止盈次数 %d，期望 2", scaleOuts)
+	}
+	if soldNum != firstBuy.StockNum {
+		t.Errorf("卖出数量合计 %d 与买入数量 %d 不一致", soldNum, firstBuy.StockNum)
+	}
+
+	// 所有批次的买入手续费都应分摊到卖出成本中
+	totalCost := 0.0
+	for _, record := range records {
+		if record.Action == "sell" {
+			totalCost += record.CostBasis
+		}
+	}
+	expectedCost := firstBuy.Amount + firstBuy.Commission + firstBuy.TransferFee
+	if math.Abs(totalCost-expectedCost) > 1e-6 {
+		t.Errorf("卖出成本合计 %.4f，期望 %.4f", totalCost, expectedCost)
+	}
+}
@@ -0,0 +1,103 @@
+package tradeTest
+
+import (
+	"stock-go/logger"
+	"stock-go/stockStrategy"
+)
+
+// RegimeStats 某一市场状态下的回测表现（收益率均为百分比）
+type RegimeStats struct {
+	Regime       string  // 市场状态
+	Days         int     // 交易日数
+	Return       float64 // 该状态下每日收益复合后的收益率
+	AvgDaily     float64 // 日均收益率
+	Trades       int     // 在该状态下建仓的平仓交易笔数
+	WinRate      float64 // 胜率
+	AvgReturnPct float64 // 平均每笔净收益率
+}
+
+// SetRegimeProvider 设置市场状态来源，回测报告按市场状态分组统计
+// 未设置时使用策略提供的市场状态（策略实现 stockStrategy.RegimeProvider）
+func (e *TimeBasedBacktestEngine) SetRegimeProvider(provider stockStrategy.RegimeProvider) {
+	e.regimes = provider
+}
+
+// regimeProvider 获取市场状态来源，没有时返回nil
+func (e *TimeBasedBacktestEngine) regimeProvider() stockStrategy.RegimeProvider {
+	if e.regimes != nil {
+		return e.regimes
+	}
+	if provider, ok := e.strategy.(stockStrategy.RegimeProvider); ok {
+		return provider
+	}
+	return nil
+}
+
+// BreakdownByRegime 按市场状态分组统计每日收益和平仓交易
+// 每天的收益归入当天的市场状态，平仓交易归入建仓日的市场状态；结果按首次出现的顺序排列
+func BreakdownByRegime(equity []DailyEquity, trades []ClosedTrade, regimeAt func(date string) string) []RegimeStats {
+	stats := make([]RegimeStats, 0)
+	positions := make(map[string]int)
+	growth := make(map[string]float64)
+	stat := func(regime string) *RegimeStats {
+		idx, ok := positions[regime]
+		if !ok {
+			idx = len(stats)
+			positions[regime] = idx
+			stats = append(stats, RegimeStats{Regime: regime})
+			growth[regime] = 1
+		}
+		return &stats[idx]
+	}
+
+	for i := 1; i < len(equity); i++ {
+		prev := equity[i-1].TotalAssets
+		if prev <= 0 {
+			continue
+		}
+		dailyReturn := equity[i].TotalAssets/prev - 1
+		regime := regimeAt(equity[i].Date)
+		s := stat(regime)
+		s.Days++
+		s.AvgDaily += dailyReturn
+		growth[regime] *= 1 + dailyReturn
+	}
+
+	wins := make(map[string]int)
+	for _, trade := range trades {
+		regime := regimeAt(trade.EntryDate)
+		s := stat(regime)
+		s.Trades++
+		s.AvgReturnPct += trade.ReturnPct
+		if trade.IsWin() {
+			wins[regime]++
+		}
+	}
+
+	for i := range stats {
+		s := &stats[i]
+		s.Return = (growth[s.Regime] - 1) * 100
+		if s.Days > 0 {
+			s.AvgDaily = s.AvgDaily / float64(s.Days) * 100
+		}
+		if s.Trades > 0 {
+			s.WinRate = float64(wins[s.Regime]) / float64(s.Trades) * 100
+			s.AvgReturnPct /= float64(s.Trades)
+		}
+	}
+	return stats
+}
+
+// printRegimeBreakdown 打印按市场状态分组的表现
+func printRegimeBreakdown(result *TimeBasedBacktestResult) {
+	if len(result.RegimeBreakdown) == 0 {
+		return
+	}
+
+	logger.Infof("")
+	logger.Infof("按市场状态统计:")
+	for _, s := range result.RegimeBreakdown {
+		logger.Infof("  %s: %d 个交易日 收益 %.2f%% (日均 %.3f%%) 交易 %d 笔 胜率 %.2f%% 平均每笔 %.2f%%",
+			s.Regime, s.Days, s.Return, s.AvgDaily, s.Trades, s.WinRate, s.AvgReturnPct)
+	}
+}
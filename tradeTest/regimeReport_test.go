@@ -0,0 +1,93 @@
+package tradeTest
+
+import (
+	"math"
+	"stock-go/stockData"
+	"stock-go/stockStrategy/regime"
+	"stock-go/stockStrategy/strategies"
+	"testing"
+)
+
+// TestBreakdownByRegime 测试每日收益按当天状态、平仓交易按建仓日状态分组
+func TestBreakdownByRegime(t *testing.T) {
+	equity := []DailyEquity{
+		{Date: "2020-01-02", TotalAssets: 100},
+		{Date: "2020-01-03", TotalAssets: 110},
+		{Date: "2020-01-06", TotalAssets: 99},
+		{Date: "2020-01-07", TotalAssets: 108.9},
+	}
+	trades := []ClosedTrade{
+		{EntryDate: "2020-01-02", NetPnL: 10, ReturnPct: 10},
+		{EntryDate: "2020-01-06", NetPnL: -5, ReturnPct: -5},
+		{EntryDate: "2020-01-06", NetPnL: 3, ReturnPct: 3},
+	}
+	regimes := map[string]string{"2020-01-02": "牛市", "2020-01-03": "牛市", "2020-01-06": "熊市", "2020-01-07": "牛市"}
+
+	stats := BreakdownByRegime(equity, trades, func(date string) string { return regimes[date] })
+	if len(stats) != 2 || stats[0].Regime != "牛市" || stats[1].Regime != "熊市" {
+		t.Fatalf("分组结果 %+v 不正确", stats)
+	}
+
+	bull, bear := stats[0], stats[1]
+	// 牛市：+10% 和 +10% 复合为 21%
+	if bull.Days != 2 || math.Abs(bull.Return-21) > 1e-9 || math.Abs(bull.AvgDaily-10) > 1e-9 {
+		t.Errorf("牛市统计 %+v 不正确", bull)
+	}
+	if bear.Days != 1 || math.Abs(bear.Return-(-10)) > 1e-9 {
+		t.Errorf("熊市统计 %+v 不正确", bear)
+	}
+	if bull.Trades != 1 || bull.WinRate != 100 || bear.Trades != 2 || bear.WinRate != 50 || math.Abs(bear.AvgReturnPct-(-1)) > 1e-9 {
+		t.Errorf("交易统计 牛市 %+v 熊市 %+v 不正确", bull, bear)
+	}
+}
+
+// TestMarketRegimeStrategyBacktest 测试大盘策略在熊市不开仓，回测结果按市场状态分组
+func TestMarketRegimeStrategyBacktest(t *testing.T) {
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "测试1", risingPrices(900, 10, 0.002)),
+	}
+	// 指数前700天下跌，之后上涨
+	indexPrices := risingPrices(700, 3000, -0.003)
+	indexPrices = append(indexPrices, risingPrices(200, indexPrices[699], 0.005)...)
+	index := newSyntheticStock("sh.000300", "沪深300", indexPrices)
+
+	filter := regime.NewFilter(regime.NewTrendClassifier(60, 20, 0.02), "").SetIndexData(index)
+	strategy := strategies.NewMarketRegimeStrategy(
+		strategies.NewBuyHighSellLowStrategyWithParams(100, 10, 50, 0.05, 15), filter)
+
+	engine := NewTimeBasedBacktestEngine(1000000, strategy, 1, 1.0)
+	engine.SetStockData(data)
+	engine.SetQuiet(true)
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	buys := 0
+	for _, record := range result.TradeRecords {
+		if record.Action != "buy" {
+			continue
+		}
+		buys++
+		if r := filter.RegimeAt(record.Date); r == regime.Bear {
+			t.Errorf("%s 熊市买入", record.Date)
+		}
+	}
+	if buys == 0 {
+		t.Fatal("指数转为上涨后没有买入")
+	}
+
+	days, trades := 0, 0
+	found := make(map[string]bool)
+	for _, s := range result.RegimeBreakdown {
+		days += s.Days
+		trades += s.Trades
+		found[s.Regime] = true
+	}
+	if !found["熊市"] || !found["牛市"] {
+		t.Errorf("市场状态分组 %+v 缺少熊市或牛市", result.RegimeBreakdown)
+	}
+	if days != len(result.DailyEquity)-1 || trades != len(result.ClosedTrades) {
+		t.Errorf("分组合计 %d 天 %d 笔，期望 %d 天 %d 笔", days, trades, len(result.DailyEquity)-1, len(result.ClosedTrades))
+	}
+}
@@ -0,0 +1,73 @@
+package robustness
+
+import (
+	"stock-go/logger"
+	"stock-go/tradeTest"
+	"stock-go/tradeTest/performance"
+)
+
+// Analysis 一次回测结果的蒙特卡洛稳健性分析
+type Analysis struct {
+	TradeBootstrap *Report // 交易自助法
+	TradeShuffle   *Report // 交易顺序重排
+	BlockBootstrap *Report // 日收益块自助法
+}
+
+// Analyze 对回测结果执行三种蒙特卡洛模拟
+func Analyze(result *tradeTest.TimeBasedBacktestResult, cfg Config) *Analysis {
+	tradeReturns := TradeReturns(result)
+	dailyReturns := performance.DailyReturns(tradeTest.EquityPoints(result.DailyEquity))
+
+	// 每年交易笔数（用于年化交易序列的夏普）
+	cfg = normalize(cfg)
+	periodsPerYear := 0.0
+	if days := len(result.DailyEquity); days > 0 {
+		periodsPerYear = float64(len(tradeReturns)) / (float64(days) / float64(cfg.TradingDaysPerYear))
+	}
+
+	return &Analysis{
+		TradeBootstrap: TradeBootstrap(tradeReturns, periodsPerYear, cfg),
+		TradeShuffle:   TradeShuffle(tradeReturns, periodsPerYear, cfg),
+		BlockBootstrap: BlockBootstrap(dailyReturns, cfg),
+	}
+}
+
+// TradeReturns 每笔平仓交易对账户的收益贡献：净盈亏/建仓当天的总资产（按平仓先后排序）
+func TradeReturns(result *tradeTest.TimeBasedBacktestResult) []float64 {
+	assets := make(map[string]float64, len(result.DailyEquity))
+	for _, day := range result.DailyEquity {
+		assets[day.Date] = day.TotalAssets
+	}
+
+	returns := make([]float64, 0, len(result.ClosedTrades))
+	for _, trade := range result.ClosedTrades {
+		base, ok := assets[trade.EntryDate]
+		if !ok || base <= 0 {
+			base = result.InitialCash
+		}
+		returns = append(returns, trade.NetPnL/base)
+	}
+	return returns
+}
+
+// PrintAnalysis 打印稳健性分析结果
+func PrintAnalysis(analysis *Analysis, cfg Config) {
+	cfg = normalize(cfg)
+	logger.Infof("========================================")
+	logger.Infof("蒙特卡洛稳健性分析（%.0f%%置信区间，破产线 %.0f%%）", cfg.Confidence*100, cfg.RuinLevel*100)
+	logger.Infof("========================================")
+	for _, report := range []*Report{analysis.TradeBootstrap, analysis.TradeShuffle, analysis.BlockBootstrap} {
+		if report == nil || report.Simulations == 0 {
+			continue
+		}
+		logger.Infof("%s (%d 次):", report.Method, report.Simulations)
+		logger.Infof("  最终收益率: 中位数 %.2f%% 区间 [%.2f%%, %.2f%%]",
+			report.FinalReturn.Median, report.FinalReturn.Lower, report.FinalReturn.Upper)
+		logger.Infof("  最大回撤: 中位数 %.2f%% 区间 [%.2f%%, %.2f%%]",
+			report.MaxDrawdown.Median, report.MaxDrawdown.Lower, report.MaxDrawdown.Upper)
+		logger.Infof("  夏普比率: 中位数 %.2f 区间 [%.2f, %.2f]",
+			report.Sharpe.Median, report.Sharpe.Lower, report.Sharpe.Upper)
+		logger.Infof("  破产概率: %.2f%%", report.RuinProbability*100)
+	}
+	logger.Infof("========================================")
+}
@@ -0,0 +1,219 @@
+package robustness
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"stock-go/tradeTest/performance"
+)
+
+// Config 蒙特卡洛模拟参数
+type Config struct {
+	Simulations        int     // 模拟次数，默认1000
+	Seed               uint64  // 随机种子（相同种子结果可复现）
+	BlockSize          int     // 日收益块自助法的块长度（交易日），默认20
+	Confidence         float64 // 置信水平，如0.9表示取5%和95%分位数
+	RuinLevel          float64 // 破产线：权益跌到初始资金的该比例以下视为破产，如0.5
+	TradingDaysPerYear int     // 每年交易日数，默认252
+}
+
+// NewDefaultConfig 默认参数
+func NewDefaultConfig() Config {
+	return Config{
+		Simulations:        1000,
+		Seed:               1,
+		BlockSize:          20,
+		Confidence:         0.9,
+		RuinLevel:          0.5,
+		TradingDaysPerYear: 252,
+	}
+}
+
+// Interval 模拟结果的分布和置信区间
+type Interval struct {
+	Mean   float64
+	Median float64
+	Lower  float64 // 置信区间下限
+	Upper  float64 // 置信区间上限
+}
+
+// Report 一种模拟方法的结果（收益率和回撤为百分比）
+type Report struct {
+	Method          string
+	Simulations     int
+	FinalReturn     Interval // 最终收益率
+	MaxDrawdown     Interval // 最大回撤
+	Sharpe          Interval // 夏普比率
+	RuinProbability float64  // 破产概率（0-1）
+}
+
+// pathStats 单条模拟路径的统计
+type pathStats struct {
+	finalReturn float64
+	maxDrawdown float64
+	sharpe      float64
+	ruined      bool
+}
+
+// TradeBootstrap 交易自助法：有放回地抽取交易，组成与原交易数相同的新序列
+// tradeReturns 为每笔交易对账户的收益贡献（净盈亏/买入时的总资产）
+// periodsPerYear 为每年交易笔数，用于年化夏普
+func TradeBootstrap(tradeReturns []float64, periodsPerYear float64, cfg Config) *Report {
+	cfg = normalize(cfg)
+	rng := rand.New(rand.NewPCG(cfg.Seed, 1))
+	n := len(tradeReturns)
+
+	return simulate("交易自助法", cfg, func() []float64 {
+		path := make([]float64, n)
+		for i := range path {
+			path[i] = tradeReturns[rng.IntN(n)]
+		}
+		return path
+	}, n, periodsPerYear)
+}
+
+// TradeShuffle 交易顺序重排：交易不变、顺序随机，最终收益相同，回撤分布不同
+func TradeShuffle(tradeReturns []float64, periodsPerYear float64, cfg Config) *Report {
+	cfg = normalize(cfg)
+	rng := rand.New(rand.NewPCG(cfg.Seed, 2))
+	n := len(tradeReturns)
+
+	return simulate("交易顺序重排", cfg, func() []float64 {
+		path := append([]float64(nil), tradeReturns...)
+		rng.Shuffle(len(path), func(i, j int) { path[i], path[j] = path[j], path[i] })
+		return path
+	}, n, periodsPerYear)
+}
+
+// BlockBootstrap 日收益块自助法：有放回地抽取连续 BlockSize 天的日收益块拼接成新路径，保留短期的自相关
+func BlockBootstrap(dailyReturns []float64, cfg Config) *Report {
+	cfg = normalize(cfg)
+	rng := rand.New(rand.NewPCG(cfg.Seed, 3))
+	n := len(dailyReturns)
+	blockSize := cfg.BlockSize
+	if blockSize > n {
+		blockSize = n
+	}
+
+	return simulate("日收益块自助法", cfg, func() []float64 {
+		path := make([]float64, 0, n)
+		for len(path) < n {
+			start := rng.IntN(n - blockSize + 1)
+			end := start + blockSize
+			if remain := n - len(path); end-start > remain {
+				end = start + remain
+			}
+			path = append(path, dailyReturns[start:end]...)
+		}
+		return path
+	}, n, float64(cfg.TradingDaysPerYear))
+}
+
+// simulate 执行模拟并汇总结果
+func simulate(method string, cfg Config, sample func() []float64, n int, periodsPerYear float64) *Report {
+	report := &Report{Method: method}
+	if n == 0 {
+		return report
+	}
+
+	stats := make([]pathStats, 0, cfg.Simulations)
+	for i := 0; i < cfg.Simulations; i++ {
+		stats = append(stats, evaluatePath(sample(), periodsPerYear, cfg.RuinLevel))
+	}
+
+	report.Simulations = len(stats)
+	finalReturns := make([]float64, len(stats))
+	drawdowns := make([]float64, len(stats))
+	sharpes := make([]float64, len(stats))
+	ruined := 0
+	for i, s := range stats {
+		finalReturns[i] = s.finalReturn
+		drawdowns[i] = s.maxDrawdown
+		sharpes[i] = s.sharpe
+		if s.ruined {
+			ruined++
+		}
+	}
+
+	report.FinalReturn = newInterval(finalReturns, cfg.Confidence)
+	report.MaxDrawdown = newInterval(drawdowns, cfg.Confidence)
+	report.Sharpe = newInterval(sharpes, cfg.Confidence)
+	report.RuinProbability = float64(ruined) / float64(len(stats))
+	return report
+}
+
+// evaluatePath 按收益率序列复利计算权益路径（初始为1），统计收益、回撤、夏普和是否破产
+func evaluatePath(returns []float64, periodsPerYear, ruinLevel float64) pathStats {
+	equity, peak := 1.0, 1.0
+	stats := pathStats{}
+	for _, r := range returns {
+		equity *= 1 + r
+		if equity > peak {
+			peak = equity
+		}
+		if dd := (peak - equity) / peak; dd > stats.maxDrawdown {
+			stats.maxDrawdown = dd
+		}
+		if equity <= ruinLevel {
+			stats.ruined = true
+		}
+	}
+	stats.finalReturn = (equity - 1) * 100
+	stats.maxDrawdown *= 100
+
+	if std := performance.StdDev(returns); std > 0 {
+		stats.sharpe = performance.Mean(returns) / std * math.Sqrt(periodsPerYear)
+	}
+	return stats
+}
+
+// newInterval 计算均值、中位数和置信区间
+func newInterval(values []float64, confidence float64) Interval {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	tail := (1 - confidence) / 2
+	return Interval{
+		Mean:   performance.Mean(sorted),
+		Median: Percentile(sorted, 0.5),
+		Lower:  Percentile(sorted, tail),
+		Upper:  Percentile(sorted, 1-tail),
+	}
+}
+
+// Percentile 已排序数据的分位数（线性插值），p 取 0-1
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if p <= 0 {
+		return sorted[0]
+	}
+	if p >= 1 {
+		return sorted[len(sorted)-1]
+	}
+
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	frac := pos - float64(lower)
+	return sorted[lower]*(1-frac) + sorted[upper]*frac
+}
+
+// normalize 补全未设置的参数
+func normalize(cfg Config) Config {
+	def := NewDefaultConfig()
+	if cfg.Simulations <= 0 {
+		cfg.Simulations = def.Simulations
+	}
+	if cfg.BlockSize <= 0 {
+		cfg.BlockSize = def.BlockSize
+	}
+	if cfg.Confidence <= 0 || cfg.Confidence >= 1 {
+		cfg.Confidence = def.Confidence
+	}
+	if cfg.TradingDaysPerYear <= 0 {
+		cfg.TradingDaysPerYear = def.TradingDaysPerYear
+	}
+	return cfg
+}
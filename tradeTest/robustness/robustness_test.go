@@ -0,0 +1,109 @@
+package robustness
+
+import (
+	"math"
+	"stock-go/tradeTest"
+	"stock-go/tradeTest/performance"
+	"testing"
+)
+
+// TestTradeShuffleKeepsFinalReturn 测试重排交易顺序不改变最终收益，但回撤不低于原顺序的最好情况
+func TestTradeShuffleKeepsFinalReturn(t *testing.T) {
+	returns := []float64{0.05, -0.03, 0.02, -0.04, 0.06, 0.01, -0.02, 0.03}
+	expected := 1.0
+	for _, r := range returns {
+		expected *= 1 + r
+	}
+	expected = (expected - 1) * 100
+
+	cfg := NewDefaultConfig()
+	cfg.Simulations = 200
+	report := TradeShuffle(returns, 8, cfg)
+
+	if report.Simulations != 200 {
+		t.Fatalf("模拟次数 %d，期望 200", report.Simulations)
+	}
+	if math.Abs(report.FinalReturn.Lower-expected) > 1e-9 || math.Abs(report.FinalReturn.Upper-expected) > 1e-9 {
+		t.Errorf("重排后最终收益区间 [%.4f, %.4f]，期望都为 %.4f",
+			report.FinalReturn.Lower, report.FinalReturn.Upper, expected)
+	}
+	if report.MaxDrawdown.Lower > report.MaxDrawdown.Upper || report.MaxDrawdown.Upper <= 0 {
+		t.Errorf("回撤区间 %+v 不正确", report.MaxDrawdown)
+	}
+}
+
+// TestTradeBootstrapRuin 测试破产概率
+func TestTradeBootstrapRuin(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Simulations = 500
+
+	// 全部亏损：必然跌破破产线
+	losing := []float64{-0.1, -0.1, -0.1, -0.1, -0.1, -0.1, -0.1, -0.1}
+	if report := TradeBootstrap(losing, 8, cfg); report.RuinProbability != 1 {
+		t.Errorf("全部亏损时破产概率 %.2f，期望 1", report.RuinProbability)
+	}
+
+	// 全部盈利：不会破产
+	winning := []float64{0.01, 0.02, 0.03}
+	report := TradeBootstrap(winning, 3, cfg)
+	if report.RuinProbability != 0 || report.MaxDrawdown.Upper != 0 {
+		t.Errorf("全部盈利时破产概率 %.2f 回撤 %+v，期望都为0", report.RuinProbability, report.MaxDrawdown)
+	}
+	if report.FinalReturn.Lower < 3 || report.FinalReturn.Upper > 9.3 {
+		t.Errorf("最终收益区间 %+v 超出可能范围", report.FinalReturn)
+	}
+}
+
+// TestBlockBootstrapReproducible 测试相同种子结果可复现，块自助法保持路径长度
+func TestBlockBootstrapReproducible(t *testing.T) {
+	daily := make([]float64, 250)
+	for i := range daily {
+		daily[i] = 0.001 * math.Sin(float64(i)/5)
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.Simulations = 100
+	a := BlockBootstrap(daily, cfg)
+	b := BlockBootstrap(daily, cfg)
+	if a.FinalReturn != b.FinalReturn || a.Sharpe != b.Sharpe {
+		t.Errorf("相同种子结果不一致: %+v vs %+v", a.FinalReturn, b.FinalReturn)
+	}
+
+	cfg.Seed = 2
+	if c := BlockBootstrap(daily, cfg); c.FinalReturn == a.FinalReturn {
+		t.Errorf("不同种子结果相同")
+	}
+}
+
+// TestAnalyzeResult 测试从回测结果提取交易贡献和日收益
+func TestAnalyzeResult(t *testing.T) {
+	result := &tradeTest.TimeBasedBacktestResult{
+		InitialCash: 1000,
+		DailyEquity: []tradeTest.DailyEquity{
+			{Date: "2020-01-02", TotalAssets: 1000},
+			{Date: "2020-01-03", TotalAssets: 1100},
+			{Date: "2020-01-06", TotalAssets: 1045},
+		},
+		ClosedTrades: []tradeTest.ClosedTrade{
+			{EntryDate: "2020-01-02", NetPnL: 100},
+			{EntryDate: "2020-01-03", NetPnL: -55},
+		},
+	}
+
+	returns := TradeReturns(result)
+	if len(returns) != 2 || math.Abs(returns[0]-0.1) > 1e-9 || math.Abs(returns[1]+0.05) > 1e-9 {
+		t.Errorf("交易贡献 %v，期望 [0.1 -0.05]", returns)
+	}
+
+	daily := performance.DailyReturns(tradeTest.EquityPoints(result.DailyEquity))
+	if len(daily) != 2 || math.Abs(daily[0]-0.1) > 1e-9 || math.Abs(daily[1]+0.05) > 1e-9 {
+		t.Errorf("日收益 %v，期望 [0.1 -0.05]", daily)
+	}
+
+	cfg := NewDefaultConfig()
+	cfg.Simulations = 50
+	analysis := Analyze(result, cfg)
+	if analysis.TradeBootstrap.Simulations != 50 || analysis.BlockBootstrap.Simulations != 50 {
+		t.Errorf("模拟次数不正确: %+v", analysis)
+	}
+}
@@ -0,0 +1,191 @@
+package tradeTest
+
+import (
+	"fmt"
+	"stock-go/logger"
+	"stock-go/stockStrategy/sizers"
+)
+
+// RotationMode 轮换方式
+type RotationMode int
+
+const (
+	RotationSellAll  RotationMode = iota // 卖出最弱持仓，腾出名额和资金
+	RotationSellHalf                     // 最弱持仓减半，仅在名额未满、资金不足时使用
+)
+
+// RotationRankBy 持仓强弱的排序依据
+type RotationRankBy int
+
+const (
+	RankByProfit   RotationRankBy = iota // 持仓盈利率（候选票票视为0）
+	RankByMomentum                       // 最近 MomentumDays 天涨幅
+	RankByDrawdown                       // 距回看期最高价的回撤（回撤越小越强）
+)
+
+// RotationPolicy 满仓时的换仓策略
+// 当新的买入信号出现且满仓（名额已满或资金不足）时，
+// 若候选票票的得分比最弱持仓高出 Hysteresis，则卖出（或减半）最弱持仓换入候选票票
+type RotationPolicy struct {
+	Mode               RotationMode   // 轮换方式
+	RankBy             RotationRankBy // 排序依据
+	MomentumDays       int            // 动量/回撤的回看天数，默认20
+	Hysteresis         float64        // 迟滞：候选得分需超过最弱持仓得分的幅度，如0.05表示5个百分点
+	MinHoldDays        int            // 持有不足该天数的持仓不参与轮换
+	CooldownDays       int            // 被换出的票票在该天数内禁止买回
+	MaxRotationsPerDay int            // 每天最多轮换次数
+}
+
+// NewRotationPolicy 创建换仓策略（默认参数）
+func NewRotationPolicy(mode RotationMode, rankBy RotationRankBy) *RotationPolicy {
+	return &RotationPolicy{
+		Mode:               mode,
+		RankBy:             rankBy,
+		MomentumDays:       20,
+		Hysteresis:         0.05,
+		MinHoldDays:        5,
+		CooldownDays:       20,
+		MaxRotationsPerDay: 1,
+	}
+}
+
+// GetName 获取换仓策略名称
+func (p *RotationPolicy) GetName() string {
+	mode := "卖出最弱"
+	if p.Mode == RotationSellHalf {
+		mode = "最弱减半"
+	}
+
+	rankBy := "盈利率"
+	switch p.RankBy {
+	case RankByMomentum:
+		rankBy = fmt.Sprintf("%d天动量", p.MomentumDays)
+	case RankByDrawdown:
+		rankBy = fmt.Sprintf("%d天回撤", p.MomentumDays)
+	}
+
+	return fmt.Sprintf("%s(按%s,迟滞%.1f%%)", mode, rankBy, p.Hysteresis*100)
+}
+
+// SetRotationPolicy 设置满仓时的换仓策略，nil表示满仓时直接放弃新的买入信号
+func (e *TimeBasedBacktestEngine) SetRotationPolicy(policy *RotationPolicy) {
+	e.rotation = policy
+}
+
+// isBookFull 判断是否满仓：名额已满或现金不足以买入最少数量
+func (e *TimeBasedBacktestEngine) isBookFull(code string, price float64) bool {
+	if len(e.positions) >= e.maxPositions {
+		return true
+	}
+	minCost := price * float64(sizers.GetLotRule(code).MinShares)
+	return e.wallet.Cash < minCost
+}
+
+// scoreCandidate 计算候选票票的得分（排序依据与持仓一致）
+func (e *TimeBasedBacktestEngine) scoreCandidate(code string, price float64) float64 {
+	switch e.rotation.RankBy {
+	case RankByMomentum:
+		return e.momentumScore(code, price)
+	case RankByDrawdown:
+		return e.drawdownScore(code, price)
+	}
+	// 按盈利率排序时，新买入的票票盈利率为0
+	return 0
+}
+
+// scorePosition 计算持仓的得分
+func (e *TimeBasedBacktestEngine) scorePosition(pos *PositionState, price float64) float64 {
+	if e.rotation == nil {
+		return (price - pos.BuyPrice) / pos.BuyPrice
+	}
+
+	switch e.rotation.RankBy {
+	case RankByMomentum:
+		return e.momentumScore(pos.Code, price)
+	case RankByDrawdown:
+		return e.drawdownScore(pos.Code, price)
+	}
+	return (price - pos.BuyPrice) / pos.BuyPrice
+}
+
+// momentumScore 最近N天涨幅（以前N个交易日收盘价为基准）
+func (e *TimeBasedBacktestEngine) momentumScore(code string, price float64) float64 {
+	history := e.getHistory(code, e.currentDate)
+	days := e.rotation.MomentumDays
+	if days <= 0 || len(history) < days {
+		return 0
+	}
+
+	basePrice := float64(history[len(history)-days].PriceEnd)
+	if basePrice <= 0 {
+		return 0
+	}
+	return (price - basePrice) / basePrice
+}
+
+// drawdownScore 距最近N天最高价的回撤（取负值，回撤越小得分越高）
+func (e *TimeBasedBacktestEngine) drawdownScore(code string, price float64) float64 {
+	history := e.getHistory(code, e.currentDate)
+	days := e.rotation.MomentumDays
+	if days <= 0 || len(history) < days {
+		return 0
+	}
+
+	highest := price
+	for _, dayData := range history[len(history)-days:] {
+		if float64(dayData.PriceHigh) > highest {
+			highest = float64(dayData.PriceHigh)
+		}
+	}
+	return -(highest - price) / highest
+}
+
+// tryRotate 尝试为候选票票换仓
+// 返回 true 表示已卖出（或减半）最弱持仓，可以继续买入候选票票
+func (e *TimeBasedBacktestEngine) tryRotate(code string, price float64, dayIdx int) bool {
+	// 候选票票当天涨停无法买入时不换仓，避免卖出后买不进
+	if e.checkPriceLimit(code, e.currentDate, price) {
+		return false
+	}
+
+	weakest := e.findWeakestPosition(e.rotation.MinHoldDays)
+	if weakest == nil {
+		return false
+	}
+
+	weakestPrice := weakest.CurrentPrice
+	if dayData := e.getDayData(weakest.Code, e.currentDate); dayData != nil {
+		weakestPrice = float64(dayData.PriceBegin)
+	}
+
+	candidateScore := e.scoreCandidate(code, price)
+	weakestScore := e.scorePosition(weakest, weakestPrice)
+	if candidateScore-weakestScore < e.rotation.Hysteresis {
+		return false
+	}
+
+	reason := fmt.Sprintf("轮换卖出(换入%s,得分%.2f%%<%.2f%%)",
+		code, weakestScore*100, candidateScore*100)
+
+	sold := false
+	if e.rotation.Mode == RotationSellHalf && len(e.positions) < e.maxPositions {
+		// 名额未满、仅资金不足时减半
+		sold = e.sellHalfPosition(weakest, reason) > 0
+	} else {
+		sold = e.executeSell(weakest, reason)
+	}
+	if !sold {
+		return false
+	}
+
+	e.tradeRecords[len(e.tradeRecords)-1].IsRotation = true
+	if e.rotation.CooldownDays > 0 {
+		e.buyCooldowns[weakest.Code] = dayIdx + e.rotation.CooldownDays
+	}
+
+	if !e.quiet {
+		logger.Infof("换仓 %s: 换出 %s(得分%.2f%%) 换入 %s(得分%.2f%%)",
+			e.currentDate, weakest.Code, weakestScore*100, code, candidateScore*100)
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package tradeTest
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockStrategy/strategies"
+	"testing"
+)
+
+// rotationTestData 构造换仓测试数据
+// A: 前801天缓慢上涨后横盘，第800天创新高被买入后动量逐渐消失
+// B: 前815天横盘，之后每天上涨2%，持续创新高
+func rotationTestData() map[string]*stockData.StockInfo {
+	pricesA := risingPrices(900, 10, 0.002)
+	for i := 801; i < len(pricesA); i++ {
+		pricesA[i] = pricesA[800]
+	}
+
+	pricesB := make([]float64, 900)
+	for i := range pricesB {
+		pricesB[i] = 10
+	}
+	for i := 815; i < len(pricesB); i++ {
+		pricesB[i] = pricesB[i-1] * 1.02
+	}
+
+	return map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "测试A", pricesA),
+		"sz.000002": newSyntheticStock("sz.000002", "测试B", pricesB),
+	}
+}
+
+// TestRotationPolicySellWeakest 测试满仓时按动量卖出最弱持仓换入更强的票票
+func TestRotationPolicySellWeakest(t *testing.T) {
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 1, 1.0)
+	engine.SetStockData(rotationTestData())
+	engine.SetRotationPolicy(NewRotationPolicy(RotationSellAll, RankByMomentum))
+
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	if result.RotationCount == 0 {
+		t.Fatal("没有发生换仓")
+	}
+
+	rotationSell, rotationBuy := false, false
+	for _, record := range result.TradeRecords {
+		if !record.IsRotation {
+			continue
+		}
+		if record.Action == "sell" && record.Code == "sz.000001" {
+			rotationSell = true
+		}
+		if record.Action == "buy" && record.Code == "sz.000002" {
+			rotationBuy = true
+		}
+	}
+	if !rotationSell || !rotationBuy {
+		t.Errorf("换仓交易标记不正确: 换出A=%v 换入B=%v", rotationSell, rotationBuy)
+	}
+}
+
+// TestRotationPolicyDisabled 测试未设置换仓策略时满仓直接放弃新信号
+func TestRotationPolicyDisabled(t *testing.T) {
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 1, 1.0)
+	engine.SetStockData(rotationTestData())
+
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	if result.RotationCount != 0 {
+		t.Errorf("未启用换仓时换仓次数为 %d", result.RotationCount)
+	}
+	for _, record := range result.TradeRecords {
+		if record.IsRotation {
+			t.Errorf("未启用换仓时出现换仓交易: %+v", record)
+		}
+	}
+}
+
+// TestRotationPolicyHysteresis 测试迟滞过大时不换仓
+func TestRotationPolicyHysteresis(t *testing.T) {
+	policy := NewRotationPolicy(RotationSellAll, RankByMomentum)
+	policy.Hysteresis = 10 // 要求得分高出1000个百分点，不可能满足
+
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 1, 1.0)
+	engine.SetStockData(rotationTestData())
+	engine.SetRotationPolicy(policy)
+
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	if result.RotationCount != 0 {
+		t.Errorf("迟滞过大时仍换仓 %d 次", result.RotationCount)
+	}
+}
+
+// TestFindWeakestPositionSkipsYoung 测试持有天数不足的持仓不参与最弱持仓的比较
+func TestFindWeakestPositionSkipsYoung(t *testing.T) {
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBuyHighSellLowStrategy(), 3, 0.3)
+	for _, code := range []string{"sz.000001", "sz.000002", "sz.000003"} {
+		engine.allStockData[code] = newSyntheticStock(code, code, risingPrices(10, 10, 0))
+	}
+	engine.buildTradingDays()
+	engine.currentDate = engine.allStockData["sz.000001"].Datas.DayDatas[9].DataStr
+
+	// 刚买入的持仓亏损最大，但持有天数不足，应选择其余持仓中最弱的
+	engine.positions["sz.000001"] = &PositionState{Code: "sz.000001", BuyPrice: 20, HoldDays: 1}
+	engine.positions["sz.000002"] = &PositionState{Code: "sz.000002", BuyPrice: 12, HoldDays: 10}
+	engine.positions["sz.000003"] = &PositionState{Code: "sz.000003", BuyPrice: 5, HoldDays: 10}
+
+	if weakest := engine.findWeakestPosition(5); weakest == nil || weakest.Code != "sz.000002" {
+		t.Errorf("最弱持仓 %+v，期望 sz.000002", weakest)
+	}
+	if weakest := engine.findWeakestPosition(0); weakest == nil || weakest.Code != "sz.000001" {
+		t.Errorf("不限持有天数时最弱持仓 %+v，期望 sz.000001", weakest)
+	}
+	if weakest := engine.findWeakestPosition(20); weakest != nil {
+		t.Errorf("持有天数都不足时不应换仓: %+v", weakest)
+	}
+}
@@ -0,0 +1,177 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"stock-go/stockData"
+	"stock-go/stockStrategy/strategies"
+	"stock-go/tradeTest"
+	"stock-go/tradeTest/optimizer"
+)
+
+// UniverseList 票票池：票票代码 -> 名称（指定 codes 时名称取自已加载的全局票票列表，可能为空）
+func (u UniverseConfig) UniverseList() map[string]string {
+	switch {
+	case len(u.Codes) > 0:
+		list := make(map[string]string, len(u.Codes))
+		for _, code := range u.Codes {
+			list[code] = stockData.StockList[code]
+		}
+		return list
+	case u.SampleSeed != 0:
+		return stockData.SampleStockList(u.SampleSeed)
+	}
+	if len(stockData.StockList) == 0 {
+		stockData.LoadAllStockList()
+	}
+	return stockData.StockList
+}
+
+// UniverseCodes 票票池的代码列表（已排序）
+func (u UniverseConfig) UniverseCodes() []string {
+	return sortedCodes(u.UniverseList())
+}
+
+// sortedCodes 票票代码（排序后）
+func sortedCodes(list map[string]string) []string {
+	codes := make([]string, 0, len(list))
+	for code := range list {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// LoadUniverse 加载票票池的原始数据
+func (u UniverseConfig) LoadUniverse() map[string]*stockData.StockInfo {
+	return optimizer.LoadStockData(u.UniverseCodes())
+}
+
+// ReadUniverse 从数据文件读取票票池的原始数据（带票票名称），不读写全局缓存
+// 票票池需要指定 codes 或 sampleSeed（全市场需要加载全局票票列表）
+func (u UniverseConfig) ReadUniverse() (map[string]*stockData.StockInfo, error) {
+	if len(u.Codes) == 0 && u.SampleSeed == 0 {
+		return nil, fmt.Errorf("backtest.universe 需要指定 codes 或 sampleSeed")
+	}
+	list := u.UniverseList()
+	data := make(map[string]*stockData.StockInfo)
+	for _, code := range sortedCodes(list) {
+		info := stockData.ReadStockRaw(code)
+		if len(info.Datas.DayDatas) == 0 {
+			continue
+		}
+		if name := list[code]; name != "" {
+			info.Name = name
+		}
+		data[code] = info
+	}
+	return data, nil
+}
+
+// NewEngine 按配置创建回测引擎
+// data 为nil时按 backtest.universe 加载数据；基准和市场状态指数从数据文件读取后注入，不读写全局缓存
+func (c *Config) NewEngine(data map[string]*stockData.StockInfo) (*tradeTest.TimeBasedBacktestEngine, error) {
+	strategy, err := c.BuildStrategy()
+	if err != nil {
+		return nil, err
+	}
+	sizer, err := c.BuildSizer()
+	if err != nil {
+		return nil, err
+	}
+
+	b := c.Backtest
+	engine := tradeTest.NewTimeBasedBacktestEngine(b.InitialCash, strategy, b.MaxPositions, b.CashPerPosition)
+	engine.SetFees(b.Fees.CommissionRate, b.Fees.StampTaxRate, b.Fees.TransferFeeRate, b.Fees.MinCommission)
+	engine.SetDateRange(b.StartDate, b.EndDate)
+	if sizer != nil {
+		engine.SetSizer(sizer)
+	}
+	if b.Benchmark != "" {
+		engine.SetBenchmark(b.Benchmark)
+		engine.SetBenchmarkData(stockData.ReadStockRaw(b.Benchmark))
+	}
+	if filtered, ok := strategy.(*strategies.MarketRegimeStrategy); ok {
+		filter := filtered.GetFilter()
+		filter.SetIndexData(stockData.ReadStockRaw(filter.IndexCode))
+	}
+	if data == nil {
+		data = b.Universe.LoadUniverse()
+	}
+	engine.SetStockData(data)
+	return engine, nil
+}
+
+// Run 按配置运行回测
+func (c *Config) Run(data map[string]*stockData.StockInfo, quiet bool) (*tradeTest.TimeBasedBacktestResult, error) {
+	engine, err := c.NewEngine(data)
+	if err != nil {
+		return nil, err
+	}
+	return c.run(engine, quiet)
+}
+
+// RunIsolated 按配置运行回测，票票池从数据文件读取，不读写全局缓存
+// 用于 HTTP 接口等与其他代码并发运行的场景，票票池需要指定 codes 或 sampleSeed
+func (c *Config) RunIsolated(quiet bool) (*tradeTest.TimeBasedBacktestResult, error) {
+	data, err := c.Backtest.Universe.ReadUniverse()
+	if err != nil {
+		return nil, err
+	}
+	engine, err := c.NewEngine(data)
+	if err != nil {
+		return nil, err
+	}
+	return c.run(engine, quiet)
+}
+
+// run 运行回测引擎
+func (c *Config) run(engine *tradeTest.TimeBasedBacktestEngine, quiet bool) (*tradeTest.TimeBasedBacktestResult, error) {
+	engine.SetQuiet(quiet)
+	result := engine.Run()
+	if result == nil {
+		return nil, fmt.Errorf("回测 %s 失败: %w", c.Name, engine.Err())
+	}
+	return result, nil
+}
+
+// Summary 回测结果摘要（用于 HTTP 接口和命令行输出）
+type Summary struct {
+	Name             string  `json:"name"`
+	Strategy         string  `json:"strategy"`
+	StartDate        string  `json:"startDate"`
+	EndDate          string  `json:"endDate"`
+	InitialCash      float64 `json:"initialCash"`
+	FinalAssets      float64 `json:"finalAssets"`
+	TotalReturnPct   float64 `json:"totalReturnPct"`   // 总收益率（百分比）
+	AnnualizedReturn float64 `json:"annualizedReturn"` // 年化收益率
+	MaxDrawdown      float64 `json:"maxDrawdown"`      // 最大回撤（百分比）
+	SharpeRatio      float64 `json:"sharpeRatio"`
+	TotalTrades      int     `json:"totalTrades"`
+	WinRate          float64 `json:"winRate"` // 胜率（百分比）
+	TotalFees        float64 `json:"totalFees"`
+}
+
+// Summarize 生成回测结果摘要
+func (c *Config) Summarize(result *tradeTest.TimeBasedBacktestResult) Summary {
+	s := Summary{
+		Name:           c.Name,
+		Strategy:       result.Strategy,
+		InitialCash:    result.InitialCash,
+		FinalAssets:    result.FinalAssets,
+		TotalReturnPct: result.TotalReturnPct,
+		MaxDrawdown:    result.MaxDrawdown,
+		SharpeRatio:    result.SharpeRatio,
+		TotalTrades:    result.TotalTrades,
+		WinRate:        result.WinRate,
+		TotalFees:      result.TotalFees,
+	}
+	if n := len(result.DailyEquity); n > 0 {
+		s.StartDate = result.DailyEquity[0].Date
+		s.EndDate = result.DailyEquity[n-1].Date
+	}
+	if result.Performance != nil {
+		s.AnnualizedReturn = result.Performance.AnnualizedReturn
+	}
+	return s
+}
@@ -0,0 +1,165 @@
+// Package runner 按配置文件运行策略
+//
+// 一个 JSON 配置文件完整描述策略（选股器、信号、卖出规则、仓位管理，组件来自 stockStrategy/registry）
+// 和回测（票票池、日期、资金、手续费）。回测命令行、HTTP 接口和每日分析任务使用同一份配置。
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/registry"
+	"time"
+)
+
+// Config 策略和回测配置
+type Config struct {
+	Name     string         `json:"name"`     // 策略名称
+	Strategy StrategyConfig `json:"strategy"` // 策略组成
+	Backtest BacktestConfig `json:"backtest"` // 回测参数
+}
+
+// StrategyConfig 策略组成：preset 指定完整策略，或由 selector + signal (+ exit) 组合
+// sizer 两种方式都可以指定
+type StrategyConfig struct {
+	Preset   *registry.Spec `json:"preset,omitempty"`   // 完整策略
+	Selector *registry.Spec `json:"selector,omitempty"` // 选股器，默认选择K线数量足够计算信号的票票
+	Signal   *registry.Spec `json:"signal,omitempty"`   // 信号生成器
+	Exit     *registry.Spec `json:"exit,omitempty"`     // 卖出规则，替换信号生成器自带的卖出
+	Sizer    *registry.Spec `json:"sizer,omitempty"`    // 仓位管理器，默认按 cashPerPosition 固定比例
+	Regime   bool           `json:"regime,omitempty"`   // 是否按大盘市场状态过滤（同策略4）
+}
+
+// BacktestConfig 回测参数
+type BacktestConfig struct {
+	Universe        UniverseConfig `json:"universe"`        // 票票池
+	StartDate       string         `json:"startDate"`       // 开始日期（2006-01-02，空表示不限制）
+	EndDate         string         `json:"endDate"`         // 结束日期（包含）
+	InitialCash     float64        `json:"initialCash"`     // 初始资金
+	MaxPositions    int            `json:"maxPositions"`    // 最大持仓数
+	CashPerPosition float64        `json:"cashPerPosition"` // 每仓位资金比例（0-1）
+	Benchmark       string         `json:"benchmark"`       // 基准指数代码，空表示不计算相对基准的绩效
+	Fees            FeeConfig      `json:"fees"`            // 手续费
+}
+
+// UniverseConfig 票票池：codes 和 sampleSeed 都不指定时使用 stockList.csv 中的全部票票
+type UniverseConfig struct {
+	Codes      []string `json:"codes,omitempty"`      // 指定票票
+	SampleSeed uint64   `json:"sampleSeed,omitempty"` // 按种子随机抽样（与 stockData.SampleStockList 一致）
+}
+
+// FeeConfig 手续费
+type FeeConfig struct {
+	CommissionRate  float64 `json:"commissionRate"`  // 佣金费率（买入和卖出都收取）
+	StampTaxRate    float64 `json:"stampTaxRate"`    // 印花税率（仅卖出时收取）
+	TransferFeeRate float64 `json:"transferFeeRate"` // 过户费率（买入和卖出都收取）
+	MinCommission   float64 `json:"minCommission"`   // 单笔最低佣金
+}
+
+// DefaultConfig 默认配置：追高杀跌策略，最多2只持仓、每仓50%，A股标准费率
+func DefaultConfig() *Config {
+	return &Config{
+		Name: "默认策略",
+		Strategy: StrategyConfig{
+			Preset: &registry.Spec{Type: "mode", Params: map[string]any{"mode": stockStrategy.Strategy_Mode_1}},
+		},
+		Backtest: BacktestConfig{
+			InitialCash:     1000000,
+			MaxPositions:    2,
+			CashPerPosition: 0.5,
+			Fees: FeeConfig{
+				CommissionRate:  0.0001,
+				StampTaxRate:    0.0005,
+				TransferFeeRate: 0.00001,
+				MinCommission:   5.0,
+			},
+		},
+	}
+}
+
+// LoadConfig 读取并校验配置文件
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("配置文件 %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig 解析并校验配置，未指定的字段使用 DefaultConfig 的值
+// 配置中出现未知字段时报错，避免拼写错误被静默忽略
+func ParseConfig(data []byte) (*Config, error) {
+	cfg := DefaultConfig()
+	var probe struct {
+		Strategy json.RawMessage `json:"strategy"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+	// 配置了策略时不沿用默认策略的组成
+	if len(probe.Strategy) > 0 {
+		cfg.Strategy = StrategyConfig{}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate 校验配置：策略组件存在且参数合法，回测参数在有效范围内
+func (c *Config) Validate() error {
+	if _, err := c.BuildStrategy(); err != nil {
+		return err
+	}
+	if _, err := c.BuildSizer(); err != nil {
+		return err
+	}
+	return c.Backtest.validate()
+}
+
+// validate 校验回测参数
+func (b *BacktestConfig) validate() error {
+	if b.InitialCash <= 0 {
+		return fmt.Errorf("backtest.initialCash 应大于0，实际为 %v", b.InitialCash)
+	}
+	if b.MaxPositions < 1 {
+		return fmt.Errorf("backtest.maxPositions 应至少为1，实际为 %d", b.MaxPositions)
+	}
+	if b.CashPerPosition <= 0 || b.CashPerPosition > 1 {
+		return fmt.Errorf("backtest.cashPerPosition 应在 (0, 1] 之间，实际为 %v", b.CashPerPosition)
+	}
+	for _, date := range []string{b.StartDate, b.EndDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("backtest 日期 %q 格式应为 2006-01-02", date)
+		}
+	}
+	if b.StartDate != "" && b.EndDate != "" && b.StartDate > b.EndDate {
+		return fmt.Errorf("backtest.startDate %s 晚于 endDate %s", b.StartDate, b.EndDate)
+	}
+	if len(b.Universe.Codes) > 0 && b.Universe.SampleSeed != 0 {
+		return fmt.Errorf("backtest.universe 的 codes 和 sampleSeed 只能指定一个")
+	}
+	fees := b.Fees
+	if fees.CommissionRate < 0 || fees.StampTaxRate < 0 || fees.TransferFeeRate < 0 || fees.MinCommission < 0 {
+		return fmt.Errorf("backtest.fees 不能为负数: %+v", fees)
+	}
+	if fees.CommissionRate >= 0.01 || fees.StampTaxRate >= 0.01 || fees.TransferFeeRate >= 0.01 {
+		return fmt.Errorf("backtest.fees 费率应为小数（如万1写作0.0001）: %+v", fees)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package runner
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"stock-go/stockData"
+	"stock-go/stockData/synthetic"
+	"stock-go/stockStrategy"
+	"strings"
+	"testing"
+)
+
+// syntheticStock 构造测试用票票数据（按正弦波动上涨）
+func syntheticStock(code string, days int, rate float64) *stockData.StockInfo {
+	return synthetic.Stock(code, days, func(i int) float64 {
+		return 10 * math.Pow(1+rate, float64(i)) * (1 + 0.1*math.Sin(float64(i)/8))
+	})
+}
+
+// testData 构造测试数据
+func testData() map[string]*stockData.StockInfo {
+	return map[string]*stockData.StockInfo{
+		"sz.000001": syntheticStock("sz.000001", 800, 0.002),
+		"sz.000002": syntheticStock("sz.000002", 800, 0.001),
+		"sh.600000": syntheticStock("sh.600000", 800, -0.001),
+	}
+}
+
+// TestParseConfig 测试默认值、未知字段和校验错误
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"name": "默认", "backtest": {"maxPositions": 3}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Strategy.Preset == nil || cfg.Backtest.MaxPositions != 3 || cfg.Backtest.CashPerPosition != 0.5 || cfg.Backtest.Fees.MinCommission != 5 {
+		t.Errorf("未指定的字段应使用默认值: %+v", cfg)
+	}
+
+	cfg, err = ParseConfig([]byte(`{"strategy": {"signal": {"type": "breakout"}}, "backtest": {"fees": {"stampTaxRate": 0.001}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Strategy.Preset != nil || cfg.Backtest.Fees.StampTaxRate != 0.001 || cfg.Backtest.Fees.CommissionRate != 0.0001 {
+		t.Errorf("配置了策略时不应沿用默认策略，未指定的费率应使用默认值: %+v", cfg)
+	}
+
+	cases := []struct{ src, want string }{
+		{`{"backtest": {"initalCash": 1}}`, `unknown field "initalCash"`},
+		{`{"strategy": {}}`, "需要指定 preset 或 signal"},
+		{`{"strategy": {"preset": {"type": "momentumRank"}, "signal": {"type": "breakout"}}}`, "不能与 selector、signal、exit 同时指定"},
+		{`{"strategy": {"preset": {"type": "momentumRank"}, "regime": true}}`, "regime 不支持横截面策略"},
+		{`{"strategy": {"signal": {"type": "breakout"}, "exit": {"type": "expr", "params": {"expression": "profit >"}}}}`, "表达式第"},
+		{`{"strategy": {"signal": {"type": "breakout"}, "sizer": {"type": "kelly", "params": {"minTrades": -1}}}}`, "sizer \"kelly\": 参数 minTrades"},
+		{`{"backtest": {"startDate": "2020-13-01"}}`, "格式应为 2006-01-02"},
+		{`{"backtest": {"startDate": "2021-01-01", "endDate": "2020-01-01"}}`, "晚于"},
+		{`{"backtest": {"universe": {"codes": ["sz.000001"], "sampleSeed": 1}}}`, "只能指定一个"},
+		{`{"backtest": {"fees": {"commissionRate": 3}}}`, "费率应为小数"},
+		{`{"backtest": {"cashPerPosition": 0}}`, "cashPerPosition"},
+	}
+	for _, c := range cases {
+		if _, err := ParseConfig([]byte(c.src)); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: 错误 %v，期望包含 %q", c.src, err, c.want)
+		}
+	}
+}
+
+// TestExampleConfigs 测试 conf 目录下的示例配置都能通过校验
+func TestExampleConfigs(t *testing.T) {
+	paths, _ := filepath.Glob("../../conf/*.json")
+	if len(paths) == 0 {
+		t.Skip("没有示例配置")
+	}
+	for _, path := range paths {
+		if _, err := LoadConfig(path); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// TestRunConfig 测试按配置回测：与直接构造的引擎使用相同的组件和手续费
+func TestRunConfig(t *testing.T) {
+	data := testData()
+	src := `{
+		"name": "测试配置",
+		"strategy": {
+			"signal": {"type": "expr", "params": {"entry": "close > ref(close, 1) and close > ma(close, 20)"}},
+			"exit": {"type": "rule", "params": {"takeProfit": 0.1, "stopLoss": 0.05, "maxHoldDays": 20}},
+			"sizer": {"type": "fixedFraction", "params": {"fraction": 0.3}}
+		},
+		"backtest": {"startDate": "2018-06-01", "initialCash": 500000, "maxPositions": 3,
+			"fees": {"commissionRate": 0.0003, "stampTaxRate": 0.001, "transferFeeRate": 0, "minCommission": 0}}
+	}`
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := cfg.Run(data, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalTrades == 0 || result.InitialCash != 500000 {
+		t.Fatalf("回测没有交易: 初始资金 %.0f", result.InitialCash)
+	}
+	for _, record := range result.TradeRecords {
+		if record.Date < "2018-06-01" {
+			t.Fatalf("开始日期之前不应交易: %+v", record)
+		}
+	}
+	if result.TotalFees <= 0 {
+		t.Error("应按配置的费率收取手续费")
+	}
+
+	again, _ := cfg.Run(data, true)
+	if again.FinalAssets != result.FinalAssets || again.TotalTrades != result.TotalTrades {
+		t.Error("同一份配置的回测结果应一致")
+	}
+
+	summary := cfg.Summarize(result)
+	if summary.Name != "测试配置" || summary.Strategy == "" || summary.StartDate < "2018-06-01" || summary.TotalTrades != result.TotalTrades {
+		t.Errorf("摘要 %+v 不正确", summary)
+	}
+}
+
+// TestComposedSignalGenerators 测试组合策略每次返回独立的信号生成器
+func TestComposedSignalGenerators(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"strategy": {"signal": {"type": "breakout"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	strategy, err := cfg.BuildStrategy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory := strategy.(stockStrategy.SignalGeneratorFactory)
+	a, b := factory.NewSignalGenerator("sz.000001"), factory.NewSignalGenerator("sz.000002")
+	if a == nil || b == nil || a == b {
+		t.Errorf("每只票票应使用独立的信号生成器: %p %p", a, b)
+	}
+}
+
+// TestRunIsolatedRequiresUniverse 测试不读全局缓存的回测要求指定票票池
+func TestRunIsolatedRequiresUniverse(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{"strategy": {"signal": {"type": "breakout"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.RunIsolated(true); err == nil {
+		t.Error("未指定 codes 或 sampleSeed 时应返回错误")
+	}
+}
+
+// TestScan 测试每日分析：只报告最新交易日出现买入信号的票票，停牌（最新K线不是最新交易日）的跳过
+func TestScan(t *testing.T) {
+	data := testData()
+	stale := data["sz.000002"]
+	stale.Datas.DayDatas = stale.Datas.DayDatas[:len(stale.Datas.DayDatas)-1]
+
+	cfg, err := ParseConfig([]byte(`{"strategy": {"signal": {"type": "expr", "params": {"entry": "close > ref(close, 1)"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidates, err := cfg.Scan(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := make([]string, 0)
+	for _, code := range []string{"sh.600000", "sz.000001"} {
+		bars := data[code].Datas.DayDatas
+		if bars[len(bars)-1].PriceEnd > bars[len(bars)-2].PriceEnd {
+			want = append(want, code)
+		}
+	}
+	got := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		got = append(got, c.Code)
+		if c.Date != "2019-01-25" || c.Name != c.Code {
+			t.Errorf("候选 %+v 不正确", c)
+		}
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("候选 %v，期望 %v", got, want)
+	}
+
+	// 横截面策略：按动量给出目标持仓
+	cfg, err = ParseConfig([]byte(`{"strategy": {"preset": {"type": "momentumRank", "params": {"lookbackDays": 60, "topN": 1}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidates, err = cfg.Scan(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].Weight != 1 {
+		t.Fatalf("动量排名候选 %+v，期望1只", candidates)
+	}
+}
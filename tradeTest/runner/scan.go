@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"sort"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+)
+
+// Candidate 每日分析发现的买入信号
+type Candidate struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Date   string  `json:"date"`
+	Price  float64 `json:"price"`            // 最新收盘价
+	Weight float64 `json:"weight,omitempty"` // 横截面策略的目标权重
+}
+
+// Scan 用配置的策略分析票票的最新K线，返回最新交易日出现买入信号的票票（按代码排序）
+// 只分析最新K线是最新交易日的票票（停牌的跳过）；信号生成器先用最近的历史窗口预热，
+// 与回测中空仓时的买入判断一致。横截面策略按最新交易日调仓的目标持仓给出候选
+func (c *Config) Scan(data map[string]*stockData.StockInfo) ([]Candidate, error) {
+	strategy, err := c.BuildStrategy()
+	if err != nil {
+		return nil, err
+	}
+	stockStrategy.InjectStockData(strategy, data)
+
+	latest := ""
+	for _, info := range data {
+		if n := len(info.Datas.DayDatas); n > 0 && info.Datas.DayDatas[n-1].DataStr > latest {
+			latest = info.Datas.DayDatas[n-1].DataStr
+		}
+	}
+	codes := make([]string, 0, len(data))
+	for code, info := range data {
+		if n := len(info.Datas.DayDatas); n > 0 && info.Datas.DayDatas[n-1].DataStr == latest {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	selected := make([]string, 0, len(codes))
+	for _, code := range codes {
+		last := len(data[code].Datas.DayDatas) - 1
+		selected = append(selected, strategy.GetSelector().SelectStocksAtDate([]string{code}, last)...)
+	}
+
+	if portfolio, ok := strategy.(stockStrategy.PortfolioStrategy); ok {
+		return c.scanPortfolio(portfolio, data, selected, latest), nil
+	}
+
+	candidates := make([]Candidate, 0)
+	for _, code := range selected {
+		if scanSignal(strategy, code, data[code].Datas.DayDatas) {
+			candidates = append(candidates, newCandidate(code, data[code], 0))
+		}
+	}
+	return candidates, nil
+}
+
+// scanSignal 用最近的历史窗口预热信号生成器，判断最新K线是否出现买入信号
+func scanSignal(strategy stockStrategy.Strategy, code string, bars []*stockData.StockDataDay) bool {
+	var gen stockStrategy.SignalGenerator
+	if factory, ok := strategy.(stockStrategy.SignalGeneratorFactory); ok {
+		gen = factory.NewSignalGenerator(code)
+	} else {
+		gen = strategy.GetSignalGenerator()
+	}
+	if gen == nil {
+		return false
+	}
+	gen.Reset()
+
+	window := stockStrategy.HistoryWindow(gen)
+	last := len(bars) - 1
+	signal := 0
+	for i := max(last-window, 0); i <= last; i++ {
+		signal = gen.ProcessDay(stockStrategy.NewBarContext(code, bars, i, window), nil)
+	}
+	return signal == 1
+}
+
+// scanPortfolio 横截面策略：以空仓、初始资金计算最新交易日的目标持仓
+func (c *Config) scanPortfolio(strategy stockStrategy.PortfolioStrategy, data map[string]*stockData.StockInfo, codes []string, date string) []Candidate {
+	window := stockStrategy.DefaultHistoryWindow
+	if provider, ok := strategy.(stockStrategy.HistoryWindowProvider); ok {
+		window = provider.GetHistoryWindow()
+	}
+	ctx := &stockStrategy.PortfolioContext{
+		Date:        date,
+		Bars:        make(map[string]*stockStrategy.BarContext, len(codes)),
+		Holdings:    make(map[string]stockStrategy.Holding),
+		Cash:        c.Backtest.InitialCash,
+		TotalAssets: c.Backtest.InitialCash,
+	}
+	for _, code := range codes {
+		bars := data[code].Datas.DayDatas
+		ctx.Bars[code] = stockStrategy.NewBarContext(code, bars, len(bars)-1, window)
+	}
+
+	candidates := make([]Candidate, 0)
+	for _, target := range strategy.Rebalance(ctx) {
+		if info := data[target.Code]; info != nil && target.Weight > 0 {
+			candidates = append(candidates, newCandidate(target.Code, info, target.Weight))
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Code < candidates[j].Code })
+	return candidates
+}
+
+// newCandidate 以最新K线创建候选
+func newCandidate(code string, info *stockData.StockInfo, weight float64) Candidate {
+	bar := info.Datas.DayDatas[len(info.Datas.DayDatas)-1]
+	name := info.Name
+	if name == "" {
+		name = stockData.StockList[code]
+	}
+	return Candidate{
+		Code:   code,
+		Name:   name,
+		Date:   bar.DataStr,
+		Price:  float64(bar.PriceEnd),
+		Weight: weight,
+	}
+}
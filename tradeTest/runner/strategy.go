@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"fmt"
+	"stock-go/logger"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/combine"
+	"stock-go/stockStrategy/regime"
+	"stock-go/stockStrategy/registry"
+	"stock-go/stockStrategy/selectors"
+	"stock-go/stockStrategy/strategies"
+)
+
+// BuildStrategy 按配置创建策略
+// 组合方式下每只票票的信号生成器都由注册表重新创建，状态互不影响
+func (c *Config) BuildStrategy() (stockStrategy.Strategy, error) {
+	sc := c.Strategy
+	var strategy stockStrategy.Strategy
+	switch {
+	case sc.Preset != nil:
+		if sc.Selector != nil || sc.Signal != nil || sc.Exit != nil {
+			return nil, fmt.Errorf("strategy.preset 不能与 selector、signal、exit 同时指定")
+		}
+		preset, err := registry.BuildStrategy(*sc.Preset)
+		if err != nil {
+			return nil, err
+		}
+		strategy = preset
+	case sc.Signal != nil:
+		composed, err := c.buildComposed()
+		if err != nil {
+			return nil, err
+		}
+		strategy = composed
+	default:
+		return nil, fmt.Errorf("strategy 需要指定 preset 或 signal")
+	}
+
+	if sc.Regime {
+		if _, ok := strategy.(stockStrategy.PortfolioStrategy); ok {
+			return nil, fmt.Errorf("strategy.regime 不支持横截面策略 %s", strategy.GetName())
+		}
+		strategy = strategies.NewMarketRegimeStrategy(strategy, regime.NewDefaultFilter())
+	}
+	return strategy, nil
+}
+
+// buildComposed 由选股器、信号生成器和卖出规则组合策略
+func (c *Config) buildComposed() (*strategies.ComposedStrategy, error) {
+	sc := c.Strategy
+	// 先创建一次，校验参数和表达式
+	signal, err := newSignal(sc.Signal, sc.Exit)
+	if err != nil {
+		return nil, err
+	}
+
+	var selector stockStrategy.StockSelector = selectors.NewListedDaysSelector(stockStrategy.HistoryWindow(signal))
+	if sc.Selector != nil {
+		if selector, err = registry.BuildSelector(*sc.Selector); err != nil {
+			return nil, err
+		}
+	}
+
+	name := c.Name
+	if name == "" {
+		name = "配置策略"
+	}
+	// 校验时创建的信号生成器给第一次调用使用，之后每次按同一份配置重新创建
+	// 创建失败时返回nil，回测引擎不为该票票生成信号
+	validated := signal
+	return strategies.NewComposedStrategy(name, selector, func() stockStrategy.SignalGenerator {
+		if sg := validated; sg != nil {
+			validated = nil
+			return sg
+		}
+		sg, err := newSignal(sc.Signal, sc.Exit)
+		if err != nil {
+			logger.Errorf("创建信号生成器失败: %v", err)
+			return nil
+		}
+		return sg
+	}), nil
+}
+
+// newSignal 创建信号生成器，指定了卖出规则时用卖出规则替换信号自带的卖出
+func newSignal(signalSpec, exitSpec *registry.Spec) (stockStrategy.SignalGenerator, error) {
+	signal, err := registry.BuildSignal(*signalSpec)
+	if err != nil {
+		return nil, err
+	}
+	if exitSpec == nil {
+		return signal, nil
+	}
+	exit, err := registry.BuildExit(*exitSpec)
+	if err != nil {
+		return nil, err
+	}
+	return combine.EntryExit(signal, exit), nil
+}
+
+// BuildSizer 按配置创建仓位管理器，未配置时返回nil（使用回测引擎默认的固定比例仓位）
+func (c *Config) BuildSizer() (stockStrategy.PositionSizer, error) {
+	if c.Strategy.Sizer == nil {
+		return nil, nil
+	}
+	return registry.BuildSizer(*c.Strategy.Sizer)
+}
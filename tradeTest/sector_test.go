@@ -0,0 +1,93 @@
+package tradeTest
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockStrategy/sector"
+	"stock-go/stockStrategy/signals"
+	"stock-go/stockStrategy/strategies"
+	"testing"
+)
+
+// sectorTestData 银行持续小幅上涨，地产前600天下跌、之后快速上涨；每个行业一只龙头、一只跟随
+func sectorTestData() (map[string]*stockData.StockInfo, *sector.Model) {
+	strong := risingPrices(700, 10, 0.002)
+	weak := risingPrices(700, 10, 0.0005)
+	late := append(risingPrices(600, 20, -0.001), risingPrices(100, 20*0.5488, 0.006)...)
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "银行龙头", strong),
+		"sz.000002": newSyntheticStock("sz.000002", "银行跟随", weak),
+		"sh.600001": newSyntheticStock("sh.600001", "地产龙头", late),
+		"sh.600002": newSyntheticStock("sh.600002", "地产跟随", risingPrices(700, 10, -0.0005)),
+	}
+	model := sector.NewModel(map[string]string{
+		"sz.000001": "银行", "sz.000002": "银行",
+		"sh.600001": "地产", "sh.600002": "地产",
+	}).SetStockData(data)
+	model.MinMembers = 2
+	return data, model
+}
+
+// TestSectorRotationBacktest 测试板块轮动在调仓日从走弱的行业换到走强的行业
+func TestSectorRotationBacktest(t *testing.T) {
+	data, model := sectorTestData()
+	strategy := strategies.NewSectorRotationStrategyWithParams(model, 1, 1, 20)
+	engine := NewTimeBasedBacktestEngine(1000000, strategy, 2, 0.5)
+	engine.SetStockData(data)
+	engine.SetQuiet(true)
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	var actions []string
+	for _, record := range result.TradeRecords {
+		actions = append(actions, record.Action+" "+record.Code+" "+record.Date)
+		if !model.IsRebalanceDay(record.Date, 20) && record.Reason == "调仓卖出" {
+			t.Errorf("%s 不是调仓日", record.Date)
+		}
+	}
+	if len(result.TradeRecords) < 3 {
+		t.Fatalf("交易记录 %v 不足", actions)
+	}
+	first, sell, second := result.TradeRecords[0], result.TradeRecords[1], result.TradeRecords[2]
+	if first.Action != "buy" || first.Code != "sz.000001" {
+		t.Errorf("应先买入银行龙头: %v", actions)
+	}
+	if sell.Action != "sell" || sell.Code != "sz.000001" || sell.Reason != "调仓卖出" {
+		t.Errorf("应调仓卖出银行龙头: %+v", sell)
+	}
+	if second.Action != "buy" || second.Code != "sh.600001" || second.Date != sell.Date {
+		t.Errorf("应在同一调仓日买入地产龙头: %v", actions)
+	}
+}
+
+// TestSectorBreakoutBacktest 测试板块突破只买入创新高行业的龙头
+func TestSectorBreakoutBacktest(t *testing.T) {
+	data, model := sectorTestData()
+	strategy := strategies.NewSectorBreakoutStrategyWithParams(model, 20, 20, 1, signals.NewDefaultExitRule())
+	engine := NewTimeBasedBacktestEngine(1000000, strategy, 2, 0.5)
+	engine.SetStockData(data)
+	engine.SetQuiet(true)
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	buys := 0
+	for _, record := range result.TradeRecords {
+		if record.Action != "buy" {
+			continue
+		}
+		buys++
+		industry := model.IndustryOf(record.Code)
+		if !model.IsBreakout(industry, record.Date, 20) {
+			t.Errorf("%s %s 买入时行业没有创新高", record.Date, record.Code)
+		}
+		if leaders := model.Leaders(industry, record.Date, 20, 1); len(leaders) != 1 || leaders[0] != record.Code {
+			t.Errorf("%s %s 买入时不是行业龙头 %v", record.Date, record.Code, leaders)
+		}
+	}
+	if buys == 0 {
+		t.Error("没有买入")
+	}
+}
@@ -0,0 +1,58 @@
+package tradeTest
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockStrategy/strategies"
+	"strings"
+	"testing"
+)
+
+// oscillatingPrices 每天上涨 step、并叠加±0.1交替波动的价格序列
+func oscillatingPrices(days int, start, step float64) []float64 {
+	prices := make([]float64, days)
+	for i := range prices {
+		noise := 0.1
+		if i%2 == 1 {
+			noise = -0.1
+		}
+		prices[i] = start + step*float64(i) + noise
+	}
+	return prices
+}
+
+// TestGenericSignalSell 测试非追涨杀跌信号生成器通过 ProcessDay 卖出，且每只票票的信号状态独立
+func TestGenericSignalSell(t *testing.T) {
+	// 震荡上涨580天，第580天向上突破，之后每天上涨1%
+	// 横盘票票的价格更高，如果两只票票共用信号状态会干扰突破判断
+	prices := oscillatingPrices(580, 10, 0.02)
+	prices = append(prices, risingPrices(30, 22.5, 0.01)...)
+	data := map[string]*stockData.StockInfo{
+		"sz.000001": newSyntheticStock("sz.000001", "突破", prices),
+		"sz.000002": newSyntheticStock("sz.000002", "横盘", oscillatingPrices(610, 30, 0)),
+	}
+
+	engine := NewTimeBasedBacktestEngine(1000000, strategies.NewBreakoutStrategy(), 2, 0.5)
+	engine.SetStockData(data)
+	engine.SetQuiet(true)
+	result := engine.Run()
+	if result == nil {
+		t.Fatal("回测结果为空")
+	}
+
+	buy := findFirstTrade(result, "buy")
+	if buy == nil {
+		t.Fatal("没有买入")
+	}
+	breakoutDate := data["sz.000001"].Datas.DayDatas[580].DataStr
+	if buy.Code != "sz.000001" || buy.Date != breakoutDate {
+		t.Fatalf("买入 %s %s，期望 sz.000001 %s", buy.Code, buy.Date, breakoutDate)
+	}
+
+	sell := findFirstTrade(result, "sell")
+	if sell == nil || !strings.HasPrefix(sell.Reason, "止盈") {
+		t.Fatalf("卖出记录 %+v，期望止盈卖出", sell)
+	}
+	if sell.Date != data["sz.000001"].Datas.DayDatas[595].DataStr {
+		t.Errorf("卖出日期 %s，期望第595天", sell.Date)
+	}
+}
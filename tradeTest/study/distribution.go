@@ -0,0 +1,88 @@
+package study
+
+import (
+	"math"
+	"sort"
+	"stock-go/tradeTest/robustness"
+)
+
+// HistogramBin 直方图的一个区间 [Lower, Upper)
+type HistogramBin struct {
+	Lower float64
+	Upper float64
+	Count int
+}
+
+// Distribution 多次抽样结果的分布
+type Distribution struct {
+	Count     int
+	Mean      float64
+	Median    float64
+	StdDev    float64
+	Min       float64
+	Max       float64
+	P5        float64
+	P25       float64
+	P75       float64
+	P95       float64
+	Histogram []HistogramBin
+}
+
+// NewDistribution 计算分布统计和等宽直方图
+func NewDistribution(values []float64, bins int) Distribution {
+	dist := Distribution{Count: len(values)}
+	if len(values) == 0 {
+		return dist
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	for _, v := range sorted {
+		dist.Mean += v
+	}
+	dist.Mean /= float64(len(sorted))
+	for _, v := range sorted {
+		dist.StdDev += (v - dist.Mean) * (v - dist.Mean)
+	}
+	if len(sorted) > 1 {
+		dist.StdDev = math.Sqrt(dist.StdDev / float64(len(sorted)-1))
+	}
+
+	dist.Min = sorted[0]
+	dist.Max = sorted[len(sorted)-1]
+	dist.Median = robustness.Percentile(sorted, 0.5)
+	dist.P5 = robustness.Percentile(sorted, 0.05)
+	dist.P25 = robustness.Percentile(sorted, 0.25)
+	dist.P75 = robustness.Percentile(sorted, 0.75)
+	dist.P95 = robustness.Percentile(sorted, 0.95)
+	dist.Histogram = histogram(sorted, bins)
+	return dist
+}
+
+// histogram 等宽直方图，最大值计入最后一个区间
+func histogram(sorted []float64, bins int) []HistogramBin {
+	if bins <= 0 {
+		bins = 10
+	}
+
+	min, max := sorted[0], sorted[len(sorted)-1]
+	if max == min {
+		return []HistogramBin{{Lower: min, Upper: max, Count: len(sorted)}}
+	}
+
+	width := (max - min) / float64(bins)
+	result := make([]HistogramBin, bins)
+	for i := range result {
+		result[i].Lower = min + float64(i)*width
+		result[i].Upper = min + float64(i+1)*width
+	}
+	for _, v := range sorted {
+		i := int((v - min) / width)
+		if i >= bins {
+			i = bins - 1
+		}
+		result[i].Count++
+	}
+	return result
+}
@@ -0,0 +1,193 @@
+package study
+
+import (
+	"runtime"
+	"sort"
+	"stock-go/logger"
+	"stock-go/stockData"
+	"stock-go/stockStrategy"
+	"stock-go/tradeTest"
+	"stock-go/tradeTest/optimizer"
+	"strings"
+	"sync"
+)
+
+// SampleResult 一次抽样的回测结果
+type SampleResult struct {
+	Seed       uint64
+	StockCount int                                // 抽样的票票数量（有足够数据的）
+	Result     *tradeTest.TimeBasedBacktestResult // 回测结果（回测失败时为nil）
+}
+
+// StudyResult 多次抽样的汇总
+type StudyResult struct {
+	Samples     []SampleResult // 按种子排序
+	Return      Distribution   // 总收益率（百分比）
+	MaxDrawdown Distribution   // 最大回撤（百分比）
+	WinRate     Distribution   // 胜率（百分比）
+	Sharpe      Distribution   // 夏普比率
+}
+
+// Study 多种子抽样研究
+// 用 N 个种子分别随机抽取票票池，对同一策略各回测一次，用结果的分布而不是单次结果评价策略
+type Study struct {
+	Seeds           []uint64                                          // 抽样种子
+	Factory         func() stockStrategy.Strategy                     // 策略工厂（每次回测创建新实例）
+	InitialCash     float64                                           // 初始资金
+	MaxPositions    int                                               // 最大持仓数
+	CashPerPosition float64                                           // 每仓位资金比例
+	Workers         int                                               // 并发协程数，1表示顺序执行
+	Bins            int                                               // 直方图区间数
+	Options         []func(engine *tradeTest.TimeBasedBacktestEngine) // 引擎额外设置
+
+	Sampler func(seed uint64) map[string]string                  // 票票池抽样，默认 stockData.SampleStockList
+	Loader  func(codes []string) map[string]*stockData.StockInfo // 数据加载，默认 optimizer.LoadStockData
+}
+
+// NewStudy 创建抽样研究，种子为 1..runs
+func NewStudy(factory func() stockStrategy.Strategy, runs int) *Study {
+	seeds := make([]uint64, runs)
+	for i := range seeds {
+		seeds[i] = uint64(i + 1)
+	}
+	return &Study{
+		Seeds:           seeds,
+		Factory:         factory,
+		InitialCash:     1000000,
+		MaxPositions:    4,
+		CashPerPosition: 1.0,
+		Workers:         runtime.NumCPU(),
+		Bins:            10,
+		Sampler:         stockData.SampleStockList,
+		Loader:          optimizer.LoadStockData,
+	}
+}
+
+// Run 执行所有抽样回测并汇总分布
+func (s *Study) Run() *StudyResult {
+	// 1. 抽样并顺序加载所有需要的数据（全局数据缓存不支持并发写入）
+	universes := make([][]string, len(s.Seeds))
+	codeSet := make(map[string]bool)
+	for i, seed := range s.Seeds {
+		sample := s.Sampler(seed)
+		codes := make([]string, 0, len(sample))
+		for code := range sample {
+			codes = append(codes, code)
+			codeSet[code] = true
+		}
+		sort.Strings(codes)
+		universes[i] = codes
+	}
+	allCodes := make([]string, 0, len(codeSet))
+	for code := range codeSet {
+		allCodes = append(allCodes, code)
+	}
+	sort.Strings(allCodes)
+	data := s.Loader(allCodes)
+	logger.Infof("抽样研究: %d 个种子, 共加载 %d 只票票", len(s.Seeds), len(data))
+
+	// 2. 并发回测，各次回测共享只读数据
+	samples := make([]SampleResult, len(s.Seeds))
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				samples[i] = s.runOne(s.Seeds[i], universes[i], data)
+				if r := samples[i].Result; r != nil {
+					logger.Infof("种子 %d: %d 只票票 收益 %.2f%% 回撤 %.2f%% 胜率 %.2f%%",
+						s.Seeds[i], samples[i].StockCount, r.TotalReturnPct, r.MaxDrawdown, r.WinRate)
+				}
+			}
+		}()
+	}
+	for i := range s.Seeds {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return s.summarize(samples)
+}
+
+// runOne 用一个抽样票票池回测
+func (s *Study) runOne(seed uint64, codes []string, data map[string]*stockData.StockInfo) SampleResult {
+	universe := make(map[string]*stockData.StockInfo, len(codes))
+	for _, code := range codes {
+		if info, ok := data[code]; ok {
+			universe[code] = info
+		}
+	}
+
+	engine := tradeTest.NewTimeBasedBacktestEngine(s.InitialCash, s.Factory(), s.MaxPositions, s.CashPerPosition)
+	engine.SetStockData(universe)
+	engine.SetQuiet(true)
+	for _, option := range s.Options {
+		option(engine)
+	}
+
+	return SampleResult{
+		Seed:       seed,
+		StockCount: len(universe),
+		Result:     engine.Run(),
+	}
+}
+
+// summarize 汇总各次回测的分布
+func (s *Study) summarize(samples []SampleResult) *StudyResult {
+	returns := make([]float64, 0, len(samples))
+	drawdowns := make([]float64, 0, len(samples))
+	winRates := make([]float64, 0, len(samples))
+	sharpes := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		if sample.Result == nil {
+			continue
+		}
+		returns = append(returns, sample.Result.TotalReturnPct)
+		drawdowns = append(drawdowns, sample.Result.MaxDrawdown)
+		winRates = append(winRates, sample.Result.WinRate)
+		sharpes = append(sharpes, sample.Result.SharpeRatio)
+	}
+
+	return &StudyResult{
+		Samples:     samples,
+		Return:      NewDistribution(returns, s.Bins),
+		MaxDrawdown: NewDistribution(drawdowns, s.Bins),
+		WinRate:     NewDistribution(winRates, s.Bins),
+		Sharpe:      NewDistribution(sharpes, s.Bins),
+	}
+}
+
+// PrintStudy 打印抽样研究的分布和直方图
+func PrintStudy(result *StudyResult) {
+	logger.Infof("========================================")
+	logger.Infof("多种子抽样研究（%d 次）", len(result.Samples))
+	logger.Infof("========================================")
+	printDistribution("总收益率(%)", result.Return)
+	printDistribution("最大回撤(%)", result.MaxDrawdown)
+	printDistribution("胜率(%)", result.WinRate)
+	printDistribution("夏普比率", result.Sharpe)
+	logger.Infof("========================================")
+}
+
+// printDistribution 打印一个指标的分布
+func printDistribution(name string, dist Distribution) {
+	if dist.Count == 0 {
+		return
+	}
+
+	logger.Infof("%s: 均值 %.2f 中位数 %.2f 标准差 %.2f", name, dist.Mean, dist.Median, dist.StdDev)
+	logger.Infof("  最小 %.2f | P5 %.2f | P25 %.2f | P75 %.2f | P95 %.2f | 最大 %.2f",
+		dist.Min, dist.P5, dist.P25, dist.P75, dist.P95, dist.Max)
+	for _, bin := range dist.Histogram {
+		logger.Infof("  [%8.2f, %8.2f) %3d %s", bin.Lower, bin.Upper, bin.Count, strings.Repeat("#", bin.Count))
+	}
+	logger.Infof("  (共 %d 次)", dist.Count)
+}
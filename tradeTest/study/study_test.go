@@ -0,0 +1,95 @@
+package study
+
+import (
+	"fmt"
+	"math"
+	"stock-go/stockData"
+	"stock-go/stockData/synthetic"
+	"stock-go/stockStrategy"
+	"stock-go/stockStrategy/strategies"
+	"testing"
+)
+
+// syntheticStock 构造测试用票票数据（每天按固定比例上涨）
+func syntheticStock(code string, days int, rate float64) *stockData.StockInfo {
+	return synthetic.Stock(code, days, func(i int) float64 { return 10 * math.Pow(1+rate, float64(i+1)) })
+}
+
+// TestNewDistribution 测试分布统计和直方图
+func TestNewDistribution(t *testing.T) {
+	values := []float64{5, 1, 4, 2, 3, 10}
+	dist := NewDistribution(values, 3)
+
+	if dist.Count != 6 || dist.Min != 1 || dist.Max != 10 {
+		t.Fatalf("分布 %+v 不正确", dist)
+	}
+	if math.Abs(dist.Mean-25.0/6) > 1e-9 || dist.Median != 3.5 {
+		t.Errorf("均值 %.4f 中位数 %.4f 不正确", dist.Mean, dist.Median)
+	}
+	if dist.P25 > dist.Median || dist.Median > dist.P75 || dist.P5 < dist.Min || dist.P95 > dist.Max {
+		t.Errorf("分位数 %+v 不正确", dist)
+	}
+
+	total := 0
+	for _, bin := range dist.Histogram {
+		total += bin.Count
+	}
+	if len(dist.Histogram) != 3 || total != 6 || dist.Histogram[0].Count != 3 || dist.Histogram[1].Count != 2 || dist.Histogram[2].Count != 1 {
+		t.Errorf("直方图 %+v 不正确", dist.Histogram)
+	}
+}
+
+// TestStudyRun 测试不同种子使用不同票票池，并发结果与顺序一致
+func TestStudyRun(t *testing.T) {
+	// 4只票票涨速不同，每个种子选其中两只
+	data := make(map[string]*stockData.StockInfo)
+	codes := make([]string, 4)
+	for i := range codes {
+		codes[i] = fmt.Sprintf("sz.00000%d", i+1)
+		data[codes[i]] = syntheticStock(codes[i], 900, 0.001*float64(i+1))
+	}
+	sampler := func(seed uint64) map[string]string {
+		a := codes[seed%4]
+		b := codes[(seed+1)%4]
+		return map[string]string{a: a, b: b}
+	}
+	loaded := 0
+	loader := func(list []string) map[string]*stockData.StockInfo {
+		loaded++
+		result := make(map[string]*stockData.StockInfo)
+		for _, code := range list {
+			result[code] = data[code]
+		}
+		return result
+	}
+	factory := func() stockStrategy.Strategy { return strategies.NewBuyHighSellLowStrategy() }
+
+	run := func(workers int) *StudyResult {
+		s := NewStudy(factory, 4)
+		s.Workers = workers
+		s.Sampler = sampler
+		s.Loader = loader
+		return s.Run()
+	}
+
+	sequential := run(1)
+	concurrent := run(4)
+	if loaded != 2 {
+		t.Errorf("每次研究应只加载一次数据，共加载 %d 次", loaded)
+	}
+
+	if len(concurrent.Samples) != 4 || concurrent.Return.Count != 4 {
+		t.Fatalf("抽样结果数量不正确: %d", len(concurrent.Samples))
+	}
+	for i, sample := range concurrent.Samples {
+		if sample.StockCount != 2 || sample.Result == nil {
+			t.Fatalf("种子 %d 结果不正确: %+v", sample.Seed, sample)
+		}
+		if sample.Result.FinalAssets != sequential.Samples[i].Result.FinalAssets {
+			t.Errorf("种子 %d 并发结果与顺序结果不一致", sample.Seed)
+		}
+	}
+	if concurrent.Return.Min == concurrent.Return.Max {
+		t.Errorf("不同票票池的收益应不同: %+v", concurrent.Return)
+	}
+}
@@ -0,0 +1,24 @@
+package tradeTest
+
+import (
+	"stock-go/stockData"
+	"stock-go/stockData/synthetic"
+)
+
+// newSyntheticStock 根据收盘价序列构造票票数据（用于不依赖本地数据文件的测试）
+func newSyntheticStock(code, name string, prices []float64) *stockData.StockInfo {
+	stock := synthetic.FromPrices(code, prices)
+	stock.Name = name
+	return stock
+}
+
+// risingPrices 生成每天按固定比例上涨的价格序列
+func risingPrices(days int, start, dailyRate float64) []float64 {
+	prices := make([]float64, days)
+	price := start
+	for i := range prices {
+		prices[i] = price
+		price *= 1 + dailyRate
+	}
+	return prices
+}
@@ -18,25 +18,37 @@ package tradeTest
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"stock-go/logger"
 	"stock-go/stockData"
 	"stock-go/stockStrategy"
 	"stock-go/stockStrategy/signals"
+	"stock-go/stockStrategy/sizers"
+	"stock-go/tradeTest/performance"
 )
 
 // TimeBasedBacktestEngine 基于时间流逝的回测引擎
 type TimeBasedBacktestEngine struct {
-	initialCash     float64                // 初始资金
-	strategy        stockStrategy.Strategy // 交易策略
-	maxPositions    int                    // 最大持仓数量
-	cashPerPosition float64                // 每个持仓的资金比例（0-1）
+	initialCash     float64                      // 初始资金
+	strategy        stockStrategy.Strategy       // 交易策略
+	maxPositions    int                          // 最大持仓数量
+	cashPerPosition float64                      // 每个持仓的资金比例（0-1）
+	sizer           stockStrategy.PositionSizer  // 默认仓位管理器（策略未提供时使用）
+	rotation        *RotationPolicy              // 满仓时的换仓策略（nil表示不换仓）
+	pyramid         *PyramidPolicy               // 加仓/分批止盈策略（nil表示不加仓）
+	benchmarkCode   string                       // 基准指数代码（空表示不对比基准）
+	benchmarkData   *stockData.StockInfo         // 外部注入的基准数据（nil表示从注入的票票数据中按代码查找）
+	regimes         stockStrategy.RegimeProvider // 市场状态（nil表示使用策略提供的市场状态）
+	quiet           bool                         // 静默模式：不输出回测参数、进度和总结（参数寻优时使用）
+	startDate       string                       // 开始交易日期（空表示从第一个交易日开始），之前的交易日只用于预热信号
+	endDate         string                       // 结束交易日期（空表示到最后一个交易日），当天收盘后强制平仓
 
 	// 手续费配置
-	commissionRate float64 // 佣金费率（买入和卖出都收取）
-	stampTaxRate   float64 // 印花税率（仅卖出时收取）
+	commissionRate  float64 // 佣金费率（买入和卖出都收取）
+	stampTaxRate    float64 // 印花税率（仅卖出时收取）
 	transferFeeRate float64 // 过户费率（买入和卖出都收取）
-	minCommission  float64 // 最低佣金（单笔交易）
+	minCommission   float64 // 最低佣金（单笔交易）
 
 	// 回测状态
 	currentDate      string                                   // 当前日期
@@ -44,15 +56,29 @@ type TimeBasedBacktestEngine struct {
 	positions        map[string]*PositionState                // 持仓状态（key: 票票代码）
 	signalGenerators map[string]stockStrategy.SignalGenerator // 每只票票的信号生成器
 	buyCooldowns     map[string]int                           // 买入冷却期（key: 票票代码, value: 冷却结束的dayIndex）
+	signalDays       map[string]int                           // 信号生成器最近处理的dayIndex（避免同一天重复处理）
+	portfolioDays    int                                      // 横截面策略已处理的交易日数
 
 	// 回测数据
-	allStockData map[string]*stockData.StockInfo // 所有票票的数据
+	allStockData map[string]*stockData.StockInfo // 所有票票的数据（只读，可在多个引擎间共享）
+	dateIndex    map[string]map[string]int       // 每只票票的日期索引（key: 票票代码 -> 日期 -> DayDatas下标）
 	tradingDays  []string                        // 所有交易日（排序后）
+	presetData   map[string]*stockData.StockInfo // 外部注入的票票数据（nil表示从全局数据加载）
 
 	// 回测结果
-	dailyEquity   []DailyEquity // 每日权益
-	tradeRecords  []TradeRecord // 交易记录
-	totalFees     float64       // 总手续费（佣金+印花税+过户费）
+	dailyEquity  []DailyEquity    // 每日权益
+	tradeRecords []TradeRecord    // 交易记录
+	totalFees    float64          // 总手续费（佣金+印花税+过户费）
+	closedStats  closedTradeStats // 已完成交易统计（供凯利仓位使用）
+	err          error            // 最近一次 Run 没有结果的原因
+}
+
+// closedTradeStats 已完成交易的累计统计
+type closedTradeStats struct {
+	winCount   int
+	lossCount  int
+	sumWinPct  float64
+	sumLossPct float64
 }
 
 // Wallet 钱包
@@ -66,14 +92,21 @@ type Wallet struct {
 type PositionState struct {
 	Code         string                        // 票票代码
 	Name         string                        // 票票名称
-	StockNum     int                           // 持仓数量
-	BuyPrice     float64                       // 买入价格
-	BuyDate      string                        // 买入日期
-	BuyIndex     int                           // 买入时的数据索引
-	HoldDays     int                           // 持有天数
+	StockNum     int                           // 持仓数量（所有批次合计）
+	BuyPrice     float64                       // 买入价格（所有批次的加权平均）
+	BuyDate      string                        // 首次买入日期
+	BuyIndex     int                           // 首次买入时的数据索引
+	HoldDays     int                           // 持有天数（自首次买入）
 	HighestPrice float64                       // 持有期间最高价
 	CurrentPrice float64                       // 当前价格
 	SignalGen    stockStrategy.SignalGenerator // 该持仓的信号生成器
+
+	Lots         []*PositionLot // 持仓批次（按买入先后排序，卖出时先进先出）
+	UnitNum      int            // 加仓单位股数（首次买入数量）
+	LastAddPrice float64        // 最近一次买入（含加仓）的价格
+	ScaleOutDone int            // 已执行的分批止盈档位数
+	RealizedPnL  float64        // 部分卖出累计的已实现盈亏
+	ClosedCost   float64        // 已卖出部分的成本（含买入手续费）
 }
 
 // DailyEquity 每日权益
@@ -87,19 +120,25 @@ type DailyEquity struct {
 
 // TradeRecord 交易记录
 type TradeRecord struct {
-	Code       string  // 票票代码
-	Name       string  // 票票名称
-	Action     string  // 动作：buy/sell
-	Date       string  // 日期
-	Price      float64 // 价格
-	StockNum   int     // 数量
-	Amount     float64 // 金额（不含手续费）
-	Commission float64 // 佣金
-	StampTax   float64 // 印花税（仅卖出）
+	Code        string  // 票票代码
+	Name        string  // 票票名称
+	Action      string  // 动作：buy/sell
+	Date        string  // 日期
+	Price       float64 // 价格
+	StockNum    int     // 数量
+	Amount      float64 // 金额（不含手续费）
+	Commission  float64 // 佣金
+	StampTax    float64 // 印花税（仅卖出）
 	TransferFee float64 // 过户费
-	TotalFee   float64 // 总手续费（佣金+印花税+过户费）
-	Cash       float64 // 交易后现金
-	Reason     string  // 原因（买入信号、止损、止盈等）
+	TotalFee    float64 // 总手续费（佣金+印花税+过户费）
+	Cash        float64 // 交易后现金
+	Reason      string  // 原因（买入信号、止损、止盈等）
+	IsRotation  bool    // 是否为满仓换仓产生的交易
+
+	// 以下字段仅卖出时有效
+	LotFills    []LotFill // 本次卖出对应的买入批次（先进先出）
+	CostBasis   float64   // 卖出部分的成本（买入金额+分摊的买入手续费）
+	RealizedPnL float64   // 卖出部分的已实现盈亏（扣除买卖手续费）
 }
 
 // NewTimeBasedBacktestEngine 创建基于时间流逝的回测引擎
@@ -114,49 +153,97 @@ func NewTimeBasedBacktestEngine(
 		strategy:        strategy,
 		maxPositions:    maxPositions,
 		cashPerPosition: cashPerPosition,
+		sizer:           sizers.NewFixedFractionSizer(cashPerPosition),
 		// 手续费配置（A股标准费率）
-		commissionRate: 0.0001, // 万1佣金
-		stampTaxRate:   0.0005, // 万5印花税（仅卖出）
+		commissionRate:  0.0001,  // 万1佣金
+		stampTaxRate:    0.0005,  // 万5印花税（仅卖出）
 		transferFeeRate: 0.00001, // 10万分之1过户费（买入和卖出都收取）
-		minCommission:  5.0,    // 最低佣金5元
+		minCommission:   5.0,     // 最低佣金5元
 		wallet: &Wallet{
 			Cash:        initialCash,
 			TotalAssets: initialCash,
 		},
 		positions:        make(map[string]*PositionState),
 		signalGenerators: make(map[string]stockStrategy.SignalGenerator),
+		signalDays:       make(map[string]int),
 		buyCooldowns:     make(map[string]int),
 		allStockData:     make(map[string]*stockData.StockInfo),
+		dateIndex:        make(map[string]map[string]int),
 		dailyEquity:      make([]DailyEquity, 0),
 		tradeRecords:     make([]TradeRecord, 0),
 		totalFees:        0,
 	}
 }
 
+// SetSizer 设置默认仓位管理器
+// 策略实现了 stockStrategy.SizedStrategy 且返回非nil时，以策略的仓位管理器为准
+func (e *TimeBasedBacktestEngine) SetSizer(sizer stockStrategy.PositionSizer) {
+	e.sizer = sizer
+}
+
+// SetStockData 注入回测使用的票票数据，不再从全局票票列表加载
+// 引擎只读取注入的数据，同一份数据可以供多个引擎并发回测
+func (e *TimeBasedBacktestEngine) SetStockData(data map[string]*stockData.StockInfo) {
+	e.presetData = data
+}
+
+// SetQuiet 设置静默模式，不输出回测参数、进度和总结
+func (e *TimeBasedBacktestEngine) SetQuiet(quiet bool) {
+	e.quiet = quiet
+}
+
+// SetDateRange 设置回测的交易日期范围（包含首尾，格式2006-01-02，空字符串表示不限制）
+// 开始日期之前的交易日只把数据喂给信号生成器预热，不交易也不记录权益
+func (e *TimeBasedBacktestEngine) SetDateRange(startDate, endDate string) {
+	e.startDate = startDate
+	e.endDate = endDate
+}
+
+// SetFees 设置手续费：佣金费率、印花税率（仅卖出）、过户费率和单笔最低佣金
+func (e *TimeBasedBacktestEngine) SetFees(commissionRate, stampTaxRate, transferFeeRate, minCommission float64) {
+	e.commissionRate = commissionRate
+	e.stampTaxRate = stampTaxRate
+	e.transferFeeRate = transferFeeRate
+	e.minCommission = minCommission
+}
+
 // Run 执行回测
 func (e *TimeBasedBacktestEngine) Run() *TimeBasedBacktestResult {
-	logger.Infof("========================================")
-	logger.Infof("基于时间流逝的回测引擎")
-	logger.Infof("策略: %s", e.strategy.GetName())
-	logger.Infof("初始资金: %.2f", e.initialCash)
-	logger.Infof("最大持仓数: %d", e.maxPositions)
-	logger.Infof("每仓位资金: %.1f%%", e.cashPerPosition*100)
-	logger.Infof("========================================")
+	if !e.quiet {
+		e.printHeader()
+	}
 
 	// 1. 加载所有票票数据
+	e.err = nil
 	if err := e.loadAllStockData(); err != nil {
-		logger.Infof("加载票票数据失败: %v", err)
-		return nil
+		return e.fail(fmt.Errorf("加载票票数据失败: %w", err))
+	}
+	stockStrategy.InjectStockData(e.strategy, e.allStockData)
+	if err := stockStrategy.CheckStockData(e.strategy); err != nil {
+		return e.fail(err)
+	}
+	if err := e.loadBenchmark(); err != nil {
+		return e.fail(err)
 	}
 
 	// 2. 构建交易日列表
 	e.buildTradingDays()
-	logger.Infof("回测时间范围: %s 至 %s (共 %d 个交易日)",
-		e.tradingDays[0], e.tradingDays[len(e.tradingDays)-1], len(e.tradingDays))
+	if len(e.tradingDays) == 0 {
+		return e.fail(fmt.Errorf("没有可用的交易日数据"))
+	}
+	if !e.quiet {
+		logger.Infof("回测时间范围: %s 至 %s (共 %d 个交易日)",
+			e.tradingDays[0], e.tradingDays[len(e.tradingDays)-1], len(e.tradingDays))
+		if e.startDate != "" || e.endDate != "" {
+			logger.Infof("交易日期范围: %s 至 %s", e.startDate, e.endDate)
+		}
+	}
 
 	// 3. 初始选股
 	selectedCodes := e.performStockSelection(500) // 使用第500天的数据进行初始选股
-	logger.Infof("初始选股结果: %d 只票票", len(selectedCodes))
+	if !e.quiet {
+		logger.Infof("初始选股结果: %d 只票票", len(selectedCodes))
+	}
 
 	// 4. 逐日模拟
 	e.runDailySimulation(selectedCodes)
@@ -166,13 +253,60 @@ func (e *TimeBasedBacktestEngine) Run() *TimeBasedBacktestResult {
 
 	// 6. 生成回测结果
 	result := e.generateResult()
-	e.printSummary(result)
+	if !e.quiet {
+		e.printSummary(result)
+	}
 
 	return result
 }
 
+// fail 记录回测失败的原因，返回nil
+func (e *TimeBasedBacktestEngine) fail(err error) *TimeBasedBacktestResult {
+	e.err = err
+	logger.Infof("回测失败: %v", err)
+	return nil
+}
+
+// Err 最近一次 Run 返回nil的原因
+func (e *TimeBasedBacktestEngine) Err() error {
+	return e.err
+}
+
+// printHeader 打印回测参数
+func (e *TimeBasedBacktestEngine) printHeader() {
+	logger.Infof("========================================")
+	logger.Infof("基于时间流逝的回测引擎")
+	logger.Infof("策略: %s", e.strategy.GetName())
+	logger.Infof("初始资金: %.2f", e.initialCash)
+	logger.Infof("最大持仓数: %d", e.maxPositions)
+	logger.Infof("每仓位资金: %.1f%%", e.cashPerPosition*100)
+	logger.Infof("仓位管理: %s", e.getSizer().GetName())
+	if e.rotation != nil {
+		logger.Infof("满仓换仓: %s", e.rotation.GetName())
+	}
+	if e.pyramid != nil {
+		logger.Infof("加仓/减仓: %s", e.pyramid.GetName())
+	}
+	if e.benchmarkCode != "" {
+		logger.Infof("基准: %s", e.benchmarkCode)
+	}
+	logger.Infof("========================================")
+}
+
 // loadAllStockData 加载所有票票数据
 func (e *TimeBasedBacktestEngine) loadAllStockData() error {
+	if e.presetData != nil {
+		for code, stockInfo := range e.presetData {
+			if stockInfo != nil && len(stockInfo.Datas.DayDatas) >= 500 {
+				e.allStockData[code] = stockInfo
+			}
+		}
+		if !e.quiet {
+			logger.Infof("使用注入数据 %d 只票票", len(e.allStockData))
+		}
+		return nil
+	}
+
 	allCodes := getAllStockCodes()
 
 	loadedCount := 0
@@ -188,13 +322,28 @@ func (e *TimeBasedBacktestEngine) loadAllStockData() error {
 	return nil
 }
 
-// buildTradingDays 构建交易日列表
-// 从所有票票数据中提取共同的交易日
+// buildTradingDays 构建交易日列表和每只票票的日期索引
 func (e *TimeBasedBacktestEngine) buildTradingDays() {
+	for code, stockInfo := range e.allStockData {
+		index := make(map[string]int, len(stockInfo.Datas.DayDatas))
+		for i, dayData := range stockInfo.Datas.DayDatas {
+			index[dayData.DataStr] = i
+		}
+		e.dateIndex[code] = index
+	}
+
+	e.tradingDays = TradingDays(e.allStockData)
+}
+
+// TradingDays 从所有票票数据中提取交易日列表（排序后，跳过前500天用于积累历史数据）
+func TradingDays(data map[string]*stockData.StockInfo) []string {
 	dateMap := make(map[string]bool)
 
 	// 收集所有日期
-	for _, stockInfo := range e.allStockData {
+	for _, stockInfo := range data {
+		if stockInfo == nil || len(stockInfo.Datas.DayDatas) < 500 {
+			continue
+		}
 		for _, dayData := range stockInfo.Datas.DayDatas {
 			dateMap[dayData.DataStr] = true
 		}
@@ -209,10 +358,9 @@ func (e *TimeBasedBacktestEngine) buildTradingDays() {
 
 	// 只保留有足够数据的日期（至少500天历史）
 	if len(dates) > 500 {
-		e.tradingDays = dates[500:]
-	} else {
-		e.tradingDays = dates
+		return dates[500:]
 	}
+	return dates
 }
 
 // performStockSelection 执行选股
@@ -229,30 +377,60 @@ func (e *TimeBasedBacktestEngine) performStockSelection(dateIndex int) []string
 // runDailySimulation 逐日模拟
 func (e *TimeBasedBacktestEngine) runDailySimulation(candidateCodes []string) {
 	for dayIdx, date := range e.tradingDays {
+		// 超过结束日期：停止（当前日期停留在最后一个交易日，用于强制平仓）
+		if e.endDate != "" && date > e.endDate {
+			break
+		}
 		e.currentDate = date
 
+		// 开始日期之前只预热信号生成器
+		if e.startDate != "" && date < e.startDate {
+			e.warmUpSignals(candidateCodes)
+			continue
+		}
+
 		// 1. 处理卖出（必须先卖后买）
 		e.processSells(dayIdx)
 
-		// 2. 处理买入
-		e.processBuys(candidateCodes, dayIdx)
+		// 2. 处理分批止盈和加仓
+		e.processScaling(dayIdx)
 
-		// 3. 更新持仓价格和统计
+		// 3. 处理买入（横截面策略按目标权重调仓）
+		if portfolio, ok := e.strategy.(stockStrategy.PortfolioStrategy); ok {
+			e.processRebalance(portfolio, candidateCodes, dayIdx)
+		} else {
+			e.processBuys(candidateCodes, dayIdx)
+		}
+
+		// 4. 更新持仓价格和统计
 		e.updatePositions(dayIdx)
 
-		// 4. 记录每日权益
+		// 5. 记录每日权益
 		e.recordDailyEquity()
 
 		// 每100个交易日输出一次进度
-		if (dayIdx+1)%100 == 0 {
+		if !e.quiet && (dayIdx+1)%100 == 0 {
 			logger.Infof("回测进度: %d/%d 日期: %s 持仓: %d 总资产: %.2f",
 				dayIdx+1, len(e.tradingDays), date, len(e.positions), e.wallet.TotalAssets)
 		}
 	}
 }
 
+// warmUpSignals 让信号生成器处理开始日期之前的数据（更新其内部状态），不产生交易
+func (e *TimeBasedBacktestEngine) warmUpSignals(candidateCodes []string) {
+	for _, code := range candidateCodes {
+		gen := e.getOrCreateSignalGenerator(code)
+		if gen == nil {
+			continue
+		}
+		if ctx := e.barContext(code, gen); ctx != nil {
+			gen.ProcessDay(ctx, nil)
+		}
+	}
+}
+
 // processSells 处理卖出
-// 直接实现卖出逻辑，不调用 ProcessDay，以便正确更新持仓状态
+// BuyHighSellLowSignal 直接实现卖出逻辑，以便正确更新持仓状态；其他信号生成器调用 ProcessDay 判断
 func (e *TimeBasedBacktestEngine) processSells(dayIdx int) {
 	// 用于存储需要卖出的持仓及其原因
 	type sellInfo struct {
@@ -276,14 +454,10 @@ func (e *TimeBasedBacktestEngine) processSells(dayIdx int) {
 		}
 
 		// 判断卖出信号
-		// 这里直接实现卖出逻辑，而不是调用 ProcessDay
-		// 参考 BuyHighSellLowSignal 的卖出条件
+		// BuyHighSellLowSignal 的卖出条件直接在这里实现，其他信号生成器通过 ProcessDay 判断
 		shouldSell := false
 		sellReason := ""
 
-		// 从信号生成器获取参数
-		// 注意：这里需要类型断言，假设使用的是 BuyHighSellLowSignal
-		// 如果使用其他信号生成器，需要相应调整
 		if bhslSignal, ok := pos.SignalGen.(*signals.BuyHighSellLowSignal); ok {
 			// 条件1: 相对买入价的跌幅止损
 			dropPercent := (pos.BuyPrice - currentPrice) / pos.BuyPrice
@@ -306,6 +480,9 @@ func (e *TimeBasedBacktestEngine) processSells(dayIdx int) {
 				shouldSell = true
 				sellReason = fmt.Sprintf("持有超过%d天", bhslSignal.MaxHoldDays)
 			}
+		} else {
+			// 其他信号生成器：把持仓状态传给 ProcessDay 判断卖出
+			shouldSell, sellReason = e.checkSellSignal(pos, dayIdx)
 		}
 
 		if shouldSell {
@@ -314,8 +491,8 @@ func (e *TimeBasedBacktestEngine) processSells(dayIdx int) {
 				reason: sellReason,
 			})
 		} else {
-			// 不卖出，更新持有天数
-			pos.HoldDays++
+			// 不卖出，更新持有天数（整体和每个批次）
+			pos.incrementHoldDays()
 		}
 
 		// 更新当前价格
@@ -332,10 +509,43 @@ func (e *TimeBasedBacktestEngine) processSells(dayIdx int) {
 	}
 }
 
+// checkSellSignal 调用信号生成器的 ProcessDay 判断持仓是否卖出
+// 返回是否卖出和卖出原因（信号生成器实现 ExitReasonProvider 时使用其原因）
+func (e *TimeBasedBacktestEngine) checkSellSignal(pos *PositionState, dayIdx int) (bool, string) {
+	if pos.SignalGen == nil {
+		return false, "" // 横截面策略没有信号生成器时只按调仓卖出
+	}
+	ctx := e.barContext(pos.Code, pos.SignalGen)
+	if ctx == nil {
+		return false, ""
+	}
+
+	position := &stockStrategy.Position{
+		StockCode:    pos.Code,
+		StockName:    pos.Name,
+		StockNum:     pos.StockNum,
+		BuyPrice:     float32(pos.BuyPrice),
+		BuyDate:      pos.BuyDate,
+		BuyIndex:     pos.BuyIndex,
+		HoldDays:     pos.HoldDays,
+		HighestPrice: float32(pos.HighestPrice),
+	}
+
+	e.signalDays[pos.Code] = dayIdx
+	if pos.SignalGen.ProcessDay(ctx, position) != -1 {
+		return false, ""
+	}
+
+	if provider, ok := pos.SignalGen.(stockStrategy.ExitReasonProvider); ok {
+		return true, provider.GetExitReason()
+	}
+	return true, ""
+}
+
 // processBuys 处理买入
 func (e *TimeBasedBacktestEngine) processBuys(candidateCodes []string, dayIdx int) {
-	// 如果已达到最大持仓数，不再买入
-	if len(e.positions) >= e.maxPositions {
+	// 如果已达到最大持仓数且未启用换仓，不再买入
+	if len(e.positions) >= e.maxPositions && e.rotation == nil {
 		return
 	}
 
@@ -348,6 +558,11 @@ func (e *TimeBasedBacktestEngine) processBuys(candidateCodes []string, dayIdx in
 			continue
 		}
 
+		// 当天已处理过（持仓当天按信号卖出），不再重复处理
+		if signalDay, ok := e.signalDays[code]; ok && signalDay == dayIdx {
+			continue
+		}
+
 		// 检查是否在冷却期内
 		if cooldownEnd, inCooldown := e.buyCooldowns[code]; inCooldown {
 			if dayIdx < cooldownEnd {
@@ -366,54 +581,164 @@ func (e *TimeBasedBacktestEngine) processBuys(candidateCodes []string, dayIdx in
 			continue
 		}
 
-		// 获取当天数据
-		dayData := e.getDayData(code, e.currentDate)
-		if dayData == nil {
-			continue
-		}
-
 		// 获取或创建信号生成器
 		signalGen := e.getOrCreateSignalGenerator(code)
 
+		// 获取截至当天的K线（当天停牌则跳过）
+		ctx := e.barContext(code, signalGen)
+		if ctx == nil {
+			continue
+		}
+
 		// 检查买入信号
-		signal := signalGen.ProcessDay(dayData, dayIdx, nil)
+		signal := signalGen.ProcessDay(ctx, nil)
 
 		if signal == 1 {
 			buySignals = append(buySignals, code)
 		}
 	}
 
-	// 执行买入
-	for _, code := range buySignals {
-		// 检查持仓数限制
-		if len(e.positions) >= e.maxPositions {
-			break
+	// 启用换仓时，按得分从高到低处理买入信号，最强的信号优先
+	if e.rotation != nil {
+		scores := make(map[string]float64, len(buySignals))
+		for _, code := range buySignals {
+			if dayData := e.getDayData(code, e.currentDate); dayData != nil {
+				scores[code] = e.scoreCandidate(code, float64(dayData.PriceBegin))
+			}
 		}
+		sort.SliceStable(buySignals, func(i, j int) bool {
+			return scores[buySignals[i]] > scores[buySignals[j]]
+		})
+	}
 
-		// 检查是否有足够现金买入（至少能买一手）
+	// 执行买入
+	rotations := 0
+	for _, code := range buySignals {
 		dayData := e.getDayData(code, e.currentDate)
 		if dayData == nil {
 			continue
 		}
 		price := float64(dayData.PriceBegin)
-		minCost := price * 100 // 一手的成本
 
-		// 如果现金不足，跳过本次买入
+		// 满仓时尝试换仓，未启用换仓或换仓条件不满足则放弃该信号
+		isRotation := false
+		if e.isBookFull(code, price) {
+			if e.rotation == nil || rotations >= e.rotation.MaxRotationsPerDay {
+				continue
+			}
+			if !e.tryRotate(code, price, dayIdx) {
+				continue
+			}
+			rotations++
+			isRotation = true
+		}
+
+		// 检查持仓数限制
+		if len(e.positions) >= e.maxPositions {
+			continue
+		}
+
+		// 检查是否有足够现金买入（至少能买最少数量）
+		minCost := price * float64(sizers.GetLotRule(code).MinShares) // 最少买入数量的成本
 		if e.wallet.Cash < minCost {
 			continue
 		}
 
+		// 由仓位管理器计算买入数量
+		targetNum := e.calculateBuyNum(code, price)
+		if targetNum <= 0 {
+			continue
+		}
+
 		// 尝试买入
-		e.executeBuy(code, dayIdx, 0)
+		reason := "买入信号"
+		if isRotation {
+			reason = "轮换买入"
+		}
+		if e.executeBuy(code, dayIdx, targetNum, reason) && isRotation {
+			e.tradeRecords[len(e.tradeRecords)-1].IsRotation = true
+		}
+	}
+}
+
+// getSizer 获取当前使用的仓位管理器
+// 策略自带的仓位管理器优先，否则使用引擎默认值
+func (e *TimeBasedBacktestEngine) getSizer() stockStrategy.PositionSizer {
+	if sized, ok := e.strategy.(stockStrategy.SizedStrategy); ok {
+		if sizer := sized.GetSizer(); sizer != nil {
+			return sizer
+		}
+	}
+	return e.sizer
+}
+
+// calculateBuyNum 计算计划买入的股数
+func (e *TimeBasedBacktestEngine) calculateBuyNum(code string, price float64) int {
+	ctx := &stockStrategy.SizingContext{
+		Code:          code,
+		Date:          e.currentDate,
+		Price:         price,
+		Cash:          e.wallet.Cash,
+		TotalAssets:   e.currentTotalAssets(),
+		MaxPositions:  e.maxPositions,
+		OpenPositions: len(e.positions),
+		History:       e.getHistory(code, e.currentDate),
+		Stats:         e.getTradeStats(),
+	}
+
+	if provider, ok := e.getOrCreateSignalGenerator(code).(stockStrategy.StopLossProvider); ok {
+		ctx.StopPercent = provider.GetStopLossPercent()
+	}
+
+	stockNum := e.getSizer().Size(ctx)
+	if adjuster, ok := e.strategy.(stockStrategy.SizeAdjuster); ok && stockNum > 0 {
+		stockNum = adjuster.AdjustSize(ctx, stockNum)
+	}
+	return stockNum
+}
+
+// currentTotalAssets 计算当前总资产（现金+持仓按最新价格估值）
+func (e *TimeBasedBacktestEngine) currentTotalAssets() float64 {
+	total := e.wallet.Cash
+	for _, pos := range e.positions {
+		total += pos.CurrentPrice * float64(pos.StockNum)
+	}
+	return total
+}
+
+// getTradeStats 获取已完成交易的统计
+func (e *TimeBasedBacktestEngine) getTradeStats() stockStrategy.TradeStats {
+	stats := stockStrategy.TradeStats{
+		ClosedTrades: e.closedStats.winCount + e.closedStats.lossCount,
+		WinCount:     e.closedStats.winCount,
+	}
+	if e.closedStats.winCount > 0 {
+		stats.AvgWinPct = e.closedStats.sumWinPct / float64(e.closedStats.winCount)
+	}
+	if e.closedStats.lossCount > 0 {
+		stats.AvgLossPct = e.closedStats.sumLossPct / float64(e.closedStats.lossCount)
+	}
+	return stats
+}
+
+// recordClosedTrade 记录一笔已完成交易的收益率
+func (e *TimeBasedBacktestEngine) recordClosedTrade(returnPct float64) {
+	if returnPct > 0 {
+		e.closedStats.winCount++
+		e.closedStats.sumWinPct += returnPct
+	} else {
+		e.closedStats.lossCount++
+		e.closedStats.sumLossPct += -returnPct
 	}
 }
 
 // executeBuy 执行买入
-// 全仓买入策略：使用所有可用现金买入
-func (e *TimeBasedBacktestEngine) executeBuy(code string, dayIdx int, _ float64) {
+// targetNum 为仓位管理器计算的计划买入股数，现金不足以支付手续费时按交易单位递减
+// 返回 true 表示买入成交
+func (e *TimeBasedBacktestEngine) executeBuy(code string, dayIdx int, targetNum int, reason string) bool {
 	dayData := e.getDayData(code, e.currentDate)
 	if dayData == nil {
-		return
+		return false
 	}
 
 	stockInfo := e.allStockData[code]
@@ -424,20 +749,18 @@ func (e *TimeBasedBacktestEngine) executeBuy(code string, dayIdx int, _ float64)
 		// 触及涨停板，买入可能无法成交
 		// 将该票票加入冷却期，50天内禁止买入
 		e.buyCooldowns[code] = dayIdx + 50
-		return
+		return false
 	}
 
-	// 全仓买入：使用所有可用现金
-	cashToUse := e.wallet.Cash
-
-	// 计算买入数量（整手），需要预留手续费
+	// 计算买入数量（按交易单位取整），需要预留手续费
 	// 总成本 = 价格 * 数量 + 佣金 + 过户费
 	// 佣金 = max(价格 * 数量 * 佣金率, 最低佣金)
 	// 过户费 = 价格 * 数量 * 过户费率
-	// 为简化计算，先估算可买入的数量，然后验证是否有足够现金
-	stockNum := int(cashToUse / price / 100) * 100
-	if stockNum < 100 {
-		return // 资金不足买一手
+	// 先按计划数量估算，然后验证是否有足够现金
+	lotRule := sizers.GetLotRule(code)
+	stockNum := lotRule.RoundDown(targetNum)
+	if stockNum <= 0 {
+		return false // 不足最少买入数量
 	}
 
 	// 计算实际成本和手续费
@@ -447,130 +770,113 @@ func (e *TimeBasedBacktestEngine) executeBuy(code string, dayIdx int, _ float64)
 	totalCost := amount + commission + transferFee
 
 	// 如果总成本超过现金，减少买入数量
-	for totalCost > e.wallet.Cash && stockNum >= 100 {
-		stockNum -= 100
+	for totalCost > e.wallet.Cash && stockNum > 0 {
+		stockNum = lotRule.Reduce(stockNum)
 		amount = price * float64(stockNum)
 		commission = e.calculateCommission(amount)
 		transferFee = e.calculateTransferFee(amount)
 		totalCost = amount + commission + transferFee
 	}
 
-	if stockNum < 100 {
-		return // 资金不足买一手（含手续费）
+	if stockNum <= 0 {
+		return false // 资金不足买最少数量（含手续费）
 	}
 
 	// 扣除资金（包括手续费）
 	e.wallet.Cash -= totalCost
 	e.totalFees += commission + transferFee
 
-	// 创建持仓
-	signalGen := e.getOrCreateSignalGenerator(code)
-	pos := &PositionState{
-		Code:         code,
-		Name:         stockInfo.Name,
-		StockNum:     stockNum,
-		BuyPrice:     price,
-		BuyDate:      e.currentDate,
-		BuyIndex:     dayIdx,
-		HoldDays:     0,
-		HighestPrice: price,
-		CurrentPrice: price,
-		SignalGen:    signalGen,
+	// 创建持仓（已持仓时为加仓，增加一个批次）
+	pos, exists := e.positions[code]
+	if !exists {
+		signalGen := e.getOrCreateSignalGenerator(code)
+		pos = &PositionState{
+			Code:         code,
+			Name:         stockInfo.Name,
+			BuyPrice:     price,
+			BuyDate:      e.currentDate,
+			BuyIndex:     dayIdx,
+			HoldDays:     0,
+			HighestPrice: price,
+			CurrentPrice: price,
+			SignalGen:    signalGen,
+			UnitNum:      stockNum,
+		}
+		e.positions[code] = pos
 	}
 
-	e.positions[code] = pos
-
-	// 记录交易
-	e.tradeRecords = append(e.tradeRecords, TradeRecord{
-		Code:       code,
-		Name:       stockInfo.Name,
-		Action:     "buy",
-		Date:       e.currentDate,
-		Price:      price,
-		StockNum:   stockNum,
-		Amount:     amount,
-		Commission: commission,
-		StampTax:   0,
-		TransferFee: transferFee,
-		TotalFee:   commission + transferFee,
-		Cash:       e.wallet.Cash,
-		Reason:     "买入信号",
+	pos.addLot(&PositionLot{
+		BuyDate:  e.currentDate,
+		BuyIndex: dayIdx,
+		BuyPrice: price,
+		StockNum: stockNum,
+		BuyFee:   commission + transferFee,
 	})
-}
-
-// executeSell 执行卖出
-func (e *TimeBasedBacktestEngine) executeSell(pos *PositionState, reason string) {
-	dayData := e.getDayData(pos.Code, e.currentDate)
-	if dayData == nil {
-		return
-	}
-
-	price := float64(dayData.PriceBegin)
-
-	// 检查是否触及涨跌停板
-	if e.checkPriceLimit(pos.Code, e.currentDate, price) {
-		// 触及跌停板，卖出可能无法成交，放弃卖出（持仓继续保留）
-		return
-	}
-
-	// 计算卖出金额和手续费
-	amount := price * float64(pos.StockNum)
-	commission := e.calculateCommission(amount)
-	stampTax := e.calculateStampTax(amount)
-	transferFee := e.calculateTransferFee(amount)
-	totalFee := commission + stampTax + transferFee
-	netAmount := amount - totalFee // 实际到手金额
-
-	// 增加资金（扣除手续费后）
-	e.wallet.Cash += netAmount
-	e.totalFees += totalFee
+	pos.LastAddPrice = price
 
 	// 记录交易
 	e.tradeRecords = append(e.tradeRecords, TradeRecord{
-		Code:       pos.Code,
-		Name:       pos.Name,
-		Action:     "sell",
-		Date:       e.currentDate,
-		Price:      price,
-		StockNum:   pos.StockNum,
-		Amount:     amount,
-		Commission: commission,
-		StampTax:   stampTax,
+		Code:        code,
+		Name:        stockInfo.Name,
+		Action:      "buy",
+		Date:        e.currentDate,
+		Price:       price,
+		StockNum:    stockNum,
+		Amount:      amount,
+		Commission:  commission,
+		StampTax:    0,
 		TransferFee: transferFee,
-		TotalFee:   totalFee,
-		Cash:       e.wallet.Cash,
-		Reason:     reason,
+		TotalFee:    commission + transferFee,
+		Cash:        e.wallet.Cash,
+		Reason:      reason,
 	})
 
-	// 删除持仓
-	delete(e.positions, pos.Code)
+	return true
+}
+
+// executeSell 执行卖出（全部卖出）
+// 返回 true 表示卖出成交
+func (e *TimeBasedBacktestEngine) executeSell(pos *PositionState, reason string) bool {
+	_, ok := e.sellShares(pos, pos.StockNum, reason)
+	return ok
 }
 
 // sellHalfPosition 卖出一半仓位
 // 返回卖出获得的资金，如果卖出失败则返回0
 func (e *TimeBasedBacktestEngine) sellHalfPosition(pos *PositionState, reason string) float64 {
+	// 计算卖出一半的数量（向下取整到交易单位）
+	halfNum := sizers.GetLotRule(pos.Code).RoundDown(pos.StockNum / 2)
+	if halfNum <= 0 {
+		// 如果一半不足最少交易数量，则不卖出
+		return 0
+	}
+
+	netAmount, _ := e.sellShares(pos, halfNum, reason)
+	return netAmount
+}
+
+// sellShares 卖出指定数量，按先进先出从持仓批次中扣减
+// 返回实际到手金额和是否成交；全部卖出时删除持仓
+func (e *TimeBasedBacktestEngine) sellShares(pos *PositionState, stockNum int, reason string) (float64, bool) {
 	dayData := e.getDayData(pos.Code, e.currentDate)
 	if dayData == nil {
-		return 0
+		return 0, false
 	}
 
 	price := float64(dayData.PriceBegin)
 
 	// 检查是否触及涨跌停板
 	if e.checkPriceLimit(pos.Code, e.currentDate, price) {
-		// 触及跌停板，卖出可能无法成交
-		return 0
+		// 触及跌停板，卖出可能无法成交，放弃卖出（持仓继续保留）
+		return 0, false
 	}
 
-	// 计算卖出一半的数量（向下取整到整手）
-	halfNum := (pos.StockNum / 2 / 100) * 100
-	if halfNum < 100 {
-		// 如果一半不足一手，则不卖出
-		return 0
+	if stockNum <= 0 || stockNum > pos.StockNum {
+		return 0, false
 	}
 
 	// 计算卖出金额和手续费
-	amount := price * float64(halfNum)
+	amount := price * float64(stockNum)
 	commission := e.calculateCommission(amount)
 	stampTax := e.calculateStampTax(amount)
 	transferFee := e.calculateTransferFee(amount)
@@ -581,27 +887,41 @@ func (e *TimeBasedBacktestEngine) sellHalfPosition(pos *PositionState, reason st
 	e.wallet.Cash += netAmount
 	e.totalFees += totalFee
 
-	// 更新持仓数量
-	pos.StockNum -= halfNum
+	// 按先进先出扣减批次，计算已实现盈亏
+	fills, costBasis := pos.removeShares(stockNum)
+	realizedPnL := netAmount - costBasis
+	pos.RealizedPnL += realizedPnL
+	pos.ClosedCost += costBasis
 
 	// 记录交易
 	e.tradeRecords = append(e.tradeRecords, TradeRecord{
-		Code:       pos.Code,
-		Name:       pos.Name,
-		Action:     "sell",
-		Date:       e.currentDate,
-		Price:      price,
-		StockNum:   halfNum,
-		Amount:     amount,
-		Commission: commission,
-		StampTax:   stampTax,
+		Code:        pos.Code,
+		Name:        pos.Name,
+		Action:      "sell",
+		Date:        e.currentDate,
+		Price:       price,
+		StockNum:    stockNum,
+		Amount:      amount,
+		Commission:  commission,
+		StampTax:    stampTax,
 		TransferFee: transferFee,
-		TotalFee:   totalFee,
-		Cash:       e.wallet.Cash,
-		Reason:     reason,
+		TotalFee:    totalFee,
+		Cash:        e.wallet.Cash,
+		Reason:      reason,
+		LotFills:    fills,
+		CostBasis:   costBasis,
+		RealizedPnL: realizedPnL,
 	})
 
-	return netAmount
+	// 全部卖出：记录已完成交易的收益率并删除持仓
+	if pos.StockNum == 0 {
+		if pos.ClosedCost > 0 {
+			e.recordClosedTrade(pos.RealizedPnL / pos.ClosedCost)
+		}
+		delete(e.positions, pos.Code)
+	}
+
+	return netAmount, true
 }
 
 // updatePositions 更新持仓价格
@@ -638,6 +958,16 @@ func (e *TimeBasedBacktestEngine) closeAllPositions() {
 	}
 }
 
+// sortedPositionCodes 按代码排序的持仓列表（保证处理顺序稳定）
+func (e *TimeBasedBacktestEngine) sortedPositionCodes() []string {
+	codes := make([]string, 0, len(e.positions))
+	for code := range e.positions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
 // getDayData 获取指定日期的数据
 func (e *TimeBasedBacktestEngine) getDayData(code, date string) *stockData.StockDataDay {
 	stockInfo, exists := e.allStockData[code]
@@ -645,15 +975,21 @@ func (e *TimeBasedBacktestEngine) getDayData(code, date string) *stockData.Stock
 		return nil
 	}
 
-	for _, dayData := range stockInfo.Datas.DayDatas {
-		if dayData.DataStr == date {
-			return dayData
-		}
+	if i := e.findDayIndex(code, date); i >= 0 {
+		return stockInfo.Datas.DayDatas[i]
 	}
 
 	return nil
 }
 
+// findDayIndex 查找指定日期在票票数据中的下标，找不到返回-1
+func (e *TimeBasedBacktestEngine) findDayIndex(code, date string) int {
+	if i, ok := e.dateIndex[code][date]; ok {
+		return i
+	}
+	return -1
+}
+
 // getPreviousDayData 获取前一个交易日的数据
 func (e *TimeBasedBacktestEngine) getPreviousDayData(code, currentDate string) *stockData.StockDataDay {
 	stockInfo, exists := e.allStockData[code]
@@ -662,13 +998,7 @@ func (e *TimeBasedBacktestEngine) getPreviousDayData(code, currentDate string) *
 	}
 
 	// 找到当前日期的索引
-	var currentIndex = -1
-	for i, dayData := range stockInfo.Datas.DayDatas {
-		if dayData.DataStr == currentDate {
-			currentIndex = i
-			break
-		}
-	}
+	currentIndex := e.findDayIndex(code, currentDate)
 
 	// 如果找不到当前日期或者是第一天，返回nil
 	if currentIndex <= 0 {
@@ -678,6 +1008,20 @@ func (e *TimeBasedBacktestEngine) getPreviousDayData(code, currentDate string) *
 	return stockInfo.Datas.DayDatas[currentIndex-1]
 }
 
+// getHistory 获取指定日期之前的全部历史数据（不含当天，避免未来数据）
+func (e *TimeBasedBacktestEngine) getHistory(code, currentDate string) []*stockData.StockDataDay {
+	stockInfo, exists := e.allStockData[code]
+	if !exists {
+		return nil
+	}
+
+	if i := e.findDayIndex(code, currentDate); i >= 0 {
+		return stockInfo.Datas.DayDatas[:i:i]
+	}
+
+	return nil
+}
+
 // checkPriceLimit 检查价格是否触及涨跌停板
 // 返回 true 表示触及涨跌停，交易可能无法成交
 func (e *TimeBasedBacktestEngine) checkPriceLimit(code, currentDate string, currentPrice float64) bool {
@@ -701,7 +1045,8 @@ func (e *TimeBasedBacktestEngine) checkPriceLimit(code, currentDate string, curr
 
 // findWeakestPosition 找出最弱的持仓（盈利最少或亏损最大）
 // 策略：优先卖出表现差的票票，保留表现好的
-func (e *TimeBasedBacktestEngine) findWeakestPosition() *PositionState {
+// 设置了换仓策略时按换仓策略的排序依据计算得分
+func (e *TimeBasedBacktestEngine) findWeakestPosition(minHoldDays int) *PositionState {
 	if len(e.positions) == 0 {
 		return nil
 	}
@@ -709,7 +1054,14 @@ func (e *TimeBasedBacktestEngine) findWeakestPosition() *PositionState {
 	var weakest *PositionState
 	minProfitRate := 999999.0 // 设置一个很大的初始值
 
-	for _, pos := range e.positions {
+	// 按代码顺序遍历，得分相同时结果固定
+	for _, code := range e.sortedPositionCodes() {
+		pos := e.positions[code]
+		// 持有天数不足的不参与比较
+		if pos.HoldDays < minHoldDays {
+			continue
+		}
+
 		// 获取当前价格
 		dayData := e.getDayData(pos.Code, e.currentDate)
 		if dayData == nil {
@@ -718,10 +1070,10 @@ func (e *TimeBasedBacktestEngine) findWeakestPosition() *PositionState {
 
 		currentPrice := float64(dayData.PriceBegin)
 
-		// 计算盈利率
-		profitRate := (currentPrice - pos.BuyPrice) / pos.BuyPrice
+		// 计算得分（未设置换仓策略时为盈利率）
+		profitRate := e.scorePosition(pos, currentPrice)
 
-		// 找出盈利率最低的（可能是亏损最大的）
+		// 找出得分最低的（可能是亏损最大的）
 		if profitRate < minProfitRate {
 			minProfitRate = profitRate
 			weakest = pos
@@ -740,13 +1092,7 @@ func (e *TimeBasedBacktestEngine) checkRecentHighRisk(code, currentDate string)
 	}
 
 	// 找到当前日期的索引
-	var currentIndex = -1
-	for i, dayData := range stockInfo.Datas.DayDatas {
-		if dayData.DataStr == currentDate {
-			currentIndex = i
-			break
-		}
-	}
+	currentIndex := e.findDayIndex(code, currentDate)
 
 	if currentIndex < 5 {
 		// 数据不足5天，无法判断
@@ -800,14 +1146,34 @@ func (e *TimeBasedBacktestEngine) calculateTransferFee(amount float64) float64 {
 	return amount * e.transferFeeRate
 }
 
+// barContext 截至当前日期的K线上下文，当天没有数据时返回nil
+func (e *TimeBasedBacktestEngine) barContext(code string, gen stockStrategy.SignalGenerator) *stockStrategy.BarContext {
+	i := e.findDayIndex(code, e.currentDate)
+	if i < 0 {
+		return nil
+	}
+	return stockStrategy.NewBarContext(code, e.allStockData[code].Datas.DayDatas, i, stockStrategy.HistoryWindow(gen))
+}
+
 // getOrCreateSignalGenerator 获取或创建信号生成器
+// 策略未实现 SignalGeneratorFactory 时所有票票共用策略的信号生成器，
+// 历史价格来自 BarContext，共用的信号生成器不能保存票票相关的状态
+// 横截面策略可以没有信号生成器，此时返回nil
 func (e *TimeBasedBacktestEngine) getOrCreateSignalGenerator(code string) stockStrategy.SignalGenerator {
 	if gen, exists := e.signalGenerators[code]; exists {
 		return gen
 	}
 
-	// 创建新的信号生成器
-	gen := e.strategy.GetSignalGenerator()
+	// 创建新的信号生成器，策略支持时每只票票使用独立的实例
+	var gen stockStrategy.SignalGenerator
+	if factory, ok := e.strategy.(stockStrategy.SignalGeneratorFactory); ok {
+		gen = factory.NewSignalGenerator(code)
+	} else {
+		gen = e.strategy.GetSignalGenerator()
+	}
+	if gen == nil {
+		return nil
+	}
 	gen.Reset()
 	e.signalGenerators[code] = gen
 
@@ -816,6 +1182,8 @@ func (e *TimeBasedBacktestEngine) getOrCreateSignalGenerator(code string) stockS
 
 // TimeBasedBacktestResult 回测结果
 type TimeBasedBacktestResult struct {
+	Strategy string // 策略名称
+
 	InitialCash    float64
 	FinalCash      float64
 	FinalAssets    float64
@@ -831,18 +1199,52 @@ type TimeBasedBacktestResult struct {
 
 	DailyEquity  []DailyEquity
 	TradeRecords []TradeRecord
+	RoundTrips   []RoundTrip   // 按先进先出配对的完整交易台账
+	ClosedTrades []ClosedTrade // 按持仓合并的平仓交易，交易笔数和胜率等统计基于此
 
 	// 新增统计
-	MaxDrawdown  float64
-	SharpeRatio  float64
-	MaxPositions int
-	AvgHoldDays  float64
-	TotalFees    float64 // 总手续费（佣金+印花税+过户费）
+	MaxDrawdown   float64
+	SharpeRatio   float64
+	MaxPositions  int
+	AvgHoldDays   float64 // 平均持有交易日数
+	AvgWin        float64 // 平均盈利金额（净）
+	AvgLoss       float64 // 平均亏损金额（净，负数）
+	AvgWinPct     float64 // 平均盈利收益率（百分比）
+	AvgLossPct    float64 // 平均亏损收益率（百分比，负数）
+	RotationCount int     // 满仓换仓次数
+	TotalFees     float64 // 总手续费（佣金+印花税+过户费）
+
+	Performance *performance.Report // 绩效分析（年化收益、夏普、回撤、月度收益等）
+
+	BenchmarkCode   string                // 基准指数代码
+	BenchmarkEquity []BenchmarkEquity     // 与 DailyEquity 对齐的基准权益（从第一个共同日期开始）
+	Benchmark       *performance.Relative // 相对基准的绩效（未设置基准时为nil）
+
+	RegimeBreakdown []RegimeStats // 按市场状态分组的表现（策略或引擎未提供市场状态时为空）
+}
+
+// Analyze 按指定参数（如无风险利率）重新计算绩效分析
+func (r *TimeBasedBacktestResult) Analyze(cfg performance.Config) *performance.Report {
+	return performance.Analyze(EquityPoints(r.DailyEquity), PerformanceTrades(r.ClosedTrades), cfg)
+}
+
+// EquityPoints 把每日权益转换为绩效分析使用的权益点
+func EquityPoints(equity []DailyEquity) []performance.EquityPoint {
+	points := make([]performance.EquityPoint, 0, len(equity))
+	for _, day := range equity {
+		points = append(points, performance.EquityPoint{
+			Date:          day.Date,
+			TotalAssets:   day.TotalAssets,
+			PositionValue: day.PositionValue,
+		})
+	}
+	return points
 }
 
 // generateResult 生成回测结果
 func (e *TimeBasedBacktestEngine) generateResult() *TimeBasedBacktestResult {
 	result := &TimeBasedBacktestResult{
+		Strategy:     e.strategy.GetName(),
 		InitialCash:  e.initialCash,
 		FinalCash:    e.wallet.Cash,
 		FinalAssets:  e.wallet.TotalAssets,
@@ -855,43 +1257,49 @@ func (e *TimeBasedBacktestEngine) generateResult() *TimeBasedBacktestResult {
 	result.TotalReturn = result.FinalAssets - result.InitialCash
 	result.TotalReturnPct = (result.TotalReturn / result.InitialCash) * 100
 
-	// 统计交易
-	buyTrades := make(map[string]*TradeRecord) // key: code_date
-
+	// 统计买卖次数
 	for i := range e.tradeRecords {
 		record := &e.tradeRecords[i]
 
 		if record.Action == "buy" {
 			result.BuyCount++
-			key := record.Code + "_" + record.Date
-			buyTrades[key] = record
 		} else if record.Action == "sell" {
 			result.SellCount++
-
-			// 找到对应的买入记录
-			// 简化处理：假设先买后卖
-			for _, buyRecord := range buyTrades {
-				if buyRecord.Code == record.Code {
-					profit := record.Amount - buyRecord.Amount
-					if profit > 0 {
-						result.WinCount++
-					} else {
-						result.LoseCount++
-					}
-					break
-				}
+			if record.IsRotation {
+				result.RotationCount++
 			}
 		}
 	}
 
-	result.TotalTrades = result.SellCount // 完成的交易数
-	if result.TotalTrades > 0 {
-		result.WinRate = float64(result.WinCount) / float64(result.TotalTrades) * 100
-	}
+	// 完整交易台账来自卖出时的批次明细，胜率等统计按持仓合并的平仓交易计算（扣除手续费）
+	result.RoundTrips = BuildRoundTrips(e.tradeRecords)
+	result.ClosedTrades = BuildClosedTrades(e.tradeRecords, e.tradingDays)
+	stats := SummarizeClosedTrades(result.ClosedTrades)
+	result.TotalTrades = stats.TotalTrades
+	result.WinCount = stats.WinCount
+	result.LoseCount = stats.LoseCount
+	result.WinRate = stats.WinRate
+	result.AvgWin = stats.AvgWin
+	result.AvgLoss = stats.AvgLoss
+	result.AvgWinPct = stats.AvgWinPct
+	result.AvgLossPct = stats.AvgLossPct
+	result.AvgHoldDays = stats.AvgHoldDays
 
 	// 计算最大回撤
 	result.MaxDrawdown = e.calculateMaxDrawdown()
 
+	// 绩效分析
+	result.Performance = result.Analyze(performance.NewDefaultConfig())
+	result.SharpeRatio = result.Performance.SharpeRatio
+
+	// 相对基准的绩效
+	e.compareBenchmark(result)
+
+	// 按市场状态分组统计
+	if provider := e.regimeProvider(); provider != nil {
+		result.RegimeBreakdown = BreakdownByRegime(result.DailyEquity, result.ClosedTrades, provider.RegimeAt)
+	}
+
 	return result
 }
 
@@ -939,5 +1347,42 @@ func (e *TimeBasedBacktestEngine) printSummary(result *TimeBasedBacktestResult)
 	logger.Infof("  盈利次数: %d", result.WinCount)
 	logger.Infof("  亏损次数: %d", result.LoseCount)
 	logger.Infof("  胜率: %.2f%%", result.WinRate)
+	logger.Infof("  平均盈利: %.2f (%.2f%%)", result.AvgWin, result.AvgWinPct)
+	logger.Infof("  平均亏损: %.2f (%.2f%%)", result.AvgLoss, result.AvgLossPct)
+	logger.Infof("  平均持有天数: %.1f", result.AvgHoldDays)
+	logger.Infof("  换仓次数: %d", result.RotationCount)
+
+	if perf := result.Performance; perf != nil {
+		logger.Infof("")
+		logger.Infof("绩效分析:")
+		logger.Infof("  年化收益率: %.2f%%", perf.AnnualizedReturn)
+		logger.Infof("  年化波动率: %.2f%%", perf.Volatility)
+		logger.Infof("  夏普比率: %.2f", perf.SharpeRatio)
+		logger.Infof("  索提诺比率: %.2f", perf.SortinoRatio)
+		logger.Infof("  卡玛比率: %.2f", perf.CalmarRatio)
+		logger.Infof("  最大回撤区间: %s ~ %s", perf.MaxDrawdownPeak, perf.MaxDrawdownTrough)
+		logger.Infof("  最长水下时间: %d 个交易日", perf.MaxDrawdownDuration)
+		if perf.RecoveryDays >= 0 {
+			logger.Infof("  最大回撤恢复时间: %d 个交易日", perf.RecoveryDays)
+		} else {
+			logger.Infof("  最大回撤恢复时间: 未恢复")
+		}
+		if math.IsInf(perf.ProfitFactor, 1) {
+			logger.Infof("  盈亏比: 无亏损交易")
+		} else {
+			logger.Infof("  盈亏比: %.2f", perf.ProfitFactor)
+		}
+		logger.Infof("  每笔期望: %.2f (%.2f%%)", perf.Expectancy, perf.ExpectancyPct)
+		logger.Infof("  持仓时间占比: %.2f%%", perf.Exposure)
+		logger.Infof("  平均仓位: %.2f%%", perf.AvgExposure)
+		logger.Infof("  年化换手率: %.2f 倍", perf.Turnover)
+		logger.Infof("")
+		logger.Infof("月度收益:")
+		for _, line := range perf.MonthlyTable() {
+			logger.Infof("  %s", line)
+		}
+	}
+	printBenchmark(result)
+	printRegimeBreakdown(result)
 	logger.Infof("========================================")
 }
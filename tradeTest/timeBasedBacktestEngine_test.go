@@ -26,7 +26,7 @@ func TestTimeBasedBacktestEngine(t *testing.T) {
 	// 3. 创建基于时间流逝的回测引擎
 	initialCash := 1000000.0 // 100万初始资金
 	maxPositions := 4        // 最多同时持有4只票票
-	cashPerPosition := 1.0   // 全仓买入（该参数在新逻辑中不再使用）
+	cashPerPosition := 1.0   // 每仓位最多使用总资产的100%（固定比例仓位）
 
 	engine := NewTimeBasedBacktestEngine(
 		initialCash,
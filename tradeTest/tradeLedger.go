@@ -0,0 +1,225 @@
+package tradeTest
+
+import "stock-go/tradeTest/performance"
+
+// RoundTrip 一次完整的交易（一个买入批次与一次卖出按先进先出配对的部分）
+// 一次卖出跨多个买入批次时拆分为多条，一个买入批次分多次卖出时也拆分为多条；按持仓合并的统计见 ClosedTrade
+type RoundTrip struct {
+	Code       string  // 票票代码
+	Name       string  // 票票名称
+	EntryDate  string  // 买入日期
+	ExitDate   string  // 卖出日期
+	EntryPrice float64 // 买入价格
+	ExitPrice  float64 // 卖出价格
+	StockNum   int     // 数量
+	HoldDays   int     // 持有交易日数
+	EntryFee   float64 // 分摊的买入手续费
+	ExitFee    float64 // 分摊的卖出手续费
+	GrossPnL   float64 // 毛盈亏（不含手续费）
+	NetPnL     float64 // 净盈亏（扣除买卖手续费）
+	ReturnPct  float64 // 净收益率（净盈亏/买入成本，买入成本含买入手续费）
+	ExitReason string  // 卖出原因
+	IsRotation bool    // 是否为满仓换仓卖出
+}
+
+// TotalFee 买卖手续费合计
+func (rt RoundTrip) TotalFee() float64 {
+	return rt.EntryFee + rt.ExitFee
+}
+
+// IsWin 是否盈利（按净盈亏）
+func (rt RoundTrip) IsWin() bool {
+	return rt.NetPnL > 0
+}
+
+// BuildRoundTrips 根据卖出记录中的批次明细（LotFills，卖出时按先进先出扣减批次得到）生成完整交易台账
+func BuildRoundTrips(records []TradeRecord) []RoundTrip {
+	trips := make([]RoundTrip, 0)
+	for _, record := range records {
+		if record.Action != "sell" || record.StockNum <= 0 {
+			continue
+		}
+
+		for _, fill := range record.LotFills {
+			// 按数量比例分摊卖出手续费
+			exitFee := record.TotalFee * float64(fill.StockNum) / float64(record.StockNum)
+			grossPnL := (record.Price - fill.BuyPrice) * float64(fill.StockNum)
+			netPnL := grossPnL - fill.BuyFee - exitFee
+			entryCost := fill.BuyPrice*float64(fill.StockNum) + fill.BuyFee
+
+			trip := RoundTrip{
+				Code:       record.Code,
+				Name:       record.Name,
+				EntryDate:  fill.BuyDate,
+				ExitDate:   record.Date,
+				EntryPrice: fill.BuyPrice,
+				ExitPrice:  record.Price,
+				StockNum:   fill.StockNum,
+				HoldDays:   fill.HoldDays,
+				EntryFee:   fill.BuyFee,
+				ExitFee:    exitFee,
+				GrossPnL:   grossPnL,
+				NetPnL:     netPnL,
+				ExitReason: record.Reason,
+				IsRotation: record.IsRotation,
+			}
+			if entryCost > 0 {
+				trip.ReturnPct = netPnL / entryCost * 100
+			}
+			trips = append(trips, trip)
+		}
+	}
+
+	return trips
+}
+
+// ClosedTrade 一笔已平仓的交易：从建仓到全部卖出，期间的加仓和分批卖出合并为一笔
+// 胜率、交易笔数等统计按平仓交易计算，与凯利仓位使用的已完成交易一致
+type ClosedTrade struct {
+	Code        string  // 票票代码
+	Name        string  // 票票名称
+	EntryDate   string  // 建仓日期
+	ExitDate    string  // 全部卖出的日期
+	BuyCount    int     // 买入次数（含加仓）
+	SellCount   int     // 卖出次数
+	EntryAmount float64 // 买入金额合计（不含手续费）
+	ExitAmount  float64 // 卖出金额合计（不含手续费）
+	Cost        float64 // 成本（买入金额+买入手续费）
+	NetPnL      float64 // 净盈亏（各次卖出的已实现盈亏之和）
+	ReturnPct   float64 // 净收益率（净盈亏/成本）
+	HoldDays    int     // 建仓到全部卖出的交易日数
+	ExitReason  string  // 最后一次卖出的原因
+}
+
+// IsWin 是否盈利（按净盈亏）
+func (ct ClosedTrade) IsWin() bool {
+	return ct.NetPnL > 0
+}
+
+// BuildClosedTrades 根据交易记录按持仓合并为平仓交易：持仓数量归零时完成一笔，期末未平仓的持仓不计入
+// 盈亏使用卖出记录中的已实现盈亏（RealizedPnL）和成本（CostBasis）
+// tradingDays 为按日期排序的交易日列表，用于计算持有交易日数；为空时持有天数为0
+func BuildClosedTrades(records []TradeRecord, tradingDays []string) []ClosedTrade {
+	dayIndex := make(map[string]int, len(tradingDays))
+	for i, date := range tradingDays {
+		dayIndex[date] = i
+	}
+
+	holding := make(map[string]int)
+	open := make(map[string]*ClosedTrade)
+	trades := make([]ClosedTrade, 0)
+
+	for _, record := range records {
+		if record.StockNum <= 0 {
+			continue
+		}
+
+		switch record.Action {
+		case "buy":
+			trade := open[record.Code]
+			if trade == nil {
+				trade = &ClosedTrade{Code: record.Code, Name: record.Name, EntryDate: record.Date}
+				open[record.Code] = trade
+			}
+			trade.BuyCount++
+			trade.EntryAmount += record.Price * float64(record.StockNum)
+			holding[record.Code] += record.StockNum
+		case "sell":
+			trade := open[record.Code]
+			if trade == nil {
+				continue
+			}
+			trade.SellCount++
+			trade.ExitAmount += record.Price * float64(record.StockNum)
+			trade.Cost += record.CostBasis
+			trade.NetPnL += record.RealizedPnL
+			trade.ExitDate = record.Date
+			trade.ExitReason = record.Reason
+			holding[record.Code] -= record.StockNum
+			if holding[record.Code] > 0 {
+				continue
+			}
+
+			if trade.Cost > 0 {
+				trade.ReturnPct = trade.NetPnL / trade.Cost * 100
+			}
+			entryIdx, ok1 := dayIndex[trade.EntryDate]
+			exitIdx, ok2 := dayIndex[trade.ExitDate]
+			if ok1 && ok2 {
+				trade.HoldDays = exitIdx - entryIdx
+			}
+			trades = append(trades, *trade)
+			delete(open, record.Code)
+			delete(holding, record.Code)
+		}
+	}
+
+	return trades
+}
+
+// TradeLedgerStats 平仓交易的统计
+type TradeLedgerStats struct {
+	TotalTrades int     // 平仓交易笔数
+	WinCount    int     // 盈利笔数
+	LoseCount   int     // 亏损笔数（含持平）
+	WinRate     float64 // 胜率（百分比）
+	AvgWin      float64 // 平均盈利金额（净）
+	AvgLoss     float64 // 平均亏损金额（净，负数）
+	AvgWinPct   float64 // 平均盈利收益率（百分比）
+	AvgLossPct  float64 // 平均亏损收益率（百分比，负数）
+	AvgHoldDays float64 // 平均持有交易日数
+	NetPnL      float64 // 净盈亏合计
+}
+
+// SummarizeClosedTrades 统计平仓交易
+func SummarizeClosedTrades(trades []ClosedTrade) TradeLedgerStats {
+	stats := TradeLedgerStats{TotalTrades: len(trades)}
+	if len(trades) == 0 {
+		return stats
+	}
+
+	totalHoldDays := 0
+	for _, trade := range trades {
+		stats.NetPnL += trade.NetPnL
+		if trade.IsWin() {
+			stats.WinCount++
+			stats.AvgWin += trade.NetPnL
+			stats.AvgWinPct += trade.ReturnPct
+		} else {
+			stats.LoseCount++
+			stats.AvgLoss += trade.NetPnL
+			stats.AvgLossPct += trade.ReturnPct
+		}
+		totalHoldDays += trade.HoldDays
+	}
+
+	if stats.WinCount > 0 {
+		stats.AvgWin /= float64(stats.WinCount)
+		stats.AvgWinPct /= float64(stats.WinCount)
+	}
+	if stats.LoseCount > 0 {
+		stats.AvgLoss /= float64(stats.LoseCount)
+		stats.AvgLossPct /= float64(stats.LoseCount)
+	}
+	stats.WinRate = float64(stats.WinCount) / float64(stats.TotalTrades) * 100
+	stats.AvgHoldDays = float64(totalHoldDays) / float64(stats.TotalTrades)
+
+	return stats
+}
+
+// PerformanceTrades 把平仓交易转换为绩效分析使用的交易
+func PerformanceTrades(trades []ClosedTrade) []performance.Trade {
+	result := make([]performance.Trade, 0, len(trades))
+	for _, trade := range trades {
+		result = append(result, performance.Trade{
+			EntryDate:   trade.EntryDate,
+			ExitDate:    trade.ExitDate,
+			EntryAmount: trade.EntryAmount,
+			ExitAmount:  trade.ExitAmount,
+			NetPnL:      trade.NetPnL,
+			ReturnPct:   trade.ReturnPct,
+			HoldDays:    trade.HoldDays,
+		})
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+package tradeTest
+
+import (
+	"math"
+	"testing"
+)
+
+// testLedger 按引擎的方式记录买卖：买入形成批次，卖出按先进先出扣减批次并记录批次明细和已实现盈亏
+type testLedger struct {
+	tradingDays []string
+	positions   map[string]*PositionState
+	records     []TradeRecord
+}
+
+func newTestLedger(tradingDays []string) *testLedger {
+	return &testLedger{tradingDays: tradingDays, positions: make(map[string]*PositionState)}
+}
+
+func (l *testLedger) dayIndex(date string) int {
+	for i, day := range l.tradingDays {
+		if day == date {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *testLedger) buy(code, date string, price float64, num int, fee float64) {
+	pos := l.positions[code]
+	if pos == nil {
+		pos = &PositionState{Code: code}
+		l.positions[code] = pos
+	}
+	pos.addLot(&PositionLot{BuyDate: date, BuyIndex: l.dayIndex(date), BuyPrice: price, StockNum: num, BuyFee: fee})
+	l.records = append(l.records, TradeRecord{Code: code, Action: "buy", Date: date, Price: price, StockNum: num, TotalFee: fee})
+}
+
+func (l *testLedger) sell(code, date string, price float64, num int, fee float64, reason string) {
+	pos := l.positions[code]
+	for _, lot := range pos.Lots {
+		lot.HoldDays = l.dayIndex(date) - lot.BuyIndex
+	}
+	fills, costBasis := pos.removeShares(num)
+	l.records = append(l.records, TradeRecord{
+		Code: code, Action: "sell", Date: date, Price: price, StockNum: num, TotalFee: fee, Reason: reason,
+		LotFills: fills, CostBasis: costBasis, RealizedPnL: price*float64(num) - fee - costBasis,
+	})
+	if pos.StockNum == 0 {
+		delete(l.positions, code)
+	}
+}
+
+// TestBuildRoundTripsFIFO 测试台账按卖出时的批次明细拆分，统计按持仓合并
+func TestBuildRoundTripsFIFO(t *testing.T) {
+	ledger := newTestLedger([]string{"2020-01-02", "2020-01-03", "2020-01-06", "2020-01-07", "2020-01-08", "2020-01-09", "2020-01-10"})
+	ledger.buy("sz.000001", "2020-01-02", 10, 1000, 10)
+	ledger.buy("sz.000001", "2020-01-03", 12, 1000, 12)
+	// 卖出1500股：先卖完第一批，再卖第二批的一半
+	ledger.sell("sz.000001", "2020-01-06", 11, 1500, 15, "止损")
+	ledger.sell("sz.000001", "2020-01-08", 13, 500, 5, "止盈")
+	// 清仓后重新建仓是另一笔交易
+	ledger.buy("sz.000001", "2020-01-09", 10, 1000, 10)
+	ledger.sell("sz.000001", "2020-01-10", 9, 1000, 10, "止损")
+
+	trips := BuildRoundTrips(ledger.records)
+	if len(trips) != 4 {
+		t.Fatalf("完整交易台账 %d 条，期望 4", len(trips))
+	}
+
+	expected := []struct {
+		entryPrice float64
+		stockNum   int
+		holdDays   int
+		netPnL     float64
+		reason     string
+	}{
+		{10, 1000, 2, 1000 - 10 - 10, "止损"},
+		{12, 500, 1, -500 - 6 - 5, "止损"},
+		{12, 500, 3, 500 - 6 - 5, "止盈"},
+		{10, 1000, 1, -1000 - 10 - 10, "止损"},
+	}
+	for i, want := range expected {
+		trip := trips[i]
+		if trip.EntryPrice != want.entryPrice || trip.StockNum != want.stockNum {
+			t.Errorf("第%d笔配对错误: 买入价 %.2f 数量 %d", i+1, trip.EntryPrice, trip.StockNum)
+		}
+		if trip.HoldDays != want.holdDays {
+			t.Errorf("第%d笔持有天数 %d，期望 %d", i+1, trip.HoldDays, want.holdDays)
+		}
+		if math.Abs(trip.NetPnL-want.netPnL) > 1e-9 {
+			t.Errorf("第%d笔净盈亏 %.4f，期望 %.4f", i+1, trip.NetPnL, want.netPnL)
+		}
+		if trip.ExitReason != want.reason {
+			t.Errorf("第%d笔卖出原因 %s，期望 %s", i+1, trip.ExitReason, want.reason)
+		}
+	}
+
+	// 加仓和分批卖出合并为一笔平仓交易，与台账的净盈亏合计一致
+	trades := BuildClosedTrades(ledger.records, ledger.tradingDays)
+	if len(trades) != 2 {
+		t.Fatalf("平仓交易 %d 笔，期望 2", len(trades))
+	}
+	first := trades[0]
+	if first.EntryDate != "2020-01-02" || first.ExitDate != "2020-01-08" || first.HoldDays != 4 ||
+		first.BuyCount != 2 || first.SellCount != 2 || first.ExitReason != "止盈" {
+		t.Errorf("第一笔平仓交易 %+v 不正确", first)
+	}
+	if math.Abs(first.NetPnL-958) > 1e-9 || math.Abs(first.Cost-22022) > 1e-9 || math.Abs(first.ReturnPct-958.0/22022*100) > 1e-9 {
+		t.Errorf("第一笔净盈亏 %.4f 成本 %.4f 收益率 %.4f", first.NetPnL, first.Cost, first.ReturnPct)
+	}
+
+	stats := SummarizeClosedTrades(trades)
+	if stats.TotalTrades != 2 || stats.WinCount != 1 || stats.LoseCount != 1 {
+		t.Fatalf("统计应按持仓计算: %+v", stats)
+	}
+	tripPnL := 0.0
+	for _, trip := range trips {
+		tripPnL += trip.NetPnL
+	}
+	if math.Abs(stats.NetPnL-tripPnL) > 1e-9 {
+		t.Errorf("平仓交易净盈亏 %.4f，台账合计 %.4f", stats.NetPnL, tripPnL)
+	}
+}
+
+// TestBuildClosedTradesOpenPosition 测试期末未平仓的持仓不计入平仓交易
+func TestBuildClosedTradesOpenPosition(t *testing.T) {
+	ledger := newTestLedger([]string{"2020-01-02", "2020-01-03"})
+	ledger.buy("sz.000001", "2020-01-02", 10, 1000, 10)
+	ledger.sell("sz.000001", "2020-01-03", 11, 500, 5, "止盈")
+
+	if trips := BuildRoundTrips(ledger.records); len(trips) != 1 {
+		t.Errorf("部分卖出的台账 %d 条，期望 1", len(trips))
+	}
+	if trades := BuildClosedTrades(ledger.records, ledger.tradingDays); len(trades) != 0 {
+		t.Errorf("未平仓的持仓不应计入: %+v", trades)
+	}
+}
+
+// TestSummarizeClosedTradesNetOfFees 测试胜负按扣除手续费后的净盈亏判断
+func TestSummarizeClosedTradesNetOfFees(t *testing.T) {
+	ledger := newTestLedger([]string{"2020-01-02", "2020-01-03", "2020-01-06", "2020-01-07"})
+	// 毛盈利10元，但手续费20元，净亏损
+	ledger.buy("sz.000001", "2020-01-02", 10.00, 1000, 10)
+	ledger.sell("sz.000001", "2020-01-03", 10.01, 1000, 10, "止盈")
+	// 净盈利
+	ledger.buy("sz.000002", "2020-01-02", 10, 1000, 10)
+	ledger.sell("sz.000002", "2020-01-07", 11, 1000, 10, "止盈")
+
+	stats := SummarizeClosedTrades(BuildClosedTrades(ledger.records, ledger.tradingDays))
+	if stats.TotalTrades != 2 || stats.WinCount != 1 || stats.LoseCount != 1 {
+		t.Fatalf("统计错误: %+v", stats)
+	}
+	if math.Abs(stats.WinRate-50) > 1e-9 {
+		t.Errorf("胜率 %.2f，期望 50", stats.WinRate)
+	}
+	if math.Abs(stats.AvgWin-980) > 1e-6 {
+		t.Errorf("平均盈利 %.4f，期望 980", stats.AvgWin)
+	}
+	if math.Abs(stats.AvgLoss-(-10)) > 1e-6 {
+		t.Errorf("平均亏损 %.4f，期望 -10", stats.AvgLoss)
+	}
+	if math.Abs(stats.AvgHoldDays-2) > 1e-9 {
+		t.Errorf("平均持有天数 %.2f，期望 2", stats.AvgHoldDays)
+	}
+}
@@ -15,6 +15,7 @@ func CostTime(start time.Time) {
 
 // DoWorkEveryDayOnce 每天在指定时间执行一次任务
 // executeTime 参数格式为 "HH:MM"，如 "19:00"，当为 nil 时默认为 "19:00"
+// 新代码请使用 scheduler 包（交易日历、任务依赖、失败重试、补跑和运行记录）
 func DoWorkEveryDayOnce(f func(), executeTime *string) bool {
 	// 设置默认执行时间
 	defaultTime := "19:00"